@@ -166,3 +166,162 @@ Bar[42] xfa
 		t.Errorf("Failed to generate message: %v", err)
 	}
 }
+
+func TestGenerateProto(t *testing.T) {
+	const text string = `
+uint8 U8 = 255
+Header header
+int8 i8
+uint16 u16
+float32 f32
+string[] sva
+uint8[4] ufa
+time t
+duration[] dva
+geometry_msgs/Point[3] pfa
+Bar x
+`
+	ctx, e := libgengo.NewPkgContext(nil)
+	if e != nil {
+		t.Fatalf("Failed to create MsgContext.")
+	}
+	spec, e := ctx.LoadMsgFromString(text, "foo/Foo")
+	if e != nil {
+		t.Fatalf("Failed to parse: %v", e)
+	}
+
+	code, err := libgengo.GenerateProto(ctx, spec)
+	if err != nil {
+		t.Fatalf("Failed to generate proto: %v", err)
+	}
+	const expected string = `// Automatically generated from the message definition "foo/Foo.msg"
+syntax = "proto3";
+
+package foo;
+
+import "std_msgs/Header.proto";
+import "google/protobuf/timestamp.proto";
+import "google/protobuf/duration.proto";
+import "geometry_msgs/Point.proto";
+import "foo/Bar.proto";
+
+message Foo {
+  // uint8 U8 = 255
+  std_msgs.Header header = 1;
+  int32 i8 = 2;
+  uint32 u16 = 3;
+  float f32 = 4;
+  repeated string sva = 5;
+  bytes ufa = 6; // 4 elements
+  google.protobuf.Timestamp t = 7;
+  repeated google.protobuf.Duration dva = 8;
+  repeated geometry_msgs.Point pfa = 9; // 3 elements
+  foo.Bar x = 10;
+}
+`
+	assertEqual(t, code, expected)
+
+	srvSpec, e := ctx.LoadSrvFromString("int64 a\n---\nint64 sum", "foo/Add")
+	if e != nil {
+		t.Fatalf("Failed to parse: %v", e)
+	}
+	srvCode, reqCode, _, err := libgengo.GenerateProtoService(ctx, srvSpec)
+	if err != nil {
+		t.Fatalf("Failed to generate proto service: %v", err)
+	}
+	if !strings.Contains(srvCode, "import \"foo/AddRequest.proto\";") || !strings.Contains(srvCode, "rpc Call(AddRequest) returns (AddResponse);") {
+		t.Errorf("Unexpected service:\n%s", srvCode)
+	}
+	if !strings.Contains(reqCode, "message AddRequest {\n  int64 a = 1;\n}") {
+		t.Errorf("Unexpected request:\n%s", reqCode)
+	}
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	const text string = `
+uint8 U8 = 255
+int64 I64 = -9223372036854775808
+string S = Lorem "Ipsum"
+bool B = 1
+Header header
+int8 i8
+float32 f32
+string[] sva
+uint8[4] ufa
+time t
+duration[] dva
+float64[3] dfa
+geometry_msgs/Point[] pva
+Bar x
+`
+	ctx, e := libgengo.NewPkgContext(nil)
+	if e != nil {
+		t.Fatalf("Failed to create MsgContext.")
+	}
+	spec, e := ctx.LoadMsgFromString(text, "foo/Foo")
+	if e != nil {
+		t.Fatalf("Failed to parse: %v", e)
+	}
+
+	code, err := libgengo.GenerateTypeScript(ctx, spec)
+	if err != nil {
+		t.Fatalf("Failed to generate TypeScript: %v", err)
+	}
+	const expected string = `// Automatically generated from the message definition "foo/Foo.msg"
+import { Header as std_msgs_Header } from "../std_msgs/Header";
+import { Point as geometry_msgs_Point } from "../geometry_msgs/Point";
+import { Bar } from "./Bar";
+
+export const enum FooConstants {
+  U8 = 255,
+  I64 = -9223372036854775808,
+  S = "Lorem \"Ipsum\"",
+}
+
+export const Foo_B = true;
+
+export interface Foo {
+  header: std_msgs_Header;
+  i8: number;
+  f32: number | "nan" | "+inf" | "-inf";
+  sva: string[];
+  ufa: string;
+  t: { sec: number; nsec: number };
+  dva: { sec: number; nsec: number }[];
+  dfa: (number | "nan" | "+inf" | "-inf")[]; // 3 elements
+  pva: geometry_msgs_Point[];
+  x: Bar;
+}
+`
+	assertEqual(t, code, expected)
+
+	actionSpec, e := ctx.LoadActionFromString("int32 g\n---\nint32 r\n---\nint32 f", "foo/Count")
+	if e != nil {
+		t.Fatalf("Failed to parse: %v", e)
+	}
+	actionCode, codeMap, err := libgengo.GenerateTypeScriptAction(ctx, actionSpec)
+	if err != nil {
+		t.Fatalf("Failed to generate TypeScript action: %v", err)
+	}
+	if !strings.Contains(actionCode, "  goal: CountActionGoal;\n  feedback: CountActionFeedback;\n  result: CountActionResult;\n") {
+		t.Errorf("Unexpected action:\n%s", actionCode)
+	}
+	if len(codeMap) != 6 || !strings.Contains(codeMap["foo/CountActionGoal"], "import { CountGoal } from \"./CountGoal\";") {
+		t.Errorf("Unexpected action messages: %v", codeMap)
+	}
+
+	srvSpec, e := ctx.LoadSrvFromString("int64 a\n---\nint64 sum", "foo/Add")
+	if e != nil {
+		t.Fatalf("Failed to parse: %v", e)
+	}
+	srvCode, _, resCode, err := libgengo.GenerateTypeScriptService(ctx, srvSpec)
+	if err != nil {
+		t.Fatalf("Failed to generate TypeScript service: %v", err)
+	}
+	if !strings.Contains(srvCode, "  request: AddRequest;\n  response: AddResponse;\n") {
+		t.Errorf("Unexpected service:\n%s", srvCode)
+	}
+	if !strings.Contains(resCode, "export interface AddResponse {\n  sum: number;\n}") {
+		t.Errorf("Unexpected response:\n%s", resCode)
+	}
+}
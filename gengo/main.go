@@ -36,6 +36,126 @@ func writeCode(fullname string, code string) error {
 	return ioutil.WriteFile(filename, res, os.FileMode(0664))
 }
 
+func writeFile(filename string, code string) error {
+	filename = filepath.Join(*out, filename)
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModeDir|os.FileMode(0775)); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, []byte(code), os.FileMode(0664))
+}
+
+func generateProto(context *libgengo.PkgContext, mode string, fullname string, file string) error {
+	switch mode {
+	case "msg":
+		var spec *libgengo.MsgSpec
+		var err error
+		if file == "" {
+			spec, err = context.LoadMsg(fullname)
+		} else {
+			spec, err = context.LoadMsgFromFile(file, fullname)
+		}
+		if err != nil {
+			return err
+		}
+		code, err := libgengo.GenerateProto(context, spec)
+		if err != nil {
+			return err
+		}
+		return writeFile(libgengo.ProtoFileName(fullname), code)
+	case "srv":
+		var spec *libgengo.SrvSpec
+		var err error
+		if file == "" {
+			spec, err = context.LoadSrv(fullname)
+		} else {
+			spec, err = context.LoadSrvFromFile(file, fullname)
+		}
+		if err != nil {
+			return err
+		}
+		srvCode, reqCode, resCode, err := libgengo.GenerateProtoService(context, spec)
+		if err != nil {
+			return err
+		}
+		if err = writeFile(libgengo.ProtoFileName(fullname), srvCode); err != nil {
+			return err
+		}
+		if err = writeFile(libgengo.ProtoFileName(spec.Request.FullName), reqCode); err != nil {
+			return err
+		}
+		return writeFile(libgengo.ProtoFileName(spec.Response.FullName), resCode)
+	}
+	return fmt.Errorf("unsupported proto mode %s", mode)
+}
+
+func generateTypeScript(context *libgengo.PkgContext, mode string, fullname string, file string) error {
+	switch mode {
+	case "msg":
+		var spec *libgengo.MsgSpec
+		var err error
+		if file == "" {
+			spec, err = context.LoadMsg(fullname)
+		} else {
+			spec, err = context.LoadMsgFromFile(file, fullname)
+		}
+		if err != nil {
+			return err
+		}
+		code, err := libgengo.GenerateTypeScript(context, spec)
+		if err != nil {
+			return err
+		}
+		return writeFile(libgengo.TypeScriptFileName(fullname), code)
+	case "srv":
+		var spec *libgengo.SrvSpec
+		var err error
+		if file == "" {
+			spec, err = context.LoadSrv(fullname)
+		} else {
+			spec, err = context.LoadSrvFromFile(file, fullname)
+		}
+		if err != nil {
+			return err
+		}
+		srvCode, reqCode, resCode, err := libgengo.GenerateTypeScriptService(context, spec)
+		if err != nil {
+			return err
+		}
+		if err = writeFile(libgengo.TypeScriptFileName(fullname), srvCode); err != nil {
+			return err
+		}
+		if err = writeFile(libgengo.TypeScriptFileName(spec.Request.FullName), reqCode); err != nil {
+			return err
+		}
+		return writeFile(libgengo.TypeScriptFileName(spec.Response.FullName), resCode)
+	case "action":
+		var spec *libgengo.ActionSpec
+		var err error
+		if file == "" {
+			spec, err = context.LoadAction(fullname)
+		} else {
+			spec, err = context.LoadActionFromFile(file, fullname)
+		}
+		if err != nil {
+			return err
+		}
+		actionCode, codeMap, err := libgengo.GenerateTypeScriptAction(context, spec)
+		if err != nil {
+			return err
+		}
+		if err = writeFile(libgengo.TypeScriptFileName(fullname), actionCode); err != nil {
+			return err
+		}
+		for name, code := range codeMap {
+			if err = writeFile(libgengo.TypeScriptFileName(name), code); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported ts mode %s", mode)
+}
+
 func main() {
 	flag.Parse()
 	if _, err := os.Stat(*out); os.IsNotExist(err) {
@@ -48,6 +168,8 @@ func main() {
 
 	if flag.NArg() < 2 {
 		fmt.Println("USAGE: gengo [-out=] [-import_path=] msg|srv|action <NAME> [<FILE>]")
+		fmt.Println("       gengo [-out=] proto msg|srv <NAME> [<FILE>]")
+		fmt.Println("       gengo [-out=] ts msg|srv|action <NAME> [<FILE>]")
 		os.Exit(-1)
 	}
 
@@ -61,6 +183,26 @@ func main() {
 	mode := flag.Arg(0)
 	fullname := flag.Arg(1)
 
+	if mode == "proto" || mode == "ts" {
+		if flag.NArg() < 3 {
+			fmt.Println("USAGE: gengo [-out=] proto msg|srv <NAME> [<FILE>]")
+			fmt.Println("       gengo [-out=] ts msg|srv|action <NAME> [<FILE>]")
+			os.Exit(-1)
+		}
+		fmt.Printf("Generating %v...", flag.Arg(2))
+		if mode == "proto" {
+			err = generateProto(context, flag.Arg(1), flag.Arg(2), flag.Arg(3))
+		} else {
+			err = generateTypeScript(context, flag.Arg(1), flag.Arg(2), flag.Arg(3))
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(-1)
+		}
+		fmt.Println("Done")
+		return
+	}
+
 	fmt.Printf("Generating %v...", fullname)
 
 	if mode == "msg" {
@@ -89,6 +89,12 @@ func FindAllActions(rosPkgPaths []string) (map[string]string, error) {
 	return findPackages("action", rosPkgPaths)
 }
 
+// definitionText is the text of a definition added to a context, which is parsed when it is first loaded.
+type definitionText struct {
+	text     string
+	fallback bool // Fallback definitions are only used when no definition file is found.
+}
+
 type PkgContext struct {
 	msgPathMap      map[string]string
 	msgRegistry     map[string]*MsgSpec
@@ -101,6 +107,11 @@ type PkgContext struct {
 	actionPathMap   map[string]string
 	actRegistry     map[string]*ActionSpec
 	actRegistryLock sync.RWMutex
+
+	msgTextMap    map[string]definitionText
+	srvTextMap    map[string]definitionText
+	actionTextMap map[string]definitionText
+	textMapLock   sync.RWMutex
 }
 
 func NewPkgContext(rosPkgPaths []string) (*PkgContext, error) {
@@ -126,9 +137,45 @@ func NewPkgContext(rosPkgPaths []string) (*PkgContext, error) {
 	ctx.msgRegistry = make(map[string]*MsgSpec)
 	ctx.srvRegistry = make(map[string]*SrvSpec)
 	ctx.actRegistry = make(map[string]*ActionSpec)
+
+	ctx.msgTextMap = make(map[string]definitionText)
+	ctx.srvTextMap = make(map[string]definitionText)
+	ctx.actionTextMap = make(map[string]definitionText)
 	return ctx, nil
 }
 
+// AddMsgText adds the text of a message definition, which is parsed when the message is first loaded.  The text takes precedence over definition files found on the package
+// paths, unless it is a fallback, in which case it's only used if no definition file is found.
+func (ctx *PkgContext) AddMsgText(fullname string, text string, fallback bool) {
+	ctx.textMapLock.Lock()
+	ctx.msgTextMap[fullname] = definitionText{text, fallback}
+	ctx.textMapLock.Unlock()
+}
+
+// AddSrvText adds the text of a service definition, in the same way as AddMsgText.
+func (ctx *PkgContext) AddSrvText(fullname string, text string, fallback bool) {
+	ctx.textMapLock.Lock()
+	ctx.srvTextMap[fullname] = definitionText{text, fallback}
+	ctx.textMapLock.Unlock()
+}
+
+// AddActionText adds the text of an action definition, in the same way as AddMsgText.
+func (ctx *PkgContext) AddActionText(fullname string, text string, fallback bool) {
+	ctx.textMapLock.Lock()
+	ctx.actionTextMap[fullname] = definitionText{text, fallback}
+	ctx.textMapLock.Unlock()
+}
+
+func (ctx *PkgContext) lookupText(textMap map[string]definitionText, fullname string, fallback bool) (string, bool) {
+	ctx.textMapLock.RLock()
+	definition, ok := textMap[fullname]
+	ctx.textMapLock.RUnlock()
+	if !ok || definition.fallback != fallback {
+		return "", false
+	}
+	return definition.text, true
+}
+
 func (ctx *PkgContext) RegisterMsg(fullname string, spec *MsgSpec) {
 	ctx.msgRegistryLock.Lock()
 	ctx.msgRegistry[fullname] = spec
@@ -201,6 +248,9 @@ func (ctx *PkgContext) LoadMsg(fullname string) (*MsgSpec, error) {
 		return spec, nil
 	} else {
 		ctx.msgRegistryLock.RUnlock()
+		if text, ok := ctx.lookupText(ctx.msgTextMap, fullname, false); ok {
+			return ctx.LoadMsgFromString(text, fullname)
+		}
 		if path, ok := ctx.msgPathMap[fullname]; ok {
 			spec, err := ctx.LoadMsgFromFile(path, fullname)
 			if err != nil {
@@ -211,6 +261,8 @@ func (ctx *PkgContext) LoadMsg(fullname string) (*MsgSpec, error) {
 				ctx.msgRegistryLock.Unlock()
 				return spec, nil
 			}
+		} else if text, ok := ctx.lookupText(ctx.msgTextMap, fullname, true); ok {
+			return ctx.LoadMsgFromString(text, fullname)
 		} else {
 			return nil, fmt.Errorf("Message definition of `%s` is not found", fullname)
 		}
@@ -262,6 +314,15 @@ func (ctx *PkgContext) LoadSrvFromFile(filePath string, fullname string) (*SrvSp
 }
 
 func (ctx *PkgContext) LoadSrv(fullname string) (*SrvSpec, error) {
+	ctx.srvRegistryLock.RLock()
+	spec, ok := ctx.srvRegistry[fullname]
+	ctx.srvRegistryLock.RUnlock()
+	if ok {
+		return spec, nil
+	}
+	if text, ok := ctx.lookupText(ctx.srvTextMap, fullname, false); ok {
+		return ctx.LoadSrvFromString(text, fullname)
+	}
 	if path, ok := ctx.srvPathMap[fullname]; ok {
 		spec, err := ctx.LoadSrvFromFile(path, fullname)
 		if err != nil {
@@ -269,6 +330,8 @@ func (ctx *PkgContext) LoadSrv(fullname string) (*SrvSpec, error) {
 		} else {
 			return spec, nil
 		}
+	} else if text, ok := ctx.lookupText(ctx.srvTextMap, fullname, true); ok {
+		return ctx.LoadSrvFromString(text, fullname)
 	} else {
 		return nil, fmt.Errorf("Service definition of `%s` is not found", fullname)
 	}
@@ -354,6 +417,9 @@ func (ctx *PkgContext) LoadAction(fullname string) (*ActionSpec, error) {
 		return spec, nil
 	} else {
 		ctx.actRegistryLock.RUnlock()
+		if text, ok := ctx.lookupText(ctx.actionTextMap, fullname, false); ok {
+			return ctx.LoadActionFromString(text, fullname)
+		}
 		if path, ok := ctx.actionPathMap[fullname]; ok {
 			spec, err := ctx.LoadActionFromFile(path, fullname)
 			if err != nil {
@@ -364,6 +430,8 @@ func (ctx *PkgContext) LoadAction(fullname string) (*ActionSpec, error) {
 				ctx.actRegistryLock.Unlock()
 				return spec, nil
 			}
+		} else if text, ok := ctx.lookupText(ctx.actionTextMap, fullname, true); ok {
+			return ctx.LoadActionFromString(text, fullname)
 		} else {
 			return nil, fmt.Errorf("Action definition of `%s` is not found", fullname)
 		}
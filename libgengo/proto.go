@@ -0,0 +1,187 @@
+package libgengo
+
+import (
+	"bytes"
+	"text/template"
+)
+
+const (
+	ProtoTimestampType   = "google.protobuf.Timestamp"
+	ProtoDurationType    = "google.protobuf.Duration"
+	ProtoTimestampImport = "google/protobuf/timestamp.proto"
+	ProtoDurationImport  = "google/protobuf/duration.proto"
+)
+
+var protoMsgTemplate = `// Automatically generated from the message definition "{{ .FullName }}.msg"
+syntax = "proto3";
+
+package {{ .Package }};
+{{ if .Imports }}
+{{- range .Imports }}
+import "{{ . }}";
+{{- end }}
+{{ end }}
+message {{ .ShortName }} {
+{{- range .Constants }}
+  // {{ .Type }} {{ .Name }} = {{ .ValueText }}
+{{- end }}
+{{- range .ProtoFields }}
+  {{ if .Repeated }}repeated {{ end }}{{ .Type }} {{ .Name }} = {{ .Number }};{{ if ge .ArrayLen 0 }} // {{ .ArrayLen }} elements{{ end }}
+{{- end }}
+}
+`
+
+var protoSrvTemplate = `// Automatically generated from the service definition "{{ .FullName }}.srv"
+syntax = "proto3";
+
+package {{ .Package }};
+
+import "{{ .Package }}/{{ .ShortName }}Request.proto";
+import "{{ .Package }}/{{ .ShortName }}Response.proto";
+
+service {{ .ShortName }} {
+  rpc Call({{ .ShortName }}Request) returns ({{ .ShortName }}Response);
+}
+`
+
+// ProtoField is a field of a message as declared in a .proto file.
+type ProtoField struct {
+	Name     string
+	Type     string
+	Number   int
+	Repeated bool
+	ArrayLen int
+}
+
+type ProtoGen struct {
+	MsgSpec
+	Imports     []string
+	ProtoFields []ProtoField
+}
+
+// ToProtoType returns the protobuf type of a field, or of each element of an array field.  Integers smaller than 32 bits are widened, time and duration are the well known
+// Timestamp and Duration types, nested messages are referred to by package and name, and arrays of uint8 are bytes.
+func ToProtoType(field *Field) string {
+	if field.IsArray && field.BuiltInType == Uint8 {
+		return "bytes"
+	}
+	switch field.BuiltInType {
+	case Bool:
+		return "bool"
+	case Int8, Int16, Int32:
+		return "int32"
+	case Int64:
+		return "int64"
+	case Uint8, Uint16, Uint32:
+		return "uint32"
+	case Uint64:
+		return "uint64"
+	case Float32:
+		return "float"
+	case Float64:
+		return "double"
+	case String:
+		return "string"
+	case Time:
+		return ProtoTimestampType
+	case Duration:
+		return ProtoDurationType
+	}
+	return field.Package + "." + field.Type
+}
+
+// IsProtoRepeated reports whether a field is a repeated field in protobuf; all arrays are, apart from arrays of uint8.
+func IsProtoRepeated(field *Field) bool {
+	return field.IsArray && field.BuiltInType != Uint8
+}
+
+// ProtoFieldNumber returns the protobuf field number of the field at index in the message's fields; fields are numbered in definition order.
+func ProtoFieldNumber(index int) int {
+	return index + 1
+}
+
+// ProtoFileName returns the path of the .proto file holding the message or service, relative to the root of the generated files.
+func ProtoFileName(fullname string) string {
+	return fullname + ".proto"
+}
+
+func (gen *ProtoGen) analyzeImports() {
+	seen := make(map[string]bool)
+	for i, field := range gen.Fields {
+		protoField := ProtoField{
+			Name:     field.Name,
+			Type:     ToProtoType(&gen.Fields[i]),
+			Number:   ProtoFieldNumber(i),
+			Repeated: IsProtoRepeated(&gen.Fields[i]),
+			ArrayLen: -1,
+		}
+		if field.IsArray {
+			protoField.ArrayLen = field.ArrayLen
+		}
+		gen.ProtoFields = append(gen.ProtoFields, protoField)
+
+		var imp string
+		switch {
+		case field.BuiltInType == Time:
+			imp = ProtoTimestampImport
+		case field.BuiltInType == Duration:
+			imp = ProtoDurationImport
+		case !field.IsBuiltin:
+			imp = ProtoFileName(field.Package + Sep + field.Type)
+		default:
+			continue
+		}
+		if !seen[imp] {
+			seen[imp] = true
+			gen.Imports = append(gen.Imports, imp)
+		}
+	}
+}
+
+// GenerateProto generates a proto3 .proto file declaring the message, importing the files of the nested messages it uses.
+func GenerateProto(context *PkgContext, spec *MsgSpec) (string, error) {
+	var gen ProtoGen
+	gen.Fields = spec.Fields
+	gen.Constants = spec.Constants
+	gen.FullName = spec.FullName
+	gen.ShortName = spec.ShortName
+	gen.Package = spec.Package
+
+	gen.analyzeImports()
+
+	tmpl, err := template.New("proto").Parse(protoMsgTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, gen)
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// GenerateProtoService generates the .proto files of a service and its request and response messages.  The service has a single method, Call.
+func GenerateProtoService(context *PkgContext, spec *SrvSpec) (string, string, string, error) {
+	reqCode, err := GenerateProto(context, spec.Request)
+	if err != nil {
+		return "", "", "", err
+	}
+	resCode, err := GenerateProto(context, spec.Response)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	tmpl, err := template.New("protoSrv").Parse(protoSrvTemplate)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, spec)
+	if err != nil {
+		return "", "", "", err
+	}
+	return buffer.String(), reqCode, resCode, nil
+}
@@ -0,0 +1,214 @@
+package libgengo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+)
+
+var tsMsgTemplate = `// Automatically generated from the message definition "{{ .FullName }}.msg"
+{{- range .Imports }}
+import { {{ .Name }}{{ if ne .Name .Alias }} as {{ .Alias }}{{ end }} } from "{{ .Path }}";
+{{- end }}
+{{ if .EnumConstants }}
+export const enum {{ .ShortName }}Constants {
+{{- range .EnumConstants }}
+  {{ .Name }} = {{ .Value }},
+{{- end }}
+}
+{{ end }}
+{{- range .BoolConstants }}
+export const {{ $.ShortName }}_{{ .Name }} = {{ .Value }};
+{{ end }}
+export interface {{ .ShortName }} {
+{{- range .TSFields }}
+  {{ .Name }}: {{ .Type }};{{ if ge .ArrayLen 0 }} // {{ .ArrayLen }} elements{{ end }}
+{{- end }}
+}
+`
+
+var tsSrvTemplate = `// Automatically generated from the service definition "{{ .FullName }}.srv"
+import { {{ .ShortName }}Request } from "./{{ .ShortName }}Request";
+import { {{ .ShortName }}Response } from "./{{ .ShortName }}Response";
+
+export interface {{ .ShortName }} {
+  request: {{ .ShortName }}Request;
+  response: {{ .ShortName }}Response;
+}
+`
+
+var tsActionTemplate = `// Automatically generated from the action definition "{{ .FullName }}.action"
+import { {{ .ShortName }}ActionGoal } from "./{{ .ShortName }}ActionGoal";
+import { {{ .ShortName }}ActionFeedback } from "./{{ .ShortName }}ActionFeedback";
+import { {{ .ShortName }}ActionResult } from "./{{ .ShortName }}ActionResult";
+
+export interface {{ .ShortName }} {
+  goal: {{ .ShortName }}ActionGoal;
+  feedback: {{ .ShortName }}ActionFeedback;
+  result: {{ .ShortName }}ActionResult;
+}
+`
+
+// TSField is a property of a TypeScript interface.
+type TSField struct {
+	Name     string
+	Type     string
+	ArrayLen int
+}
+
+// TSImport is a message interface imported from another generated file.
+type TSImport struct {
+	Name  string
+	Alias string
+	Path  string
+}
+
+// TSConstant is a message constant, with its value as a TypeScript literal.
+type TSConstant struct {
+	Name  string
+	Value string
+}
+
+type TSGen struct {
+	MsgSpec
+	Imports       []TSImport
+	TSFields      []TSField
+	EnumConstants []TSConstant
+	BoolConstants []TSConstant
+}
+
+// ToTypeScriptType returns the TypeScript type of the JSON produced by DynamicMessage.MarshalJSON() for a field, or for each element of an array field, other than nested
+// messages.  Non-finite floats are marshalled as strings, times and durations as objects of sec and nsec, and arrays of uint8 as base64 strings.
+func ToTypeScriptType(field *Field) string {
+	if field.IsArray && field.BuiltInType == Uint8 {
+		return "string"
+	}
+	switch field.BuiltInType {
+	case Bool:
+		return "boolean"
+	case Int8, Int16, Int32, Int64, Uint8, Uint16, Uint32, Uint64:
+		return "number"
+	case Float32, Float64:
+		return `number | "nan" | "+inf" | "-inf"`
+	case String:
+		return "string"
+	case Time, Duration:
+		return "{ sec: number; nsec: number }"
+	}
+	return field.Type
+}
+
+// TypeScriptFileName returns the path of the .ts file holding the message, service or action, relative to the root of the generated files.
+func TypeScriptFileName(fullname string) string {
+	return fullname + ".ts"
+}
+
+func (gen *TSGen) analyzeImports() {
+	seen := make(map[string]bool)
+	for i, field := range gen.Fields {
+		tsType := ToTypeScriptType(&gen.Fields[i])
+		if !field.IsBuiltin {
+			imp := TSImport{Name: field.Type, Alias: field.Type, Path: "./" + field.Type}
+			if field.Package != gen.Package {
+				imp.Alias = field.Package + "_" + field.Type
+				imp.Path = "../" + field.Package + Sep + field.Type
+			}
+			if !seen[imp.Path] {
+				seen[imp.Path] = true
+				gen.Imports = append(gen.Imports, imp)
+			}
+			tsType = imp.Alias
+		}
+
+		tsField := TSField{Name: field.Name, Type: tsType, ArrayLen: -1}
+		if field.IsArray && field.BuiltInType != Uint8 {
+			if field.BuiltInType == Float32 || field.BuiltInType == Float64 {
+				tsType = "(" + tsType + ")"
+			}
+			tsField.Type = tsType + "[]"
+			tsField.ArrayLen = field.ArrayLen
+		}
+		gen.TSFields = append(gen.TSFields, tsField)
+	}
+}
+
+func (gen *TSGen) analyzeConstants() error {
+	for _, constant := range gen.Constants {
+		switch value := constant.Value.(type) {
+		case bool:
+			gen.BoolConstants = append(gen.BoolConstants, TSConstant{constant.Name, fmt.Sprint(value)})
+		case string:
+			literal, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			gen.EnumConstants = append(gen.EnumConstants, TSConstant{constant.Name, string(literal)})
+		default:
+			gen.EnumConstants = append(gen.EnumConstants, TSConstant{constant.Name, fmt.Sprint(value)})
+		}
+	}
+	return nil
+}
+
+// GenerateTypeScript generates a TypeScript interface describing the JSON of the message, along with a const enum of its constants.
+func GenerateTypeScript(context *PkgContext, spec *MsgSpec) (string, error) {
+	var gen TSGen
+	gen.Fields = spec.Fields
+	gen.Constants = spec.Constants
+	gen.FullName = spec.FullName
+	gen.ShortName = spec.ShortName
+	gen.Package = spec.Package
+
+	gen.analyzeImports()
+	if err := gen.analyzeConstants(); err != nil {
+		return "", err
+	}
+
+	return executeTypeScriptTemplate("ts", tsMsgTemplate, gen)
+}
+
+// GenerateTypeScriptService generates the TypeScript of a service and its request and response messages; the service's interface has request and response properties.
+func GenerateTypeScriptService(context *PkgContext, spec *SrvSpec) (string, string, string, error) {
+	reqCode, err := GenerateTypeScript(context, spec.Request)
+	if err != nil {
+		return "", "", "", err
+	}
+	resCode, err := GenerateTypeScript(context, spec.Response)
+	if err != nil {
+		return "", "", "", err
+	}
+	srvCode, err := executeTypeScriptTemplate("tsSrv", tsSrvTemplate, spec)
+	if err != nil {
+		return "", "", "", err
+	}
+	return srvCode, reqCode, resCode, nil
+}
+
+// GenerateTypeScriptAction generates the TypeScript of an action and its messages; the action's interface has goal, feedback and result properties, holding the messages sent
+// on the action's topics.
+func GenerateTypeScriptAction(context *PkgContext, spec *ActionSpec) (actionCode string, codeMap map[string]string, err error) {
+	codeMap = make(map[string]string)
+	for _, msgSpec := range []*MsgSpec{spec.Goal, spec.ActionGoal, spec.Result, spec.ActionResult, spec.Feedback, spec.ActionFeedback} {
+		codeMap[msgSpec.FullName], err = GenerateTypeScript(context, msgSpec)
+		if err != nil {
+			return
+		}
+	}
+	actionCode, err = executeTypeScriptTemplate("tsAction", tsActionTemplate, spec)
+	return
+}
+
+func executeTypeScriptTemplate(name string, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, data)
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
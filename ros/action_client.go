@@ -3,6 +3,7 @@ package ros
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	modular "github.com/edwinhayes/logrus-modular"
 	"github.com/pkg/errors"
@@ -25,9 +26,16 @@ type defaultActionClient struct {
 	logger           *modular.ModuleLogger
 	handlers         []*clientGoalHandler
 	handlersMutex    sync.RWMutex
-	goalIDGen        *goalIDGenerator
+	goalIDGen        GoalIDGenerator
+	goalIDGenMutex   sync.RWMutex
 	statusReceived   bool
+	lastStatusTime   Time
+	statusTimeout    Duration
+	statusMutex      sync.RWMutex
 	callerID         string
+	lastStatusArray  ActionStatusArray
+	shutdownChan     chan struct{}
+	shutdownOnce     sync.Once
 }
 
 func newDefaultActionClient(node Node, action string, actType ActionType) (*defaultActionClient, error) {
@@ -42,7 +50,9 @@ func newDefaultActionClient(node Node, action string, actType ActionType) (*defa
 		actionGoal:     actType.GoalType(),
 		logger:         node.Logger(),
 		statusReceived: false,
+		statusTimeout:  NewDuration(5, 0),
 		goalIDGen:      newGoalIDGenerator(node.Name()),
+		shutdownChan:   make(chan struct{}),
 	}
 
 	// Create goal publisher
@@ -70,6 +80,9 @@ func newDefaultActionClient(node Node, action string, actType ActionType) (*defa
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to create status subscriber:")
 	}
+
+	go ac.monitorServer()
+
 	return ac, nil
 }
 
@@ -88,7 +101,9 @@ func (ac *defaultActionClient) SendGoal(goal Message, transitionCb, feedbackCb i
 	// If goalID not provided, generate ID using time stamp and node name.
 	if goalID == "" {
 		goalid.SetStamp(Now())
-		goalid.SetID(ac.goalIDGen.generateID())
+		ac.goalIDGenMutex.RLock()
+		goalid.SetID(ac.goalIDGen.GenerateID())
+		ac.goalIDGenMutex.RUnlock()
 	} else {
 		goalid.SetStamp(Now())
 		goalid.SetID(goalID)
@@ -118,6 +133,97 @@ func (ac *defaultActionClient) SendGoal(goal Message, transitionCb, feedbackCb i
 	return handler, nil
 }
 
+// AttachGoal starts tracking a goal which is already known to the action
+// server, such as a goal sent before the client restarted. The returned
+// handler follows the goal's status and result as published by the server.
+func (ac *defaultActionClient) AttachGoal(goalID string, transitionCb, feedbackCb interface{}) (ClientGoalHandler, error) {
+	if goalID == "" {
+		return nil, fmt.Errorf("cannot attach to a goal without an id")
+	}
+
+	if handler, ok := ac.findGoalHandler(goalID); ok {
+		return handler, nil
+	}
+
+	// Create an action goal message which carries just the goal id
+	ag := ac.actionType.GoalType().NewGoalMessage().(*DynamicActionGoal)
+	goalid := NewActionGoalIDType().NewGoalIDMessage()
+	goalid.SetID(goalID)
+	ag.SetGoalId(goalid)
+	ag.SetHeader(NewActionHeader())
+
+	handler, err := newClientGoalHandler(ac, ag, transitionCb, feedbackCb)
+	if err != nil {
+		return nil, err
+	}
+	handler.attached = true
+
+	// Catch up with the most recent status, if the server has published one
+	ac.statusMutex.RLock()
+	statusArray := ac.lastStatusArray
+	ac.statusMutex.RUnlock()
+	if statusArray != nil {
+		if st := findGoalStatus(statusArray, goalID); st != nil {
+			if err := handler.updateStatus(statusArray); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ac.handlersMutex.Lock()
+	ac.handlers = append(ac.handlers, handler)
+	ac.handlersMutex.Unlock()
+
+	return handler, nil
+}
+
+// GetGoalHandler returns the handler tracking the goal with the given id.
+func (ac *defaultActionClient) GetGoalHandler(goalID string) (ClientGoalHandler, bool) {
+	handler, ok := ac.findGoalHandler(goalID)
+	if !ok {
+		return nil, false
+	}
+	return handler, true
+}
+
+// GetServerGoalIDs returns the ids of all goals in the most recent status
+// published by the action server.
+func (ac *defaultActionClient) GetServerGoalIDs() []string {
+	ac.statusMutex.RLock()
+	statusArray := ac.lastStatusArray
+	ac.statusMutex.RUnlock()
+
+	ids := make([]string, 0)
+	if statusArray == nil {
+		return ids
+	}
+	for _, st := range statusArray.GetStatusArray() {
+		goalID := st.GetGoalID()
+		ids = append(ids, goalID.GetID())
+	}
+	return ids
+}
+
+// SetGoalIDGenerator replaces the generator of ids for goals sent without one.
+func (ac *defaultActionClient) SetGoalIDGenerator(gen GoalIDGenerator) {
+	ac.goalIDGenMutex.Lock()
+	defer ac.goalIDGenMutex.Unlock()
+
+	ac.goalIDGen = gen
+}
+
+func (ac *defaultActionClient) findGoalHandler(goalID string) (*clientGoalHandler, bool) {
+	ac.handlersMutex.RLock()
+	defer ac.handlersMutex.RUnlock()
+
+	for _, h := range ac.handlers {
+		if h.actionGoalID == goalID {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
 func (ac *defaultActionClient) CancelAllGoals() {
 	logger := *ac.logger
 	if !ac.started {
@@ -146,7 +252,8 @@ func (ac *defaultActionClient) CancelAllGoalsBeforeTime(stamp Time) {
 // Shutdown client ends an action client and its pub/subs, but keeps the node alive
 // Takes a set of current active subscription booleans as to not remove any subscribers that the node is consuming
 func (ac *defaultActionClient) ShutdownClient(status bool, feedback bool, result bool) {
-	// Create a goal id message
+	ac.stopMonitor()
+
 	ac.handlersMutex.Lock()
 	defer ac.handlersMutex.Unlock()
 
@@ -173,6 +280,8 @@ func (ac *defaultActionClient) ShutdownClient(status bool, feedback bool, result
 
 // Shutdown completely ends a client and its associated node
 func (ac *defaultActionClient) Shutdown() {
+	ac.stopMonitor()
+
 	ac.handlersMutex.Lock()
 	defer ac.handlersMutex.Unlock()
 
@@ -235,6 +344,92 @@ LOOP:
 	return started
 }
 
+// ServerConnected returns true if the action server is connected to all of
+// the client's topics and has published status within the status timeout.
+func (ac *defaultActionClient) ServerConnected() bool {
+	ac.statusMutex.RLock()
+	statusReceived := ac.statusReceived
+	lastStatusTime := ac.lastStatusTime
+	statusTimeout := ac.statusTimeout
+	ac.statusMutex.RUnlock()
+
+	if !statusReceived {
+		return false
+	}
+
+	if ac.goalPub.GetNumSubscribers() == 0 ||
+		ac.cancelPub.GetNumSubscribers() == 0 ||
+		ac.feedbackSub.GetNumPublishers() == 0 ||
+		ac.resultSub.GetNumPublishers() == 0 ||
+		ac.statusSub.GetNumPublishers() == 0 {
+		return false
+	}
+
+	now := Now()
+	sinceStatus := now.Diff(lastStatusTime)
+	if !statusTimeout.IsZero() && sinceStatus.Cmp(statusTimeout) > 0 {
+		return false
+	}
+
+	return true
+}
+
+// SetStatusTimeout sets how long the client waits for a status message before
+// treating the action server as disconnected. A zero timeout disables the
+// staleness check.
+func (ac *defaultActionClient) SetStatusTimeout(timeout Duration) {
+	ac.statusMutex.Lock()
+	defer ac.statusMutex.Unlock()
+
+	ac.statusTimeout = timeout
+}
+
+// monitorServer watches the action server connection, moving outstanding
+// goals to Lost once a connected server disconnects or goes stale.
+func (ac *defaultActionClient) monitorServer() {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	wasConnected := false
+	for {
+		select {
+		case <-ac.shutdownChan:
+			return
+		case <-ticker.C:
+		}
+
+		wasConnected = ac.checkServerConnection(wasConnected)
+	}
+}
+
+// checkServerConnection moves outstanding goals to Lost if the server was
+// connected and no longer is, returning whether the server is connected.
+func (ac *defaultActionClient) checkServerConnection(wasConnected bool) bool {
+	logger := *ac.logger
+	connected := ac.ServerConnected()
+	if wasConnected && !connected {
+		logger.Warnf("[ActionClient] Lost connection to action server %s", ac.action)
+		ac.markGoalsLost()
+	}
+	return connected
+}
+
+// markGoalsLost moves every outstanding goal to Lost.
+func (ac *defaultActionClient) markGoalsLost() {
+	ac.handlersMutex.RLock()
+	defer ac.handlersMutex.RUnlock()
+
+	for _, h := range ac.handlers {
+		h.setLost()
+	}
+}
+
+func (ac *defaultActionClient) stopMonitor() {
+	ac.shutdownOnce.Do(func() {
+		close(ac.shutdownChan)
+	})
+}
+
 func (ac *defaultActionClient) DeleteGoalHandler(gh *clientGoalHandler) {
 	ac.handlersMutex.Lock()
 	defer ac.handlersMutex.Unlock()
@@ -283,16 +478,22 @@ func (ac *defaultActionClient) internalStatusCallback(statusArr interface{}, eve
 	ac.handlersMutex.RLock()
 	defer ac.handlersMutex.RUnlock()
 
+	ac.statusMutex.Lock()
 	if !ac.statusReceived {
 		ac.statusReceived = true
 		logger.Debug("Recieved first status message from action server ")
 	} else if ac.callerID != event.PublisherName {
 		logger.Debug("Previously received status from %s, now from %s. Did the action server change", ac.callerID, event.PublisherName)
 	}
+	ac.lastStatusTime = Now()
+	ac.callerID = event.PublisherName
+	ac.statusMutex.Unlock()
 
 	// Interface to status array conversion
 	statusArray := NewActionStatusArrayType().(*DynamicActionStatusArrayType).NewStatusArrayFromInterface(statusArr)
-	ac.callerID = event.PublisherName
+	ac.statusMutex.Lock()
+	ac.lastStatusArray = statusArray
+	ac.statusMutex.Unlock()
 	for _, h := range ac.handlers {
 		if err := h.updateStatus(statusArray); err != nil {
 			logger.Error(err)
@@ -0,0 +1,337 @@
+package ros
+
+import (
+	"sort"
+	"testing"
+)
+
+// Fake publishers and subscribers reporting a fixed number of connections.
+type testCountingPublisher struct {
+	numSubscribers int
+}
+
+func (p *testCountingPublisher) TryPublish(msg Message) error { return nil }
+func (p *testCountingPublisher) Publish(msg Message)          {}
+func (p *testCountingPublisher) GetNumSubscribers() int       { return p.numSubscribers }
+func (p *testCountingPublisher) Shutdown()                    {}
+
+type testCountingSubscriber struct {
+	numPublishers int
+}
+
+func (s *testCountingSubscriber) GetNumPublishers() int { return s.numPublishers }
+func (s *testCountingSubscriber) Shutdown()             {}
+
+func TestActionClient_ServerConnected(t *testing.T) {
+	goalPub := &testCountingPublisher{1}
+	statusSub := &testCountingSubscriber{1}
+	ac := &defaultActionClient{
+		goalPub:       goalPub,
+		cancelPub:     &testCountingPublisher{1},
+		resultSub:     &testCountingSubscriber{1},
+		feedbackSub:   &testCountingSubscriber{1},
+		statusSub:     statusSub,
+		statusTimeout: NewDuration(5, 0),
+	}
+
+	if ac.ServerConnected() {
+		t.Fatal("expected server not to be connected before any status is received")
+	}
+
+	ac.statusReceived = true
+	ac.lastStatusTime = Now()
+	if !ac.ServerConnected() {
+		t.Fatal("expected server to be connected")
+	}
+
+	statusSub.numPublishers = 0
+	if ac.ServerConnected() {
+		t.Fatal("expected server not to be connected without a status publisher")
+	}
+	statusSub.numPublishers = 1
+
+	goalPub.numSubscribers = 0
+	if ac.ServerConnected() {
+		t.Fatal("expected server not to be connected without a goal subscriber")
+	}
+	goalPub.numSubscribers = 1
+
+	now := Now()
+	ac.lastStatusTime = now.Sub(NewDuration(10, 0))
+	if ac.ServerConnected() {
+		t.Fatal("expected server not to be connected with stale status")
+	}
+
+	ac.SetStatusTimeout(NewDuration(0, 0))
+	if !ac.ServerConnected() {
+		t.Fatal("expected server to be connected with the staleness check disabled")
+	}
+}
+
+func TestActionClient_LostOnDisconnect(t *testing.T) {
+	ac, statusSub := newTestActionClient(t)
+	var states []CommState
+	transitionCb := func(gh ClientGoalHandler) {
+		state, _ := gh.GetCommState()
+		states = append(states, state)
+	}
+	gh, err := ac.SendGoal(newTestActionGoalMessage(t, 3), transitionCb, nil, "goal1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Goals aren't lost while the server's topics are still connecting after the first status.
+	statusSub.numPublishers = 0
+	ac.statusReceived = true
+	ac.lastStatusTime = Now()
+	if ac.checkServerConnection(false) {
+		t.Fatal("expected server not to be connected")
+	}
+	statusSub.numPublishers = 1
+	if !ac.checkServerConnection(false) {
+		t.Fatal("expected server to be connected")
+	}
+	if state, _ := gh.GetCommState(); state != WaitingForGoalAck {
+		t.Fatalf("expected goal to be waiting for ack, got %s", state)
+	}
+
+	// Goals are lost once a connected server disconnects, and only once.
+	statusSub.numPublishers = 0
+	if ac.checkServerConnection(true) {
+		t.Fatal("expected server not to be connected")
+	}
+	ac.checkServerConnection(false)
+	if len(states) != 1 || states[0] != Lost {
+		t.Fatalf("expected a single transition to Lost, got %v", states)
+	}
+	if status, _ := gh.GetGoalStatus(); status != 9 {
+		t.Fatalf("expected goal status LOST, got %d", status)
+	}
+}
+
+func TestActionClient_MarkGoalsLost(t *testing.T) {
+	ac, _ := newTestActionClient(t)
+	var handlers []ClientGoalHandler
+	for _, id := range []string{"active", "done", "lost"} {
+		gh, err := ac.SendGoal(newTestActionGoalMessage(t, 3), nil, nil, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handlers = append(handlers, gh)
+	}
+	handlers[0].(*clientGoalHandler).stateMachine.setState(Active)
+	handlers[1].(*clientGoalHandler).stateMachine.setState(Done)
+	handlers[2].(*clientGoalHandler).stateMachine.setState(Lost)
+
+	ac.markGoalsLost()
+	for i, expected := range []CommState{Lost, Done, Lost} {
+		if state, _ := handlers[i].GetCommState(); state != expected {
+			t.Fatalf("goal %d: expected %s, got %s", i, expected, state)
+		}
+	}
+	if status, _ := handlers[1].GetGoalStatus(); status == 9 {
+		t.Fatal("expected the done goal's status to be left alone")
+	}
+}
+
+func TestActionClient_LostWhenMissingFromStatus(t *testing.T) {
+	ac, _ := newTestActionClient(t)
+	var states []CommState
+	transitionCb := func(gh ClientGoalHandler) {
+		state, _ := gh.GetCommState()
+		states = append(states, state)
+	}
+	gh, err := ac.SendGoal(newTestActionGoalMessage(t, 3), transitionCb, nil, "goal1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	statusArray := NewActionStatusArrayType().NewStatusArrayMessage()
+	statusArray.SetStatusArray([]ActionStatus{})
+
+	// Goals which haven't been acknowledged yet aren't expected in the status.
+	if err := gh.(*clientGoalHandler).updateStatus(statusArray); err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 0 {
+		t.Fatalf("expected no transitions, got %v", states)
+	}
+
+	// A goal the server stops reporting is lost, as it is when the server disconnects.
+	gh.(*clientGoalHandler).stateMachine.setState(Active)
+	if err := gh.(*clientGoalHandler).updateStatus(statusArray); err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 || states[0] != Lost {
+		t.Fatalf("expected a single transition to Lost, got %v", states)
+	}
+	if status, _ := gh.GetTerminalState(); status != 9 {
+		t.Fatalf("expected terminal status LOST, got %d", status)
+	}
+}
+
+func TestSimpleActionClient_LostDoneCallback(t *testing.T) {
+	doneCbs := []interface{}{
+		func(state uint8, result *DynamicMessage) {
+			if state != 9 || result != nil {
+				t.Errorf("expected LOST without a result, got %d, %v", state, result)
+			}
+		},
+		func(state uint8, result Message) {
+			if state != 9 || result != nil {
+				t.Errorf("expected LOST without a result, got %d, %v", state, result)
+			}
+		},
+		func(state uint8) {
+			if state != 9 {
+				t.Errorf("expected LOST, got %d", state)
+			}
+		},
+	}
+	for _, doneCb := range doneCbs {
+		ac, _ := newTestActionClient(t)
+		sc := &simpleActionClient{
+			ac:          ac,
+			simpleState: SimpleStateDone,
+			doneChan:    make(chan struct{}, 10),
+			logger:      ac.logger,
+		}
+		if err := sc.SendGoal(newTestActionGoalMessage(t, 3), doneCb, nil, nil, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		ac.markGoalsLost()
+		if sc.simpleState != SimpleStateDone {
+			t.Fatalf("%T: expected simple state done, got %d", doneCb, sc.simpleState)
+		}
+		if state, _ := sc.GetState(); state != 9 {
+			t.Fatalf("%T: expected state LOST, got %d", doneCb, state)
+		}
+	}
+}
+
+// Test helper functions.
+
+// newTestActionClient creates a started action client for the test action type, whose server is connected to every topic but hasn't published a status.
+func newTestActionClient(t *testing.T) (*defaultActionClient, *testCountingSubscriber) {
+	node := newTestActionNode()
+	statusSub := &testCountingSubscriber{1}
+	ac := &defaultActionClient{
+		started:       true,
+		node:          node,
+		action:        "/test",
+		actionType:    newTestActionType(t),
+		goalPub:       &testCountingPublisher{1},
+		cancelPub:     &testCountingPublisher{1},
+		resultSub:     &testCountingSubscriber{1},
+		feedbackSub:   &testCountingSubscriber{1},
+		statusSub:     statusSub,
+		logger:        node.Logger(),
+		goalIDGen:     newGoalIDGenerator(node.Name()),
+		statusTimeout: NewDuration(5, 0),
+		shutdownChan:  make(chan struct{}),
+	}
+	return ac, statusSub
+}
+
+// newTestActionGoalMessage creates the goal of the test action type, as passed to SendGoal.
+func newTestActionGoalMessage(t *testing.T, target int32) *DynamicMessage {
+	registry := newTestActionRegistry()
+	if _, err := registry.NewDynamicActionType("test_msgs/Count"); err != nil {
+		t.Fatal(err)
+	}
+	goalType, err := registry.NewDynamicMessageType("test_msgs/CountGoal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	goal, err := goalType.NewDynamicMessageFromMap(map[string]interface{}{"target": target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return goal
+}
+
+func TestActionClient_AttachGoal(t *testing.T) {
+	ac, _ := newTestActionClient(t)
+	if ids := ac.GetServerGoalIDs(); ids == nil || len(ids) != 0 {
+		t.Fatalf("expected no server goal ids before any status, got %v", ids)
+	}
+	if _, err := ac.AttachGoal("", nil, nil); err == nil {
+		t.Fatal("expected error attaching to a goal without an id")
+	}
+
+	ac.internalStatusCallback(newTestStatusArray(t, map[string]uint8{"goal1": 1, "goal2": 3}), MessageEvent{PublisherName: "/server"})
+	if ids := ac.GetServerGoalIDs(); len(ids) != 2 || ids[0] != "goal1" || ids[1] != "goal2" {
+		t.Fatalf("expected server goal ids [goal1 goal2], got %v", ids)
+	}
+
+	// Attached goals catch up with the most recent status.
+	var states []CommState
+	transitionCb := func(gh ClientGoalHandler) {
+		state, _ := gh.GetCommState()
+		states = append(states, state)
+	}
+	gh, err := ac.AttachGoal("goal1", transitionCb, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 || states[0] != Active {
+		t.Fatalf("expected a transition to Active, got %v", states)
+	}
+	if status, _ := gh.GetGoalStatus(); status != 1 {
+		t.Fatalf("expected goal status ACTIVE, got %d", status)
+	}
+	if err := gh.Resend(); err == nil {
+		t.Fatal("expected error resending an attached goal")
+	}
+
+	finished, err := ac.AttachGoal("goal2", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := finished.GetCommState(); state != WaitingForResult {
+		t.Fatalf("expected finished goal to be waiting for its result, got %s", state)
+	}
+
+	// Attaching twice returns the same handler, which can be looked up by id.
+	if again, err := ac.AttachGoal("goal1", nil, nil); err != nil || again != gh {
+		t.Fatalf("expected the existing handler, got %v, %v", again, err)
+	}
+	if found, ok := ac.GetGoalHandler("goal1"); !ok || found != gh {
+		t.Fatalf("expected to find the attached handler, got %v", found)
+	}
+	if _, ok := ac.GetGoalHandler("goal3"); ok {
+		t.Fatal("expected no handler for an unknown goal")
+	}
+
+	// Goals unknown to the server wait for it to report them.
+	unknown, err := ac.AttachGoal("goal3", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state, _ := unknown.GetCommState(); state != WaitingForGoalAck {
+		t.Fatalf("expected unknown goal to be waiting for ack, got %s", state)
+	}
+	ac.internalStatusCallback(newTestStatusArray(t, map[string]uint8{"goal1": 1, "goal3": 0}), MessageEvent{PublisherName: "/server"})
+	if state, _ := unknown.GetCommState(); state != Pending {
+		t.Fatalf("expected goal to be pending once reported, got %s", state)
+	}
+}
+
+// newTestStatusArray creates a status array message, as received on the status topic, reporting the goals with the statuses in order of id.
+func newTestStatusArray(t *testing.T, statuses map[string]uint8) *DynamicMessage {
+	ids := make([]string, 0, len(statuses))
+	for id := range statuses {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	statusList := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		statusList = append(statusList, map[string]interface{}{"goal_id": map[string]interface{}{"id": id}, "status": statuses[id]})
+	}
+	statusArray, err := NewActionStatusArrayType().(*DynamicActionStatusArrayType).NewDynamicMessageFromMap(map[string]interface{}{"status_list": statusList})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return statusArray
+}
@@ -3,6 +3,7 @@ package ros
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -36,10 +37,22 @@ type defaultActionServer struct {
 	feedbackPub      Publisher
 	statusPub        Publisher
 	statusPubChan    chan struct{}
-	goalIDGen        *goalIDGenerator
+	goalIDGen        GoalIDGenerator
+	feedbackPeriod   Duration
+	lastFeedback     map[string]Time
+	feedbackMutex    sync.Mutex
 	shutdownChan     chan struct{}
 }
 
+// ActionGoalInfo describes a goal tracked by an action server.
+type ActionGoalInfo struct {
+	ID              string
+	Stamp           Time
+	Status          uint8
+	StatusText      string
+	DestructionTime Time
+}
+
 func newDefaultActionServer(node Node, action string, actType ActionType, goalCb interface{}, cancelCb interface{}, start bool) *defaultActionServer {
 	return &defaultActionServer{
 		node:            node,
@@ -54,6 +67,7 @@ func newDefaultActionServer(node Node, action string, actType ActionType, goalCb
 		goalCallback:    goalCb,
 		cancelCallback:  cancelCb,
 		lastCancel:      Now(),
+		goalIDGen:       newGoalIDGenerator(node.Name()),
 	}
 }
 
@@ -63,16 +77,32 @@ func (as *defaultActionServer) initialize() error {
 	as.statusPubChan = make(chan struct{}, 10)
 	as.shutdownChan = make(chan struct{}, 10)
 
-	// setup goal id generator and goal handlers
-	as.goalIDGen = newGoalIDGenerator(as.node.Name())
+	// setup goal handlers
 	as.handlers = map[string]*serverGoalHandler{}
 
 	// setup action result type so that we can create default result messages
 	//res := .NewMessage().(ActionResult).GetResult()
 	as.actionResultType = as.actionResult
 
-	// get frequency from ros params
-	as.statusFrequency = NewRate(5.0)
+	// get status frequency and timeouts from ros params, falling back to the
+	// actionlib defaults
+	statusFrequency := as.getFloatParam(5.0, as.action+"/status_frequency", "~status_frequency", "actionlib_status_frequency")
+	if statusFrequency <= 0 {
+		logger := *as.node.Logger()
+		logger.Warnf("invalid status frequency %v for action server %s, using 5.0", statusFrequency, as.action)
+		statusFrequency = 5.0
+	}
+	as.statusFrequency = NewRate(statusFrequency)
+
+	statusListTimeout := as.getFloatParam(as.handlersTimeout.ToSec(), as.action+"/status_list_timeout", "~status_list_timeout")
+	as.handlersTimeout.FromSec(statusListTimeout)
+
+	// feedback is not throttled unless a feedback frequency is set
+	feedbackFrequency := as.getFloatParam(0.0, as.action+"/feedback_frequency", "~feedback_frequency")
+	if feedbackFrequency > 0 {
+		as.feedbackPeriod.FromSec(1.0 / feedbackFrequency)
+	}
+	as.lastFeedback = map[string]Time{}
 
 	// get queue sizes from ros params
 	// queue sizes not implemented by Node yet
@@ -112,7 +142,9 @@ func (as *defaultActionServer) Start() {
 	logger := *as.node.Logger()
 	defer func() {
 		logger.Debug("defaultActionServer.start exit")
+		as.statusMutex.Lock()
 		as.started = false
+		as.statusMutex.Unlock()
 	}()
 
 	// initialize subscribers and publishers
@@ -121,11 +153,14 @@ func (as *defaultActionServer) Start() {
 		logger.Errorf("failed to initialize action server: %s", err)
 	}
 
-	// start status publish ticker that notifies at 5hz
-	as.statusTimer = time.NewTicker(time.Second / 5.0)
+	// start status publish ticker that notifies at the status frequency
+	statusPeriod := as.statusFrequency.ExpectedCycleTime()
+	as.statusTimer = time.NewTicker(time.Duration(statusPeriod.ToNSec()))
 	defer as.statusTimer.Stop()
 
+	as.statusMutex.Lock()
 	as.started = true
+	as.statusMutex.Unlock()
 
 	for {
 		select {
@@ -157,6 +192,24 @@ func (as *defaultActionServer) PublishResult(status ActionStatus, result Message
 
 // PublishFeedback publishes action feedback messages
 func (as *defaultActionServer) PublishFeedback(status ActionStatus, feedback Message) {
+	if !as.feedbackPeriod.IsZero() {
+		id := status.GetGoalID().GetID()
+		now := Now()
+
+		as.feedbackMutex.Lock()
+		last, ok := as.lastFeedback[id]
+		sinceLast := now.Diff(last)
+		throttle := ok && sinceLast.Cmp(as.feedbackPeriod) < 0
+		if !throttle {
+			as.lastFeedback[id] = now
+		}
+		as.feedbackMutex.Unlock()
+
+		if throttle {
+			return
+		}
+	}
+
 	msg := as.actionFeedback.(*DynamicActionFeedbackType).NewFeedbackMessage()
 
 	msg.SetHeader(NewActionHeader())
@@ -171,15 +224,9 @@ func (as *defaultActionServer) getStatus() (ActionStatusArray, error) {
 	var statusList []ActionStatus
 
 	if as.node.OK() {
-		for id, gh := range as.handlers {
-			handlerTime := gh.GetHandlerDestructionTime()
-			destroyTime := handlerTime.Add(as.handlersTimeout)
-			if !handlerTime.IsZero() && destroyTime.Cmp(Now()) <= 0 {
-				delete(as.handlers, id)
-				continue
-			}
-
-			status, err := gh.GetGoalStatus()
+		as.reapGoals(Now())
+		for _, id := range as.sortedHandlerIDs() {
+			status, err := as.handlers[id].GetGoalStatus()
 			if err != nil {
 				return nil, err
 			}
@@ -195,8 +242,114 @@ func (as *defaultActionServer) getStatus() (ActionStatusArray, error) {
 	return statusArray, nil
 }
 
+// GetGoals returns the goals currently tracked by the action server, ordered
+// by goal stamp and then id.
+func (as *defaultActionServer) GetGoals() []ActionGoalInfo {
+	as.handlersMutex.Lock()
+	defer as.handlersMutex.Unlock()
+
+	goals := make([]ActionGoalInfo, 0, len(as.handlers))
+	for _, id := range as.sortedHandlerIDs() {
+		gh := as.handlers[id]
+		st := gh.sm.getStatus()
+		goalID := st.GetGoalID()
+		goals = append(goals, ActionGoalInfo{
+			ID:              id,
+			Stamp:           goalID.GetStamp(),
+			Status:          st.GetStatus(),
+			StatusText:      st.GetStatusText(),
+			DestructionTime: gh.GetHandlerDestructionTime(),
+		})
+	}
+	return goals
+}
+
+// reapGoals removes terminal goals whose status has been published for the
+// status list timeout. Goals which are still running, e.g. while preempting,
+// are kept however long ago they were cancelled. Requires handlersMutex.
+func (as *defaultActionServer) reapGoals(now Time) {
+	for id, gh := range as.handlers {
+		handlerTime := gh.GetHandlerDestructionTime()
+		if handlerTime.IsZero() {
+			continue
+		}
+		if gh.goal != nil && !isTerminalGoalStatus(gh.sm.getStatus().GetStatus()) {
+			continue
+		}
+		destroyTime := handlerTime.Add(as.handlersTimeout)
+		if destroyTime.Cmp(now) <= 0 {
+			delete(as.handlers, id)
+
+			as.feedbackMutex.Lock()
+			delete(as.lastFeedback, id)
+			as.feedbackMutex.Unlock()
+		}
+	}
+}
+
+// sortedHandlerIDs returns the ids of the goal handlers ordered by goal stamp
+// and then id. Requires handlersMutex.
+func (as *defaultActionServer) sortedHandlerIDs() []string {
+	ids := make([]string, 0, len(as.handlers))
+	stamps := make(map[string]Time, len(as.handlers))
+	for id, gh := range as.handlers {
+		ids = append(ids, id)
+		goalID := gh.sm.getStatus().GetGoalID()
+		stamps[id] = goalID.GetStamp()
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		stampI := stamps[ids[i]]
+		if c := stampI.Cmp(stamps[ids[j]]); c != 0 {
+			return c < 0
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// getFloatParam returns the first of the named parameters which is set, or
+// the default value if none are.
+func (as *defaultActionServer) getFloatParam(defaultValue float64, names ...string) float64 {
+	for _, name := range names {
+		value, err := as.node.GetParam(name)
+		if err != nil || value == nil {
+			continue
+		}
+		switch v := value.(type) {
+		case float64:
+			return v
+		case float32:
+			return float64(v)
+		case int:
+			return float64(v)
+		case int32:
+			return float64(v)
+		case int64:
+			return float64(v)
+		}
+	}
+	return defaultValue
+}
+
+// isTerminalGoalStatus checks whether the status is preempted, succeeded,
+// aborted, rejected or recalled.
+func isTerminalGoalStatus(status uint8) bool {
+	switch status {
+	case uint8(2), uint8(3), uint8(4), uint8(5), uint8(8):
+		return true
+	}
+	return false
+}
+
+// PublishStatus asks the status loop to publish the status of the goals. The
+// request is dropped if requests are already pending, as they publish the
+// latest status anyway; this keeps callers which hold locks the status loop
+// needs, such as goal callbacks, from blocking on a full channel.
 func (as *defaultActionServer) PublishStatus() {
-	as.statusPubChan <- struct{}{}
+	select {
+	case as.statusPubChan <- struct{}{}:
+	default:
+	}
 }
 
 // internalCancelCallback recieves cancel message from client
@@ -277,7 +430,9 @@ func (as *defaultActionServer) internalGoalCallback(goals interface{}, event Mes
 			}
 			logger.Debugf("Goal %s was already in the status list with status %+v", goalID.GetID(), st.GetStatus())
 			if st.GetStatus() == uint8(7) {
-				st.SetStatus(uint8(8))
+				if st, err = gh.sm.transition(Cancel, st.GetStatusText()); err != nil {
+					return err
+				}
 				result := as.actionResultType.NewMessage()
 				as.PublishResult(st, result)
 			}
@@ -289,7 +444,7 @@ func (as *defaultActionServer) internalGoalCallback(goals interface{}, event Mes
 
 	id := goalID.GetID()
 	if len(id) == 0 {
-		id = as.goalIDGen.generateID()
+		id = as.goalIDGen.GenerateID()
 		// Create goal id message with id and time stamp
 		newGoalID := NewActionGoalIDType().NewGoalIDMessage()
 		newGoalID.SetID(id)
@@ -334,6 +489,19 @@ func (as *defaultActionServer) RegisterCancelCallback(cancelCb interface{}) {
 	as.cancelCallback = cancelCb
 }
 
+// SetGoalIDGenerator replaces the generator of ids for goals received without
+// one. It must be called before the server is started.
+func (as *defaultActionServer) SetGoalIDGenerator(gen GoalIDGenerator) {
+	as.goalIDGen = gen
+}
+
+// isStarted checks whether the server has been initialized and is running.
+func (as *defaultActionServer) isStarted() bool {
+	as.statusMutex.RLock()
+	defer as.statusMutex.RUnlock()
+	return as.started
+}
+
 func (as *defaultActionServer) Shutdown() {
 	as.shutdownChan <- struct{}{}
 }
@@ -0,0 +1,231 @@
+package ros
+
+import (
+	"testing"
+)
+
+func TestActionServer_GetFloatParam(t *testing.T) {
+	node := newTestActionNode()
+	as := newDefaultActionServer(node, "/test", newTestActionType(t), nil, nil, false)
+
+	if value := as.getFloatParam(5.0, "/test/a", "~a"); value != 5.0 {
+		t.Fatalf("expected the default when no parameter is set, got %v", value)
+	}
+
+	// The first parameter which is set is used, whatever its numeric type.
+	node.params["~a"] = int32(7)
+	if value := as.getFloatParam(5.0, "/test/a", "~a"); value != 7.0 {
+		t.Fatalf("expected 7 from ~a, got %v", value)
+	}
+	node.params["/test/a"] = 2.5
+	if value := as.getFloatParam(5.0, "/test/a", "~a"); value != 2.5 {
+		t.Fatalf("expected 2.5 from /test/a, got %v", value)
+	}
+
+	// Parameters which aren't numbers are skipped.
+	node.params["/test/a"] = "fast"
+	if value := as.getFloatParam(5.0, "/test/a", "~a"); value != 7.0 {
+		t.Fatalf("expected 7 from ~a, got %v", value)
+	}
+}
+
+func TestActionServer_InitializeParams(t *testing.T) {
+	as, _ := newTestActionServer(t, nil)
+	if period := as.statusFrequency.ExpectedCycleTime(); period.Cmp(NewDuration(0, 200000000)) != 0 {
+		t.Fatalf("expected the default status period of 0.2s, got %v", period)
+	}
+	if as.handlersTimeout.Cmp(NewDuration(60, 0)) != 0 {
+		t.Fatalf("expected the default status list timeout of 60s, got %v", as.handlersTimeout)
+	}
+	if !as.feedbackPeriod.IsZero() {
+		t.Fatalf("expected feedback not to be throttled by default, got period %v", as.feedbackPeriod)
+	}
+
+	as, _ = newTestActionServer(t, map[string]interface{}{
+		"/test/status_frequency":     10.0,
+		"~status_list_timeout":       5,
+		"/test/feedback_frequency":   4.0,
+		"actionlib_status_frequency": 1.0,
+	})
+	if period := as.statusFrequency.ExpectedCycleTime(); period.Cmp(NewDuration(0, 100000000)) != 0 {
+		t.Fatalf("expected a status period of 0.1s, got %v", period)
+	}
+	if as.handlersTimeout.Cmp(NewDuration(5, 0)) != 0 {
+		t.Fatalf("expected a status list timeout of 5s, got %v", as.handlersTimeout)
+	}
+	if as.feedbackPeriod.Cmp(NewDuration(0, 250000000)) != 0 {
+		t.Fatalf("expected a feedback period of 0.25s, got %v", as.feedbackPeriod)
+	}
+
+	// The global status frequency is used when the action's isn't set, and invalid frequencies fall back to the default.
+	as, _ = newTestActionServer(t, map[string]interface{}{"actionlib_status_frequency": 2.0})
+	if period := as.statusFrequency.ExpectedCycleTime(); period.Cmp(NewDuration(0, 500000000)) != 0 {
+		t.Fatalf("expected a status period of 0.5s, got %v", period)
+	}
+	as, _ = newTestActionServer(t, map[string]interface{}{"~status_frequency": -1.0})
+	if period := as.statusFrequency.ExpectedCycleTime(); period.Cmp(NewDuration(0, 200000000)) != 0 {
+		t.Fatalf("expected the default status period of 0.2s, got %v", period)
+	}
+}
+
+func TestActionServer_ReapGoals(t *testing.T) {
+	as, _ := newTestActionServer(t, map[string]interface{}{"~status_list_timeout": 10})
+	now := Now()
+	old := now.Sub(NewDuration(11, 0))
+	recent := now.Sub(NewDuration(9, 0))
+
+	finishedOld := addTestServerGoal(t, as, "finished_old", now)
+	finishedOld.SetAccepted("")
+	finishedOld.SetSucceeded(nil, "")
+	finishedOld.SetHandlerDestructionTime(old)
+
+	finishedRecent := addTestServerGoal(t, as, "finished_recent", now)
+	finishedRecent.SetAccepted("")
+	finishedRecent.SetAborted(nil, "")
+	finishedRecent.SetHandlerDestructionTime(recent)
+
+	// A goal which has been preempting for longer than the timeout is still running.
+	preempting := addTestServerGoal(t, as, "preempting", now)
+	preempting.SetAccepted("")
+	preempting.SetCancelRequested()
+	preempting.SetHandlerDestructionTime(old)
+
+	active := addTestServerGoal(t, as, "active", now)
+	active.SetAccepted("")
+
+	// Cancel requests for goals which were never received are tracked without a goal.
+	goalID := NewActionGoalIDType().NewGoalIDMessage()
+	goalID.SetID("cancelled_unknown")
+	cancelled := newServerGoalHandlerWithGoalId(as, goalID)
+	cancelled.SetHandlerDestructionTime(old)
+	as.handlers["cancelled_unknown"] = cancelled
+
+	as.feedbackPeriod = NewDuration(1, 0)
+	as.PublishFeedback(finishedOld.sm.getStatus(), nil)
+
+	// Goals are reaped as the status is published.
+	if _, err := as.getStatus(); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, goal := range as.GetGoals() {
+		ids = append(ids, goal.ID)
+	}
+	if expected := []string{"active", "finished_recent", "preempting"}; len(ids) != len(expected) || ids[0] != expected[0] || ids[1] != expected[1] || ids[2] != expected[2] {
+		t.Fatalf("expected goals %v to be kept, got %v", expected, ids)
+	}
+	if _, ok := as.lastFeedback["finished_old"]; ok {
+		t.Fatal("expected the feedback time of the reaped goal to be removed")
+	}
+}
+
+func TestActionServer_PublishStatusDoesNotBlock(t *testing.T) {
+	as, _ := newTestActionServer(t, nil)
+
+	// The status loop isn't running, so requests beyond the channel's capacity are dropped.
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 2*cap(as.statusPubChan); i++ {
+			as.PublishStatus()
+		}
+		close(done)
+	}()
+	waitForChannel(t, done, "status requests to return")
+	if n := len(as.statusPubChan); n != cap(as.statusPubChan) {
+		t.Fatalf("expected %d pending status requests, got %d", cap(as.statusPubChan), n)
+	}
+}
+
+func TestActionServer_FeedbackThrottle(t *testing.T) {
+	as, node := newTestActionServer(t, nil)
+	goal1 := addTestServerGoal(t, as, "goal1", Now())
+	goal2 := addTestServerGoal(t, as, "goal2", Now())
+	feedback := node.publisher("/test/feedback")
+
+	// Every feedback is published when feedback isn't throttled.
+	goal1.PublishFeedback(nil)
+	goal1.PublishFeedback(nil)
+	if n := len(feedback.published()); n != 2 {
+		t.Fatalf("expected 2 feedback messages, got %d", n)
+	}
+
+	// Feedback is throttled per goal.
+	as.feedbackPeriod = NewDuration(60, 0)
+	goal1.PublishFeedback(nil)
+	goal1.PublishFeedback(nil)
+	goal2.PublishFeedback(nil)
+	if n := len(feedback.published()); n != 4 {
+		t.Fatalf("expected 4 feedback messages, got %d", n)
+	}
+
+	// Feedback is published again once the period has passed.
+	now := Now()
+	past := now.Sub(NewDuration(61, 0))
+	as.lastFeedback["goal1"] = past
+	goal1.PublishFeedback(nil)
+	if n := len(feedback.published()); n != 5 {
+		t.Fatalf("expected 5 feedback messages, got %d", n)
+	}
+}
+
+func TestActionServer_GetGoals(t *testing.T) {
+	as, _ := newTestActionServer(t, nil)
+	if goals := as.GetGoals(); goals == nil || len(goals) != 0 {
+		t.Fatalf("expected no goals, got %v", goals)
+	}
+
+	// Goals are ordered by stamp, then by id.
+	early := NewTime(100, 0)
+	late := NewTime(200, 0)
+	addTestServerGoal(t, as, "c", late)
+	b := addTestServerGoal(t, as, "b", late)
+	a := addTestServerGoal(t, as, "a", early)
+	b.SetAccepted("")
+	b.SetSucceeded(nil, "done")
+	a.SetAccepted("running")
+
+	goals := as.GetGoals()
+	if len(goals) != 3 {
+		t.Fatalf("expected 3 goals, got %v", goals)
+	}
+	expected := []ActionGoalInfo{
+		{ID: "a", Stamp: early, Status: 1, StatusText: "running"},
+		{ID: "b", Stamp: late, Status: 3, StatusText: "done", DestructionTime: b.GetHandlerDestructionTime()},
+		{ID: "c", Stamp: late, Status: 0},
+	}
+	for i := range expected {
+		if goals[i] != expected[i] {
+			t.Fatalf("goal %d: expected %+v, got %+v", i, expected[i], goals[i])
+		}
+	}
+	if goals[1].DestructionTime.IsZero() {
+		t.Fatal("expected the finished goal to have a destruction time")
+	}
+}
+
+// Test helper functions.
+
+// newTestActionServer creates an action server for the test action type on a fake node with the parameters, and initializes it without starting it.
+func newTestActionServer(t *testing.T, params map[string]interface{}) (*defaultActionServer, *testActionNode) {
+	node := newTestActionNode()
+	for name, value := range params {
+		node.params[name] = value
+	}
+	as := newDefaultActionServer(node, "/test", newTestActionType(t), nil, nil, false)
+	if err := as.initialize(); err != nil {
+		t.Fatal(err)
+	}
+	return as, node
+}
+
+// addTestServerGoal adds a pending goal of the test action type to the action server.
+func addTestServerGoal(t *testing.T, as *defaultActionServer, id string, stamp Time) *serverGoalHandler {
+	msg := newTestActionGoal(t, as.actionType, id, stamp, 1)
+	goal := as.actionType.GoalType().(*DynamicActionGoalType).NewGoalMessageFromInterface(msg)
+	gh, err := newServerGoalHandlerWithGoal(as, goal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	as.handlers[id] = gh
+	return gh
+}
@@ -12,10 +12,26 @@ func NewSimpleActionClient(node Node, action string, actionType ActionType) (Sim
 	return newSimpleActionClient(node, action, actionType)
 }
 
-func NewSimpleActionServer(node Node, action string, actionType ActionType, executeCb interface{}, autoStart bool) SimpleActionServer {
+// NewSimpleActionServer creates a simple action server. executeCb may either
+// take the goal (and optionally the action type) and poll
+// IsPreemptRequested, or have the form
+// func(ctx context.Context, feedback FeedbackPublisher, goal T) (R, error)
+// where ctx is cancelled on preempt or shutdown; the returned result and error
+// are then mapped onto SetSucceeded, SetPreempted or SetAborted.
+func NewSimpleActionServer(node Node, action string, actionType ActionType, executeCb interface{}, autoStart bool) StoppableSimpleActionServer {
 	return newSimpleActionServer(node, action, actionType, executeCb, autoStart)
 }
 
+// NewManagedActionServer creates an action server which executes each goal in
+// its own goroutine according to the goal policy. executeCb must have the form
+// func(ctx context.Context, feedback FeedbackPublisher, goal T) (R, error)
+// where ctx is cancelled when the goal is preempted or the server shuts down.
+// maxGoals bounds the number of goals executing at once; it is ignored by
+// GoalPolicyReject, which only ever executes one goal.
+func NewManagedActionServer(node Node, action string, actionType ActionType, executeCb interface{}, policy GoalPolicy, maxGoals int, autoStart bool) (ManagedActionServer, error) {
+	return newManagedActionServer(node, action, actionType, executeCb, policy, maxGoals, autoStart)
+}
+
 func NewServerGoalHandlerWithGoal(as ActionServer, goal ActionGoal) (ServerGoalHandler, error) {
 	return newServerGoalHandlerWithGoal(as, goal)
 }
@@ -26,6 +42,12 @@ func NewServerGoalHandlerWithGoalId(as ActionServer, goalID ActionGoalID) Server
 
 type ActionClient interface {
 	WaitForServer(timeout Duration) bool
+	ServerConnected() bool
+	SetStatusTimeout(timeout Duration)
+	SetGoalIDGenerator(gen GoalIDGenerator)
+	AttachGoal(goalID string, transitionCallback interface{}, feedbackCallback interface{}) (ClientGoalHandler, error)
+	GetGoalHandler(goalID string) (ClientGoalHandler, bool)
+	GetServerGoalIDs() []string
 	SendGoal(goal Message, transitionCallback interface{}, feedbackCallback interface{}, goalID string) (ClientGoalHandler, error)
 	CancelAllGoals()
 	CancelAllGoalsBeforeTime(stamp Time)
@@ -39,12 +61,15 @@ type ActionServer interface {
 	PublishStatus()
 	RegisterGoalCallback(interface{})
 	RegisterCancelCallback(interface{})
+	SetGoalIDGenerator(GoalIDGenerator)
+	GetGoals() []ActionGoalInfo
 }
 
 type SimpleActionClient interface {
 	SendGoal(goal Message, doneCb, activeCb, feedbackCb interface{}, goalID string) error
 	SendGoalAndWait(goal Message, executeTimeout, preeptTimeout Duration) (uint8, error)
 	WaitForServer(timeout Duration) bool
+	ServerConnected() bool
 	WaitForResult(timeout Duration) bool
 	GetResult() (Message, error)
 	GetState() (uint8, error)
@@ -71,6 +96,27 @@ type SimpleActionServer interface {
 	RegisterPreemptCallback(callback interface{})
 }
 
+// StoppableSimpleActionServer is a SimpleActionServer which can be shut down,
+// cancelling the context of a running context execute callback. Shutdown
+// isn't part of SimpleActionServer so that existing implementations of that
+// interface remain valid.
+type StoppableSimpleActionServer interface {
+	SimpleActionServer
+	Shutdown()
+}
+
+type ManagedActionServer interface {
+	Start()
+	Shutdown()
+	NumActiveGoals() int
+	NumQueuedGoals() int
+}
+
+// FeedbackPublisher publishes feedback for a single goal.
+type FeedbackPublisher interface {
+	PublishFeedback(feedback Message)
+}
+
 type ClientGoalHandler interface {
 	IsExpired() bool
 	GetCommState() (CommState, error)
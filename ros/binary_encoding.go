@@ -0,0 +1,392 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// binaryFormat is implemented by the self-describing binary encodings, CBOR and MessagePack.  Messages are written as maps from field names to values, in definition order;
+// uint8 arrays are byte strings, other arrays are arrays, and times and durations use each format's own representation.
+type binaryFormat interface {
+	appendBool(buf []byte, value bool) []byte
+	appendInt(buf []byte, value int64) []byte
+	appendUint(buf []byte, value uint64) []byte
+	appendFloat32(buf []byte, value float32) []byte
+	appendFloat64(buf []byte, value float64) []byte
+	appendString(buf []byte, value string) []byte
+	appendBytes(buf []byte, value []byte) []byte
+	appendArrayHeader(buf []byte, length int) []byte
+	appendMapHeader(buf []byte, length int) []byte
+	appendTime(buf []byte, value Time) []byte
+	appendDuration(buf []byte, value Duration) []byte
+
+	// decodeValue reads one value into a tree of map[string]interface{}, []interface{}, []byte, string, bool, int64 (for negative integers), uint64, float32, float64, Time,
+	// Duration and nil.
+	decodeValue(r binaryReader, depth int) (interface{}, error)
+}
+
+// binaryReader is the source of encoded values; both bytes.Reader and bufio.Reader satisfy it.
+type binaryReader interface {
+	io.Reader
+	io.ByteScanner
+}
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// maxBinaryDepth limits the nesting of decoded values, so that malicious input can't exhaust the stack.
+const maxBinaryDepth = 1000
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// marshalBinary encodes a DynamicMessage or a message generated by gengo in a binary format.
+func marshalBinary(f binaryFormat, msg Message) ([]byte, error) {
+	if msg == nil {
+		return nil, errors.New("nil message")
+	}
+	return appendBinaryValue(f, nil, msg)
+}
+
+// unmarshalBinary decodes a single value in a binary format from buf into a message, which must be a *DynamicMessage or a pointer to a message generated by gengo.
+func unmarshalBinary(f binaryFormat, buf []byte, msg Message) error {
+	r := bytes.NewReader(buf)
+	if err := decodeBinary(f, r, msg); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return errors.New(strconv.Itoa(r.Len()) + " unexpected bytes after message")
+	}
+	return nil
+}
+
+// decodeBinary reads a single value in a binary format from r into a message.
+func decodeBinary(f binaryFormat, r binaryReader, msg Message) error {
+	node, err := f.decodeValue(r, 0)
+	if err != nil {
+		return err
+	}
+	return binaryIntoMessage(node, msg)
+}
+
+// appendBinaryValue encodes a value held by a DynamicMessage or a generated message.
+func appendBinaryValue(f binaryFormat, buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case *DynamicMessage:
+		if err := messagePointerError(v); err != nil {
+			return nil, err
+		}
+		if err := v.decodeLazyFields(); err != nil {
+			return nil, err
+		}
+		buf = f.appendMapHeader(buf, len(v.dynamicType.spec.Fields))
+		for _, field := range v.dynamicType.spec.Fields {
+			fieldValue, ok := v.data[field.Name]
+			if !ok {
+				return nil, errors.New("Field: " + field.Name + ": No data found.")
+			}
+			buf = f.appendString(buf, field.Name)
+			var err error
+			if buf, err = appendBinaryValue(f, buf, fieldValue); err != nil {
+				return nil, errors.Wrap(err, "Field: "+field.Name)
+			}
+		}
+		return buf, nil
+	case []Message:
+		buf = f.appendArrayHeader(buf, len(v))
+		for i, item := range v {
+			var err error
+			if buf, err = appendBinaryValue(f, buf, item); err != nil {
+				return nil, errors.Wrap(err, "index "+strconv.Itoa(i))
+			}
+		}
+		return buf, nil
+	case []uint8:
+		return f.appendBytes(buf, v), nil
+	case Time:
+		return f.appendTime(buf, v), nil
+	case Duration:
+		return f.appendDuration(buf, v), nil
+	case JsonFloat32:
+		return f.appendFloat32(buf, v.F), nil
+	case JsonFloat64:
+		return f.appendFloat64(buf, v.F), nil
+	case string:
+		return f.appendString(buf, v), nil
+	case bool:
+		return f.appendBool(buf, v), nil
+	}
+
+	// Integers of every width, messages generated by gengo, and arrays.
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f.appendInt(buf, rv.Int()), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return f.appendUint(buf, rv.Uint()), nil
+	case reflect.Float32:
+		return f.appendFloat32(buf, float32(rv.Float())), nil
+	case reflect.Float64:
+		return f.appendFloat64(buf, rv.Float()), nil
+
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil, errors.New("nil message")
+		}
+		return appendBinaryValue(f, buf, rv.Elem().Interface())
+
+	case reflect.Struct:
+		index := rosmsgFieldIndex(rv.Type())
+		buf = f.appendMapHeader(buf, len(index))
+		for i := 0; i < rv.NumField(); i++ {
+			name, ok := rosmsgFieldName(rv.Type().Field(i))
+			if !ok {
+				continue
+			}
+			buf = f.appendString(buf, name)
+			var err error
+			if buf, err = appendBinaryValue(f, buf, rv.Field(i).Interface()); err != nil {
+				return nil, errors.Wrap(err, "Field: "+name)
+			}
+		}
+		return buf, nil
+
+	case reflect.Slice, reflect.Array:
+		// Fixed size uint8 arrays of generated messages are byte strings too.
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(data), rv)
+			return f.appendBytes(buf, data), nil
+		}
+		buf = f.appendArrayHeader(buf, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			var err error
+			if buf, err = appendBinaryValue(f, buf, rv.Index(i).Interface()); err != nil {
+				return nil, errors.Wrap(err, "index "+strconv.Itoa(i))
+			}
+		}
+		return buf, nil
+	}
+
+	return nil, errors.New("cannot encode " + describeValue(value))
+}
+
+// binaryIntoMessage stores a decoded tree into a message; fields which aren't given are zeroed.
+func binaryIntoMessage(node interface{}, msg Message) error {
+	if dynamic, ok := msg.(*DynamicMessage); ok {
+		if dynamic == nil || dynamic.dynamicType == nil || dynamic.dynamicType.spec == nil {
+			return errors.New("invalid dynamic message")
+		}
+		mapping, ok := node.(map[string]interface{})
+		if !ok {
+			return errors.New("expected a map for " + dynamic.dynamicType.Name() + ", got " + describeValue(node))
+		}
+		// The tree holds the same kinds of values as decoded JSON, so can be coerced in the same way.
+		result, err := dynamic.dynamicType.NewDynamicMessageFromMap(mapping)
+		if err != nil {
+			return err
+		}
+		dynamic.data = result.data
+		dynamic.raw = nil
+		dynamic.rawOffsets = nil
+		return nil
+	}
+
+	target := reflect.ValueOf(msg)
+	if !target.IsValid() || target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return errors.New("expected a pointer to a generated message struct")
+	}
+	zero := reflect.New(target.Elem().Type()).Elem()
+	if err := binaryIntoValue(node, zero); err != nil {
+		return err
+	}
+	target.Elem().Set(zero)
+	return nil
+}
+
+// binaryIntoValue stores a decoded tree into a field of a generated message struct.
+func binaryIntoValue(node interface{}, target reflect.Value) error {
+	if node == nil {
+		return nil // Missing values are zeroed.
+	}
+
+	switch target.Type() {
+	case timeType:
+		switch v := node.(type) {
+		case Time:
+			target.Set(reflect.ValueOf(v))
+			return nil
+		case map[string]interface{}:
+			return binaryTemporalInto(v, target)
+		}
+		return errors.New("cannot use " + describeValue(node) + " as time")
+	case durationType:
+		switch v := node.(type) {
+		case Duration:
+			target.Set(reflect.ValueOf(v))
+			return nil
+		case map[string]interface{}:
+			return binaryTemporalInto(v, target)
+		}
+		return errors.New("cannot use " + describeValue(node) + " as duration")
+	}
+
+	switch target.Kind() {
+	case reflect.Struct:
+		mapping, ok := node.(map[string]interface{})
+		if !ok {
+			return errors.New("expected a map for " + target.Type().String() + ", got " + describeValue(node))
+		}
+		index := rosmsgFieldIndex(target.Type())
+		for key, value := range mapping {
+			i, ok := index[key]
+			if !ok {
+				return errors.New("Field: " + key + ": unknown field in " + target.Type().String())
+			}
+			if err := binaryIntoValue(value, target.Field(i)); err != nil {
+				return errors.Wrap(err, "Field: "+key)
+			}
+		}
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		var length int
+		var element func(i int) interface{}
+		switch v := node.(type) {
+		case []byte:
+			length, element = len(v), func(i int) interface{} { return uint64(v[i]) }
+		case []interface{}:
+			length, element = len(v), func(i int) interface{} { return v[i] }
+		default:
+			return errors.New("expected an array, got " + describeValue(node))
+		}
+		if target.Kind() == reflect.Slice {
+			target.Set(reflect.MakeSlice(target.Type(), length, length))
+		} else if target.Len() != length {
+			return errors.New("expected array of length " + strconv.Itoa(target.Len()) + ", got " + strconv.Itoa(length))
+		}
+		for i := 0; i < length; i++ {
+			if err := binaryIntoValue(element(i), target.Index(i)); err != nil {
+				return errors.Wrap(err, "index "+strconv.Itoa(i))
+			}
+		}
+		return nil
+
+	case reflect.Bool:
+		if b, ok := node.(bool); ok {
+			target.SetBool(b)
+			return nil
+		}
+	case reflect.String:
+		if s, ok := node.(string); ok {
+			target.SetString(s)
+			return nil
+		}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// The kinds of the generated fields are named as the integer types of DynamicMessages are.
+		value, err := coerceInteger(target.Kind().String(), node)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(value).Convert(target.Type()))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if f, ok := goNumberToFloat64(node); ok {
+			target.SetFloat(f)
+			return nil
+		}
+	}
+	return errors.New("cannot store " + describeValue(node) + " in " + target.Type().String())
+}
+
+// binaryTemporalInto stores a map with sec and nsec keys into a time or duration field of a generated message struct.
+func binaryTemporalInto(mapping map[string]interface{}, target reflect.Value) error {
+	if len(mapping) != 2 {
+		return errors.New("expected map with keys sec and nsec")
+	}
+	for _, key := range []string{"sec", "nsec"} {
+		part, ok := mapping[key]
+		if !ok {
+			return errors.New("expected map with keys sec and nsec")
+		}
+		value, err := coerceInteger("uint32", part)
+		if err != nil {
+			return errors.Wrap(err, key)
+		}
+		if key == "sec" {
+			target.FieldByName("Sec").SetUint(uint64(value.(uint32)))
+		} else {
+			target.FieldByName("NSec").SetUint(uint64(value.(uint32)))
+		}
+	}
+	return nil
+}
+
+// readBinaryBytes reads a byte or text string of the specified length.  The string is read in chunks rather than allocated up front, so that a corrupt length fails at the end
+// of the input rather than exhausting memory.
+func readBinaryBytes(r binaryReader, length uint64) ([]byte, error) {
+	if length > math.MaxInt32 {
+		return nil, errors.New("string length " + strconv.FormatUint(length, 10) + " too long")
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(length)); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// readBinaryUint reads a big-endian unsigned integer of the specified number of bytes.
+func readBinaryUint(r binaryReader, size int) (uint64, error) {
+	var value uint64
+	for i := 0; i < size; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		value = value<<8 | uint64(b)
+	}
+	return value, nil
+}
+
+// appendBigEndian appends the low size bytes of an unsigned integer, most significant first.
+func appendBigEndian(buf []byte, value uint64, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		buf = append(buf, byte(value>>(8*uint(i))))
+	}
+	return buf
+}
+
+// binaryContainerCapacity limits the initial capacity of a decoded array or map, whose length hasn't been checked against the input yet.
+func binaryContainerCapacity(length uint64) int {
+	if length > 1024 {
+		return 1024
+	}
+	return int(length)
+}
+
+// unexpectedEOF converts io.EOF part way through a value into io.ErrUnexpectedEOF.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// ALL DONE.
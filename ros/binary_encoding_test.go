@@ -0,0 +1,278 @@
+package ros
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"math"
+	"testing"
+)
+
+type binaryTestFormat struct {
+	name      string
+	marshal   func(Message) ([]byte, error)
+	unmarshal func([]byte, Message) error
+}
+
+var binaryTestFormats = []binaryTestFormat{
+	{"cbor", MarshalCBOR, UnmarshalCBOR},
+	{"msgpack", MarshalMsgPack, UnmarshalMsgPack},
+}
+
+func newBinaryTestType(t *testing.T) *DynamicMessageType {
+	return newRegisteredTestType(t, "test_msgs/Binary", "int8 a\nuint8[] b\ntime t")
+}
+
+func TestBinaryEncoding_RoundTripDynamicMessage(t *testing.T) {
+	msg := newJSONOptionsTestMessage(t)
+	if err := msg.Set("max_count", int32(math.MinInt32)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range binaryTestFormats {
+		buf, err := format.marshal(msg)
+		if err != nil {
+			t.Fatalf("%s: %v", format.name, err)
+		}
+		result := msg.dynamicType.NewDynamicMessage()
+		if err := format.unmarshal(buf, result); err != nil {
+			t.Fatalf("%s: %v", format.name, err)
+		}
+		if diff, err := msg.Diff(result, WithNaNEqual()); err != nil || len(diff) != 0 {
+			t.Fatalf("%s: unmarshalled message differs: %v, %v", format.name, diff, err)
+		}
+		if data, _ := result.Get("data"); len(data.([]uint8)) != 3 {
+			t.Fatalf("%s: expected uint8 array, got %#v", format.name, data)
+		}
+	}
+
+	// Nested messages, arrays of messages and fixed length arrays.
+	path := newPathTestType(t).NewDynamicMessage()
+	if err := path.Set("points", []interface{}{map[string]interface{}{"x": 1.5}, map[string]interface{}{"z": -2.0}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := path.Set("counts", []int32{7, -8, 9}); err != nil {
+		t.Fatal(err)
+	}
+	if err := path.Set("pose.id", 200); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range binaryTestFormats {
+		buf, err := format.marshal(path)
+		if err != nil {
+			t.Fatalf("%s: %v", format.name, err)
+		}
+		result := path.dynamicType.NewDynamicMessage()
+		if err := format.unmarshal(buf, result); err != nil {
+			t.Fatalf("%s: %v", format.name, err)
+		}
+		if diff, err := path.Diff(result); err != nil || len(diff) != 0 {
+			t.Fatalf("%s: unmarshalled message differs: %v, %v", format.name, diff, err)
+		}
+	}
+}
+
+func TestBinaryEncoding_RoundTripGeneratedMessage(t *testing.T) {
+	msgType := newPathTestType(t)
+	original := &testPath{
+		msgType: msgType,
+		Stamp:   NewTime(1577934245, 500000000),
+		Period:  NewDuration(uint32(0xfffffffe), 250000000),
+		FrameID: "map",
+		Pose:    testPose{Position: testPoint{X: 1, Y: 2, Z: 3}, ID: 255},
+		Points:  []testPoint{{X: 5}, {Y: 6}},
+		Counts:  [3]int32{7, -8, 9},
+		Scale:   0.5,
+	}
+
+	for _, format := range binaryTestFormats {
+		buf, err := format.marshal(original)
+		if err != nil {
+			t.Fatalf("%s: %v", format.name, err)
+		}
+
+		// Generated and dynamic messages are encoded the same way.
+		msg := msgType.NewDynamicMessage()
+		if err := msg.FromMessage(original); err != nil {
+			t.Fatal(err)
+		}
+		if dynamic, err := format.marshal(msg); err != nil || !bytes.Equal(dynamic, buf) {
+			t.Fatalf("%s: expected %x, got %x, %v", format.name, buf, dynamic, err)
+		}
+
+		result := &testPath{msgType: msgType, FrameID: "stale", Unmapped: 1}
+		if err := format.unmarshal(buf, result); err != nil {
+			t.Fatalf("%s: %v", format.name, err)
+		}
+		if result.Stamp != original.Stamp || result.Period != original.Period || result.FrameID != original.FrameID ||
+			result.Pose != original.Pose || result.Counts != original.Counts || result.Scale != original.Scale ||
+			len(result.Points) != 2 || result.Points[0] != original.Points[0] || result.Points[1] != original.Points[1] || result.Unmapped != 0 {
+			t.Fatalf("%s: round trip mismatch: expected %+v, got %+v", format.name, original, result)
+		}
+	}
+}
+
+func TestBinaryEncoding_KnownEncodings(t *testing.T) {
+	msg, err := newBinaryTestType(t).NewDynamicMessageFromMap(map[string]interface{}{"a": -1, "b": []byte{1, 2}, "t": NewTime(1, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		format   binaryTestFormat
+		expected string
+	}{
+		{binaryTestFormats[0], "a36161206162420102" + "6174d903e9a10101"},
+		{binaryTestFormats[1], "83a161ffa162c4020102a174d6ff00000001"},
+	}
+	for _, testCase := range testCases {
+		expected, _ := hex.DecodeString(testCase.expected)
+		buf, err := testCase.format.marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, expected) {
+			t.Fatalf("%s: expected %x, got %x", testCase.format.name, expected, buf)
+		}
+	}
+}
+
+func TestBinaryEncoding_DecodeAlternatives(t *testing.T) {
+	msgType := newBinaryTestType(t)
+
+	testCases := []struct {
+		format binaryTestFormat
+		input  string
+		a      int8
+		b      []byte
+		t      Time
+	}{
+		// Indefinite length map, array and chunked byte string, with an epoch time.
+		{binaryTestFormats[0], "bf6161206162" + "5f4101420203ff" + "6174c1fb3ff8000000000000" + "ff", -1, []byte{1, 2, 3}, NewTime(1, 500000000)},
+		// RFC 3339 time, a half float integer and an array for the bytes.
+		{binaryTestFormats[0], "a36161f93c006162820102" + "6174c077" + hex.EncodeToString([]byte("1970-01-01T00:00:02.25Z")), 1, []byte{1, 2}, NewTime(2, 250000000)},
+		// Extended time with nanoseconds.
+		{binaryTestFormats[0], "a16174d903e9a2010228181a", 0, nil, NewTime(2, 26)},
+		// 64 bit timestamp and a wide integer.
+		{binaryTestFormats[1], "82a161d1fffea174d7ff0000000800000003", -2, nil, NewTime(3, 2)},
+		// 96 bit timestamp and an array for the bytes.
+		{binaryTestFormats[1], "82a16292cc0102a174c70cff000000050000000000000004", 0, []byte{1, 2}, NewTime(4, 5)},
+	}
+	for _, testCase := range testCases {
+		input, err := hex.DecodeString(testCase.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := msgType.NewDynamicMessage()
+		if err := testCase.format.unmarshal(input, msg); err != nil {
+			t.Fatalf("%s %s: %v", testCase.format.name, testCase.input, err)
+		}
+		a, _ := msg.Get("a")
+		b, _ := msg.Get("b")
+		stamp, _ := msg.Get("t")
+		if a != testCase.a || !bytes.Equal(b.([]uint8), testCase.b) || stamp != testCase.t {
+			t.Fatalf("%s %s: unexpected %v, %v, %v", testCase.format.name, testCase.input, a, b, stamp)
+		}
+	}
+}
+
+func TestBinaryEncoding_Errors(t *testing.T) {
+	msgType := newPathTestType(t)
+	msg := msgType.NewDynamicMessage()
+
+	for _, format := range binaryTestFormats {
+		buf, err := format.marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := format.unmarshal(buf[:len(buf)-1], msgType.NewDynamicMessage()); err != io.ErrUnexpectedEOF {
+			t.Errorf("%s: expected unexpected EOF for truncated input, got %v", format.name, err)
+		}
+		if err := format.unmarshal(append(buf, 0), msgType.NewDynamicMessage()); err == nil {
+			t.Errorf("%s: expected error for trailing bytes", format.name)
+		}
+		if _, err := format.marshal(&DynamicMessage{}); err == nil {
+			t.Errorf("%s: expected error for invalid message", format.name)
+		}
+	}
+
+	errorCases := []struct {
+		format binaryTestFormat
+		input  string
+	}{
+		{binaryTestFormats[0], "a1656f74686572f5"},                   // Unknown field.
+		{binaryTestFormats[0], "a1657363616c6563616263"},             // Wrong type.
+		{binaryTestFormats[0], "a166636f756e74738201" + "02"},        // Wrong fixed array length.
+		{binaryTestFormats[0], "a10101"},                             // Integer key.
+		{binaryTestFormats[0], "a1657374616d70d903e9a1013a7fffffff"}, // Negative time.
+		{binaryTestFormats[0], "a1657374616d70d903e9a20101286178"},   // Invalid nanoseconds type.
+		{binaryTestFormats[0], "a1657374616d70c1fbbff0000000000000"}, // Time before the epoch.
+		{binaryTestFormats[0], "a1657363616c65fc"},                   // Reserved initial byte.
+		{binaryTestFormats[1], "81a7706f73652e696401"},               // Unknown field.
+		{binaryTestFormats[1], "81a6706572696f64c1"},                 // Invalid format.
+		{binaryTestFormats[1], "81a6636f756e747393010203" + "04"},    // Trailing byte after map.
+		{binaryTestFormats[1], "81a6636f756e747393ceffffffff0203"},   // Out of range.
+		{binaryTestFormats[1], "81a57374616d70d4ff01"},               // Invalid timestamp length.
+		{binaryTestFormats[1], "81a57374616d70d60500000000"},         // Unsupported extension.
+	}
+	for _, errorCase := range errorCases {
+		input, err := hex.DecodeString(errorCase.input)
+		if err != nil {
+			t.Fatalf("%s: %v", errorCase.input, err)
+		}
+		if err := errorCase.format.unmarshal(input, msgType.NewDynamicMessage()); err == nil {
+			t.Errorf("%s %s: expected error", errorCase.format.name, errorCase.input)
+		}
+		if err := errorCase.format.unmarshal(input, &testPath{msgType: msgType}); err == nil {
+			t.Errorf("%s %s: expected error for generated message", errorCase.format.name, errorCase.input)
+		}
+	}
+
+	// Deeply nested input fails without exhausting the stack.
+	deep := bytes.Repeat([]byte{0x81}, 2*maxBinaryDepth)
+	if err := UnmarshalCBOR(deep, msgType.NewDynamicMessage()); err == nil {
+		t.Error("expected error for deeply nested input")
+	}
+}
+
+func TestBinaryEncoding_Streams(t *testing.T) {
+	msgType := newBinaryTestType(t)
+	messages := make([]*DynamicMessage, 3)
+	for i := range messages {
+		msg, err := msgType.NewDynamicMessageFromMap(map[string]interface{}{"a": i, "b": bytes.Repeat([]byte{byte(i)}, 300*i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages[i] = msg
+	}
+
+	var cborStream, msgPackStream bytes.Buffer
+	cborEncoder, msgPackEncoder := NewCBOREncoder(&cborStream), NewMsgPackEncoder(&msgPackStream)
+	for _, msg := range messages {
+		if err := cborEncoder.Encode(msg); err != nil {
+			t.Fatal(err)
+		}
+		if err := msgPackEncoder.Encode(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	decoders := map[string]func(Message) error{
+		"cbor":    NewCBORDecoder(&cborStream).Decode,
+		"msgpack": NewMsgPackDecoder(&msgPackStream).Decode,
+	}
+	for name, decode := range decoders {
+		for i, expected := range messages {
+			result := msgType.NewDynamicMessage()
+			if err := decode(result); err != nil {
+				t.Fatalf("%s %d: %v", name, i, err)
+			}
+			if diff, err := expected.Diff(result); err != nil || len(diff) != 0 {
+				t.Fatalf("%s %d: decoded message differs: %v, %v", name, i, diff, err)
+			}
+		}
+		if err := decode(msgType.NewDynamicMessage()); err != io.EOF {
+			t.Fatalf("%s: expected EOF at end of stream, got %v", name, err)
+		}
+	}
+}
@@ -1,6 +1,14 @@
 package ros
 
-import "bytes"
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
 
 // ByteDecoder provides an interface that dynamic message deserialization expects for decoding ROS messages.
 type ByteDecoder interface {
@@ -36,3 +44,395 @@ type ByteDecoder interface {
 	DecodeDuration(buf *bytes.Reader) (Duration, error)
 	DecodeMessage(buf *bytes.Reader, msgType *DynamicMessageType) (Message, error)
 }
+
+// byteOrderDecoder decodes the TCPROS message layout in a byte order; LEByteDecoder and BEByteDecoder decode with an instance of it.  Messages aren't decoded by it, so that
+// nested messages are decoded by the decoder which holds it.
+type byteOrderDecoder struct {
+	order binary.ByteOrder
+}
+
+// leDecoder and beDecoder are the decoders of LEByteDecoder and BEByteDecoder.
+var leDecoder = byteOrderDecoder{binary.LittleEndian}
+var beDecoder = byteOrderDecoder{binary.BigEndian}
+
+// readElements reads size elements of elemSize bytes from the buffer into the memory of the array at ptr, passing the bytes of each to set along with its index, so that set
+// converts the elements from the byte order in place.  Arrays too large to view as bytes are read an element at a time instead.
+func readElements(buf *bytes.Reader, ptr unsafe.Pointer, elemSize int, size int, set func(i int, p []byte)) error {
+	if n := elemSize * size; n <= maxAliasBytes {
+		p := bytesOf(ptr, n)
+		if _, err := io.ReadFull(buf, p); err != nil {
+			return errors.New("Could not read array from buffer")
+		}
+		for i := 0; i < size; i++ {
+			set(i, p[elemSize*i:])
+		}
+		return nil
+	}
+	var arr [8]byte
+	for i := 0; i < size; i++ {
+		if _, err := io.ReadFull(buf, arr[:elemSize]); err != nil {
+			return errors.New("Could not read array from buffer")
+		}
+		set(i, arr[:elemSize])
+	}
+	return nil
+}
+
+// decodeMessage decodes a DynamicMessage with the decoder.
+func decodeMessage(d ByteDecoder, buf *bytes.Reader, msgType *DynamicMessageType) (Message, error) {
+	// Skip the zero value initialization, this would just get discarded anyway.
+	msg := &DynamicMessage{}
+	msg.dynamicType = msgType
+	if err := msg.deserialize(d, buf); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// decodeMessageArray decodes an array of DynamicMessages with the decoder.
+func decodeMessageArray(d ByteDecoder, buf *bytes.Reader, size int, msgType *DynamicMessageType) ([]Message, error) {
+	slice := make([]Message, size)
+
+	for i := 0; i < size; i++ {
+		msg, err := decodeMessage(d, buf, msgType)
+		if err != nil {
+			return slice, err
+		}
+		slice[i] = msg
+	}
+	return slice, nil
+}
+
+// Array decoders.
+
+// decodeBools decodes the elements of an array of boolean values.
+func (d byteOrderDecoder) decodeBools(buf *bytes.Reader, s []bool) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return readElements(buf, unsafe.Pointer(&s[0]), 1, len(s), func(i int, p []byte) { s[i] = (p[0] != 0x00) })
+}
+
+// decodeInt8s decodes the elements of an array of int8 values.
+func (d byteOrderDecoder) decodeInt8s(buf *bytes.Reader, s []int8) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return readElements(buf, unsafe.Pointer(&s[0]), 1, len(s), func(i int, p []byte) { s[i] = int8(p[0]) })
+}
+
+// decodeUint8s decodes the elements of an array of uint8 values.
+func (d byteOrderDecoder) decodeUint8s(buf *bytes.Reader, s []uint8) error {
+	if len(s) == 0 {
+		// Early return to avoid potentail EOF error.
+		return nil
+	}
+	if n, err := buf.Read(s); n != len(s) || err != nil {
+		return errors.New("Did not read entire uint8 buffer")
+	}
+	return nil
+}
+
+// decodeInt16s decodes the elements of an array of int16 values.
+func (d byteOrderDecoder) decodeInt16s(buf *bytes.Reader, s []int16) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return readElements(buf, unsafe.Pointer(&s[0]), 2, len(s), func(i int, p []byte) { s[i] = int16(d.order.Uint16(p)) })
+}
+
+// decodeUint16s decodes the elements of an array of uint16 values.
+func (d byteOrderDecoder) decodeUint16s(buf *bytes.Reader, s []uint16) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return readElements(buf, unsafe.Pointer(&s[0]), 2, len(s), func(i int, p []byte) { s[i] = d.order.Uint16(p) })
+}
+
+// decodeInt32s decodes the elements of an array of int32 values.
+func (d byteOrderDecoder) decodeInt32s(buf *bytes.Reader, s []int32) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return readElements(buf, unsafe.Pointer(&s[0]), 4, len(s), func(i int, p []byte) { s[i] = int32(d.order.Uint32(p)) })
+}
+
+// decodeUint32s decodes the elements of an array of uint32 values.
+func (d byteOrderDecoder) decodeUint32s(buf *bytes.Reader, s []uint32) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return readElements(buf, unsafe.Pointer(&s[0]), 4, len(s), func(i int, p []byte) { s[i] = d.order.Uint32(p) })
+}
+
+// decodeFloat32s decodes the elements of an array of float32 values.
+func (d byteOrderDecoder) decodeFloat32s(buf *bytes.Reader, s []JsonFloat32) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return readElements(buf, unsafe.Pointer(&s[0]), 4, len(s), func(i int, p []byte) { s[i] = JsonFloat32{F: math.Float32frombits(d.order.Uint32(p))} })
+}
+
+// decodeInt64s decodes the elements of an array of int64 values.
+func (d byteOrderDecoder) decodeInt64s(buf *bytes.Reader, s []int64) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return readElements(buf, unsafe.Pointer(&s[0]), 8, len(s), func(i int, p []byte) { s[i] = int64(d.order.Uint64(p)) })
+}
+
+// decodeUint64s decodes the elements of an array of uint64 values.
+func (d byteOrderDecoder) decodeUint64s(buf *bytes.Reader, s []uint64) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return readElements(buf, unsafe.Pointer(&s[0]), 8, len(s), func(i int, p []byte) { s[i] = d.order.Uint64(p) })
+}
+
+// decodeFloat64s decodes the elements of an array of float64 values.
+func (d byteOrderDecoder) decodeFloat64s(buf *bytes.Reader, s []JsonFloat64) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return readElements(buf, unsafe.Pointer(&s[0]), 8, len(s), func(i int, p []byte) { s[i] = JsonFloat64{F: math.Float64frombits(d.order.Uint64(p))} })
+}
+
+// DecodeBoolArray decodes an array of boolean values.
+func (d byteOrderDecoder) DecodeBoolArray(buf *bytes.Reader, size int) ([]bool, error) {
+	slice := make([]bool, size)
+	return slice, d.decodeBools(buf, slice)
+}
+
+// DecodeInt8Array decodes an array of int8 values.
+func (d byteOrderDecoder) DecodeInt8Array(buf *bytes.Reader, size int) ([]int8, error) {
+	slice := make([]int8, size)
+	return slice, d.decodeInt8s(buf, slice)
+}
+
+// DecodeUint8Array decodes an array of uint8 values.
+func (d byteOrderDecoder) DecodeUint8Array(buf *bytes.Reader, size int) ([]uint8, error) {
+	slice := make([]uint8, size)
+	return slice, d.decodeUint8s(buf, slice)
+}
+
+// DecodeInt16Array decodes an array of int16 values.
+func (d byteOrderDecoder) DecodeInt16Array(buf *bytes.Reader, size int) ([]int16, error) {
+	slice := make([]int16, size)
+	return slice, d.decodeInt16s(buf, slice)
+}
+
+// DecodeUint16Array decodes an array of uint16 values.
+func (d byteOrderDecoder) DecodeUint16Array(buf *bytes.Reader, size int) ([]uint16, error) {
+	slice := make([]uint16, size)
+	return slice, d.decodeUint16s(buf, slice)
+}
+
+// DecodeInt32Array decodes an array of int32 values.
+func (d byteOrderDecoder) DecodeInt32Array(buf *bytes.Reader, size int) ([]int32, error) {
+	slice := make([]int32, size)
+	return slice, d.decodeInt32s(buf, slice)
+}
+
+// DecodeUint32Array decodes an array of uint32 values.
+func (d byteOrderDecoder) DecodeUint32Array(buf *bytes.Reader, size int) ([]uint32, error) {
+	slice := make([]uint32, size)
+	return slice, d.decodeUint32s(buf, slice)
+}
+
+// DecodeFloat32Array decodes an array of float32 values.
+func (d byteOrderDecoder) DecodeFloat32Array(buf *bytes.Reader, size int) ([]JsonFloat32, error) {
+	slice := make([]JsonFloat32, size)
+	return slice, d.decodeFloat32s(buf, slice)
+}
+
+// DecodeInt64Array decodes an array of int64 values.
+func (d byteOrderDecoder) DecodeInt64Array(buf *bytes.Reader, size int) ([]int64, error) {
+	slice := make([]int64, size)
+	return slice, d.decodeInt64s(buf, slice)
+}
+
+// DecodeUint64Array decodes an array of uint64 values.
+func (d byteOrderDecoder) DecodeUint64Array(buf *bytes.Reader, size int) ([]uint64, error) {
+	slice := make([]uint64, size)
+	return slice, d.decodeUint64s(buf, slice)
+}
+
+// DecodeFloat64Array decodes an array of float64 values.
+func (d byteOrderDecoder) DecodeFloat64Array(buf *bytes.Reader, size int) ([]JsonFloat64, error) {
+	slice := make([]JsonFloat64, size)
+	return slice, d.decodeFloat64s(buf, slice)
+}
+
+// DecodeStringArray decodes an array of strings.
+func (d byteOrderDecoder) DecodeStringArray(buf *bytes.Reader, size int) ([]string, error) {
+	var err error
+
+	slice := make([]string, size)
+	for i := 0; i < size; i++ {
+		if slice[i], err = d.DecodeString(buf); err != nil {
+			return slice, err
+		}
+	}
+	return slice, nil
+}
+
+// DecodeTimeArray decodes an array of Time structs.
+func (d byteOrderDecoder) DecodeTimeArray(buf *bytes.Reader, size int) ([]Time, error) {
+	var err error
+
+	slice := make([]Time, size)
+	for i := 0; i < size; i++ {
+		if slice[i], err = d.DecodeTime(buf); err != nil {
+			return slice, err
+		}
+	}
+	return slice, nil
+}
+
+// DecodeDurationArray decodes an array of Duration structs.
+func (d byteOrderDecoder) DecodeDurationArray(buf *bytes.Reader, size int) ([]Duration, error) {
+	var err error
+
+	slice := make([]Duration, size)
+	for i := 0; i < size; i++ {
+		if slice[i], err = d.DecodeDuration(buf); err != nil {
+			return slice, err
+		}
+	}
+	return slice, nil
+}
+
+// Singular decodes.
+
+// DecodeBool decodes a boolean.
+func (d byteOrderDecoder) DecodeBool(buf *bytes.Reader) (bool, error) {
+	raw, err := d.DecodeUint8(buf)
+	return (raw != 0x00), err
+}
+
+// DecodeInt8 decodes a int8.
+func (d byteOrderDecoder) DecodeInt8(buf *bytes.Reader) (int8, error) {
+	raw, err := d.DecodeUint8(buf)
+	return int8(raw), err
+}
+
+// DecodeUint8 decodes a uint8.
+func (d byteOrderDecoder) DecodeUint8(buf *bytes.Reader) (uint8, error) {
+	var arr [1]byte
+
+	if n, err := buf.Read(arr[:]); n != 1 || err != nil {
+		return 0, errors.New("Could not read 1 byte from buffer")
+	}
+
+	return arr[0], nil
+}
+
+// DecodeInt16 decodes a int16.
+func (d byteOrderDecoder) DecodeInt16(buf *bytes.Reader) (int16, error) {
+	raw, err := d.DecodeUint16(buf)
+	return int16(raw), err
+}
+
+// DecodeUint16 decodes a uint16.
+func (d byteOrderDecoder) DecodeUint16(buf *bytes.Reader) (uint16, error) {
+	var arr [2]byte
+
+	if n, err := buf.Read(arr[:]); n != 2 || err != nil {
+		return 0, errors.New("Could not read 2 bytes from buffer")
+	}
+
+	return d.order.Uint16(arr[:]), nil
+}
+
+// DecodeInt32 decodes a int32.
+func (d byteOrderDecoder) DecodeInt32(buf *bytes.Reader) (int32, error) {
+	raw, err := d.DecodeUint32(buf)
+	return int32(raw), err
+}
+
+// DecodeUint32 decodes a uint32.
+func (d byteOrderDecoder) DecodeUint32(buf *bytes.Reader) (uint32, error) {
+	var arr [4]byte
+
+	if n, err := buf.Read(arr[:]); n != 4 || err != nil {
+		return 0, errors.New("Could not read 4 bytes from buffer")
+	}
+
+	return d.order.Uint32(arr[:]), nil
+}
+
+// DecodeFloat32 decodes a JsonFloat32.
+func (d byteOrderDecoder) DecodeFloat32(buf *bytes.Reader) (JsonFloat32, error) {
+	raw, err := d.DecodeUint32(buf)
+	return JsonFloat32{F: math.Float32frombits(raw)}, err
+}
+
+// DecodeInt64 decodes a int64.
+func (d byteOrderDecoder) DecodeInt64(buf *bytes.Reader) (int64, error) {
+	raw, err := d.DecodeUint64(buf)
+	return int64(raw), err
+}
+
+// DecodeUint64 decodes a uint64.
+func (d byteOrderDecoder) DecodeUint64(buf *bytes.Reader) (uint64, error) {
+	var arr [8]byte
+
+	if n, err := buf.Read(arr[:]); n != 8 || err != nil {
+		return 0, errors.New("Could not read 8 bytes from buffer")
+	}
+
+	return d.order.Uint64(arr[:]), nil
+}
+
+// DecodeFloat64 decodes a JsonFloat64.
+func (d byteOrderDecoder) DecodeFloat64(buf *bytes.Reader) (JsonFloat64, error) {
+	raw, err := d.DecodeUint64(buf)
+	return JsonFloat64{F: math.Float64frombits(raw)}, err
+}
+
+// DecodeString decodes a string.
+func (d byteOrderDecoder) DecodeString(buf *bytes.Reader) (string, error) {
+	// String format is: [size|string] where size is a u32.
+	strSize, err := d.DecodeUint32(buf)
+	if err != nil {
+		return "", err
+	}
+	value := make([]uint8, strSize)
+	if err = d.decodeUint8s(buf, value); err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// DecodeTime decodes a Time struct.
+func (d byteOrderDecoder) DecodeTime(buf *bytes.Reader) (Time, error) {
+	var err error
+	var value Time
+
+	// Time format is: [sec|nanosec] where sec and nanosec are unsigned integers.
+	if value.Sec, err = d.DecodeUint32(buf); err != nil {
+		return Time{}, err
+	}
+	if value.NSec, err = d.DecodeUint32(buf); err != nil {
+		return Time{}, err
+	}
+
+	return value, nil
+}
+
+// DecodeDuration decodes a Duraction struct.
+func (d byteOrderDecoder) DecodeDuration(buf *bytes.Reader) (Duration, error) {
+	var err error
+	var value Duration
+
+	// Duration format is: [sec|nanosec] where sec and nanosec are unsigned integers.
+	if value.Sec, err = d.DecodeUint32(buf); err != nil {
+		return Duration{}, err
+	}
+	if value.NSec, err = d.DecodeUint32(buf); err != nil {
+		return Duration{}, err
+	}
+
+	return value, nil
+}
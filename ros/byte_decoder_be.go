@@ -0,0 +1,163 @@
+package ros
+
+import "bytes"
+
+// BEByteDecoder is a big-endian byte decoder, implements the ByteDecoder interface.  It reads the TCPROS message layout with the byte order swapped, as used by some legacy
+// embedded bridges.
+type BEByteDecoder struct{}
+
+var _ ByteDecoder = BEByteDecoder{}
+
+// Array decoders.
+
+// DecodeBoolArray decodes an array of boolean values.
+func (d BEByteDecoder) DecodeBoolArray(buf *bytes.Reader, size int) ([]bool, error) {
+	return beDecoder.DecodeBoolArray(buf, size)
+}
+
+// DecodeInt8Array decodes an array of int8 values.
+func (d BEByteDecoder) DecodeInt8Array(buf *bytes.Reader, size int) ([]int8, error) {
+	return beDecoder.DecodeInt8Array(buf, size)
+}
+
+// DecodeUint8Array decodes an array of uint8 values.
+func (d BEByteDecoder) DecodeUint8Array(buf *bytes.Reader, size int) ([]uint8, error) {
+	return beDecoder.DecodeUint8Array(buf, size)
+}
+
+// DecodeInt16Array decodes an array of int16 values.
+func (d BEByteDecoder) DecodeInt16Array(buf *bytes.Reader, size int) ([]int16, error) {
+	return beDecoder.DecodeInt16Array(buf, size)
+}
+
+// DecodeUint16Array decodes an array of uint16 values.
+func (d BEByteDecoder) DecodeUint16Array(buf *bytes.Reader, size int) ([]uint16, error) {
+	return beDecoder.DecodeUint16Array(buf, size)
+}
+
+// DecodeInt32Array decodes an array of int32 values.
+func (d BEByteDecoder) DecodeInt32Array(buf *bytes.Reader, size int) ([]int32, error) {
+	return beDecoder.DecodeInt32Array(buf, size)
+}
+
+// DecodeUint32Array decodes an array of uint32 values.
+func (d BEByteDecoder) DecodeUint32Array(buf *bytes.Reader, size int) ([]uint32, error) {
+	return beDecoder.DecodeUint32Array(buf, size)
+}
+
+// DecodeFloat32Array decodes an array of float32 values.
+func (d BEByteDecoder) DecodeFloat32Array(buf *bytes.Reader, size int) ([]JsonFloat32, error) {
+	return beDecoder.DecodeFloat32Array(buf, size)
+}
+
+// DecodeInt64Array decodes an array of int64 values.
+func (d BEByteDecoder) DecodeInt64Array(buf *bytes.Reader, size int) ([]int64, error) {
+	return beDecoder.DecodeInt64Array(buf, size)
+}
+
+// DecodeUint64Array decodes an array of uint64 values.
+func (d BEByteDecoder) DecodeUint64Array(buf *bytes.Reader, size int) ([]uint64, error) {
+	return beDecoder.DecodeUint64Array(buf, size)
+}
+
+// DecodeFloat64Array decodes an array of float64 values.
+func (d BEByteDecoder) DecodeFloat64Array(buf *bytes.Reader, size int) ([]JsonFloat64, error) {
+	return beDecoder.DecodeFloat64Array(buf, size)
+}
+
+// DecodeStringArray decodes an array of strings.
+func (d BEByteDecoder) DecodeStringArray(buf *bytes.Reader, size int) ([]string, error) {
+	return beDecoder.DecodeStringArray(buf, size)
+}
+
+// DecodeTimeArray decodes an array of Time structs.
+func (d BEByteDecoder) DecodeTimeArray(buf *bytes.Reader, size int) ([]Time, error) {
+	return beDecoder.DecodeTimeArray(buf, size)
+}
+
+// DecodeDurationArray decodes an array of Duration structs.
+func (d BEByteDecoder) DecodeDurationArray(buf *bytes.Reader, size int) ([]Duration, error) {
+	return beDecoder.DecodeDurationArray(buf, size)
+}
+
+// DecodeMessageArray decodes an array of DynamicMessages.
+func (d BEByteDecoder) DecodeMessageArray(buf *bytes.Reader, size int, msgType *DynamicMessageType) ([]Message, error) {
+	return decodeMessageArray(d, buf, size, msgType)
+}
+
+// Singular decodes.
+
+// DecodeBool decodes a boolean.
+func (d BEByteDecoder) DecodeBool(buf *bytes.Reader) (bool, error) {
+	return beDecoder.DecodeBool(buf)
+}
+
+// DecodeInt8 decodes a int8.
+func (d BEByteDecoder) DecodeInt8(buf *bytes.Reader) (int8, error) {
+	return beDecoder.DecodeInt8(buf)
+}
+
+// DecodeUint8 decodes a uint8.
+func (d BEByteDecoder) DecodeUint8(buf *bytes.Reader) (uint8, error) {
+	return beDecoder.DecodeUint8(buf)
+}
+
+// DecodeInt16 decodes a int16.
+func (d BEByteDecoder) DecodeInt16(buf *bytes.Reader) (int16, error) {
+	return beDecoder.DecodeInt16(buf)
+}
+
+// DecodeUint16 decodes a uint16.
+func (d BEByteDecoder) DecodeUint16(buf *bytes.Reader) (uint16, error) {
+	return beDecoder.DecodeUint16(buf)
+}
+
+// DecodeInt32 decodes a int32.
+func (d BEByteDecoder) DecodeInt32(buf *bytes.Reader) (int32, error) {
+	return beDecoder.DecodeInt32(buf)
+}
+
+// DecodeUint32 decodes a uint32.
+func (d BEByteDecoder) DecodeUint32(buf *bytes.Reader) (uint32, error) {
+	return beDecoder.DecodeUint32(buf)
+}
+
+// DecodeFloat32 decodes a JsonFloat32.
+func (d BEByteDecoder) DecodeFloat32(buf *bytes.Reader) (JsonFloat32, error) {
+	return beDecoder.DecodeFloat32(buf)
+}
+
+// DecodeInt64 decodes a int64.
+func (d BEByteDecoder) DecodeInt64(buf *bytes.Reader) (int64, error) {
+	return beDecoder.DecodeInt64(buf)
+}
+
+// DecodeUint64 decodes a uint64.
+func (d BEByteDecoder) DecodeUint64(buf *bytes.Reader) (uint64, error) {
+	return beDecoder.DecodeUint64(buf)
+}
+
+// DecodeFloat64 decodes a JsonFloat64.
+func (d BEByteDecoder) DecodeFloat64(buf *bytes.Reader) (JsonFloat64, error) {
+	return beDecoder.DecodeFloat64(buf)
+}
+
+// DecodeString decodes a string.
+func (d BEByteDecoder) DecodeString(buf *bytes.Reader) (string, error) {
+	return beDecoder.DecodeString(buf)
+}
+
+// DecodeTime decodes a Time struct.
+func (d BEByteDecoder) DecodeTime(buf *bytes.Reader) (Time, error) {
+	return beDecoder.DecodeTime(buf)
+}
+
+// DecodeDuration decodes a Duraction struct.
+func (d BEByteDecoder) DecodeDuration(buf *bytes.Reader) (Duration, error) {
+	return beDecoder.DecodeDuration(buf)
+}
+
+// DecodeMessage decodes a DynamicMessage.
+func (d BEByteDecoder) DecodeMessage(buf *bytes.Reader, msgType *DynamicMessageType) (Message, error) {
+	return decodeMessage(d, buf, msgType)
+}
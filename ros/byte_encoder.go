@@ -0,0 +1,265 @@
+package ros
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// ByteEncoder provides an interface that dynamic message serialization expects for encoding ROS messages.
+type ByteEncoder interface {
+	EncodeBoolArray(buf *bytes.Buffer, value []bool) error
+	EncodeInt8Array(buf *bytes.Buffer, value []int8) error
+	EncodeInt16Array(buf *bytes.Buffer, value []int16) error
+	EncodeInt32Array(buf *bytes.Buffer, value []int32) error
+	EncodeInt64Array(buf *bytes.Buffer, value []int64) error
+	EncodeUint8Array(buf *bytes.Buffer, value []uint8) error
+	EncodeUint16Array(buf *bytes.Buffer, value []uint16) error
+	EncodeUint32Array(buf *bytes.Buffer, value []uint32) error
+	EncodeUint64Array(buf *bytes.Buffer, value []uint64) error
+	EncodeFloat32Array(buf *bytes.Buffer, value []JsonFloat32) error
+	EncodeFloat64Array(buf *bytes.Buffer, value []JsonFloat64) error
+	EncodeStringArray(buf *bytes.Buffer, value []string) error
+	EncodeTimeArray(buf *bytes.Buffer, value []Time) error
+	EncodeDurationArray(buf *bytes.Buffer, value []Duration) error
+
+	EncodeBool(buf *bytes.Buffer, value bool) error
+	EncodeInt8(buf *bytes.Buffer, value int8) error
+	EncodeInt16(buf *bytes.Buffer, value int16) error
+	EncodeInt32(buf *bytes.Buffer, value int32) error
+	EncodeInt64(buf *bytes.Buffer, value int64) error
+	EncodeUint8(buf *bytes.Buffer, value uint8) error
+	EncodeUint16(buf *bytes.Buffer, value uint16) error
+	EncodeUint32(buf *bytes.Buffer, value uint32) error
+	EncodeUint64(buf *bytes.Buffer, value uint64) error
+	EncodeFloat32(buf *bytes.Buffer, value JsonFloat32) error
+	EncodeFloat64(buf *bytes.Buffer, value JsonFloat64) error
+	EncodeString(buf *bytes.Buffer, value string) error
+	EncodeTime(buf *bytes.Buffer, value Time) error
+	EncodeDuration(buf *bytes.Buffer, value Duration) error
+	EncodeMessage(buf *bytes.Buffer, msg *DynamicMessage) error
+}
+
+// byteOrderEncoder encodes the TCPROS message layout in a byte order; LEByteEncoder and BEByteEncoder encode with an instance of it.  Messages aren't encoded by it, so that
+// nested messages are encoded by the encoder which holds it.
+type byteOrderEncoder struct {
+	order binary.ByteOrder
+}
+
+// leEncoder and beEncoder are the encoders of LEByteEncoder and BEByteEncoder.
+var leEncoder = byteOrderEncoder{binary.LittleEndian}
+var beEncoder = byteOrderEncoder{binary.BigEndian}
+
+// Array encoders.
+
+// EncodeBoolArray encodes an array of boolean values.
+func (e byteOrderEncoder) EncodeBoolArray(buf *bytes.Buffer, value []bool) error {
+	for _, v := range value {
+		e.EncodeBool(buf, v)
+	}
+	return nil
+}
+
+// EncodeInt8Array encodes an array of int8 values.
+func (e byteOrderEncoder) EncodeInt8Array(buf *bytes.Buffer, value []int8) error {
+	buf.Grow(len(value))
+	for _, v := range value {
+		buf.WriteByte(uint8(v))
+	}
+	return nil
+}
+
+// EncodeUint8Array encodes an array of uint8 values.
+func (e byteOrderEncoder) EncodeUint8Array(buf *bytes.Buffer, value []uint8) error {
+	buf.Write(value)
+	return nil
+}
+
+// EncodeInt16Array encodes an array of int16 values.
+func (e byteOrderEncoder) EncodeInt16Array(buf *bytes.Buffer, value []int16) error {
+	buf.Grow(2 * len(value))
+	for _, v := range value {
+		e.EncodeUint16(buf, uint16(v))
+	}
+	return nil
+}
+
+// EncodeUint16Array encodes an array of uint16 values.
+func (e byteOrderEncoder) EncodeUint16Array(buf *bytes.Buffer, value []uint16) error {
+	buf.Grow(2 * len(value))
+	for _, v := range value {
+		e.EncodeUint16(buf, v)
+	}
+	return nil
+}
+
+// EncodeInt32Array encodes an array of int32 values.
+func (e byteOrderEncoder) EncodeInt32Array(buf *bytes.Buffer, value []int32) error {
+	buf.Grow(4 * len(value))
+	for _, v := range value {
+		e.EncodeUint32(buf, uint32(v))
+	}
+	return nil
+}
+
+// EncodeUint32Array encodes an array of uint32 values.
+func (e byteOrderEncoder) EncodeUint32Array(buf *bytes.Buffer, value []uint32) error {
+	buf.Grow(4 * len(value))
+	for _, v := range value {
+		e.EncodeUint32(buf, v)
+	}
+	return nil
+}
+
+// EncodeFloat32Array encodes an array of float32 values.
+func (e byteOrderEncoder) EncodeFloat32Array(buf *bytes.Buffer, value []JsonFloat32) error {
+	buf.Grow(4 * len(value))
+	for _, v := range value {
+		e.EncodeUint32(buf, math.Float32bits(v.F))
+	}
+	return nil
+}
+
+// EncodeInt64Array encodes an array of int64 values.
+func (e byteOrderEncoder) EncodeInt64Array(buf *bytes.Buffer, value []int64) error {
+	buf.Grow(8 * len(value))
+	for _, v := range value {
+		e.EncodeUint64(buf, uint64(v))
+	}
+	return nil
+}
+
+// EncodeUint64Array encodes an array of uint64 values.
+func (e byteOrderEncoder) EncodeUint64Array(buf *bytes.Buffer, value []uint64) error {
+	buf.Grow(8 * len(value))
+	for _, v := range value {
+		e.EncodeUint64(buf, v)
+	}
+	return nil
+}
+
+// EncodeFloat64Array encodes an array of float64 values.
+func (e byteOrderEncoder) EncodeFloat64Array(buf *bytes.Buffer, value []JsonFloat64) error {
+	buf.Grow(8 * len(value))
+	for _, v := range value {
+		e.EncodeUint64(buf, math.Float64bits(v.F))
+	}
+	return nil
+}
+
+// EncodeStringArray encodes an array of strings.
+func (e byteOrderEncoder) EncodeStringArray(buf *bytes.Buffer, value []string) error {
+	for _, v := range value {
+		e.EncodeString(buf, v)
+	}
+	return nil
+}
+
+// EncodeTimeArray encodes an array of Time structs.
+func (e byteOrderEncoder) EncodeTimeArray(buf *bytes.Buffer, value []Time) error {
+	buf.Grow(8 * len(value))
+	for _, v := range value {
+		e.EncodeTime(buf, v)
+	}
+	return nil
+}
+
+// EncodeDurationArray encodes an array of Duration structs.
+func (e byteOrderEncoder) EncodeDurationArray(buf *bytes.Buffer, value []Duration) error {
+	buf.Grow(8 * len(value))
+	for _, v := range value {
+		e.EncodeDuration(buf, v)
+	}
+	return nil
+}
+
+// Singular encodes.
+
+// EncodeBool encodes a boolean.
+func (e byteOrderEncoder) EncodeBool(buf *bytes.Buffer, value bool) error {
+	if value {
+		return buf.WriteByte(0x01)
+	}
+	return buf.WriteByte(0x00)
+}
+
+// EncodeInt8 encodes a int8.
+func (e byteOrderEncoder) EncodeInt8(buf *bytes.Buffer, value int8) error {
+	return buf.WriteByte(uint8(value))
+}
+
+// EncodeUint8 encodes a uint8.
+func (e byteOrderEncoder) EncodeUint8(buf *bytes.Buffer, value uint8) error {
+	return buf.WriteByte(value)
+}
+
+// EncodeInt16 encodes a int16.
+func (e byteOrderEncoder) EncodeInt16(buf *bytes.Buffer, value int16) error {
+	return e.EncodeUint16(buf, uint16(value))
+}
+
+// EncodeUint16 encodes a uint16.
+func (e byteOrderEncoder) EncodeUint16(buf *bytes.Buffer, value uint16) error {
+	var arr [2]byte
+	e.order.PutUint16(arr[:], value)
+	buf.Write(arr[:])
+	return nil
+}
+
+// EncodeInt32 encodes a int32.
+func (e byteOrderEncoder) EncodeInt32(buf *bytes.Buffer, value int32) error {
+	return e.EncodeUint32(buf, uint32(value))
+}
+
+// EncodeUint32 encodes a uint32.
+func (e byteOrderEncoder) EncodeUint32(buf *bytes.Buffer, value uint32) error {
+	var arr [4]byte
+	e.order.PutUint32(arr[:], value)
+	buf.Write(arr[:])
+	return nil
+}
+
+// EncodeFloat32 encodes a JsonFloat32.
+func (e byteOrderEncoder) EncodeFloat32(buf *bytes.Buffer, value JsonFloat32) error {
+	return e.EncodeUint32(buf, math.Float32bits(value.F))
+}
+
+// EncodeInt64 encodes a int64.
+func (e byteOrderEncoder) EncodeInt64(buf *bytes.Buffer, value int64) error {
+	return e.EncodeUint64(buf, uint64(value))
+}
+
+// EncodeUint64 encodes a uint64.
+func (e byteOrderEncoder) EncodeUint64(buf *bytes.Buffer, value uint64) error {
+	var arr [8]byte
+	e.order.PutUint64(arr[:], value)
+	buf.Write(arr[:])
+	return nil
+}
+
+// EncodeFloat64 encodes a JsonFloat64.
+func (e byteOrderEncoder) EncodeFloat64(buf *bytes.Buffer, value JsonFloat64) error {
+	return e.EncodeUint64(buf, math.Float64bits(value.F))
+}
+
+// EncodeString encodes a string.
+func (e byteOrderEncoder) EncodeString(buf *bytes.Buffer, value string) error {
+	// String format is: [size|string] where size is a u32.
+	e.EncodeUint32(buf, uint32(len(value)))
+	buf.WriteString(value)
+	return nil
+}
+
+// EncodeTime encodes a Time struct.
+func (e byteOrderEncoder) EncodeTime(buf *bytes.Buffer, value Time) error {
+	// Time format is: [sec|nanosec] where sec and nanosec are unsigned integers.
+	e.EncodeUint32(buf, value.Sec)
+	return e.EncodeUint32(buf, value.NSec)
+}
+
+// EncodeDuration encodes a Duration struct.
+func (e byteOrderEncoder) EncodeDuration(buf *bytes.Buffer, value Duration) error {
+	// Duration format is: [sec|nanosec] where sec and nanosec are unsigned integers.
+	e.EncodeUint32(buf, value.Sec)
+	return e.EncodeUint32(buf, value.NSec)
+}
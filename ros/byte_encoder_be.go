@@ -0,0 +1,158 @@
+package ros
+
+import "bytes"
+
+// BEByteEncoder is a big-endian byte encoder, implements the ByteEncoder interface.  It writes the TCPROS message layout with the byte order swapped, as used by some legacy
+// embedded bridges.
+type BEByteEncoder struct{}
+
+var _ ByteEncoder = BEByteEncoder{}
+
+// Array encoders.
+
+// EncodeBoolArray encodes an array of boolean values.
+func (e BEByteEncoder) EncodeBoolArray(buf *bytes.Buffer, value []bool) error {
+	return beEncoder.EncodeBoolArray(buf, value)
+}
+
+// EncodeInt8Array encodes an array of int8 values.
+func (e BEByteEncoder) EncodeInt8Array(buf *bytes.Buffer, value []int8) error {
+	return beEncoder.EncodeInt8Array(buf, value)
+}
+
+// EncodeUint8Array encodes an array of uint8 values.
+func (e BEByteEncoder) EncodeUint8Array(buf *bytes.Buffer, value []uint8) error {
+	return beEncoder.EncodeUint8Array(buf, value)
+}
+
+// EncodeInt16Array encodes an array of int16 values.
+func (e BEByteEncoder) EncodeInt16Array(buf *bytes.Buffer, value []int16) error {
+	return beEncoder.EncodeInt16Array(buf, value)
+}
+
+// EncodeUint16Array encodes an array of uint16 values.
+func (e BEByteEncoder) EncodeUint16Array(buf *bytes.Buffer, value []uint16) error {
+	return beEncoder.EncodeUint16Array(buf, value)
+}
+
+// EncodeInt32Array encodes an array of int32 values.
+func (e BEByteEncoder) EncodeInt32Array(buf *bytes.Buffer, value []int32) error {
+	return beEncoder.EncodeInt32Array(buf, value)
+}
+
+// EncodeUint32Array encodes an array of uint32 values.
+func (e BEByteEncoder) EncodeUint32Array(buf *bytes.Buffer, value []uint32) error {
+	return beEncoder.EncodeUint32Array(buf, value)
+}
+
+// EncodeFloat32Array encodes an array of float32 values.
+func (e BEByteEncoder) EncodeFloat32Array(buf *bytes.Buffer, value []JsonFloat32) error {
+	return beEncoder.EncodeFloat32Array(buf, value)
+}
+
+// EncodeInt64Array encodes an array of int64 values.
+func (e BEByteEncoder) EncodeInt64Array(buf *bytes.Buffer, value []int64) error {
+	return beEncoder.EncodeInt64Array(buf, value)
+}
+
+// EncodeUint64Array encodes an array of uint64 values.
+func (e BEByteEncoder) EncodeUint64Array(buf *bytes.Buffer, value []uint64) error {
+	return beEncoder.EncodeUint64Array(buf, value)
+}
+
+// EncodeFloat64Array encodes an array of float64 values.
+func (e BEByteEncoder) EncodeFloat64Array(buf *bytes.Buffer, value []JsonFloat64) error {
+	return beEncoder.EncodeFloat64Array(buf, value)
+}
+
+// EncodeStringArray encodes an array of strings.
+func (e BEByteEncoder) EncodeStringArray(buf *bytes.Buffer, value []string) error {
+	return beEncoder.EncodeStringArray(buf, value)
+}
+
+// EncodeTimeArray encodes an array of Time structs.
+func (e BEByteEncoder) EncodeTimeArray(buf *bytes.Buffer, value []Time) error {
+	return beEncoder.EncodeTimeArray(buf, value)
+}
+
+// EncodeDurationArray encodes an array of Duration structs.
+func (e BEByteEncoder) EncodeDurationArray(buf *bytes.Buffer, value []Duration) error {
+	return beEncoder.EncodeDurationArray(buf, value)
+}
+
+// Singular encodes.
+
+// EncodeBool encodes a boolean.
+func (e BEByteEncoder) EncodeBool(buf *bytes.Buffer, value bool) error {
+	return beEncoder.EncodeBool(buf, value)
+}
+
+// EncodeInt8 encodes a int8.
+func (e BEByteEncoder) EncodeInt8(buf *bytes.Buffer, value int8) error {
+	return beEncoder.EncodeInt8(buf, value)
+}
+
+// EncodeUint8 encodes a uint8.
+func (e BEByteEncoder) EncodeUint8(buf *bytes.Buffer, value uint8) error {
+	return beEncoder.EncodeUint8(buf, value)
+}
+
+// EncodeInt16 encodes a int16.
+func (e BEByteEncoder) EncodeInt16(buf *bytes.Buffer, value int16) error {
+	return beEncoder.EncodeInt16(buf, value)
+}
+
+// EncodeUint16 encodes a uint16.
+func (e BEByteEncoder) EncodeUint16(buf *bytes.Buffer, value uint16) error {
+	return beEncoder.EncodeUint16(buf, value)
+}
+
+// EncodeInt32 encodes a int32.
+func (e BEByteEncoder) EncodeInt32(buf *bytes.Buffer, value int32) error {
+	return beEncoder.EncodeInt32(buf, value)
+}
+
+// EncodeUint32 encodes a uint32.
+func (e BEByteEncoder) EncodeUint32(buf *bytes.Buffer, value uint32) error {
+	return beEncoder.EncodeUint32(buf, value)
+}
+
+// EncodeFloat32 encodes a JsonFloat32.
+func (e BEByteEncoder) EncodeFloat32(buf *bytes.Buffer, value JsonFloat32) error {
+	return beEncoder.EncodeFloat32(buf, value)
+}
+
+// EncodeInt64 encodes a int64.
+func (e BEByteEncoder) EncodeInt64(buf *bytes.Buffer, value int64) error {
+	return beEncoder.EncodeInt64(buf, value)
+}
+
+// EncodeUint64 encodes a uint64.
+func (e BEByteEncoder) EncodeUint64(buf *bytes.Buffer, value uint64) error {
+	return beEncoder.EncodeUint64(buf, value)
+}
+
+// EncodeFloat64 encodes a JsonFloat64.
+func (e BEByteEncoder) EncodeFloat64(buf *bytes.Buffer, value JsonFloat64) error {
+	return beEncoder.EncodeFloat64(buf, value)
+}
+
+// EncodeString encodes a string.
+func (e BEByteEncoder) EncodeString(buf *bytes.Buffer, value string) error {
+	return beEncoder.EncodeString(buf, value)
+}
+
+// EncodeTime encodes a Time struct.
+func (e BEByteEncoder) EncodeTime(buf *bytes.Buffer, value Time) error {
+	return beEncoder.EncodeTime(buf, value)
+}
+
+// EncodeDuration encodes a Duration struct.
+func (e BEByteEncoder) EncodeDuration(buf *bytes.Buffer, value Duration) error {
+	return beEncoder.EncodeDuration(buf, value)
+}
+
+// EncodeMessage encodes a DynamicMessage.
+func (e BEByteEncoder) EncodeMessage(buf *bytes.Buffer, msg *DynamicMessage) error {
+	return msg.serialize(e, buf)
+}
@@ -0,0 +1,157 @@
+package ros
+
+import "bytes"
+
+// LEByteEncoder is a little-endian byte encoder, implements the ByteEncoder interface.
+type LEByteEncoder struct{}
+
+var _ ByteEncoder = LEByteEncoder{}
+
+// Array encoders.
+
+// EncodeBoolArray encodes an array of boolean values.
+func (e LEByteEncoder) EncodeBoolArray(buf *bytes.Buffer, value []bool) error {
+	return leEncoder.EncodeBoolArray(buf, value)
+}
+
+// EncodeInt8Array encodes an array of int8 values.
+func (e LEByteEncoder) EncodeInt8Array(buf *bytes.Buffer, value []int8) error {
+	return leEncoder.EncodeInt8Array(buf, value)
+}
+
+// EncodeUint8Array encodes an array of uint8 values.
+func (e LEByteEncoder) EncodeUint8Array(buf *bytes.Buffer, value []uint8) error {
+	return leEncoder.EncodeUint8Array(buf, value)
+}
+
+// EncodeInt16Array encodes an array of int16 values.
+func (e LEByteEncoder) EncodeInt16Array(buf *bytes.Buffer, value []int16) error {
+	return leEncoder.EncodeInt16Array(buf, value)
+}
+
+// EncodeUint16Array encodes an array of uint16 values.
+func (e LEByteEncoder) EncodeUint16Array(buf *bytes.Buffer, value []uint16) error {
+	return leEncoder.EncodeUint16Array(buf, value)
+}
+
+// EncodeInt32Array encodes an array of int32 values.
+func (e LEByteEncoder) EncodeInt32Array(buf *bytes.Buffer, value []int32) error {
+	return leEncoder.EncodeInt32Array(buf, value)
+}
+
+// EncodeUint32Array encodes an array of uint32 values.
+func (e LEByteEncoder) EncodeUint32Array(buf *bytes.Buffer, value []uint32) error {
+	return leEncoder.EncodeUint32Array(buf, value)
+}
+
+// EncodeFloat32Array encodes an array of float32 values.
+func (e LEByteEncoder) EncodeFloat32Array(buf *bytes.Buffer, value []JsonFloat32) error {
+	return leEncoder.EncodeFloat32Array(buf, value)
+}
+
+// EncodeInt64Array encodes an array of int64 values.
+func (e LEByteEncoder) EncodeInt64Array(buf *bytes.Buffer, value []int64) error {
+	return leEncoder.EncodeInt64Array(buf, value)
+}
+
+// EncodeUint64Array encodes an array of uint64 values.
+func (e LEByteEncoder) EncodeUint64Array(buf *bytes.Buffer, value []uint64) error {
+	return leEncoder.EncodeUint64Array(buf, value)
+}
+
+// EncodeFloat64Array encodes an array of float64 values.
+func (e LEByteEncoder) EncodeFloat64Array(buf *bytes.Buffer, value []JsonFloat64) error {
+	return leEncoder.EncodeFloat64Array(buf, value)
+}
+
+// EncodeStringArray encodes an array of strings.
+func (e LEByteEncoder) EncodeStringArray(buf *bytes.Buffer, value []string) error {
+	return leEncoder.EncodeStringArray(buf, value)
+}
+
+// EncodeTimeArray encodes an array of Time structs.
+func (e LEByteEncoder) EncodeTimeArray(buf *bytes.Buffer, value []Time) error {
+	return leEncoder.EncodeTimeArray(buf, value)
+}
+
+// EncodeDurationArray encodes an array of Duration structs.
+func (e LEByteEncoder) EncodeDurationArray(buf *bytes.Buffer, value []Duration) error {
+	return leEncoder.EncodeDurationArray(buf, value)
+}
+
+// Singular encodes.
+
+// EncodeBool encodes a boolean.
+func (e LEByteEncoder) EncodeBool(buf *bytes.Buffer, value bool) error {
+	return leEncoder.EncodeBool(buf, value)
+}
+
+// EncodeInt8 encodes a int8.
+func (e LEByteEncoder) EncodeInt8(buf *bytes.Buffer, value int8) error {
+	return leEncoder.EncodeInt8(buf, value)
+}
+
+// EncodeUint8 encodes a uint8.
+func (e LEByteEncoder) EncodeUint8(buf *bytes.Buffer, value uint8) error {
+	return leEncoder.EncodeUint8(buf, value)
+}
+
+// EncodeInt16 encodes a int16.
+func (e LEByteEncoder) EncodeInt16(buf *bytes.Buffer, value int16) error {
+	return leEncoder.EncodeInt16(buf, value)
+}
+
+// EncodeUint16 encodes a uint16.
+func (e LEByteEncoder) EncodeUint16(buf *bytes.Buffer, value uint16) error {
+	return leEncoder.EncodeUint16(buf, value)
+}
+
+// EncodeInt32 encodes a int32.
+func (e LEByteEncoder) EncodeInt32(buf *bytes.Buffer, value int32) error {
+	return leEncoder.EncodeInt32(buf, value)
+}
+
+// EncodeUint32 encodes a uint32.
+func (e LEByteEncoder) EncodeUint32(buf *bytes.Buffer, value uint32) error {
+	return leEncoder.EncodeUint32(buf, value)
+}
+
+// EncodeFloat32 encodes a JsonFloat32.
+func (e LEByteEncoder) EncodeFloat32(buf *bytes.Buffer, value JsonFloat32) error {
+	return leEncoder.EncodeFloat32(buf, value)
+}
+
+// EncodeInt64 encodes a int64.
+func (e LEByteEncoder) EncodeInt64(buf *bytes.Buffer, value int64) error {
+	return leEncoder.EncodeInt64(buf, value)
+}
+
+// EncodeUint64 encodes a uint64.
+func (e LEByteEncoder) EncodeUint64(buf *bytes.Buffer, value uint64) error {
+	return leEncoder.EncodeUint64(buf, value)
+}
+
+// EncodeFloat64 encodes a JsonFloat64.
+func (e LEByteEncoder) EncodeFloat64(buf *bytes.Buffer, value JsonFloat64) error {
+	return leEncoder.EncodeFloat64(buf, value)
+}
+
+// EncodeString encodes a string.
+func (e LEByteEncoder) EncodeString(buf *bytes.Buffer, value string) error {
+	return leEncoder.EncodeString(buf, value)
+}
+
+// EncodeTime encodes a Time struct.
+func (e LEByteEncoder) EncodeTime(buf *bytes.Buffer, value Time) error {
+	return leEncoder.EncodeTime(buf, value)
+}
+
+// EncodeDuration encodes a Duration struct.
+func (e LEByteEncoder) EncodeDuration(buf *bytes.Buffer, value Duration) error {
+	return leEncoder.EncodeDuration(buf, value)
+}
+
+// EncodeMessage encodes a DynamicMessage.
+func (e LEByteEncoder) EncodeMessage(buf *bytes.Buffer, msg *DynamicMessage) error {
+	return msg.serialize(e, buf)
+}
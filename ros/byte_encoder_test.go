@@ -0,0 +1,163 @@
+package ros
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"testing"
+)
+
+func TestByteEncoder_LittleEndian(t *testing.T) {
+	msgType := newBinaryTestType(t)
+	msg, err := msgType.NewDynamicMessageFromMap(map[string]interface{}{"a": -2, "b": []byte{1, 2}, "t": NewTime(1, 2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "fe" + "02000000" + "0102" + "01000000" + "02000000"
+	for _, encoder := range []ByteEncoder{nil, LEByteEncoder{}, ZeroCopyByteEncoder{}} {
+		msgType.SetByteEncoder(encoder)
+		var buf bytes.Buffer
+		if err := msg.Serialize(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(buf.Bytes()) != expected {
+			t.Fatalf("%T: expected %s, got %x", encoder, expected, buf.Bytes())
+		}
+	}
+}
+
+func TestByteEncoder_BigEndian(t *testing.T) {
+	msgType := newBinaryTestType(t)
+	msgType.SetByteEncoder(BEByteEncoder{})
+	msgType.SetByteDecoder(BEByteDecoder{})
+	msg, err := msgType.NewDynamicMessageFromMap(map[string]interface{}{"a": -2, "b": []byte{1, 2}, "t": NewTime(1, 2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := msg.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "fe" + "00000002" + "0102" + "00000001" + "00000002"; hex.EncodeToString(buf.Bytes()) != expected {
+		t.Fatalf("expected %s, got %x", expected, buf.Bytes())
+	}
+
+	// Nested messages are encoded and decoded in the byte order of the enclosing message.
+	path := newPathTestType(t)
+	path.SetByteEncoder(BEByteEncoder{})
+	path.SetByteDecoder(BEByteDecoder{})
+	original, err := path.NewDynamicMessageFromMap(map[string]interface{}{
+		"stamp":    NewTime(3, 4),
+		"period":   NewDuration(uint32(0xffffffff), 5),
+		"frame_id": "map",
+		"pose":     map[string]interface{}{"position": map[string]interface{}{"x": 1.5}, "id": 7},
+		"points":   []interface{}{map[string]interface{}{"y": -2.0}},
+		"counts":   []int32{1, -1, math.MaxInt32},
+		"scale":    0.5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := original.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "3ff8000000000000"; !bytes.Contains(buf.Bytes(), mustDecodeHex(t, expected)) {
+		t.Fatalf("expected pose.position.x as %s in %x", expected, buf.Bytes())
+	}
+	result := path.NewDynamicMessage()
+	if err := result.Deserialize(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if diff, err := original.Diff(result); err != nil || len(diff) != 0 {
+		t.Fatalf("deserialized message differs: %v, %v", diff, err)
+	}
+}
+
+func TestByteEncoder_LazyPassThrough(t *testing.T) {
+	msgType := newBinaryTestType(t)
+	msgType.SetLazyDecoding(true)
+	msgType.SetByteDecoder(BEByteDecoder{})
+	input := mustDecodeHex(t, "fe"+"00000002"+"0102"+"00000001"+"00000002")
+
+	// A lazily decoded message is only written out as received when it's encoded in the same byte order.
+	for _, testCase := range []struct {
+		encoder  ByteEncoder
+		expected string
+	}{
+		{BEByteEncoder{}, "fe" + "00000002" + "0102" + "00000001" + "00000002"},
+		{LEByteEncoder{}, "fe" + "02000000" + "0102" + "01000000" + "02000000"},
+	} {
+		msgType.SetByteEncoder(testCase.encoder)
+		msg := msgType.NewDynamicMessage()
+		if err := msg.Deserialize(bytes.NewReader(input)); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := msg.Serialize(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(buf.Bytes()) != testCase.expected {
+			t.Fatalf("%T: expected %s, got %x", testCase.encoder, testCase.expected, buf.Bytes())
+		}
+	}
+}
+
+func TestByteDecoder_ZeroCopy(t *testing.T) {
+	original := newJSONOptionsTestMessage(t)
+	var buf bytes.Buffer
+	if err := original.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	msgType := original.dynamicType
+	msgType.SetByteDecoder(ZeroCopyByteDecoder{})
+	result := msgType.NewDynamicMessage()
+	if err := result.Deserialize(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if diff, err := original.Diff(result, WithNaNEqual()); err != nil || len(diff) != 0 {
+		t.Fatalf("deserialized message differs: %v, %v", diff, err)
+	}
+
+	// Arrays share memory with the caller-owned buffer they were decoded from.
+	binaryType := newBinaryTestType(t)
+	input := mustDecodeHex(t, "fe"+"02000000"+"0102"+"01000000"+"02000000")
+	msg := binaryType.NewDynamicMessage()
+	if err := msg.DeserializeZeroCopy(input); err != nil {
+		t.Fatal(err)
+	}
+	input[5] = 9
+	if b, _ := msg.Get("b"); b.([]uint8)[0] != 9 {
+		t.Fatalf("expected b to alias the buffer, got %v", b)
+	}
+
+	// Arrays read from any other reader are copied.
+	binaryType.SetByteDecoder(ZeroCopyByteDecoder{})
+	msg = binaryType.NewDynamicMessage()
+	if err := msg.Deserialize(bytes.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	input[5] = 1
+	if b, _ := msg.Get("b"); b.([]uint8)[0] != 9 {
+		t.Fatalf("expected b to be copied, got %v", b)
+	}
+	d, _ := NewZeroCopyByteDecoder(input)
+	if b, err := d.DecodeUint8Array(bytes.NewReader(input[5:]), 2); err != nil || &b[0] == &input[5] {
+		t.Fatalf("expected a copy from another reader, got %v, %v", b, err)
+	}
+
+	// Truncated arrays are still detected.
+	if err := binaryType.NewDynamicMessage().DeserializeZeroCopy(input[:6]); err == nil {
+		t.Fatal("expected error for truncated array")
+	}
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
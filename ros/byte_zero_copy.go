@@ -0,0 +1,333 @@
+package ros
+
+import (
+	"bytes"
+	"io"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// ZeroCopyByteDecoder is a little-endian byte decoder which aliases numeric arrays to the buffer being decoded instead of copying them, implements the ByteDecoder interface.
+// Only the caller-owned buffer given to NewZeroCopyByteDecoder() is aliased, when it is read through the reader returned with the decoder; arrays read from any other reader,
+// including by the zero value of the decoder, are copied.  This is unsafe: the decoded arrays share memory with the buffer, so the buffer must not be modified or reused while the
+// message is in use, and modifying the arrays of the message modifies the buffer.  Arrays which are misaligned in the buffer, or any arrays on a big-endian host, are copied as by
+// LEByteDecoder; arrays of bool are always copied.
+type ZeroCopyByteDecoder struct {
+	LEByteDecoder
+	data   []byte        // The caller-owned buffer which arrays may alias.
+	reader *bytes.Reader // The reader over data which the decoder was created with.
+}
+
+// ZeroCopyByteEncoder is a little-endian byte encoder which writes numeric arrays from their memory in a single copy instead of element by element, implements the ByteEncoder
+// interface.  On a big-endian host it encodes arrays as LEByteEncoder does.
+type ZeroCopyByteEncoder struct {
+	LEByteEncoder
+}
+
+var _ ByteDecoder = ZeroCopyByteDecoder{}
+var _ ByteEncoder = ZeroCopyByteEncoder{}
+
+// maxAliasBytes is the length of the largest array which is aliased; larger arrays are copied.
+const maxAliasBytes = 1 << 30
+
+// hostLittleEndian is whether the memory layout of numbers matches the little-endian wire format.
+var hostLittleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// NewZeroCopyByteDecoder creates a ZeroCopyByteDecoder which aliases arrays to data, returning it with the reader over data to decode from.  The reader must not be Reset().
+func NewZeroCopyByteDecoder(data []byte) (ZeroCopyByteDecoder, *bytes.Reader) {
+	reader := bytes.NewReader(data)
+	return ZeroCopyByteDecoder{data: data, reader: reader}, reader
+}
+
+// readAliased reads the next n bytes of the buffer, returning a slice which shares memory with the caller-owned buffer of the decoder if buf reads it, or a copy otherwise.
+func (d ZeroCopyByteDecoder) readAliased(buf *bytes.Reader, n int) ([]byte, error) {
+	if n < 0 || buf.Len() < n {
+		return nil, errors.New("Could not read array from buffer")
+	}
+	if buf != d.reader || buf.Size() != int64(len(d.data)) {
+		p := make([]byte, n)
+		if _, err := io.ReadFull(buf, p); err != nil {
+			return nil, errors.New("Could not read array from buffer")
+		}
+		return p, nil
+	}
+	offset := len(d.data) - buf.Len()
+	if _, err := buf.Seek(int64(n), io.SeekCurrent); err != nil {
+		return nil, err
+	}
+	return d.data[offset : offset+n : offset+n], nil
+}
+
+// canAlias reports whether the bytes of an array can be viewed as elements of the given size.
+func canAlias(p []byte, size int) bool {
+	return hostLittleEndian && len(p) <= maxAliasBytes && uintptr(unsafe.Pointer(&p[0]))%uintptr(size) == 0
+}
+
+// bytesOf views the memory of an array of n bytes as a byte slice.
+func bytesOf(ptr unsafe.Pointer, n int) []byte {
+	return (*[maxAliasBytes]byte)(ptr)[:n:n]
+}
+
+// Array decoders.
+
+// DecodeInt8Array decodes an array of int8 values.
+func (d ZeroCopyByteDecoder) DecodeInt8Array(buf *bytes.Reader, size int) ([]int8, error) {
+	if size == 0 || size > maxAliasBytes {
+		return d.LEByteDecoder.DecodeInt8Array(buf, size)
+	}
+	p, err := d.readAliased(buf, size)
+	if err != nil {
+		return nil, err
+	}
+	return (*[maxAliasBytes]int8)(unsafe.Pointer(&p[0]))[:size:size], nil
+}
+
+// DecodeUint8Array decodes an array of uint8 values.
+func (d ZeroCopyByteDecoder) DecodeUint8Array(buf *bytes.Reader, size int) ([]uint8, error) {
+	if size == 0 {
+		return d.LEByteDecoder.DecodeUint8Array(buf, size)
+	}
+	return d.readAliased(buf, size)
+}
+
+// DecodeInt16Array decodes an array of int16 values.
+func (d ZeroCopyByteDecoder) DecodeInt16Array(buf *bytes.Reader, size int) ([]int16, error) {
+	if size == 0 {
+		return d.LEByteDecoder.DecodeInt16Array(buf, size)
+	}
+	p, err := d.readAliased(buf, 2*size)
+	if err != nil {
+		return nil, err
+	}
+	if !canAlias(p, 2) {
+		return d.LEByteDecoder.DecodeInt16Array(bytes.NewReader(p), size)
+	}
+	return (*[maxAliasBytes / 2]int16)(unsafe.Pointer(&p[0]))[:size:size], nil
+}
+
+// DecodeUint16Array decodes an array of uint16 values.
+func (d ZeroCopyByteDecoder) DecodeUint16Array(buf *bytes.Reader, size int) ([]uint16, error) {
+	if size == 0 {
+		return d.LEByteDecoder.DecodeUint16Array(buf, size)
+	}
+	p, err := d.readAliased(buf, 2*size)
+	if err != nil {
+		return nil, err
+	}
+	if !canAlias(p, 2) {
+		return d.LEByteDecoder.DecodeUint16Array(bytes.NewReader(p), size)
+	}
+	return (*[maxAliasBytes / 2]uint16)(unsafe.Pointer(&p[0]))[:size:size], nil
+}
+
+// DecodeInt32Array decodes an array of int32 values.
+func (d ZeroCopyByteDecoder) DecodeInt32Array(buf *bytes.Reader, size int) ([]int32, error) {
+	if size == 0 {
+		return d.LEByteDecoder.DecodeInt32Array(buf, size)
+	}
+	p, err := d.readAliased(buf, 4*size)
+	if err != nil {
+		return nil, err
+	}
+	if !canAlias(p, 4) {
+		return d.LEByteDecoder.DecodeInt32Array(bytes.NewReader(p), size)
+	}
+	return (*[maxAliasBytes / 4]int32)(unsafe.Pointer(&p[0]))[:size:size], nil
+}
+
+// DecodeUint32Array decodes an array of uint32 values.
+func (d ZeroCopyByteDecoder) DecodeUint32Array(buf *bytes.Reader, size int) ([]uint32, error) {
+	if size == 0 {
+		return d.LEByteDecoder.DecodeUint32Array(buf, size)
+	}
+	p, err := d.readAliased(buf, 4*size)
+	if err != nil {
+		return nil, err
+	}
+	if !canAlias(p, 4) {
+		return d.LEByteDecoder.DecodeUint32Array(bytes.NewReader(p), size)
+	}
+	return (*[maxAliasBytes / 4]uint32)(unsafe.Pointer(&p[0]))[:size:size], nil
+}
+
+// DecodeFloat32Array decodes an array of float32 values.
+func (d ZeroCopyByteDecoder) DecodeFloat32Array(buf *bytes.Reader, size int) ([]JsonFloat32, error) {
+	if size == 0 {
+		return d.LEByteDecoder.DecodeFloat32Array(buf, size)
+	}
+	p, err := d.readAliased(buf, 4*size)
+	if err != nil {
+		return nil, err
+	}
+	if !canAlias(p, 4) {
+		return d.LEByteDecoder.DecodeFloat32Array(bytes.NewReader(p), size)
+	}
+	// JsonFloat32 has the same memory layout as float32.
+	return (*[maxAliasBytes / 4]JsonFloat32)(unsafe.Pointer(&p[0]))[:size:size], nil
+}
+
+// DecodeInt64Array decodes an array of int64 values.
+func (d ZeroCopyByteDecoder) DecodeInt64Array(buf *bytes.Reader, size int) ([]int64, error) {
+	if size == 0 {
+		return d.LEByteDecoder.DecodeInt64Array(buf, size)
+	}
+	p, err := d.readAliased(buf, 8*size)
+	if err != nil {
+		return nil, err
+	}
+	if !canAlias(p, 8) {
+		return d.LEByteDecoder.DecodeInt64Array(bytes.NewReader(p), size)
+	}
+	return (*[maxAliasBytes / 8]int64)(unsafe.Pointer(&p[0]))[:size:size], nil
+}
+
+// DecodeUint64Array decodes an array of uint64 values.
+func (d ZeroCopyByteDecoder) DecodeUint64Array(buf *bytes.Reader, size int) ([]uint64, error) {
+	if size == 0 {
+		return d.LEByteDecoder.DecodeUint64Array(buf, size)
+	}
+	p, err := d.readAliased(buf, 8*size)
+	if err != nil {
+		return nil, err
+	}
+	if !canAlias(p, 8) {
+		return d.LEByteDecoder.DecodeUint64Array(bytes.NewReader(p), size)
+	}
+	return (*[maxAliasBytes / 8]uint64)(unsafe.Pointer(&p[0]))[:size:size], nil
+}
+
+// DecodeFloat64Array decodes an array of float64 values.
+func (d ZeroCopyByteDecoder) DecodeFloat64Array(buf *bytes.Reader, size int) ([]JsonFloat64, error) {
+	if size == 0 {
+		return d.LEByteDecoder.DecodeFloat64Array(buf, size)
+	}
+	p, err := d.readAliased(buf, 8*size)
+	if err != nil {
+		return nil, err
+	}
+	if !canAlias(p, 8) {
+		return d.LEByteDecoder.DecodeFloat64Array(bytes.NewReader(p), size)
+	}
+	// JsonFloat64 has the same memory layout as float64.
+	return (*[maxAliasBytes / 8]JsonFloat64)(unsafe.Pointer(&p[0]))[:size:size], nil
+}
+
+// DecodeMessageArray decodes an array of DynamicMessages, aliasing their numeric arrays.
+func (d ZeroCopyByteDecoder) DecodeMessageArray(buf *bytes.Reader, size int, msgType *DynamicMessageType) ([]Message, error) {
+	slice := make([]Message, size)
+
+	for i := 0; i < size; i++ {
+		// Skip the zero value initialization, this would just get discarded anyway.
+		msg := &DynamicMessage{}
+		msg.dynamicType = msgType
+		if err := msg.deserialize(d, buf); err != nil {
+			return slice, err
+		}
+		slice[i] = msg
+	}
+	return slice, nil
+}
+
+// DecodeMessage decodes a DynamicMessage, aliasing its numeric arrays.
+func (d ZeroCopyByteDecoder) DecodeMessage(buf *bytes.Reader, msgType *DynamicMessageType) (Message, error) {
+	// Skip the zero value initialization, this would just get discarded anyway.
+	msg := &DynamicMessage{}
+	msg.dynamicType = msgType
+	if err := msg.deserialize(d, buf); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// Array encoders.
+
+// EncodeInt8Array encodes an array of int8 values.
+func (e ZeroCopyByteEncoder) EncodeInt8Array(buf *bytes.Buffer, value []int8) error {
+	if len(value) == 0 || len(value) > maxAliasBytes {
+		return e.LEByteEncoder.EncodeInt8Array(buf, value)
+	}
+	buf.Write(bytesOf(unsafe.Pointer(&value[0]), len(value)))
+	return nil
+}
+
+// EncodeInt16Array encodes an array of int16 values.
+func (e ZeroCopyByteEncoder) EncodeInt16Array(buf *bytes.Buffer, value []int16) error {
+	if !hostLittleEndian || len(value) == 0 || 2*len(value) > maxAliasBytes {
+		return e.LEByteEncoder.EncodeInt16Array(buf, value)
+	}
+	buf.Write(bytesOf(unsafe.Pointer(&value[0]), 2*len(value)))
+	return nil
+}
+
+// EncodeUint16Array encodes an array of uint16 values.
+func (e ZeroCopyByteEncoder) EncodeUint16Array(buf *bytes.Buffer, value []uint16) error {
+	if !hostLittleEndian || len(value) == 0 || 2*len(value) > maxAliasBytes {
+		return e.LEByteEncoder.EncodeUint16Array(buf, value)
+	}
+	buf.Write(bytesOf(unsafe.Pointer(&value[0]), 2*len(value)))
+	return nil
+}
+
+// EncodeInt32Array encodes an array of int32 values.
+func (e ZeroCopyByteEncoder) EncodeInt32Array(buf *bytes.Buffer, value []int32) error {
+	if !hostLittleEndian || len(value) == 0 || 4*len(value) > maxAliasBytes {
+		return e.LEByteEncoder.EncodeInt32Array(buf, value)
+	}
+	buf.Write(bytesOf(unsafe.Pointer(&value[0]), 4*len(value)))
+	return nil
+}
+
+// EncodeUint32Array encodes an array of uint32 values.
+func (e ZeroCopyByteEncoder) EncodeUint32Array(buf *bytes.Buffer, value []uint32) error {
+	if !hostLittleEndian || len(value) == 0 || 4*len(value) > maxAliasBytes {
+		return e.LEByteEncoder.EncodeUint32Array(buf, value)
+	}
+	buf.Write(bytesOf(unsafe.Pointer(&value[0]), 4*len(value)))
+	return nil
+}
+
+// EncodeFloat32Array encodes an array of float32 values.
+func (e ZeroCopyByteEncoder) EncodeFloat32Array(buf *bytes.Buffer, value []JsonFloat32) error {
+	if !hostLittleEndian || len(value) == 0 || 4*len(value) > maxAliasBytes {
+		return e.LEByteEncoder.EncodeFloat32Array(buf, value)
+	}
+	buf.Write(bytesOf(unsafe.Pointer(&value[0]), 4*len(value)))
+	return nil
+}
+
+// EncodeInt64Array encodes an array of int64 values.
+func (e ZeroCopyByteEncoder) EncodeInt64Array(buf *bytes.Buffer, value []int64) error {
+	if !hostLittleEndian || len(value) == 0 || 8*len(value) > maxAliasBytes {
+		return e.LEByteEncoder.EncodeInt64Array(buf, value)
+	}
+	buf.Write(bytesOf(unsafe.Pointer(&value[0]), 8*len(value)))
+	return nil
+}
+
+// EncodeUint64Array encodes an array of uint64 values.
+func (e ZeroCopyByteEncoder) EncodeUint64Array(buf *bytes.Buffer, value []uint64) error {
+	if !hostLittleEndian || len(value) == 0 || 8*len(value) > maxAliasBytes {
+		return e.LEByteEncoder.EncodeUint64Array(buf, value)
+	}
+	buf.Write(bytesOf(unsafe.Pointer(&value[0]), 8*len(value)))
+	return nil
+}
+
+// EncodeFloat64Array encodes an array of float64 values.
+func (e ZeroCopyByteEncoder) EncodeFloat64Array(buf *bytes.Buffer, value []JsonFloat64) error {
+	if !hostLittleEndian || len(value) == 0 || 8*len(value) > maxAliasBytes {
+		return e.LEByteEncoder.EncodeFloat64Array(buf, value)
+	}
+	buf.Write(bytesOf(unsafe.Pointer(&value[0]), 8*len(value)))
+	return nil
+}
+
+// EncodeMessage encodes a DynamicMessage, writing its numeric arrays in a single copy.
+func (e ZeroCopyByteEncoder) EncodeMessage(buf *bytes.Buffer, msg *DynamicMessage) error {
+	return msg.serialize(e, buf)
+}
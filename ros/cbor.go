@@ -0,0 +1,455 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// CBOREncoder writes a stream of messages as a sequence of CBOR data items (RFC 8742).
+type CBOREncoder struct {
+	w io.Writer
+}
+
+// CBORDecoder reads a stream of messages written by CBOREncoder.
+type CBORDecoder struct {
+	r *bufio.Reader
+}
+
+// DEFINE PRIVATE STRUCTURES.
+
+// cborFormat implements binaryFormat for CBOR (RFC 8949).
+type cborFormat struct{}
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// CBOR major types.
+const (
+	cborUnsigned byte = iota << 5
+	cborNegative
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// CBOR tags and simple values used by messages.
+const (
+	cborTagDateTime     = 0    // RFC 3339 string.
+	cborTagEpoch        = 1    // Seconds since the epoch, integer or float.
+	cborTagExtendedTime = 1001 // Map of 1: seconds, -9: nanoseconds; RFC 9581.
+	cborTagDuration     = 1002 // Map of 1: seconds, -9: nanoseconds; RFC 9581.
+	cborKeySeconds      = 1
+	cborKeyNanoseconds  = -9
+	cborFalse           = cborSimple | 20
+	cborTrue            = cborSimple | 21
+	cborNull            = cborSimple | 22
+	cborUndefined       = cborSimple | 23
+	cborFloat16         = cborSimple | 25
+	cborFloat32         = cborSimple | 26
+	cborFloat64         = cborSimple | 27
+	cborIndefinite      = 31
+	cborBreak           = cborSimple | cborIndefinite
+)
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// MarshalCBOR encodes a DynamicMessage or a message generated by gengo as CBOR.  Messages are maps from field names to values, in definition order; uint8 arrays are byte strings,
+// float32 and float64 keep their precision, times are extended times (tag 1001) and durations are tag 1002, both with signed seconds and nanoseconds.
+func MarshalCBOR(msg Message) ([]byte, error) {
+	return marshalBinary(cborFormat{}, msg)
+}
+
+// UnmarshalCBOR decodes a message encoded by MarshalCBOR(), or by any other CBOR encoder using the same mapping.  Integers and floats are converted to the types of the fields,
+// provided they are in range; times may also be given as tag 0 or tag 1 dates.  Fields which are not given are zeroed.  The message must be a *DynamicMessage or a pointer to a
+// message generated by gengo.
+func UnmarshalCBOR(buf []byte, msg Message) error {
+	return unmarshalBinary(cborFormat{}, buf, msg)
+}
+
+// NewCBOREncoder creates a CBOREncoder writing to w.
+func NewCBOREncoder(w io.Writer) *CBOREncoder {
+	return &CBOREncoder{w: w}
+}
+
+// NewCBORDecoder creates a CBORDecoder reading from r.
+func NewCBORDecoder(r io.Reader) *CBORDecoder {
+	return &CBORDecoder{r: bufio.NewReader(r)}
+}
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// Encode writes a message.
+func (e *CBOREncoder) Encode(msg Message) error {
+	buf, err := MarshalCBOR(msg)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(buf)
+	return err
+}
+
+// Decode reads the next message from the stream into msg, returning io.EOF when no messages remain.
+func (d *CBORDecoder) Decode(msg Message) error {
+	return decodeBinary(cborFormat{}, d.r, msg)
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// appendCBORHead appends the initial bytes of a data item, using the shortest encoding of the argument.
+func appendCBORHead(buf []byte, major byte, argument uint64) []byte {
+	switch {
+	case argument < 24:
+		return append(buf, major|byte(argument))
+	case argument <= math.MaxUint8:
+		return append(buf, major|24, byte(argument))
+	case argument <= math.MaxUint16:
+		return appendBigEndian(append(buf, major|25), argument, 2)
+	case argument <= math.MaxUint32:
+		return appendBigEndian(append(buf, major|26), argument, 4)
+	}
+	return appendBigEndian(append(buf, major|27), argument, 8)
+}
+
+// readCBORArgument reads the argument which follows the initial byte of a data item; indefinite is true for the indefinite length marker.
+func readCBORArgument(r binaryReader, initial byte) (argument uint64, indefinite bool, err error) {
+	info := initial & 0x1f
+	switch {
+	case info < 24:
+		return uint64(info), false, nil
+	case info <= 27:
+		argument, err = readBinaryUint(r, 1<<(info-24))
+		return argument, false, err
+	case info == cborIndefinite:
+		return 0, true, nil
+	}
+	return 0, false, errors.New("invalid cbor initial byte 0x" + strconv.FormatUint(uint64(initial), 16))
+}
+
+// float16ToFloat64 converts an IEEE 754 half precision float.
+func float16ToFloat64(bits uint16) float64 {
+	exponent := int(bits>>10) & 0x1f
+	mantissa := float64(bits & 0x3ff)
+	var value float64
+	switch exponent {
+	case 0:
+		value = math.Ldexp(mantissa, -24)
+	case 0x1f:
+		if mantissa == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mantissa+1024, exponent-25)
+	}
+	if bits&0x8000 != 0 {
+		return -value
+	}
+	return value
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+func (cborFormat) appendBool(buf []byte, value bool) []byte {
+	if value {
+		return append(buf, cborTrue)
+	}
+	return append(buf, cborFalse)
+}
+
+func (cborFormat) appendInt(buf []byte, value int64) []byte {
+	if value < 0 {
+		return appendCBORHead(buf, cborNegative, uint64(-1-value))
+	}
+	return appendCBORHead(buf, cborUnsigned, uint64(value))
+}
+
+func (cborFormat) appendUint(buf []byte, value uint64) []byte {
+	return appendCBORHead(buf, cborUnsigned, value)
+}
+
+func (cborFormat) appendFloat32(buf []byte, value float32) []byte {
+	return appendBigEndian(append(buf, cborFloat32), uint64(math.Float32bits(value)), 4)
+}
+
+func (cborFormat) appendFloat64(buf []byte, value float64) []byte {
+	return appendBigEndian(append(buf, cborFloat64), math.Float64bits(value), 8)
+}
+
+func (cborFormat) appendString(buf []byte, value string) []byte {
+	return append(appendCBORHead(buf, cborText, uint64(len(value))), value...)
+}
+
+func (cborFormat) appendBytes(buf []byte, value []byte) []byte {
+	return append(appendCBORHead(buf, cborBytes, uint64(len(value))), value...)
+}
+
+func (cborFormat) appendArrayHeader(buf []byte, length int) []byte {
+	return appendCBORHead(buf, cborArray, uint64(length))
+}
+
+func (cborFormat) appendMapHeader(buf []byte, length int) []byte {
+	return appendCBORHead(buf, cborMap, uint64(length))
+}
+
+// appendTime appends a time as an extended time, leaving out the nanoseconds when they're zero.
+func (f cborFormat) appendTime(buf []byte, value Time) []byte {
+	return f.appendTemporal(appendCBORHead(buf, cborTag, cborTagExtendedTime), int64(value.Sec), value.NSec)
+}
+
+// appendDuration appends a duration; durations are signed, so the seconds are negative when the top bit is set.
+func (f cborFormat) appendDuration(buf []byte, value Duration) []byte {
+	return f.appendTemporal(appendCBORHead(buf, cborTag, cborTagDuration), int64(int32(value.Sec)), value.NSec)
+}
+
+// appendTemporal appends the map of seconds and nanoseconds of a time or duration.
+func (f cborFormat) appendTemporal(buf []byte, sec int64, nsec uint32) []byte {
+	if nsec == 0 {
+		return f.appendInt(f.appendInt(appendCBORHead(buf, cborMap, 1), cborKeySeconds), sec)
+	}
+	buf = f.appendInt(f.appendInt(appendCBORHead(buf, cborMap, 2), cborKeySeconds), sec)
+	return f.appendUint(f.appendInt(buf, cborKeyNanoseconds), uint64(nsec))
+}
+
+func (f cborFormat) decodeValue(r binaryReader, depth int) (interface{}, error) {
+	if depth > maxBinaryDepth {
+		return nil, errors.New("cbor nested too deeply")
+	}
+	initial, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	major := initial & 0xe0
+	if major == cborSimple {
+		return f.decodeSimple(r, initial)
+	}
+	argument, indefinite, err := readCBORArgument(r, initial)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if indefinite && (major == cborUnsigned || major == cborNegative || major == cborTag) {
+		return nil, errors.New("invalid cbor initial byte 0x" + strconv.FormatUint(uint64(initial), 16))
+	}
+
+	switch major {
+	case cborUnsigned:
+		return argument, nil
+	case cborNegative:
+		if argument > math.MaxInt64 {
+			return nil, errors.New("cbor negative integer out of range")
+		}
+		return -1 - int64(argument), nil
+	case cborBytes, cborText:
+		data, err := f.decodeString(r, major, argument, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		if major == cborText {
+			return string(data), nil
+		}
+		return data, nil
+	case cborArray:
+		array := make([]interface{}, 0, binaryContainerCapacity(argument))
+		for i := uint64(0); indefinite || i < argument; i++ {
+			if indefinite && f.atBreak(r) {
+				break
+			}
+			item, err := f.decodeValue(r, depth+1)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			array = append(array, item)
+		}
+		return array, nil
+	case cborMap:
+		mapping := make(map[string]interface{}, binaryContainerCapacity(argument))
+		for i := uint64(0); indefinite || i < argument; i++ {
+			if indefinite && f.atBreak(r) {
+				break
+			}
+			key, err := f.decodeValue(r, depth+1)
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, errors.New("expected cbor map key to be a string, got " + describeValue(key))
+			}
+			if mapping[name], err = f.decodeValue(r, depth+1); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+		}
+		return mapping, nil
+	}
+
+	// It's a tag.
+	switch argument {
+	case cborTagExtendedTime, cborTagDuration:
+		sec, nsec, err := f.decodeTemporal(r, depth)
+		if err != nil {
+			return nil, err
+		}
+		if argument == cborTagDuration {
+			if sec < math.MinInt32 || sec > math.MaxInt32 {
+				return nil, errors.New("cbor duration out of range")
+			}
+			return NewDuration(uint32(sec), nsec), nil
+		}
+		if sec < 0 || sec > math.MaxUint32 {
+			return nil, errors.New("cbor time out of range")
+		}
+		return NewTime(uint32(sec), nsec), nil
+	case cborTagDateTime, cborTagEpoch:
+		content, err := f.decodeValue(r, depth+1)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		var t time.Time
+		if text, ok := content.(string); ok && argument == cborTagDateTime {
+			if t, err = time.Parse(time.RFC3339Nano, text); err != nil {
+				return nil, err
+			}
+		} else if seconds, ok := goNumberToFloat64(content); ok && argument == cborTagEpoch {
+			whole, fraction := math.Modf(seconds)
+			t = time.Unix(int64(whole), int64(math.Round(fraction*1e9)))
+		} else {
+			return nil, errors.New("invalid content of cbor tag " + strconv.FormatUint(argument, 10))
+		}
+		if t.Unix() < 0 || t.Unix() > math.MaxUint32 {
+			return nil, errors.New("cbor time out of range")
+		}
+		return NewTime(uint32(t.Unix()), uint32(t.Nanosecond())), nil
+	}
+	// Other tags carry no meaning for messages, so just their content is used.
+	return f.decodeValue(r, depth+1)
+}
+
+// decodeSimple decodes the simple values and floats.
+func (cborFormat) decodeSimple(r binaryReader, initial byte) (interface{}, error) {
+	switch initial {
+	case cborFalse:
+		return false, nil
+	case cborTrue:
+		return true, nil
+	case cborNull, cborUndefined:
+		return nil, nil
+	case cborFloat16:
+		bits, err := readBinaryUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return float16ToFloat64(uint16(bits)), nil
+	case cborFloat32:
+		bits, err := readBinaryUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(uint32(bits)), nil
+	case cborFloat64:
+		bits, err := readBinaryUint(r, 8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(bits), nil
+	}
+	return nil, errors.New("unsupported cbor simple value 0x" + strconv.FormatUint(uint64(initial), 16))
+}
+
+// decodeString reads a byte or text string, joining the chunks of indefinite length strings.
+func (f cborFormat) decodeString(r binaryReader, major byte, length uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		return readBinaryBytes(r, length)
+	}
+	data := make([]byte, 0)
+	for !f.atBreak(r) {
+		initial, err := r.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if initial&0xe0 != major || initial&0x1f == cborIndefinite {
+			return nil, errors.New("invalid chunk in indefinite length cbor string")
+		}
+		argument, _, err := readCBORArgument(r, initial)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		chunk, err := readBinaryBytes(r, argument)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+	return data, nil
+}
+
+// decodeTemporal reads the map of seconds and nanoseconds of an extended time or duration.
+func (f cborFormat) decodeTemporal(r binaryReader, depth int) (int64, uint32, error) {
+	initial, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, unexpectedEOF(err)
+	}
+	length, _, err := readCBORArgument(r, initial)
+	if err != nil || initial&0xe0 != cborMap || initial&0x1f == cborIndefinite {
+		return 0, 0, errors.New("expected cbor time to be a map of seconds and nanoseconds")
+	}
+	var sec, nsec int64
+	hasSec := false
+	for i := uint64(0); i < length; i++ {
+		var parts [2]int64
+		for j := range parts {
+			value, err := f.decodeValue(r, depth+1)
+			if err != nil {
+				return 0, 0, unexpectedEOF(err)
+			}
+			i, u, isUnsigned, ok := goNumberToInteger(value)
+			if !ok || (isUnsigned && u > math.MaxInt64) {
+				return 0, 0, errors.New("expected cbor time to hold integers, got " + describeValue(value))
+			}
+			if isUnsigned {
+				i = int64(u)
+			}
+			parts[j] = i
+		}
+		switch parts[0] {
+		case cborKeySeconds:
+			sec, hasSec = parts[1], true
+		case cborKeyNanoseconds:
+			nsec = parts[1]
+		default:
+			return 0, 0, errors.New("unsupported cbor time key " + strconv.FormatInt(parts[0], 10))
+		}
+	}
+	if !hasSec || nsec < 0 || nsec > 999999999 {
+		return 0, 0, errors.New("invalid cbor time")
+	}
+	return sec, uint32(nsec), nil
+}
+
+// atBreak consumes the break which ends an indefinite length item, reporting whether it was found.
+func (cborFormat) atBreak(r binaryReader) bool {
+	b, err := r.ReadByte()
+	if err != nil {
+		return false
+	}
+	if b == cborBreak {
+		return true
+	}
+	// Not the end, so put the byte back for the next item.
+	r.UnreadByte()
+	return false
+}
+
+// ALL DONE.
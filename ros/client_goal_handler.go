@@ -14,6 +14,7 @@ type clientGoalHandler struct {
 	actionGoalID string
 	transitionCb interface{}
 	feedbackCb   interface{}
+	attached     bool
 	logger       *modular.ModuleLogger
 }
 
@@ -80,7 +81,7 @@ func (gh *clientGoalHandler) GetTerminalState() (uint8, error) {
 		return 0, fmt.Errorf("trying to get goal status on inactive clientGoalHandler")
 	}
 
-	if gh.stateMachine.state != Done {
+	if gh.stateMachine.state != Done && gh.stateMachine.state != Lost {
 		logger.Warnf("Asking for terminal state when we are in state %v", gh.stateMachine.state)
 	}
 
@@ -118,6 +119,9 @@ func (gh *clientGoalHandler) Resend() error {
 	if gh.stateMachine == nil {
 		return fmt.Errorf("trying to call resend on inactive client goal hanlder")
 	}
+	if gh.attached {
+		return fmt.Errorf("trying to call resend on a goal attached by id; the goal itself is unknown")
+	}
 
 	gh.actionClient.goalPub.Publish(gh.actionGoal)
 	return nil
@@ -147,6 +151,26 @@ func (gh *clientGoalHandler) Shutdown(deleteFromManager bool) {
 	}
 }
 
+// setLost moves an outstanding goal to Lost, whether the server disconnected
+// or stopped reporting the goal; goals which are already done or lost are left
+// alone.
+func (gh *clientGoalHandler) setLost() {
+	logger := *gh.logger
+	sm := gh.stateMachine
+	if sm == nil {
+		return
+	}
+
+	state := sm.getState()
+	if state == Done || state == Lost {
+		return
+	}
+
+	logger.Warnf("Transitioning goal with actionGoalID: %v to `Lost`", gh.actionGoalID)
+	sm.setAsLost()
+	sm.transitionTo(Lost, gh, gh.transitionCb)
+}
+
 func (gh *clientGoalHandler) updateFeedback(af ActionFeedback) {
 	if gh.actionGoalID != af.GetStatus().GetGoalID().GetID() {
 		return
@@ -180,7 +204,8 @@ func (gh *clientGoalHandler) updateResult(result ActionResult) error {
 		state == Active ||
 		state == WaitingForResult ||
 		state == Recalling ||
-		state == Preempting {
+		state == Preempting ||
+		state == Lost {
 
 		// Create a status array message
 		statusArray := NewActionStatusArrayType().NewStatusArrayMessage()
@@ -204,21 +229,17 @@ func (gh *clientGoalHandler) updateResult(result ActionResult) error {
 }
 
 func (gh *clientGoalHandler) updateStatus(statusArr ActionStatusArray) error {
-	logger := *gh.logger
 	state := gh.stateMachine.getState()
-	if state == Done {
+	if state == Done || state == Lost {
 		return nil
 	}
 
 	status := findGoalStatus(statusArr, gh.actionGoalID)
 	if status == nil {
 		if state != WaitingForGoalAck &&
-			state != WaitingForResult &&
-			state != Done {
+			state != WaitingForResult {
 
-			logger.Warnf("Transitioning goal with actionGoalID: %v to `Lost`", gh.actionGoalID)
-			gh.stateMachine.setAsLost()
-			gh.stateMachine.transitionTo(Done, gh, gh.transitionCb)
+			gh.setLost()
 		}
 		return nil
 	}
@@ -113,7 +113,7 @@ func (sm *clientStateMachine) setAsLost() {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	sm.goalStatus.SetStatus(uint8(Lost))
+	sm.goalStatus.SetStatus(uint8(9))
 }
 
 func (sm *clientStateMachine) transitionTo(state CommState, gh ClientGoalHandler, callback interface{}) {
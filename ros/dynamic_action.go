@@ -3,8 +3,6 @@ package ros
 // IMPORT REQUIRED PACKAGES.
 
 import (
-	"strings"
-
 	"github.com/pkg/errors"
 	"github.com/team-rocos/rosgo/libgengo"
 )
@@ -63,29 +61,18 @@ type DynamicActionStatusArrayType struct{ DynamicMessageType }
 // ROS action type name.  The first time the function is run, a message/service/action 'context' is created by searching through the available ROS definitions, then the ROS action to
 // be used for the definition is looked up by name.  On subsequent calls, the ROS action type is looked up directly from the existing context.
 func NewDynamicActionType(typeName string) (*DynamicActionType, error) {
-	return newDynamicActionTypeNested(typeName, "")
+	return defaultTypeRegistry.NewDynamicActionType(typeName)
 }
 
-// newDynamicActionTypeNested generates a DynamicActionType from the available ROS definitions.  The first time the function is run, a message/service/action 'context' is created by
-// searching through the available ROS definitions, then the ROS action type to use for the defintion is looked up by name.  On subsequent calls, the ROS action type
-// is looked up directly from the existing context.  This 'nested' version of the function is able to be called recursively, where packageName should be the typeName of the
-// parent ROS action; this is used internally for handling complex ROS action.
-func newDynamicActionTypeNested(typeName string, packageName string) (*DynamicActionType, error) {
+// newDynamicActionTypeNested generates a DynamicActionType from the ROS definitions available in the provided context, as loaded by a TypeRegistry.  This 'nested' version of the
+// function is able to be called recursively, where packageName should be the typeName of the parent ROS action; this is used internally for handling complex ROS action.
+func newDynamicActionTypeNested(ctx *libgengo.PkgContext, typeName string, packageName string) (*DynamicActionType, error) {
 	// Create an empty action type.
 	m := new(DynamicActionType)
 
-	if context == nil {
-		// Create context for our ROS install.
-		c, err := libgengo.NewPkgContext(strings.Split(GetRuntimePackagePath(), ":"))
-		if err != nil {
-			return nil, err
-		}
-		context = c
-	}
-
 	// We need to try to look up the full name, in case we've just been given a short name.
 	fullname := typeName
-	_, ok := context.GetActions()[fullname]
+	_, ok := ctx.GetActions()[fullname]
 	if !ok {
 		// Messages in the same package are allowed to use relative names, so try prefixing the package.
 		if packageName != "" {
@@ -94,7 +81,7 @@ func newDynamicActionTypeNested(typeName string, packageName string) (*DynamicAc
 	}
 
 	// Load context for the target message.
-	spec, err := context.LoadAction(fullname)
+	spec, err := ctx.LoadAction(fullname)
 	if err != nil {
 		return nil, err
 	}
@@ -105,21 +92,21 @@ func newDynamicActionTypeNested(typeName string, packageName string) (*DynamicAc
 	m.text = spec.Text
 
 	// Create Dynamic Goal Type
-	goalType, err := NewDynamicMessageTypeFromSpec(spec.ActionGoal)
+	goalType, err := newDynamicMessageTypeFromSpecInContext(ctx, spec.ActionGoal)
 	if err != nil {
 		return nil, err
 	}
 	m.goalType = &DynamicActionGoalType{*goalType}
 
 	// Create Dynamic Feedback Type
-	feedbackType, err := NewDynamicMessageTypeFromSpec(spec.ActionFeedback)
+	feedbackType, err := newDynamicMessageTypeFromSpecInContext(ctx, spec.ActionFeedback)
 	if err != nil {
 		return nil, err
 	}
 	m.feedbackType = &DynamicActionFeedbackType{*feedbackType}
 
 	// Create Dynamic Result Type
-	resultType, err := NewDynamicMessageTypeFromSpec(spec.ActionResult)
+	resultType, err := newDynamicMessageTypeFromSpecInContext(ctx, spec.ActionResult)
 	if err != nil {
 		return nil, err
 	}
@@ -445,3 +432,16 @@ func (m *DynamicActionStatusArray) GetHeader() Message {
 	return m.Data()["header"].(*DynamicMessage)
 }
 func (m *DynamicActionStatusArray) SetHeader(header Message) { m.Data()["header"] = header }
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+//	DynamicActionType
+
+// clone returns a copy of the action type whose goal, feedback and result types are copies too.
+func (t *DynamicActionType) clone() *DynamicActionType {
+	m := *t
+	m.goalType = &DynamicActionGoalType{*t.goalType.(*DynamicActionGoalType).clone()}
+	m.feedbackType = &DynamicActionFeedbackType{*t.feedbackType.(*DynamicActionFeedbackType).clone()}
+	m.resultType = &DynamicActionResultType{*t.resultType.(*DynamicActionResultType).clone()}
+	return &m
+}
@@ -4,11 +4,10 @@ package ros
 
 import (
 	"bytes"
-	"encoding/binary"
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
+	"unsafe"
 
 	"github.com/pkg/errors"
 	"github.com/team-rocos/rosgo/libgengo"
@@ -23,6 +22,11 @@ type DynamicMessageType struct {
 	spec         *libgengo.MsgSpec
 	nested       map[string]*DynamicMessageType // Map with key string = messageType name.
 	jsonPrealloc int
+	pkgContext   *libgengo.PkgContext // Context used to resolve nested message types; nil denotes the default runtime context.
+	lazy         bool                 // Whether Deserialize() defers decoding fields until they are accessed.
+	decoder      ByteDecoder          // Decoder used by Deserialize(); nil denotes LEByteDecoder.
+	encoder      ByteEncoder          // Encoder used by Serialize(); nil denotes LEByteEncoder.
+	pool         unsafe.Pointer       // The *sync.Pool of released messages which Acquire() recycles, created when first needed; copies of the type share it.
 }
 
 // DynamicMessage abstracts an instance of a ROS Message whose type is only known at runtime.  The schema of the message is denoted by the referenced DynamicMessageType, while the
@@ -31,6 +35,18 @@ type DynamicMessageType struct {
 type DynamicMessage struct {
 	dynamicType *DynamicMessageType
 	data        map[string]interface{}
+	raw         []byte // Serialized fields which haven't been decoded yet, when lazily deserialized.
+	rawOffsets  []int  // Offset of each field within raw, followed by the length of raw.
+	pooled      bool   // Whether the message was acquired from the pool of its type, so that deserializing into it reuses its memory.
+	aliased     bool   // Whether the arrays of the message may alias the buffer it was decoded from, so that they must never be decoded into.
+}
+
+// DynamicMessageConstant is a constant declared in a ROS message definition, such as `uint8 SUCCEEDED=3`.  Value holds the constant using the Go type of its ROS type, e.g. uint8
+// for a byte constant and float64 for a float64 constant.
+type DynamicMessageConstant struct {
+	Name  string
+	Type  string
+	Value interface{}
 }
 
 // DEFINE PRIVATE STRUCTURES.
@@ -49,44 +65,32 @@ func (m *DynamicMessage) GetDynamicType() *DynamicMessageType {
 
 // DEFINE PRIVATE GLOBALS.
 
-var rosPkgPath string // Colon separated list of paths to search for message definitions on.
-
-var context *libgengo.PkgContext // We'll try to preserve a single message context to avoid reloading each time.
+var messageDefinitionSeparator = strings.Repeat("=", 80) // Separates dependent message definitions in a full ROS message definition.
 
 // DEFINE PUBLIC STATIC FUNCTIONS.
 
 // SetRuntimePackagePath sets the ROS package search path which will be used by DynamicMessage to look up ROS message definitions at runtime.
 func SetRuntimePackagePath(path string) {
 	// We're not going to check that the result is valid, we'll just accept it blindly.
-	rosPkgPath = path
-	// Reset the message context
-	ResetContext()
-	// All done.
-	return
+	defaultTypeRegistry.SetPackagePath(path)
 }
 
 // GetRuntimePackagePath returns the ROS package search path which will be used by DynamicMessage to look up ROS message definitions at runtime.  By default, this will
 // be equivalent to the ${ROS_PACKAGE_PATH} environment variable.
 func GetRuntimePackagePath() string {
-	// If a package path hasn't been set at the time of first use, by default we'll just use the ROS environment default.
-	if rosPkgPath == "" {
-		rosPkgPath = os.Getenv("ROS_PACKAGE_PATH")
-	}
-	// All done.
-	return rosPkgPath
+	return defaultTypeRegistry.PackagePath()
 }
 
-// ResetContext resets the package path context so that a new one will be generated
+// ResetContext resets the package path context of the default TypeRegistry so that a new one will be generated
 func ResetContext() {
-	context = nil
+	defaultTypeRegistry.Reset()
 }
 
 // NewDynamicMessageType generates a DynamicMessageType corresponding to the specified typeName from the available ROS message definitions; typeName should be a fully-qualified
 // ROS message type name.  The first time the function is run, a message 'context' is created by searching through the available ROS message definitions, then the ROS message to
-// be used for the definition is looked up by name.  On subsequent calls, the ROS message type is looked up directly from the existing context.
+// be used for the definition is looked up by name.  On subsequent calls, the ROS message type is looked up directly from the existing context.  The default TypeRegistry is used.
 func NewDynamicMessageType(typeName string) (*DynamicMessageType, error) {
-	t, err := newDynamicMessageTypeNested(typeName, "", nil, nil)
-	return t, err
+	return defaultTypeRegistry.NewDynamicMessageType(typeName)
 }
 
 // NewDynamicMessageTypeLiteral generates a DynamicMessageType, and returns a copy of the generated type. This is required by DynamicAction.
@@ -98,11 +102,17 @@ func NewDynamicMessageTypeLiteral(typeName string) (DynamicMessageType, error) {
 // NewDynamicMessageTypeFromSpec creates a DynamicMessageType using a preloaded message specification.
 // When loading a service or action, multiple message specs are loaded at once, so `NewDynamicMessageType` is not applicable.
 func NewDynamicMessageTypeFromSpec(spec *libgengo.MsgSpec) (*DynamicMessageType, error) {
+	return newDynamicMessageTypeFromSpecInContext(nil, spec)
+}
+
+// newDynamicMessageTypeFromSpecInContext creates a DynamicMessageType using a preloaded message specification, resolving any nested message types from the provided context.  A nil
+// context denotes the default runtime context.
+func newDynamicMessageTypeFromSpecInContext(ctx *libgengo.PkgContext, spec *libgengo.MsgSpec) (*DynamicMessageType, error) {
 	if spec == nil {
 		return nil, errors.New("spec is empty")
 	}
 	// Create an empty message type.
-	t := &DynamicMessageType{}
+	t := &DynamicMessageType{pkgContext: ctx}
 
 	// Create nested map and self-register.
 	t.nested = make(map[string]*DynamicMessageType)
@@ -123,19 +133,23 @@ func NewDynamicMessageTypeFromSpec(spec *libgengo.MsgSpec) (*DynamicMessageType,
 // is looked up directly from the existing context.  This 'nested' version of the function is able to be called recursively, where packageName should be the typeName of the
 // parent ROS message; this is used internally for handling complex ROS messages.
 func newDynamicMessageTypeNested(typeName string, packageName string, nested map[string]*DynamicMessageType, nestedChain map[string]struct{}) (*DynamicMessageType, error) {
-	// Create an empty message type.
-	t := &DynamicMessageType{}
-
 	// If we haven't created a message context yet, better do that.
-	if context == nil {
-		// Create context for our ROS install.
-		c, err := libgengo.NewPkgContext(strings.Split(GetRuntimePackagePath(), ":"))
-		if err != nil {
-			return t, err
-		}
-		context = c
+	defaultTypeRegistry.mutex.Lock()
+	defer defaultTypeRegistry.mutex.Unlock()
+	ctx, err := defaultTypeRegistry.loadContext()
+	if err != nil {
+		return &DynamicMessageType{}, err
 	}
 
+	return newDynamicMessageTypeNestedInContext(ctx, typeName, packageName, nested, nestedChain)
+}
+
+// newDynamicMessageTypeNestedInContext generates a DynamicMessageType by looking up the ROS message definitions registered in the provided context, rather than the default runtime
+// context.  The resulting type, and all types nested within it, will continue to resolve definitions from the same context.
+func newDynamicMessageTypeNestedInContext(ctx *libgengo.PkgContext, typeName string, packageName string, nested map[string]*DynamicMessageType, nestedChain map[string]struct{}) (*DynamicMessageType, error) {
+	// Create an empty message type.
+	t := &DynamicMessageType{pkgContext: ctx}
+
 	// We need to try to look up the full name, in case we've just been given a short name.
 	fullname := typeName
 
@@ -143,7 +157,7 @@ func newDynamicMessageTypeNested(typeName string, packageName string, nested map
 	if typeName == "Header" {
 		fullname = "std_msgs/Header"
 	} else {
-		_, ok := context.GetMsgs()[fullname]
+		_, ok := ctx.GetMsgs()[fullname]
 		if !ok {
 			// Seems like the package_name we were give wasn't the full name.
 
@@ -176,7 +190,7 @@ func newDynamicMessageTypeNested(typeName string, packageName string, nested map
 	nestedChain[fullname] = struct{}{}
 
 	// Load context for the target message.
-	spec, err := context.LoadMsg(fullname)
+	spec, err := ctx.LoadMsg(fullname)
 	if err != nil {
 		return t, err
 	}
@@ -210,7 +224,12 @@ func (t *DynamicMessageType) populateFromSpec(spec *libgengo.MsgSpec, nestedChai
 	// Generate the spec for any nested messages.
 	for _, field := range spec.Fields {
 		if field.IsBuiltin == false {
-			_, err := newDynamicMessageTypeNested(field.Type, field.Package, t.nested, nestedChain)
+			var err error
+			if t.pkgContext != nil {
+				_, err = newDynamicMessageTypeNestedInContext(t.pkgContext, field.Type, field.Package, t.nested, nestedChain)
+			} else {
+				_, err = newDynamicMessageTypeNested(field.Type, field.Package, t.nested, nestedChain)
+			}
 			if err != nil {
 				return err
 			}
@@ -248,6 +267,90 @@ func (t *DynamicMessageType) MD5Sum() string {
 	return t.spec.MD5Sum
 }
 
+// Definition returns the full ROS message definition of the message type, including the definitions of all nested message types, in the same format as the `message_definition`
+// connection header sent by roscpp and rospy publishers.
+func (t *DynamicMessageType) Definition() string {
+	if t.spec == nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(t.spec.Text)
+
+	// Walk the nested message types depth first, writing each dependency exactly once.
+	visited := map[string]struct{}{t.spec.FullName: struct{}{}}
+	var writeDependencies func(mt *DynamicMessageType)
+	writeDependencies = func(mt *DynamicMessageType) {
+		for _, field := range mt.spec.Fields {
+			if field.IsBuiltin {
+				continue
+			}
+			nestedType, err := mt.getNestedTypeFromField(&field)
+			if err != nil || nestedType.spec == nil {
+				continue
+			}
+			if _, ok := visited[nestedType.spec.FullName]; ok {
+				continue
+			}
+			visited[nestedType.spec.FullName] = struct{}{}
+			buf.WriteString("\n" + messageDefinitionSeparator + "\nMSG: " + nestedType.spec.FullName + "\n")
+			buf.WriteString(nestedType.spec.Text)
+			writeDependencies(nestedType)
+		}
+	}
+	writeDependencies(t)
+
+	return buf.String()
+}
+
+// SetByteDecoder sets the decoder which Deserialize() uses for messages of this type, including their nested messages; nil restores the default, LEByteDecoder, which reads the
+// TCPROS wire format.
+func (t *DynamicMessageType) SetByteDecoder(d ByteDecoder) {
+	t.decoder = d
+}
+
+// ByteDecoder returns the decoder which Deserialize() uses for messages of this type.
+func (t *DynamicMessageType) ByteDecoder() ByteDecoder {
+	if t.decoder == nil {
+		return LEByteDecoder{}
+	}
+	return t.decoder
+}
+
+// SetByteEncoder sets the encoder which Serialize() uses for messages of this type, including their nested messages; nil restores the default, LEByteEncoder, which writes the
+// TCPROS wire format.
+func (t *DynamicMessageType) SetByteEncoder(e ByteEncoder) {
+	t.encoder = e
+}
+
+// ByteEncoder returns the encoder which Serialize() uses for messages of this type.
+func (t *DynamicMessageType) ByteEncoder() ByteEncoder {
+	if t.encoder == nil {
+		return LEByteEncoder{}
+	}
+	return t.encoder
+}
+
+// Constants returns the constants declared in the message definition, in the order they are declared.
+func (t *DynamicMessageType) Constants() []DynamicMessageConstant {
+	if t.spec == nil {
+		return nil
+	}
+
+	constants := make([]DynamicMessageConstant, 0, len(t.spec.Constants))
+	for _, constant := range t.spec.Constants {
+		value := constant.Value
+		// libgengo parses byte constants as int8, but byte fields are held as uint8.
+		if goType := libgengo.ToGoType("", constant.Type); isIntegerGoType(goType) {
+			if converted, err := coerceInteger(goType, value); err == nil {
+				value = converted
+			}
+		}
+		constants = append(constants, DynamicMessageConstant{Name: constant.Name, Type: constant.Type, Value: value})
+	}
+	return constants
+}
+
 // NewMessage creates a new DynamicMessage instantiating the message type; required for ros.MessageType.
 func (t *DynamicMessageType) NewMessage() Message {
 	// Don't instantiate messages for incomplete types.
@@ -260,28 +363,467 @@ func (t *DynamicMessageType) NewDynamicMessage() *DynamicMessage {
 	d := &DynamicMessage{}
 	d.dynamicType = t
 
-	var err error
-	d.data, err = t.zeroValueData()
-	if err != nil {
-		return d
+	var err error
+	d.data, err = t.zeroValueData()
+	if err != nil {
+		return d
+	}
+	return d
+}
+
+//	DynamicMessage
+
+// Data returns the data map field of the DynamicMessage.  Fields of a lazily deserialized message which fail to decode are missing from the map; DecodeLazyFields() and
+// Validate() report the error.
+func (m *DynamicMessage) Data() map[string]interface{} {
+	m.decodeLazyFields()
+	return m.data
+}
+
+// Type returns the ROS type of a dynamic message; required for ros.Message.
+func (m *DynamicMessage) Type() MessageType {
+	return m.dynamicType
+}
+
+// Serialize converts a DynamicMessage into a TCPROS bytestream allowing it to be published to other nodes, using the ByteEncoder of its type; required for ros.Message.
+func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
+	return m.serialize(m.dynamicType.ByteEncoder(), buf)
+}
+
+// Deserialize parses a byte stream into a DynamicMessage, thus reconstructing the fields of a received ROS message, using the ByteDecoder of its type; required for ros.Message.
+func (m *DynamicMessage) Deserialize(buf *bytes.Reader) error {
+	return m.deserialize(m.dynamicType.ByteDecoder(), buf)
+}
+
+// DeserializeZeroCopy parses data into a DynamicMessage like Deserialize(), but decodes it with a ZeroCopyByteDecoder so that the numeric arrays of the message alias data instead of
+// being copied.  The caller hands data over to the message: it must not be modified or reused while the message is in use.
+func (m *DynamicMessage) DeserializeZeroCopy(data []byte) error {
+	d, buf := NewZeroCopyByteDecoder(data)
+	return m.deserialize(d, buf)
+}
+
+// String returns a string which represents the encapsulared DynamicMessage data.  If fields of a lazily deserialized message fail to decode, the error is included.
+func (m *DynamicMessage) String() string {
+	// Just print out the data!
+	if err := m.decodeLazyFields(); err != nil {
+		return fmt.Sprint(m.dynamicType.Name(), "::", m.data, " (", err, ")")
+	}
+	return fmt.Sprint(m.dynamicType.Name(), "::", m.data)
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// loadMessageDefinition registers a full ROS message definition, as produced by DynamicMessageType.Definition(), into the provided context under the specified fullname.  Each
+// dependent message definition is registered under its own name before the top level message is loaded, so that the MD5 sums of all registered specs are computed correctly.
+func loadMessageDefinition(ctx *libgengo.PkgContext, fullname string, definition string) (*libgengo.MsgSpec, error) {
+	// Split the definition up into the top level text and the text of each dependency.
+	sections := strings.Split(definition, "\n"+messageDefinitionSeparator+"\n")
+	text := sections[0]
+
+	dependencies := make([]*libgengo.MsgSpec, 0, len(sections)-1)
+	for _, section := range sections[1:] {
+		lines := strings.SplitN(section, "\n", 2)
+		if !strings.HasPrefix(lines[0], "MSG: ") {
+			return nil, errors.New("message definition of " + fullname + ": dependency section does not begin with 'MSG: '")
+		}
+		depName := strings.TrimSpace(strings.TrimPrefix(lines[0], "MSG: "))
+		depText := ""
+		if len(lines) > 1 {
+			depText = lines[1]
+		}
+		spec, err := ctx.LoadMsgFromString(depText, depName)
+		if err != nil {
+			return nil, errors.Wrap(err, "message definition of "+fullname+": dependency "+depName)
+		}
+		dependencies = append(dependencies, spec)
+	}
+
+	// Dependencies may reference each other in any order, so the MD5 sums are only reliable once every dependency has been registered.
+	for _, spec := range dependencies {
+		md5sum, err := ctx.ComputeMsgMD5(spec)
+		if err != nil {
+			return nil, errors.Wrap(err, "message definition of "+fullname+": dependency "+spec.FullName)
+		}
+		spec.MD5Sum = md5sum
+	}
+
+	return ctx.LoadMsgFromString(text, fullname)
+}
+
+// encodeMessage serializes a nested message, using the encoder of the enclosing message when the nested message is a DynamicMessage.
+func encodeMessage(e ByteEncoder, buf *bytes.Buffer, msg dynamicMessageLike) error {
+	if dm, ok := msg.(*DynamicMessage); ok {
+		return e.EncodeMessage(buf, dm)
+	}
+	return msg.Serialize(buf)
+}
+
+// sameByteOrder reports whether a decoder and an encoder are known to read and write the same wire format.
+func sameByteOrder(d ByteDecoder, e ByteEncoder) bool {
+	switch d.(type) {
+	case LEByteDecoder, ZeroCopyByteDecoder:
+		switch e.(type) {
+		case LEByteEncoder, ZeroCopyByteEncoder:
+			return true
+		}
+	case BEByteDecoder:
+		_, ok := e.(BEByteEncoder)
+		return ok
+	}
+	return false
+}
+
+// encodeBuiltinArray encodes the first size elements of an array of a builtin type using the array encoders of e, returning false if the array isn't held as a slice of the Go
+// type of the field.
+func encodeBuiltinArray(e ByteEncoder, buf *bytes.Buffer, field *libgengo.Field, array interface{}, size int) (bool, error) {
+	if !field.IsBuiltin || reflect.ValueOf(array).Len() < size {
+		return false, nil
+	}
+	switch v := array.(type) {
+	case []bool:
+		if field.GoType == "bool" {
+			return true, e.EncodeBoolArray(buf, v[:size])
+		}
+	case []int8:
+		if field.GoType == "int8" {
+			return true, e.EncodeInt8Array(buf, v[:size])
+		}
+	case []int16:
+		if field.GoType == "int16" {
+			return true, e.EncodeInt16Array(buf, v[:size])
+		}
+	case []int32:
+		if field.GoType == "int32" {
+			return true, e.EncodeInt32Array(buf, v[:size])
+		}
+	case []int64:
+		if field.GoType == "int64" {
+			return true, e.EncodeInt64Array(buf, v[:size])
+		}
+	case []uint8:
+		if field.GoType == "uint8" {
+			return true, e.EncodeUint8Array(buf, v[:size])
+		}
+	case []uint16:
+		if field.GoType == "uint16" {
+			return true, e.EncodeUint16Array(buf, v[:size])
+		}
+	case []uint32:
+		if field.GoType == "uint32" {
+			return true, e.EncodeUint32Array(buf, v[:size])
+		}
+	case []uint64:
+		if field.GoType == "uint64" {
+			return true, e.EncodeUint64Array(buf, v[:size])
+		}
+	case []JsonFloat32:
+		if field.GoType == "float32" {
+			return true, e.EncodeFloat32Array(buf, v[:size])
+		}
+	case []JsonFloat64:
+		if field.GoType == "float64" {
+			return true, e.EncodeFloat64Array(buf, v[:size])
+		}
+	case []string:
+		if field.GoType == "string" {
+			return true, e.EncodeStringArray(buf, v[:size])
+		}
+	case []Time:
+		if field.GoType == "ros.Time" {
+			return true, e.EncodeTimeArray(buf, v[:size])
+		}
+	case []Duration:
+		if field.GoType == "ros.Duration" {
+			return true, e.EncodeDurationArray(buf, v[:size])
+		}
+	}
+	return false, nil
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// clone returns a copy of the type which shares its schema and nested types, but has the default settings and its own pool of messages.
+func (t *DynamicMessageType) clone() *DynamicMessageType {
+	return &DynamicMessageType{
+		spec:         t.spec,
+		nested:       t.nested,
+		jsonPrealloc: t.jsonPrealloc,
+		pkgContext:   t.pkgContext,
+	}
+}
+
+// zeroValueData creates the zeroValue (default) data map for a new DynamicMessage.
+func (t *DynamicMessageType) zeroValueData() (map[string]interface{}, error) {
+	//Create map
+	d := make(map[string]interface{})
+
+	// Function guards to prevent this call from panicking.
+	if t.spec == nil {
+		return d, errors.New("dynamic message type spec is nil")
+	}
+	if t.nested == nil {
+		return d, errors.New("dynamic message type nested is nil")
+	}
+
+	var err error
+
+	//Range fields in the dynamic message type.
+	for _, field := range t.spec.Fields {
+		if field.IsArray {
+			// If the array length is static set the size, for dynamic arrays, use 0.
+			var size int = 0
+			if field.ArrayLen > 0 {
+				size = field.ArrayLen
+			}
+
+			switch field.GoType {
+			case "bool":
+				d[field.Name] = make([]bool, size)
+			case "int8":
+				d[field.Name] = make([]int8, size)
+			case "int16":
+				d[field.Name] = make([]int16, size)
+			case "int32":
+				d[field.Name] = make([]int32, size)
+			case "int64":
+				d[field.Name] = make([]int64, size)
+			case "uint8":
+				d[field.Name] = make([]uint8, size)
+			case "uint16":
+				d[field.Name] = make([]uint16, size)
+			case "uint32":
+				d[field.Name] = make([]uint32, size)
+			case "uint64":
+				d[field.Name] = make([]uint64, size)
+			case "float32":
+				d[field.Name] = make([]JsonFloat32, size)
+			case "float64":
+				d[field.Name] = make([]JsonFloat64, size)
+			case "string":
+				d[field.Name] = make([]string, size)
+			case "ros.Time":
+				d[field.Name] = make([]Time, size)
+			case "ros.Duration":
+				d[field.Name] = make([]Duration, size)
+			default:
+				if field.IsBuiltin {
+					// Something went wrong.
+					return d, errors.New("we haven't implemented this primitive yet")
+				}
+
+				// The type encapsulates another ROS message, so we nest a DynamicMessage.
+				msgType, err := t.getNestedTypeFromField(&field)
+				if err != nil {
+					return d, errors.Wrap(err, "Field: "+field.Name)
+				}
+				// Fill out our new messages.
+				messages := make([]Message, size)
+				for i := 0; i < size; i++ {
+					messages[i] = msgType.NewMessage()
+				}
+				d[field.Name] = messages
+			}
+		} else { // Not an array.
+			if field.IsBuiltin {
+				// If it is a built in type.
+				switch field.GoType {
+				case "string":
+					d[field.Name] = ""
+				case "bool":
+					d[field.Name] = bool(false)
+				case "int8":
+					d[field.Name] = int8(0)
+				case "int16":
+					d[field.Name] = int16(0)
+				case "int32":
+					d[field.Name] = int32(0)
+				case "int64":
+					d[field.Name] = int64(0)
+				case "uint8":
+					d[field.Name] = uint8(0)
+				case "uint16":
+					d[field.Name] = uint16(0)
+				case "uint32":
+					d[field.Name] = uint32(0)
+				case "uint64":
+					d[field.Name] = uint64(0)
+				case "float32":
+					d[field.Name] = JsonFloat32{F: float32(0.0)}
+				case "float64":
+					d[field.Name] = JsonFloat64{F: float64(0.0)}
+				case "ros.Time":
+					d[field.Name] = Time{}
+				case "ros.Duration":
+					d[field.Name] = Duration{}
+				default:
+					return d, errors.Wrap(err, "builtin field "+field.GoType+" not found")
+				}
+			} else {
+				// The type encapsulates another ROS message, so we nest a DynamicMessage.
+				msgType, err := t.getNestedTypeFromField(&field)
+				if err != nil {
+					return d, errors.Wrap(err, "Field: "+field.Name)
+				}
+				d[field.Name] = msgType.NewMessage()
+			}
+		}
+	}
+	return d, err
+}
+
+// Get a nested type of a dynamic message from a field.
+func (t *DynamicMessageType) getNestedTypeFromField(field *libgengo.Field) (*DynamicMessageType, error) {
+	if t.nested == nil {
+		return nil, errors.New("cannot get nested type from invalid dynamic message type")
+	}
+	fieldtype := field.Package + "/" + field.Type
+
+	if msgType, ok := t.nested[fieldtype]; ok {
+		return msgType, nil
+	}
+	// Did not find the message type. Return an error.
+	return nil, errors.Wrap(errors.New("nested map does not contain requested field"), "fieldtype: "+fieldtype)
+}
+
+// decodeField decodes the value of a single field from a byte stream.
+func (t *DynamicMessageType) decodeField(d ByteDecoder, buf *bytes.Reader, field *libgengo.Field) (interface{}, error) {
+	if field.IsArray {
+		// It's an array.
+		size, err := t.decodeArraySize(d, buf, field)
+		if err != nil {
+			return nil, err
+		}
+		return t.decodeArray(d, buf, field, size)
+	}
+
+	// Else it's a scalar.
+	if field.IsBuiltin {
+		// It's a regular primitive element.
+		switch field.GoType {
+		case "bool":
+			return d.DecodeBool(buf)
+		case "int8":
+			return d.DecodeInt8(buf)
+		case "int16":
+			return d.DecodeInt16(buf)
+		case "int32":
+			return d.DecodeInt32(buf)
+		case "int64":
+			return d.DecodeInt64(buf)
+		case "uint8":
+			return d.DecodeUint8(buf)
+		case "uint16":
+			return d.DecodeUint16(buf)
+		case "uint32":
+			return d.DecodeUint32(buf)
+		case "uint64":
+			return d.DecodeUint64(buf)
+		case "float32":
+			return d.DecodeFloat32(buf)
+		case "float64":
+			return d.DecodeFloat64(buf)
+		case "string":
+			return d.DecodeString(buf)
+		case "ros.Time":
+			return d.DecodeTime(buf)
+		case "ros.Duration":
+			return d.DecodeDuration(buf)
+		default:
+			// Something went wrong.
+			return nil, errors.New("we haven't implemented this primitive yet")
+		}
+	}
+
+	// The type encapsulates another ROS message, so we nest a DynamicMessage.
+	msgType, err := t.getNestedTypeFromField(field)
+	if err != nil {
+		return nil, err
+	}
+	return d.DecodeMessage(buf, msgType)
+}
+
+// decodeArraySize returns the number of elements of an array field, which is read from the byte stream if the array isn't a fixed length.
+func (t *DynamicMessageType) decodeArraySize(d ByteDecoder, buf *bytes.Reader, field *libgengo.Field) (int, error) {
+	if field.ArrayLen >= 0 {
+		return field.ArrayLen, nil
+	}
+	// The array is dynamic, so it starts with a declaration of the number of array elements.
+	usize, err := d.DecodeUint32(buf)
+	if err != nil {
+		return 0, err
+	}
+	return int(usize), nil
+}
+
+// decodeArray decodes the elements of an array field from a byte stream.
+func (t *DynamicMessageType) decodeArray(d ByteDecoder, buf *bytes.Reader, field *libgengo.Field, size int) (interface{}, error) {
+	// Create an array of the target type.
+	switch field.GoType {
+	case "bool":
+		return d.DecodeBoolArray(buf, size)
+	case "int8":
+		return d.DecodeInt8Array(buf, size)
+	case "int16":
+		return d.DecodeInt16Array(buf, size)
+	case "int32":
+		return d.DecodeInt32Array(buf, size)
+	case "int64":
+		return d.DecodeInt64Array(buf, size)
+	case "uint8":
+		return d.DecodeUint8Array(buf, size)
+	case "uint16":
+		return d.DecodeUint16Array(buf, size)
+	case "uint32":
+		return d.DecodeUint32Array(buf, size)
+	case "uint64":
+		return d.DecodeUint64Array(buf, size)
+	case "float32":
+		return d.DecodeFloat32Array(buf, size)
+	case "float64":
+		return d.DecodeFloat64Array(buf, size)
+	case "string":
+		return d.DecodeStringArray(buf, size)
+	case "ros.Time":
+		return d.DecodeTimeArray(buf, size)
+	case "ros.Duration":
+		return d.DecodeDurationArray(buf, size)
+	default:
+		if field.IsBuiltin {
+			// Something went wrong.
+			return nil, errors.New("we haven't implemented this primitive yet")
+		}
+
+		// The type encapsulates another ROS message, so we nest a DynamicMessage.
+		msgType, err := t.getNestedTypeFromField(field)
+		if err != nil {
+			return nil, err
+		}
+		return d.DecodeMessageArray(buf, size, msgType)
 	}
-	return d
 }
 
-//	DynamicMessage
-
-// Data returns the data map field of the DynamicMessage
-func (m *DynamicMessage) Data() map[string]interface{} {
-	return m.data
-}
+// lookupConstant finds the integer constant of the message type called name which may be stored in the field.  The name may be qualified by the message type, e.g.
+// "GoalStatus.SUCCEEDED" or "actionlib_msgs/GoalStatus.SUCCEEDED".
+func (t *DynamicMessageType) lookupConstant(field *libgengo.Field, name string) (interface{}, bool) {
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		qualifier := name[:dot]
+		if qualifier != t.spec.FullName && qualifier != t.spec.ShortName {
+			return nil, false
+		}
+		name = name[dot+1:]
+	}
 
-// Type returns the ROS type of a dynamic message; required for ros.Message.
-func (m *DynamicMessage) Type() MessageType {
-	return m.dynamicType
+	for _, constant := range t.Constants() {
+		if constant.Name != name || libgengo.ToGoType("", constant.Type) != field.GoType {
+			continue
+		}
+		return constant.Value, true
+	}
+	return nil, false
 }
 
-// Serialize converts a DynamicMessage into a TCPROS bytestream allowing it to be published to other nodes; required for ros.Message.
-func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
+// serialize writes the fields of a DynamicMessage to a byte stream using the given encoder.
+func (m *DynamicMessage) serialize(e ByteEncoder, buf *bytes.Buffer) error {
 	if m.dynamicType.spec == nil {
 		return errors.New("dynamic message type spec is nil")
 	}
@@ -289,6 +831,15 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 		return errors.New("dynamic message type nested is nil")
 	}
 
+	// A lazily deserialized message which hasn't been touched can be written out as received, as long as it is written in the format it was read in.
+	if m.raw != nil && len(m.data) == 0 && sameByteOrder(m.dynamicType.ByteDecoder(), e) {
+		buf.Write(m.raw)
+		return nil
+	}
+	if err := m.decodeLazyFields(); err != nil {
+		return err
+	}
+
 	// THIS METHOD IS BASICALLY AN UNTEMPLATED COPY OF THE TEMPLATE IN LIBGENGO.
 
 	var err error = nil
@@ -313,7 +864,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 			var size uint32
 			if field.ArrayLen < 0 {
 				size = uint32(reflect.ValueOf(array).Len())
-				if err := binary.Write(buf, binary.LittleEndian, size); err != nil {
+				if err := e.EncodeUint32(buf, size); err != nil {
 					return errors.Wrap(err, "Field: "+field.Name)
 				}
 			} else {
@@ -329,7 +880,14 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 				}
 			}
 
-			// Then we just write out all the elements one after another.
+			// Arrays of builtin types held in slices of their Go type are handed to the encoder whole.
+			if ok, err := encodeBuiltinArray(e, buf, &field, array, int(size)); err != nil {
+				return errors.Wrap(err, "Field: "+field.Name)
+			} else if ok {
+				continue
+			}
+
+			// Otherwise we just write out all the elements one after another.
 			arrayValue := reflect.ValueOf(array)
 			for i := uint32(0); i < size; i++ {
 				//Casting the array item to interface type
@@ -342,14 +900,8 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 						if !ok {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected string.")
 						}
-						// The string should start with a declaration of the number of characters.
-						var sizeStr uint32 = uint32(len(str))
-						if err := binary.Write(buf, binary.LittleEndian, sizeStr); err != nil {
-							return errors.Wrap(err, "Field: "+field.Name)
-						}
-						// Then write out the actual characters.
-						data := []byte(str)
-						if err := binary.Write(buf, binary.LittleEndian, data); err != nil {
+						// The string starts with a declaration of the number of characters, followed by the characters.
+						if err := e.EncodeString(buf, str); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 
@@ -360,7 +912,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected ros.Time.")
 						}
 						// Then write out the structure.
-						if err := binary.Write(buf, binary.LittleEndian, t); err != nil {
+						if err := e.EncodeTime(buf, t); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 
@@ -371,7 +923,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected ros.Duration.")
 						}
 						// Then write out the structure.
-						if err := binary.Write(buf, binary.LittleEndian, d); err != nil {
+						if err := e.EncodeDuration(buf, d); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 
@@ -387,7 +939,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 								return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected bool.")
 							}
 							// Then write out the value.
-							if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+							if err := e.EncodeBool(buf, v); err != nil {
 								return errors.Wrap(err, "Field: "+field.Name)
 							}
 						case "int8":
@@ -397,7 +949,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 								return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected int8.")
 							}
 							// Then write out the value.
-							if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+							if err := e.EncodeInt8(buf, v); err != nil {
 								return errors.Wrap(err, "Field: "+field.Name)
 							}
 						case "int16":
@@ -407,7 +959,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 								return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected int16.")
 							}
 							// Then write out the value.
-							if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+							if err := e.EncodeInt16(buf, v); err != nil {
 								return errors.Wrap(err, "Field: "+field.Name)
 							}
 						case "int32":
@@ -417,7 +969,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 								return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected int32.")
 							}
 							// Then write out the value.
-							if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+							if err := e.EncodeInt32(buf, v); err != nil {
 								return errors.Wrap(err, "Field: "+field.Name)
 							}
 						case "int64":
@@ -427,7 +979,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 								return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected int64.")
 							}
 							// Then write out the value.
-							if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+							if err := e.EncodeInt64(buf, v); err != nil {
 								return errors.Wrap(err, "Field: "+field.Name)
 							}
 						case "uint8":
@@ -437,7 +989,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 								return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected uint8.")
 							}
 							// Then write out the value.
-							if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+							if err := e.EncodeUint8(buf, v); err != nil {
 								return errors.Wrap(err, "Field: "+field.Name)
 							}
 						case "uint16":
@@ -447,7 +999,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 								return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected uint16.")
 							}
 							// Then write out the value.
-							if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+							if err := e.EncodeUint16(buf, v); err != nil {
 								return errors.Wrap(err, "Field: "+field.Name)
 							}
 						case "uint32":
@@ -457,7 +1009,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 								return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected uint32.")
 							}
 							// Then write out the value.
-							if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+							if err := e.EncodeUint32(buf, v); err != nil {
 								return errors.Wrap(err, "Field: "+field.Name)
 							}
 						case "uint64":
@@ -467,7 +1019,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 								return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected uint64.")
 							}
 							// Then write out the value.
-							if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+							if err := e.EncodeUint64(buf, v); err != nil {
 								return errors.Wrap(err, "Field: "+field.Name)
 							}
 						case "float32":
@@ -477,7 +1029,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 								return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected JsonFloat32.")
 							}
 							// Then write out the value.
-							if err := binary.Write(buf, binary.LittleEndian, v.F); err != nil {
+							if err := e.EncodeFloat32(buf, v); err != nil {
 								return errors.Wrap(err, "Field: "+field.Name)
 							}
 						case "float64":
@@ -487,7 +1039,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 								return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(arrayItem).Name() + ", expected JsonFloat64.")
 							}
 							// Then write out the value.
-							if err := binary.Write(buf, binary.LittleEndian, v.F); err != nil {
+							if err := e.EncodeFloat64(buf, v); err != nil {
 								return errors.Wrap(err, "Field: "+field.Name)
 							}
 						default:
@@ -511,7 +1063,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 						return errors.New("Field: " + field.Name + ": Found msg " + msg.GetDynamicType().spec.ShortName + ", expected " + field.Type + ".")
 					}
 					// Otherwise, we just recursively serialise it.
-					if err = msg.Serialize(buf); err != nil {
+					if err = encodeMessage(e, buf, msg); err != nil {
 						return errors.Wrap(err, "Field: "+field.Name)
 					}
 				}
@@ -534,14 +1086,8 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 					if !ok {
 						return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected string.")
 					}
-					// The string should start with a declaration of the number of characters.
-					var sizeStr uint32 = uint32(len(str))
-					if err := binary.Write(buf, binary.LittleEndian, sizeStr); err != nil {
-						return errors.Wrap(err, "Field: "+field.Name)
-					}
-					// Then write out the actual characters.
-					data := []byte(str)
-					if err := binary.Write(buf, binary.LittleEndian, data); err != nil {
+					// The string starts with a declaration of the number of characters, followed by the characters.
+					if err := e.EncodeString(buf, str); err != nil {
 						return errors.Wrap(err, "Field: "+field.Name)
 					}
 
@@ -552,7 +1098,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 						return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected ros.Time.")
 					}
 					// Then write out the structure.
-					if err := binary.Write(buf, binary.LittleEndian, t); err != nil {
+					if err := e.EncodeTime(buf, t); err != nil {
 						return errors.Wrap(err, "Field: "+field.Name)
 					}
 
@@ -563,7 +1109,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 						return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected ros.Duration.")
 					}
 					// Then write out the structure.
-					if err := binary.Write(buf, binary.LittleEndian, d); err != nil {
+					if err := e.EncodeDuration(buf, d); err != nil {
 						return errors.Wrap(err, "Field: "+field.Name)
 					}
 
@@ -579,7 +1125,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected bool.")
 						}
 						// Then write out the value.
-						if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+						if err := e.EncodeBool(buf, v); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 					case "int8":
@@ -589,7 +1135,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected int8.")
 						}
 						// Then write out the value.
-						if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+						if err := e.EncodeInt8(buf, v); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 					case "int16":
@@ -599,7 +1145,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected int16.")
 						}
 						// Then write out the value.
-						if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+						if err := e.EncodeInt16(buf, v); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 					case "int32":
@@ -609,7 +1155,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected int32.")
 						}
 						// Then write out the value.
-						if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+						if err := e.EncodeInt32(buf, v); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 					case "int64":
@@ -619,7 +1165,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected int64.")
 						}
 						// Then write out the value.
-						if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+						if err := e.EncodeInt64(buf, v); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 					case "uint8":
@@ -629,7 +1175,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected uint8.")
 						}
 						// Then write out the value.
-						if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+						if err := e.EncodeUint8(buf, v); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 					case "uint16":
@@ -639,7 +1185,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected uint16.")
 						}
 						// Then write out the value.
-						if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+						if err := e.EncodeUint16(buf, v); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 					case "uint32":
@@ -649,7 +1195,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected uint32.")
 						}
 						// Then write out the value.
-						if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+						if err := e.EncodeUint32(buf, v); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 					case "uint64":
@@ -659,7 +1205,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected uint64.")
 						}
 						// Then write out the value.
-						if err := binary.Write(buf, binary.LittleEndian, v); err != nil {
+						if err := e.EncodeUint64(buf, v); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 					case "float32":
@@ -669,7 +1215,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected JsonFloat32.")
 						}
 						// Then write out the value.
-						if err := binary.Write(buf, binary.LittleEndian, v.F); err != nil {
+						if err := e.EncodeFloat32(buf, v); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 					case "float64":
@@ -679,7 +1225,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 							return errors.New("Field: " + field.Name + ": Found " + reflect.TypeOf(item).Name() + ", expected JsonFloat64.")
 						}
 						// Then write out the value.
-						if err := binary.Write(buf, binary.LittleEndian, v.F); err != nil {
+						if err := e.EncodeFloat64(buf, v); err != nil {
 							return errors.Wrap(err, "Field: "+field.Name)
 						}
 					default:
@@ -700,7 +1246,7 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 					return errors.New("Field: " + field.Name + ": Found msg " + msg.GetDynamicType().spec.ShortName + ", expected " + field.Type + ".")
 				}
 				// Otherwise, we just recursively serialise it.
-				if err = msg.Serialize(buf); err != nil {
+				if err = encodeMessage(e, buf, msg); err != nil {
 					return errors.Wrap(err, "Field: "+field.Name)
 				}
 			}
@@ -711,8 +1257,8 @@ func (m *DynamicMessage) Serialize(buf *bytes.Buffer) error {
 	return err
 }
 
-// Deserialize parses a byte stream into a DynamicMessage, thus reconstructing the fields of a received ROS message; required for ros.Message.
-func (m *DynamicMessage) Deserialize(buf *bytes.Reader) error {
+// deserialize reads the fields of a DynamicMessage from a byte stream using the given decoder.
+func (m *DynamicMessage) deserialize(d ByteDecoder, buf *bytes.Reader) error {
 
 	if m.dynamicType.spec == nil {
 		return errors.New("dynamic message type spec is nil")
@@ -721,132 +1267,27 @@ func (m *DynamicMessage) Deserialize(buf *bytes.Reader) error {
 		return errors.New("dynamic message type nested is nil")
 	}
 
+	m.raw = nil
+	m.rawOffsets = nil
+	if m.dynamicType.lazy {
+		m.aliased = false
+		return m.deserializeLazy(buf)
+	}
+	if m.pooled && m.data != nil && !m.aliased {
+		return m.deserializeReusing(d, buf)
+	}
+	m.aliased = decoderAliases(d, buf)
+
 	// To give more sane results in the event of a decoding issue, we decode into a copy of the data field.
 	var err error = nil
 	tmpData := make(map[string]interface{})
 	m.data = nil
 
-	d := LEByteDecoder{}
-
 	// Iterate over each of the fields in the message.
-	for _, field := range m.dynamicType.spec.Fields {
-
-		if field.IsArray {
-			// It's an array.
-
-			// The array may be a fixed length, or it may be dynamic.
-			size := field.ArrayLen
-			if field.ArrayLen < 0 {
-				// The array is dynamic, so it starts with a declaration of the number of array elements.
-				var usize uint32
-				if usize, err = d.DecodeUint32(buf); err != nil {
-					return errors.Wrap(err, "Field: "+field.Name)
-				}
-				size = int(usize)
-			}
-			// Create an array of the target type.
-			switch field.GoType {
-			case "bool":
-				tmpData[field.Name], err = d.DecodeBoolArray(buf, size)
-			case "int8":
-				tmpData[field.Name], err = d.DecodeInt8Array(buf, size)
-			case "int16":
-				tmpData[field.Name], err = d.DecodeInt16Array(buf, size)
-			case "int32":
-				tmpData[field.Name], err = d.DecodeInt32Array(buf, size)
-			case "int64":
-				tmpData[field.Name], err = d.DecodeInt64Array(buf, size)
-			case "uint8":
-				tmpData[field.Name], err = d.DecodeUint8Array(buf, size)
-			case "uint16":
-				tmpData[field.Name], err = d.DecodeUint16Array(buf, size)
-			case "uint32":
-				tmpData[field.Name], err = d.DecodeUint32Array(buf, size)
-			case "uint64":
-				tmpData[field.Name], err = d.DecodeUint64Array(buf, size)
-			case "float32":
-				tmpData[field.Name], err = d.DecodeFloat32Array(buf, size)
-			case "float64":
-				tmpData[field.Name], err = d.DecodeFloat64Array(buf, size)
-			case "string":
-				tmpData[field.Name], err = d.DecodeStringArray(buf, size)
-			case "ros.Time":
-				tmpData[field.Name], err = d.DecodeTimeArray(buf, size)
-			case "ros.Duration":
-				tmpData[field.Name], err = d.DecodeDurationArray(buf, size)
-			default:
-				if field.IsBuiltin {
-					// Something went wrong.
-					return errors.New("we haven't implemented this primitive yet")
-				}
-
-				// The type encapsulates another ROS message, so we nest a DynamicMessage.
-				msgType, err := m.dynamicType.getNestedTypeFromField(&field)
-				if err != nil {
-					return errors.Wrap(err, "Field: "+field.Name)
-				}
-
-				if tmpData[field.Name], err = d.DecodeMessageArray(buf, size, msgType); err != nil {
-					return errors.Wrap(err, "Field: "+field.Name)
-				}
-			}
-			if err != nil {
-				return errors.Wrap(err, "Field: "+field.Name)
-			}
-
-		} else { // Else it's a scalar.
-
-			if field.IsBuiltin {
-				// It's a regular primitive element.
-				switch field.GoType {
-				case "bool":
-					tmpData[field.Name], err = d.DecodeBool(buf)
-				case "int8":
-					tmpData[field.Name], err = d.DecodeInt8(buf)
-				case "int16":
-					tmpData[field.Name], err = d.DecodeInt16(buf)
-				case "int32":
-					tmpData[field.Name], err = d.DecodeInt32(buf)
-				case "int64":
-					tmpData[field.Name], err = d.DecodeInt64(buf)
-				case "uint8":
-					tmpData[field.Name], err = d.DecodeUint8(buf)
-				case "uint16":
-					tmpData[field.Name], err = d.DecodeUint16(buf)
-				case "uint32":
-					tmpData[field.Name], err = d.DecodeUint32(buf)
-				case "uint64":
-					tmpData[field.Name], err = d.DecodeUint64(buf)
-				case "float32":
-					tmpData[field.Name], err = d.DecodeFloat32(buf)
-				case "float64":
-					tmpData[field.Name], err = d.DecodeFloat64(buf)
-				case "string":
-					tmpData[field.Name], err = d.DecodeString(buf)
-				case "ros.Time":
-					tmpData[field.Name], err = d.DecodeTime(buf)
-				case "ros.Duration":
-					tmpData[field.Name], err = d.DecodeDuration(buf)
-				default:
-					// Something went wrong.
-					return errors.New("we haven't implemented this primitive yet")
-				}
-
-				if err != nil {
-					return errors.Wrap(err, "Field: "+field.Name)
-				}
-
-			} else {
-				// The type encapsulates another ROS message, so we nest a DynamicMessage.
-				msgType, err := m.dynamicType.getNestedTypeFromField(&field)
-				if err != nil {
-					return errors.Wrap(err, "Field: "+field.Name)
-				}
-
-				if tmpData[field.Name], err = d.DecodeMessage(buf, msgType); err != nil {
-					return errors.Wrap(err, "Field: "+field.Name)
-				}
-			}
+	for i := range m.dynamicType.spec.Fields {
+		field := &m.dynamicType.spec.Fields[i]
+		if tmpData[field.Name], err = m.dynamicType.decodeField(d, buf, field); err != nil {
+			return errors.Wrap(err, "Field: "+field.Name)
 		}
 	}
 
@@ -855,149 +1296,6 @@ func (m *DynamicMessage) Deserialize(buf *bytes.Reader) error {
 	return err
 }
 
-// String returns a string which represents the encapsulared DynamicMessage data.
-func (m *DynamicMessage) String() string {
-	// Just print out the data!
-	return fmt.Sprint(m.dynamicType.Name(), "::", m.data)
-}
-
-// DEFINE PRIVATE STATIC FUNCTIONS.
-
-// DEFINE PRIVATE RECEIVER FUNCTIONS.
-
-// zeroValueData creates the zeroValue (default) data map for a new DynamicMessage.
-func (t *DynamicMessageType) zeroValueData() (map[string]interface{}, error) {
-	//Create map
-	d := make(map[string]interface{})
-
-	// Function guards to prevent this call from panicking.
-	if t.spec == nil {
-		return d, errors.New("dynamic message type spec is nil")
-	}
-	if t.nested == nil {
-		return d, errors.New("dynamic message type nested is nil")
-	}
-
-	var err error
-
-	//Range fields in the dynamic message type.
-	for _, field := range t.spec.Fields {
-		if field.IsArray {
-			// If the array length is static set the size, for dynamic arrays, use 0.
-			var size int = 0
-			if field.ArrayLen > 0 {
-				size = field.ArrayLen
-			}
-
-			switch field.GoType {
-			case "bool":
-				d[field.Name] = make([]bool, size)
-			case "int8":
-				d[field.Name] = make([]int8, size)
-			case "int16":
-				d[field.Name] = make([]int16, size)
-			case "int32":
-				d[field.Name] = make([]int32, size)
-			case "int64":
-				d[field.Name] = make([]int64, size)
-			case "uint8":
-				d[field.Name] = make([]uint8, size)
-			case "uint16":
-				d[field.Name] = make([]uint16, size)
-			case "uint32":
-				d[field.Name] = make([]uint32, size)
-			case "uint64":
-				d[field.Name] = make([]uint64, size)
-			case "float32":
-				d[field.Name] = make([]JsonFloat32, size)
-			case "float64":
-				d[field.Name] = make([]JsonFloat64, size)
-			case "string":
-				d[field.Name] = make([]string, size)
-			case "ros.Time":
-				d[field.Name] = make([]Time, size)
-			case "ros.Duration":
-				d[field.Name] = make([]Duration, size)
-			default:
-				if field.IsBuiltin {
-					// Something went wrong.
-					return d, errors.New("we haven't implemented this primitive yet")
-				}
-
-				// The type encapsulates another ROS message, so we nest a DynamicMessage.
-				msgType, err := t.getNestedTypeFromField(&field)
-				if err != nil {
-					return d, errors.Wrap(err, "Field: "+field.Name)
-				}
-				// Fill out our new messages.
-				messages := make([]Message, size)
-				for i := 0; i < size; i++ {
-					messages[i] = msgType.NewMessage()
-				}
-				d[field.Name] = messages
-			}
-		} else { // Not an array.
-			if field.IsBuiltin {
-				// If it is a built in type.
-				switch field.GoType {
-				case "string":
-					d[field.Name] = ""
-				case "bool":
-					d[field.Name] = bool(false)
-				case "int8":
-					d[field.Name] = int8(0)
-				case "int16":
-					d[field.Name] = int16(0)
-				case "int32":
-					d[field.Name] = int32(0)
-				case "int64":
-					d[field.Name] = int64(0)
-				case "uint8":
-					d[field.Name] = uint8(0)
-				case "uint16":
-					d[field.Name] = uint16(0)
-				case "uint32":
-					d[field.Name] = uint32(0)
-				case "uint64":
-					d[field.Name] = uint64(0)
-				case "float32":
-					d[field.Name] = JsonFloat32{F: float32(0.0)}
-				case "float64":
-					d[field.Name] = JsonFloat64{F: float64(0.0)}
-				case "ros.Time":
-					d[field.Name] = Time{}
-				case "ros.Duration":
-					d[field.Name] = Duration{}
-				default:
-					return d, errors.Wrap(err, "builtin field "+field.GoType+" not found")
-				}
-			} else {
-				// The type encapsulates another ROS message, so we nest a DynamicMessage.
-				msgType, err := t.getNestedTypeFromField(&field)
-				if err != nil {
-					return d, errors.Wrap(err, "Field: "+field.Name)
-				}
-				d[field.Name] = msgType.NewMessage()
-			}
-		}
-	}
-	return d, err
-}
-
-// Get a nested type of a dynamic message from a field.
-func (t *DynamicMessageType) getNestedTypeFromField(field *libgengo.Field) (*DynamicMessageType, error) {
-	if t.nested == nil {
-		return nil, errors.New("cannot get nested type from invalid dynamic message type")
-	}
-	fieldtype := field.Package + "/" + field.Type
-
-	if msgType, ok := t.nested[fieldtype]; ok {
-		return msgType, nil
-	}
-	// Did not find the message type. Return an error.
-	return nil, errors.Wrap(errors.New("nested map does not contain requested field"), "fieldtype: "+fieldtype)
-}
-
 // padArray pads the provided array to the specified length using the default value for the array type.
 func padArray(array interface{}, field libgengo.Field, actualSize, requiredSize uint32) (interface{}, error) {
 	switch field.GoType {
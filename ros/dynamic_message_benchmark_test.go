@@ -617,3 +617,96 @@ func BenchmarkDynamicMessage_NewMessage_BigArray(b *testing.B) {
 		_ = testMessage.data
 	}
 }
+
+// Benchmarks on deserializing into pooled messages.
+
+// Benchmark deserializing across all primitives as dynamic array entries into a pooled message.
+func BenchmarkDynamicMessage_Deserialize_Pooled_DynamicArrayMedley(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		testMessage := dynamicArrayMessageType.Acquire()
+		byteReader := bytes.NewReader(dynamicArraySerialized)
+		if err := testMessage.Deserialize(byteReader); err != nil {
+			b.Fatalf("deserialize failed %s", err)
+		}
+		testMessage.Release()
+	}
+}
+
+// Benchmark deserializing a one megabyte array of uint8, such as the data of a point cloud, into a pooled message.
+func BenchmarkDynamicMessage_Deserialize_Pooled_uint8BigArray(b *testing.B) {
+
+	var uint8BigArrayMessageType DynamicMessageType = DynamicMessageType{
+		spec: generateTestSpec([]gengo.Field{
+			*gengo.NewField("Testing", "uint8", "u8", true, -1),
+		}),
+		nested:       make(map[string]*DynamicMessageType),
+		jsonPrealloc: 0,
+	}
+	serialized := append([]byte{0x40, 0x42, 0x0f, 0x00}, bigArraySerialized...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		testMessage := uint8BigArrayMessageType.Acquire()
+		byteReader := bytes.NewReader(serialized)
+		if err := testMessage.Deserialize(byteReader); err != nil {
+			b.Fatalf("deserialize failed %s", err)
+		}
+		testMessage.Release()
+	}
+}
+
+// Benchmark deserializing a one megabyte array of float32 into a pooled message.
+func BenchmarkDynamicMessage_Deserialize_Pooled_float32BigArray(b *testing.B) {
+
+	var float32BigArrayMessageType DynamicMessageType = DynamicMessageType{
+		spec: generateTestSpec([]gengo.Field{
+			*gengo.NewField("Testing", "float32", "f32", true, 250_000),
+		}),
+		nested:       make(map[string]*DynamicMessageType),
+		jsonPrealloc: 0,
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		testMessage := float32BigArrayMessageType.Acquire()
+		byteReader := bytes.NewReader(bigArraySerialized)
+		if err := testMessage.Deserialize(byteReader); err != nil {
+			b.Fatalf("deserialize failed %s", err)
+		}
+		testMessage.Release()
+	}
+}
+
+// Benchmark deserializing a one megabyte array of a message with an embedded message into a pooled message.
+func BenchmarkDynamicMessage_Deserialize_Pooled_dynamicTypeBigArray(b *testing.B) {
+	// Extract 1_000_000 bytes / 56 (bytes/packet) ~= 17857 packet.
+	var dynamicTypeBigArrayMessageType DynamicMessageType = DynamicMessageType{
+		spec: generateTestSpec([]gengo.Field{
+			*gengo.NewField("geometry_msgs", "Pose", "pose", true, 17857),
+		}),
+		nested:       make(map[string]*DynamicMessageType),
+		jsonPrealloc: 0,
+	}
+
+	msgType, err := newDynamicMessageTypeNested("Pose", "geometry_msgs", nil, nil)
+	if err != nil {
+		b.Skip("benchmark skipped, ROS environment not set up")
+		return
+	}
+	dynamicTypeBigArrayMessageType.nested["geometry_msgs/Pose"] = msgType
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		testMessage := dynamicTypeBigArrayMessageType.Acquire()
+		byteReader := bytes.NewReader(bigArraySerialized)
+		if err := testMessage.Deserialize(byteReader); err != nil {
+			b.Fatalf("deserialize failed %s", err)
+		}
+		testMessage.Release()
+	}
+}
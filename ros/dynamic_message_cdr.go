@@ -0,0 +1,443 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// cdrWriter appends CDR encoded values, aligning each to its size relative to the end of the encapsulation header.
+type cdrWriter struct {
+	buf []byte
+}
+
+// cdrReader reads CDR encoded values in either byte order, aligning each to its size relative to the end of the encapsulation header.
+type cdrReader struct {
+	buf   []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// Encapsulation identifiers of plain CDR (XCDR version 1); the other two bytes of the header hold options.
+const (
+	cdrEncapsulationBE = 0x0000
+	cdrEncapsulationLE = 0x0001
+	cdrHeaderSize      = 4
+)
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+//	DynamicMessage
+
+// MarshalCDR encodes the message as the equivalent ROS 2 message, in little endian CDR with its encapsulation header, as carried by DDS.  Times and durations are encoded as
+// builtin_interfaces/Time and builtin_interfaces/Duration, the seq field of std_msgs/Header is dropped, and messages without fields are given the single uint8 that ROS 2
+// generates for them.  Times must be before 2038, since ROS 2 times have signed seconds.
+func (m *DynamicMessage) MarshalCDR() ([]byte, error) {
+	if err := messagePointerError(m); err != nil {
+		return nil, err
+	}
+	w := &cdrWriter{buf: []byte{0x00, cdrEncapsulationLE, 0x00, 0x00}}
+	if err := w.writeMessage(m); err != nil {
+		return nil, err
+	}
+	return w.buf, nil
+}
+
+// UnmarshalCDR decodes a ROS 2 message in CDR with its encapsulation header, in either byte order, as encoded by MarshalCDR().  The seq field of std_msgs/Header is zeroed.
+func (m *DynamicMessage) UnmarshalCDR(buf []byte) error {
+	if m == nil || m.dynamicType == nil {
+		return errors.New("nil pointer to DynamicMessage")
+	}
+	if len(buf) < cdrHeaderSize {
+		return errors.New("missing cdr encapsulation header")
+	}
+	r := &cdrReader{buf: buf[cdrHeaderSize:]}
+	switch binary.BigEndian.Uint16(buf) {
+	case cdrEncapsulationLE:
+		r.order = binary.LittleEndian
+	case cdrEncapsulationBE:
+		r.order = binary.BigEndian
+	default:
+		return errors.New("unsupported cdr encapsulation 0x" + strconv.FormatUint(uint64(binary.BigEndian.Uint16(buf)), 16))
+	}
+
+	result, err := r.readMessage(m.dynamicType)
+	if err != nil {
+		return err
+	}
+	// DDS pads samples to a multiple of four bytes.
+	if len(r.buf)-r.pos >= 4 {
+		return errors.New(strconv.Itoa(len(r.buf)-r.pos) + " unexpected bytes after message")
+	}
+	m.data = result.data
+	m.raw = nil
+	m.rawOffsets = nil
+	return nil
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// isCDRDroppedField reports whether a field of a ROS 1 message has no equivalent in ROS 2; only the seq of std_msgs/Header is dropped.
+func isCDRDroppedField(t *DynamicMessageType, field *libgengo.Field) bool {
+	return t.Name() == libgengo.HeaderFullName && field.Name == "seq"
+}
+
+// cdrFieldCount returns the number of fields of the ROS 2 equivalent of a message type.
+func cdrFieldCount(t *DynamicMessageType) int {
+	count := 0
+	for i := range t.spec.Fields {
+		if !isCDRDroppedField(t, &t.spec.Fields[i]) {
+			count++
+		}
+	}
+	return count
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+//	cdrWriter
+
+// align pads the buffer to a multiple of size bytes.
+func (w *cdrWriter) align(size int) {
+	for (len(w.buf)-cdrHeaderSize)%size != 0 {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+// writeUint writes an unsigned integer of the specified size, aligned to that size.
+func (w *cdrWriter) writeUint(value uint64, size int) {
+	w.align(size)
+	for i := 0; i < size; i++ {
+		w.buf = append(w.buf, byte(value>>(8*uint(i))))
+	}
+}
+
+// writeString writes a string's length, including its terminating nul, followed by the string and the nul.
+func (w *cdrWriter) writeString(value string) {
+	w.writeUint(uint64(len(value)+1), 4)
+	w.buf = append(append(w.buf, value...), 0)
+}
+
+// writeMessage writes the fields of a message in definition order.
+func (w *cdrWriter) writeMessage(m *DynamicMessage) error {
+	if err := m.decodeLazyFields(); err != nil {
+		return err
+	}
+	t := m.dynamicType
+	if cdrFieldCount(t) == 0 {
+		// ROS 2 gives structures without members a uint8 placeholder.
+		w.writeUint(0, 1)
+		return nil
+	}
+	for i := range t.spec.Fields {
+		field := &t.spec.Fields[i]
+		if isCDRDroppedField(t, field) {
+			continue
+		}
+		value, ok := m.data[field.Name]
+		if !ok {
+			return errors.New("Field: " + field.Name + ": No data found.")
+		}
+		if err := w.writeField(field, value); err != nil {
+			return errors.Wrap(err, "Field: "+field.Name)
+		}
+	}
+	return nil
+}
+
+// writeField writes a singular field, or an array field; only variable length arrays have their length written.
+func (w *cdrWriter) writeField(field *libgengo.Field, value interface{}) error {
+	if !field.IsArray {
+		return w.writeElement(field, value)
+	}
+
+	array := reflect.ValueOf(value)
+	if !array.IsValid() || array.Kind() != reflect.Slice {
+		return errors.New("cannot encode " + describeValue(value) + " as an array")
+	}
+	if field.ArrayLen < 0 {
+		w.writeUint(uint64(array.Len()), 4)
+	} else if array.Len() != field.ArrayLen {
+		return errors.New("expected array of length " + strconv.Itoa(field.ArrayLen) + ", got " + strconv.Itoa(array.Len()))
+	}
+	if data, ok := value.([]uint8); ok {
+		w.buf = append(w.buf, data...)
+		return nil
+	}
+	for i := 0; i < array.Len(); i++ {
+		if err := w.writeElement(field, array.Index(i).Interface()); err != nil {
+			return errors.Wrap(err, "index "+strconv.Itoa(i))
+		}
+	}
+	return nil
+}
+
+// writeElement writes a singular value, or an element of an array.
+func (w *cdrWriter) writeElement(field *libgengo.Field, value interface{}) error {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			w.writeUint(1, 1)
+		} else {
+			w.writeUint(0, 1)
+		}
+	case int8:
+		w.writeUint(uint64(v), 1)
+	case uint8:
+		w.writeUint(uint64(v), 1)
+	case int16:
+		w.writeUint(uint64(v), 2)
+	case uint16:
+		w.writeUint(uint64(v), 2)
+	case int32:
+		w.writeUint(uint64(v), 4)
+	case uint32:
+		w.writeUint(uint64(v), 4)
+	case int64:
+		w.writeUint(uint64(v), 8)
+	case uint64:
+		w.writeUint(v, 8)
+	case JsonFloat32:
+		w.writeUint(uint64(math.Float32bits(v.F)), 4)
+	case JsonFloat64:
+		w.writeUint(math.Float64bits(v.F), 8)
+	case string:
+		w.writeString(v)
+	case Time:
+		// builtin_interfaces/Time has signed seconds.
+		sec := uint64(v.Sec) + uint64(v.NSec/1e9)
+		if sec > math.MaxInt32 {
+			return errors.New("time " + strconv.FormatUint(sec, 10) + " out of range for ROS 2")
+		}
+		w.writeUint(sec, 4)
+		w.writeUint(uint64(v.NSec%1e9), 4)
+	case Duration:
+		// builtin_interfaces/Duration has signed seconds and positive nanoseconds, as ROS 1 durations are held.
+		w.writeUint(uint64(v.Sec), 4)
+		w.writeUint(uint64(v.NSec), 4)
+	case *DynamicMessage:
+		if err := messagePointerError(v); err != nil {
+			return err
+		}
+		return w.writeMessage(v)
+	default:
+		return errors.New("cannot encode " + describeValue(value) + " as " + field.Type)
+	}
+	return nil
+}
+
+//	cdrReader
+
+// align skips the padding before a value of the specified size.
+func (r *cdrReader) align(size int) {
+	if remainder := r.pos % size; remainder != 0 {
+		r.pos += size - remainder
+	}
+}
+
+// readUint reads an unsigned integer of the specified size, aligned to that size.
+func (r *cdrReader) readUint(size int) (uint64, error) {
+	r.align(size)
+	if r.pos+size > len(r.buf) {
+		return 0, errors.New("unexpected end of cdr data")
+	}
+	data := r.buf[r.pos : r.pos+size]
+	r.pos += size
+	switch size {
+	case 1:
+		return uint64(data[0]), nil
+	case 2:
+		return uint64(r.order.Uint16(data)), nil
+	case 4:
+		return uint64(r.order.Uint32(data)), nil
+	}
+	return r.order.Uint64(data), nil
+}
+
+// readBytes reads the specified number of bytes.
+func (r *cdrReader) readBytes(length int) ([]byte, error) {
+	if length < 0 || length > len(r.buf)-r.pos {
+		return nil, errors.New("unexpected end of cdr data")
+	}
+	data := r.buf[r.pos : r.pos+length]
+	r.pos += length
+	return data, nil
+}
+
+// readString reads a nul terminated string.
+func (r *cdrReader) readString() (string, error) {
+	length, err := r.readUint(4)
+	if err != nil {
+		return "", err
+	}
+	data, err := r.readBytes(int(length))
+	if err != nil {
+		return "", err
+	}
+	if length == 0 {
+		return "", nil
+	}
+	if data[length-1] != 0 {
+		return "", errors.New("cdr string is not nul terminated")
+	}
+	return string(data[:length-1]), nil
+}
+
+// readMessage reads a message of the specified type.
+func (r *cdrReader) readMessage(t *DynamicMessageType) (*DynamicMessage, error) {
+	if t.spec == nil {
+		return nil, errors.New("nil pointer to MsgSpec")
+	}
+	m := t.NewDynamicMessage()
+	if cdrFieldCount(t) == 0 {
+		_, err := r.readUint(1)
+		return m, err
+	}
+	for i := range t.spec.Fields {
+		field := &t.spec.Fields[i]
+		if isCDRDroppedField(t, field) {
+			continue
+		}
+		value, err := r.readField(t, field)
+		if err != nil {
+			return nil, errors.Wrap(err, "Field: "+field.Name)
+		}
+		m.data[field.Name] = value
+	}
+	return m, nil
+}
+
+// readField reads a singular field, or an array field.
+func (r *cdrReader) readField(t *DynamicMessageType, field *libgengo.Field) (interface{}, error) {
+	if !field.IsArray {
+		return r.readElement(t, field)
+	}
+
+	size := field.ArrayLen
+	if size < 0 {
+		length, err := r.readUint(4)
+		if err != nil {
+			return nil, err
+		}
+		// Every element takes at least a byte, so longer arrays must be corrupt.
+		if length > uint64(len(r.buf)-r.pos) {
+			return nil, errors.New("array length " + strconv.FormatUint(length, 10) + " exceeds cdr data")
+		}
+		size = int(length)
+	}
+	if field.GoType == "uint8" {
+		data, err := r.readBytes(size)
+		if err != nil {
+			return nil, err
+		}
+		return append([]uint8{}, data...), nil
+	}
+
+	array, err := t.zeroValueArray(field, size)
+	if err != nil {
+		return nil, err
+	}
+	arrayValue := reflect.ValueOf(array)
+	for i := 0; i < size; i++ {
+		element, err := r.readElement(t, field)
+		if err != nil {
+			return nil, errors.Wrap(err, "index "+strconv.Itoa(i))
+		}
+		arrayValue.Index(i).Set(reflect.ValueOf(element))
+	}
+	return array, nil
+}
+
+// readElement reads a singular value, or an element of an array.
+func (r *cdrReader) readElement(t *DynamicMessageType, field *libgengo.Field) (interface{}, error) {
+	switch field.GoType {
+	case "string":
+		return r.readString()
+	case "ros.Time", "ros.Duration":
+		sec, err := r.readUint(4)
+		if err != nil {
+			return nil, err
+		}
+		nsec, err := r.readUint(4)
+		if err != nil {
+			return nil, err
+		}
+		if nsec >= 1e9 {
+			return nil, errors.New("nanoseconds out of range")
+		}
+		if field.GoType == "ros.Duration" {
+			return Duration{temporal{Sec: uint32(sec), NSec: uint32(nsec)}}, nil
+		}
+		if int32(sec) < 0 {
+			return nil, errors.New("time before the epoch")
+		}
+		return Time{temporal{Sec: uint32(sec), NSec: uint32(nsec)}}, nil
+	}
+
+	if !field.IsBuiltin {
+		msgType, err := t.getNestedTypeFromField(field)
+		if err != nil {
+			return nil, err
+		}
+		return r.readMessage(msgType)
+	}
+
+	var size int
+	switch field.GoType {
+	case "bool", "int8", "uint8":
+		size = 1
+	case "int16", "uint16":
+		size = 2
+	case "int32", "uint32", "float32":
+		size = 4
+	case "int64", "uint64", "float64":
+		size = 8
+	default:
+		return nil, errors.New("we haven't implemented this primitive yet: " + field.GoType)
+	}
+	value, err := r.readUint(size)
+	if err != nil {
+		return nil, err
+	}
+	switch field.GoType {
+	case "bool":
+		return value != 0, nil
+	case "int8":
+		return int8(value), nil
+	case "uint8":
+		return uint8(value), nil
+	case "int16":
+		return int16(value), nil
+	case "uint16":
+		return uint16(value), nil
+	case "int32":
+		return int32(value), nil
+	case "uint32":
+		return uint32(value), nil
+	case "float32":
+		return JsonFloat32{F: math.Float32frombits(uint32(value))}, nil
+	case "int64":
+		return int64(value), nil
+	case "float64":
+		return JsonFloat64{F: math.Float64frombits(value)}, nil
+	}
+	return value, nil
+}
+
+// ALL DONE.
@@ -0,0 +1,141 @@
+package ros
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+)
+
+func newCDRTestType(t *testing.T) *DynamicMessageType {
+	return newRegisteredTestType(t, "test_msgs/Stamped", "Header header\nstd_msgs/Empty[] empties\nfloat64 x")
+}
+
+func TestDynamicMessage_CDRRoundTrip(t *testing.T) {
+	msg := newJSONOptionsTestMessage(t)
+	if err := msg.Set("max_count", int32(math.MinInt32)); err != nil {
+		t.Fatal(err)
+	}
+	path := newPathTestType(t).NewDynamicMessage()
+	if err := path.Set("points", []interface{}{map[string]interface{}{"x": 1.5}, map[string]interface{}{"z": -2.0}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := path.Set("counts", []int32{7, 0, -9}); err != nil {
+		t.Fatal(err)
+	}
+	if err := path.Set("frame_id", "map"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, original := range []*DynamicMessage{msg, path, newBinaryTestType(t).NewDynamicMessage()} {
+		buf, err := original.MarshalCDR()
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := original.dynamicType.NewDynamicMessage()
+		if err := result.UnmarshalCDR(buf); err != nil {
+			t.Fatalf("%x: %v", buf, err)
+		}
+		if diff, err := original.Diff(result, WithNaNEqual()); err != nil || len(diff) != 0 {
+			t.Fatalf("%x: unmarshalled message differs: %v, %v", buf, diff, err)
+		}
+	}
+}
+
+func TestDynamicMessage_MarshalCDR(t *testing.T) {
+	msg, err := newBinaryTestType(t).NewDynamicMessageFromMap(map[string]interface{}{"a": -1, "b": []byte{1, 2}, "t": NewTime(1, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := msg.MarshalCDR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "00010000" + "ff000000" + "02000000" + "0102" + "0000" + "01000000" + "00000000"; hex.EncodeToString(buf) != expected {
+		t.Fatalf("expected %s, got %x", expected, buf)
+	}
+
+	// The header's seq is dropped, and empty messages hold a placeholder byte.
+	msgType := newCDRTestType(t)
+	msg, err = msgType.NewDynamicMessageFromMap(map[string]interface{}{
+		"header":  map[string]interface{}{"seq": 7, "stamp": NewTime(1, 2), "frame_id": "a"},
+		"empties": []interface{}{map[string]interface{}{}},
+		"x":       1.5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err = msg.MarshalCDR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "00010000" + "01000000" + "02000000" + "02000000" + "6100" + "0000" + "01000000" + "00" + "000000" + "000000000000f83f"
+	if hex.EncodeToString(buf) != expected {
+		t.Fatalf("expected %s, got %x", expected, buf)
+	}
+
+	result := msgType.NewDynamicMessage()
+	if err := result.UnmarshalCDR(buf); err != nil {
+		t.Fatal(err)
+	}
+	if seq, _ := result.GetInt64("header.seq"); seq != 0 {
+		t.Fatalf("expected seq 0, got %v", seq)
+	}
+	if err := msg.Set("header.seq", uint32(0)); err != nil {
+		t.Fatal(err)
+	}
+	if diff, err := msg.Diff(result); err != nil || len(diff) != 0 {
+		t.Fatalf("unmarshalled message differs: %v, %v", diff, err)
+	}
+
+	// Times after 2038 can't be represented in ROS 2.
+	if err := msg.Set("header.stamp", NewTime(math.MaxInt32+1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := msg.MarshalCDR(); err == nil {
+		t.Fatal("expected error for time out of range")
+	}
+}
+
+func TestDynamicMessage_UnmarshalCDR(t *testing.T) {
+	msgType := newCDRTestType(t)
+
+	// Big endian, with padding after the message.
+	input := "00000000" + "00000001" + "00000002" + "00000002" + "6100" + "0000" + "00000001" + "00" + "000000" + "3ff8000000000000" + "000000"
+	buf, _ := hex.DecodeString(input)
+	msg := msgType.NewDynamicMessage()
+	if err := msg.UnmarshalCDR(buf); err != nil {
+		t.Fatal(err)
+	}
+	if stamp, _ := msg.GetTime("header.stamp"); stamp != NewTime(1, 2) {
+		t.Fatalf("unexpected stamp %v", stamp)
+	}
+	if frameID, _ := msg.GetString("header.frame_id"); frameID != "a" {
+		t.Fatalf("unexpected frame_id %v", frameID)
+	}
+	if x, _ := msg.GetFloat64("x"); x != 1.5 {
+		t.Fatalf("expected x 1.5, got %v", x)
+	}
+	if empties, _ := msg.Get("empties"); len(empties.([]Message)) != 1 {
+		t.Fatalf("unexpected empties %v", empties)
+	}
+
+	errorCases := []string{
+		"0001",                               // Missing header.
+		"00020000" + "00000000",              // Unsupported encapsulation.
+		"00010000" + "01000000",              // Truncated.
+		"00010000" + "00000080" + "00000000", // Time before the epoch.
+		"00010000" + "00000000" + "00ca9a3b", // Nanoseconds out of range.
+		"00010000" + "00000000" + "00000000" + "01000000" + "61",                                                                        // String not nul terminated.
+		"00010000" + "00000000" + "00000000" + "01000000" + "00" + "000000" + "ffffff7f",                                                // Array too long.
+		"00010000" + "00000000" + "00000000" + "01000000" + "00" + "000000" + "00000000" + "00000000" + "0000000000000000" + "00000000", // Trailing bytes.
+	}
+	for _, errorCase := range errorCases {
+		buf, err := hex.DecodeString(errorCase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := msgType.NewDynamicMessage().UnmarshalCDR(buf); err == nil {
+			t.Errorf("%s: expected error", errorCase)
+		}
+	}
+}
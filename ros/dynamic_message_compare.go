@@ -0,0 +1,271 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// EqualOption configures how DynamicMessage.Equal() and DynamicMessage.Diff() compare messages.
+type EqualOption func(*equalOptions)
+
+// DEFINE PRIVATE STRUCTURES.
+
+// equalOptions holds the configuration built from a list of EqualOptions.
+type equalOptions struct {
+	absTolerance float64
+	relTolerance float64
+	nanEqual     bool
+}
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// WithFloatTolerance treats float32 and float64 values as equal when they differ by no more than the specified absolute amount.
+func WithFloatTolerance(tolerance float64) EqualOption {
+	return func(o *equalOptions) {
+		o.absTolerance = tolerance
+	}
+}
+
+// WithRelativeFloatTolerance treats float32 and float64 values as equal when they differ by no more than the specified fraction of the larger magnitude of the two.
+func WithRelativeFloatTolerance(tolerance float64) EqualOption {
+	return func(o *equalOptions) {
+		o.relTolerance = tolerance
+	}
+}
+
+// WithNaNEqual treats two NaN float values as equal; by default NaN is not equal to anything, including itself.
+func WithNaNEqual() EqualOption {
+	return func(o *equalOptions) {
+		o.nanEqual = true
+	}
+}
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// Clone returns a deep copy of a DynamicMessage; the copy shares the message type but no data, so either message may be modified without affecting the other.
+func (m *DynamicMessage) Clone() *DynamicMessage {
+	if m == nil {
+		return nil
+	}
+	// Lazily deserialized bytes are never modified, so can be shared.
+	clone := &DynamicMessage{dynamicType: m.dynamicType, raw: m.raw, rawOffsets: m.rawOffsets}
+	if m.data != nil {
+		clone.data = make(map[string]interface{}, len(m.data))
+		for key, value := range m.data {
+			clone.data[key] = cloneDynamicValue(value)
+		}
+	}
+	return clone
+}
+
+// Equal reports whether two DynamicMessages have the same type and the same data.  By default values must match exactly; options may be used to allow float values to differ.
+func (m *DynamicMessage) Equal(other *DynamicMessage, options ...EqualOption) bool {
+	if m == other {
+		return true
+	}
+	if messagePointerError(m) != nil || messagePointerError(other) != nil || !sameDynamicType(m, other) {
+		return false
+	}
+	differences := make([]string, 0, 1)
+	m.diff(other, "", newEqualOptions(options), &differences, 1)
+	return len(differences) == 0
+}
+
+// Diff returns the paths of the fields which differ between two DynamicMessages of the same type, using the same path syntax as Get(), e.g. "pose.position.x" or
+// "points[3]".  Messages within arrays are compared element by element, while other arrays are reported as a whole when any of their elements differ or their lengths differ.
+func (m *DynamicMessage) Diff(other *DynamicMessage, options ...EqualOption) ([]string, error) {
+	if err := messagePointerError(m); err != nil {
+		return nil, err
+	}
+	if err := messagePointerError(other); err != nil {
+		return nil, err
+	}
+	if !sameDynamicType(m, other) {
+		return nil, errors.New("cannot diff message of type " + m.dynamicType.Name() + " with " + other.dynamicType.Name())
+	}
+	differences := make([]string, 0)
+	m.diff(other, "", newEqualOptions(options), &differences, -1)
+	return differences, nil
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// newEqualOptions applies a list of EqualOptions to the default configuration.
+func newEqualOptions(options []EqualOption) *equalOptions {
+	o := &equalOptions{}
+	for _, option := range options {
+		option(o)
+	}
+	return o
+}
+
+// sameDynamicType reports whether two messages have the same type.
+func sameDynamicType(a *DynamicMessage, b *DynamicMessage) bool {
+	if a.dynamicType == b.dynamicType {
+		return true
+	}
+	if a.dynamicType == nil || b.dynamicType == nil {
+		return false
+	}
+	return a.dynamicType.Name() == b.dynamicType.Name() && a.dynamicType.MD5Sum() == b.dynamicType.MD5Sum()
+}
+
+// cloneDynamicValue deep copies a value held by a DynamicMessage.
+func cloneDynamicValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *DynamicMessage:
+		return v.Clone()
+	case []Message:
+		if v == nil {
+			return v
+		}
+		messages := make([]Message, len(v))
+		for i, msg := range v {
+			if dynamic, ok := msg.(*DynamicMessage); ok {
+				messages[i] = dynamic.Clone()
+			} else {
+				messages[i] = msg
+			}
+		}
+		return messages
+	}
+
+	// Builtin arrays hold plain values, so a shallow copy of the slice is a deep copy.
+	sliceValue := reflect.ValueOf(value)
+	if sliceValue.Kind() == reflect.Slice && !sliceValue.IsNil() {
+		clone := reflect.MakeSlice(sliceValue.Type(), sliceValue.Len(), sliceValue.Len())
+		reflect.Copy(clone, sliceValue)
+		return clone.Interface()
+	}
+	return value
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// floatsEqual compares two float values using the configured tolerances.
+func (o *equalOptions) floatsEqual(a float64, b float64) bool {
+	if a == b {
+		return true
+	}
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return o.nanEqual && math.IsNaN(a) && math.IsNaN(b)
+	}
+	difference := math.Abs(a - b)
+	if difference <= o.absTolerance {
+		return true
+	}
+	return difference <= o.relTolerance*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// valuesEqual compares two singular builtin values, or two builtin arrays.
+func (o *equalOptions) valuesEqual(a interface{}, b interface{}) bool {
+	switch av := a.(type) {
+	case JsonFloat32:
+		bv, ok := b.(JsonFloat32)
+		return ok && o.floatsEqual(float64(av.F), float64(bv.F))
+	case JsonFloat64:
+		bv, ok := b.(JsonFloat64)
+		return ok && o.floatsEqual(av.F, bv.F)
+	case []JsonFloat32:
+		bv, ok := b.([]JsonFloat32)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !o.floatsEqual(float64(av[i].F), float64(bv[i].F)) {
+				return false
+			}
+		}
+		return true
+	case []JsonFloat64:
+		bv, ok := b.([]JsonFloat64)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !o.floatsEqual(av[i].F, bv[i].F) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// diff appends the paths of the fields which differ between two messages of the same type to differences, stopping once limit differences are found; a negative limit finds all
+// differences.
+func (m *DynamicMessage) diff(other *DynamicMessage, prefix string, o *equalOptions, differences *[]string, limit int) {
+	// Fields of lazily deserialized messages which fail to decode have no data, so are reported as differing below.
+	m.decodeLazyFields()
+	other.decodeLazyFields()
+	for i := range m.dynamicType.spec.Fields {
+		if limit >= 0 && len(*differences) >= limit {
+			return
+		}
+		field := &m.dynamicType.spec.Fields[i]
+		path := joinPath(prefix, field.Name)
+
+		a, aOk := m.data[field.Name]
+		b, bOk := other.data[field.Name]
+		if !aOk || !bOk {
+			if aOk != bOk {
+				*differences = append(*differences, path)
+			}
+			continue
+		}
+
+		if field.IsBuiltin {
+			if !o.valuesEqual(a, b) {
+				*differences = append(*differences, path)
+			}
+			continue
+		}
+		if field.IsArray {
+			diffMessageArrays(a, b, path, o, differences, limit)
+			continue
+		}
+		diffNestedMessages(a, b, path, o, differences, limit)
+	}
+}
+
+// diffMessageArrays compares two arrays of nested messages element by element.
+func diffMessageArrays(a interface{}, b interface{}, path string, o *equalOptions, differences *[]string, limit int) {
+	av, aOk := a.([]Message)
+	bv, bOk := b.([]Message)
+	if !aOk || !bOk || len(av) != len(bv) {
+		*differences = append(*differences, path)
+		return
+	}
+	for i := range av {
+		if limit >= 0 && len(*differences) >= limit {
+			return
+		}
+		diffNestedMessages(av[i], bv[i], path+"["+strconv.Itoa(i)+"]", o, differences, limit)
+	}
+}
+
+// diffNestedMessages compares two nested messages.
+func diffNestedMessages(a interface{}, b interface{}, path string, o *equalOptions, differences *[]string, limit int) {
+	am, aOk := a.(*DynamicMessage)
+	bm, bOk := b.(*DynamicMessage)
+	if !aOk || !bOk || am == nil || bm == nil || am.dynamicType == nil || am.dynamicType.spec == nil || !sameDynamicType(am, bm) {
+		if !reflect.DeepEqual(a, b) {
+			*differences = append(*differences, path)
+		}
+		return
+	}
+	am.diff(bm, path, o, differences, limit)
+}
+
+// ALL DONE.
@@ -0,0 +1,126 @@
+package ros
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// newCompareTestMessage creates a populated message of the type created by newPathTestType().
+func newCompareTestMessage(t *testing.T, msgType *DynamicMessageType) *DynamicMessage {
+	msg, err := msgType.NewDynamicMessageFromMap(map[string]interface{}{
+		"frame_id": "map",
+		"pose":     map[string]interface{}{"position": map[string]interface{}{"x": 1.0}, "id": 2},
+		"points":   []interface{}{map[string]interface{}{"x": 1.0}, map[string]interface{}{"y": 2.0}},
+		"counts":   []interface{}{1, 2, 3},
+		"scale":    0.5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestDynamicMessage_Clone(t *testing.T) {
+	msg := newCompareTestMessage(t, newPathTestType(t))
+
+	clone := msg.Clone()
+	if !msg.Equal(clone) {
+		t.Fatal("expected clone to equal the original")
+	}
+
+	// Modifying the clone must not affect the original.
+	if err := clone.Set("pose.position.x", 5.0); err != nil {
+		t.Fatal(err)
+	}
+	if err := clone.Set("points[1].y", 5.0); err != nil {
+		t.Fatal(err)
+	}
+	if err := clone.Set("counts[0]", 5); err != nil {
+		t.Fatal(err)
+	}
+	if x, _ := msg.GetFloat64("pose.position.x"); x != 1 {
+		t.Fatalf("original nested message was modified, x = %v", x)
+	}
+	if y, _ := msg.GetFloat64("points[1].y"); y != 2 {
+		t.Fatalf("original message array was modified, y = %v", y)
+	}
+	if c, _ := msg.GetInt64("counts[0]"); c != 1 {
+		t.Fatalf("original array was modified, counts[0] = %v", c)
+	}
+}
+
+func TestDynamicMessage_EqualDiff(t *testing.T) {
+	msgType := newPathTestType(t)
+	a := newCompareTestMessage(t, msgType)
+	b := newCompareTestMessage(t, msgType)
+
+	if diff, err := a.Diff(b); err != nil || len(diff) != 0 {
+		t.Fatalf("expected no differences, got %v, %v", diff, err)
+	}
+
+	if err := b.Set("pose.position.x", 1.0001); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set("points[1].z", 3.0); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set("counts[2]", 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set("frame_id", "odom"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"frame_id", "pose.position.x", "points[1].z", "counts"}
+	if diff, err := a.Diff(b); err != nil || !reflect.DeepEqual(diff, expected) {
+		t.Fatalf("expected %v, got %v, %v", expected, diff, err)
+	}
+	if a.Equal(b) {
+		t.Fatal("expected messages to differ")
+	}
+
+	// Tolerances only apply to floats.
+	expected = []string{"frame_id", "points[1].z", "counts"}
+	if diff, _ := a.Diff(b, WithFloatTolerance(0.001)); !reflect.DeepEqual(diff, expected) {
+		t.Fatalf("expected %v, got %v", expected, diff)
+	}
+	if diff, _ := a.Diff(b, WithRelativeFloatTolerance(0.001)); !reflect.DeepEqual(diff, expected) {
+		t.Fatalf("expected %v, got %v", expected, diff)
+	}
+
+	// Arrays of messages of different lengths are reported as a whole.
+	if err := b.Set("points", []interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if diff, _ := a.Diff(b, WithFloatTolerance(0.001)); !reflect.DeepEqual(diff, []string{"frame_id", "points", "counts"}) {
+		t.Fatalf("unexpected differences %v", diff)
+	}
+
+	// Messages of other types can't be compared.
+	other := newConstantsTestType(t).NewDynamicMessage()
+	if a.Equal(other) {
+		t.Fatal("expected messages of different types to differ")
+	}
+	if _, err := a.Diff(other); err == nil {
+		t.Fatal("expected error comparing messages of different types")
+	}
+}
+
+func TestDynamicMessage_EqualNaN(t *testing.T) {
+	msgType := newPathTestType(t)
+	a := msgType.NewDynamicMessage()
+	b := msgType.NewDynamicMessage()
+	for _, msg := range []*DynamicMessage{a, b} {
+		if err := msg.Set("scale", math.NaN()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if a.Equal(b) {
+		t.Fatal("expected NaN not to equal NaN by default")
+	}
+	if !a.Equal(b, WithNaNEqual()) {
+		t.Fatal("expected NaN to equal NaN with WithNaNEqual")
+	}
+}
@@ -0,0 +1,250 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// rosmsgTag is the struct tag which gengo attaches to the fields of generated messages, in the form `rosmsg:"name:type"`.
+const rosmsgTag = "rosmsg"
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// Into copies the data of a DynamicMessage into a message generated by gengo, matching fields using their `rosmsg` struct tags.  The message must be a pointer to a generated
+// struct with the same MD5 sum as the DynamicMessage.
+func (m *DynamicMessage) Into(msg Message) error {
+	if err := m.checkSameType(msg); err != nil {
+		return err
+	}
+
+	// Another dynamic message of the same type can't be filled by reflection, so go via the wire format.
+	if dynamic, ok := msg.(*DynamicMessage); ok {
+		return copyDynamicMessage(dynamic, m)
+	}
+
+	target := reflect.ValueOf(msg)
+	if target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return errors.New("Into: expected a pointer to a generated message struct, got " + target.Type().String())
+	}
+	return m.intoStruct(target.Elem())
+}
+
+// FromMessage replaces the data of a DynamicMessage with that of a message generated by gengo, matching fields using their `rosmsg` struct tags.  The message must have the same
+// MD5 sum as the DynamicMessage.
+func (m *DynamicMessage) FromMessage(msg Message) error {
+	if err := m.checkSameType(msg); err != nil {
+		return err
+	}
+
+	if dynamic, ok := msg.(*DynamicMessage); ok {
+		return copyDynamicMessage(m, dynamic)
+	}
+
+	source := reflect.ValueOf(msg)
+	if source.Kind() != reflect.Ptr || source.IsNil() || source.Elem().Kind() != reflect.Struct {
+		return errors.New("FromMessage: expected a pointer to a generated message struct, got " + source.Type().String())
+	}
+	return m.fromStruct(source.Elem())
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// copyDynamicMessage copies one dynamic message into another by serializing and deserializing it.
+func copyDynamicMessage(dst *DynamicMessage, src *DynamicMessage) error {
+	var buf bytes.Buffer
+	if err := src.Serialize(&buf); err != nil {
+		return err
+	}
+	return dst.Deserialize(bytes.NewReader(buf.Bytes()))
+}
+
+// rosmsgFieldIndex maps the message field names found in the `rosmsg` tags of a generated struct to the index of the Go field.
+func rosmsgFieldIndex(structType reflect.Type) map[string]int {
+	index := make(map[string]int, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		if name, ok := rosmsgFieldName(structType.Field(i)); ok {
+			index[name] = i
+		}
+	}
+	return index
+}
+
+// dynamicValueInto stores a value held by a DynamicMessage into the matching field of a generated struct.
+func dynamicValueInto(value interface{}, target reflect.Value) error {
+	switch v := value.(type) {
+	case JsonFloat32:
+		if target.Kind() != reflect.Float32 && target.Kind() != reflect.Float64 {
+			return errors.New("cannot store float32 in " + target.Type().String())
+		}
+		target.SetFloat(float64(v.F))
+		return nil
+	case JsonFloat64:
+		if target.Kind() != reflect.Float32 && target.Kind() != reflect.Float64 {
+			return errors.New("cannot store float64 in " + target.Type().String())
+		}
+		target.SetFloat(v.F)
+		return nil
+	case *DynamicMessage:
+		if target.Kind() != reflect.Struct {
+			return errors.New("cannot store " + v.dynamicType.Name() + " in " + target.Type().String())
+		}
+		return v.intoStruct(target)
+	}
+
+	source := reflect.ValueOf(value)
+	if !source.IsValid() {
+		return errors.New("cannot store nil in " + target.Type().String())
+	}
+
+	// Arrays are copied element by element, as the element representations may differ.
+	if source.Kind() == reflect.Slice && source.Type() != target.Type() {
+		size := source.Len()
+		switch target.Kind() {
+		case reflect.Slice:
+			target.Set(reflect.MakeSlice(target.Type(), size, size))
+		case reflect.Array:
+			if target.Len() != size {
+				return errors.New("expected array of length " + strconv.Itoa(target.Len()) + ", got " + strconv.Itoa(size))
+			}
+		default:
+			return errors.New("cannot store " + source.Type().String() + " in " + target.Type().String())
+		}
+		for i := 0; i < size; i++ {
+			if err := dynamicValueInto(source.Index(i).Interface(), target.Index(i)); err != nil {
+				return errors.Wrap(err, "index "+strconv.Itoa(i))
+			}
+		}
+		return nil
+	}
+
+	if !source.Type().ConvertibleTo(target.Type()) {
+		return errors.New("cannot store " + source.Type().String() + " in " + target.Type().String())
+	}
+	target.Set(source.Convert(target.Type()))
+	return nil
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// checkSameType checks that a message has the same type as the DynamicMessage, by comparing MD5 sums.
+func (m *DynamicMessage) checkSameType(msg Message) error {
+	if m == nil || m.dynamicType == nil || m.dynamicType.spec == nil {
+		return errors.New("invalid dynamic message")
+	}
+	if msg == nil || reflect.ValueOf(msg).Kind() == reflect.Ptr && reflect.ValueOf(msg).IsNil() {
+		return errors.New("nil message")
+	}
+	if msg.Type().MD5Sum() != m.dynamicType.MD5Sum() {
+		return errors.New("message type " + msg.Type().Name() + " (" + msg.Type().MD5Sum() + ") does not match " + m.dynamicType.Name() + " (" + m.dynamicType.MD5Sum() + ")")
+	}
+	return nil
+}
+
+// intoStruct copies the data of a DynamicMessage into a generated struct.
+func (m *DynamicMessage) intoStruct(target reflect.Value) error {
+	if err := m.decodeLazyFields(); err != nil {
+		return err
+	}
+	index := rosmsgFieldIndex(target.Type())
+	for _, field := range m.dynamicType.spec.Fields {
+		i, ok := index[field.Name]
+		if !ok {
+			return errors.New("Field: " + field.Name + ": no matching rosmsg tag in " + target.Type().String())
+		}
+		value, ok := m.data[field.Name]
+		if !ok {
+			return errors.New("Field: " + field.Name + ": No data found.")
+		}
+		if err := dynamicValueInto(value, target.Field(i)); err != nil {
+			return errors.Wrap(err, "Field: "+field.Name)
+		}
+	}
+	return nil
+}
+
+// fromStruct replaces the data of a DynamicMessage with the contents of a generated struct.
+func (m *DynamicMessage) fromStruct(source reflect.Value) error {
+	index := rosmsgFieldIndex(source.Type())
+	data := make(map[string]interface{}, len(m.dynamicType.spec.Fields))
+	for i := range m.dynamicType.spec.Fields {
+		field := &m.dynamicType.spec.Fields[i]
+		j, ok := index[field.Name]
+		if !ok {
+			return errors.New("Field: " + field.Name + ": no matching rosmsg tag in " + source.Type().String())
+		}
+		value, err := m.dynamicType.structValueToDynamic(field, source.Field(j))
+		if err != nil {
+			return errors.Wrap(err, "Field: "+field.Name)
+		}
+		data[field.Name] = value
+	}
+	m.data = data
+	m.raw = nil
+	m.rawOffsets = nil
+	return nil
+}
+
+// structValueToDynamic converts the value of a generated struct field into the representation DynamicMessage uses for the field.
+func (t *DynamicMessageType) structValueToDynamic(field *libgengo.Field, source reflect.Value) (interface{}, error) {
+	if field.IsArray {
+		if source.Kind() != reflect.Slice && source.Kind() != reflect.Array {
+			return nil, errors.New("cannot use " + source.Type().String() + " as " + field.Type + "[]")
+		}
+		size := source.Len()
+		if field.ArrayLen >= 0 && size != field.ArrayLen {
+			return nil, errors.New("expected array of length " + strconv.Itoa(field.ArrayLen) + ", got " + strconv.Itoa(size))
+		}
+		array, err := t.zeroValueArray(field, size)
+		if err != nil {
+			return nil, err
+		}
+		result := reflect.ValueOf(array)
+		for i := 0; i < size; i++ {
+			element, err := t.structElementToDynamic(field, source.Index(i))
+			if err != nil {
+				return nil, errors.Wrap(err, "index "+strconv.Itoa(i))
+			}
+			result.Index(i).Set(reflect.ValueOf(element))
+		}
+		return array, nil
+	}
+	return t.structElementToDynamic(field, source)
+}
+
+// structElementToDynamic converts a single element of a generated struct field into the representation DynamicMessage uses.
+func (t *DynamicMessageType) structElementToDynamic(field *libgengo.Field, source reflect.Value) (interface{}, error) {
+	if field.IsBuiltin {
+		return t.coerceSingularValue(field, source.Interface())
+	}
+
+	msgType, err := t.getNestedTypeFromField(field)
+	if err != nil {
+		return nil, err
+	}
+	if source.Kind() != reflect.Struct {
+		return nil, errors.New("cannot use " + source.Type().String() + " as " + msgType.Name())
+	}
+	msg := msgType.NewDynamicMessage()
+	if err := msg.fromStruct(source); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// ALL DONE.
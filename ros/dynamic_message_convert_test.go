@@ -0,0 +1,114 @@
+package ros
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Hand written equivalents of the structs gengo would generate for the message types created by newPathTestType().
+
+type testPoint struct {
+	X float64 `rosmsg:"x:float64"`
+	Y float64 `rosmsg:"y:float64"`
+	Z float64 `rosmsg:"z:float64"`
+}
+
+type testPose struct {
+	Position testPoint `rosmsg:"position:Point"`
+	ID       uint8     `rosmsg:"id:uint8"`
+}
+
+type testPath struct {
+	msgType  MessageType
+	Stamp    Time        `rosmsg:"stamp:time"`
+	Period   Duration    `rosmsg:"period:duration"`
+	FrameID  string      `rosmsg:"frame_id:string"`
+	Pose     testPose    `rosmsg:"pose:Pose"`
+	Points   []testPoint `rosmsg:"points:Point[]"`
+	Counts   [3]int32    `rosmsg:"counts:int32[3]"`
+	Scale    float32     `rosmsg:"scale:float32"`
+	Unmapped int
+}
+
+func (m *testPath) Type() MessageType                   { return m.msgType }
+func (m *testPath) Serialize(buf *bytes.Buffer) error   { return nil }
+func (m *testPath) Deserialize(buf *bytes.Reader) error { return nil }
+
+func TestDynamicMessage_IntoFromMessage(t *testing.T) {
+	msgType := newPathTestType(t)
+
+	original := &testPath{
+		msgType: msgType,
+		Stamp:   NewTime(1, 2),
+		Period:  NewDuration(3, 4),
+		FrameID: "map",
+		Pose:    testPose{Position: testPoint{X: 1, Y: 2, Z: 3}, ID: 4},
+		Points:  []testPoint{{X: 5}, {Y: 6}},
+		Counts:  [3]int32{7, 8, 9},
+		Scale:   0.5,
+	}
+
+	msg := msgType.NewDynamicMessage()
+	if err := msg.FromMessage(original); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := msg.GetFloat64("points[1].y"); err != nil || v != 6 {
+		t.Fatalf("expected 6, got %v, %v", v, err)
+	}
+	if v, err := msg.GetFloat64("pose.position.z"); err != nil || v != 3 {
+		t.Fatalf("expected 3, got %v, %v", v, err)
+	}
+	if v, err := msg.Get("counts"); err != nil || v.([]int32)[2] != 9 {
+		t.Fatalf("expected counts to be copied, got %#v, %v", v, err)
+	}
+
+	// The dynamic message must still serialize.
+	var buf bytes.Buffer
+	if err := msg.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	result := &testPath{msgType: msgType}
+	if err := msg.Into(result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Stamp != original.Stamp || result.Period != original.Period || result.FrameID != original.FrameID ||
+		result.Pose != original.Pose || result.Counts != original.Counts || result.Scale != original.Scale ||
+		len(result.Points) != 2 || result.Points[0] != original.Points[0] || result.Points[1] != original.Points[1] {
+		t.Fatalf("round trip mismatch: expected %+v, got %+v", original, result)
+	}
+}
+
+func TestDynamicMessage_IntoDynamicMessage(t *testing.T) {
+	msgType := newPathTestType(t)
+	msg := msgType.NewDynamicMessage()
+	if err := msg.Set("frame_id", "odom"); err != nil {
+		t.Fatal(err)
+	}
+
+	other := msgType.NewDynamicMessage()
+	if err := msg.Into(other); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := other.GetString("frame_id"); s != "odom" {
+		t.Fatalf("expected odom, got %v", s)
+	}
+}
+
+func TestDynamicMessage_IntoMismatchedType(t *testing.T) {
+	msgType := newPathTestType(t)
+	msg := msgType.NewDynamicMessage()
+
+	poseType, err := msgType.getNestedTypeFromField(&msgType.spec.Fields[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := &testPath{msgType: poseType}
+	if err := msg.Into(target); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected type mismatch error, got %v", err)
+	}
+	if err := msg.FromMessage(target); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected type mismatch error, got %v", err)
+	}
+}
@@ -3,8 +3,8 @@ package ros
 // IMPORT REQUIRED PACKAGES.
 
 import (
+	"bytes"
 	"encoding/base64"
-	"encoding/json"
 	"math"
 	"reflect"
 	"strconv"
@@ -26,295 +26,17 @@ import (
 
 // DEFINE PUBLIC RECEIVER FUNCTIONS.
 
-//	DynamicMessageType
-
-// GenerateJSONSchema generates a (primitive) JSON schema for the associated DynamicMessageType; however note that since
-// we are mostly interested in making schema's for particular _topics_, the function takes a string prefix, and string topic name, which are
-// used to id the resulting schema.
-func (t *DynamicMessageType) GenerateJSONSchema(prefix string, topic string) ([]byte, error) {
-	// The JSON schema for a message consist of the (recursive) properties names/types:
-	schemaItems, err := t.generateJSONSchemaProperties(prefix + topic)
-	if err != nil {
-		return nil, err
-	}
-
-	// Plus some extra keywords:
-	schemaItems["$schema"] = "https://json-schema.org/draft-07/schema#"
-	schemaItems["$id"] = prefix + topic
-
-	// The schema itself is created from the map of properties.
-	schemaString, err := json.Marshal(schemaItems)
-	if err != nil {
-		return nil, err
-	}
-
-	// All done.
-	return schemaString, nil
-}
-
-func (t *DynamicMessageType) generateJSONSchemaProperties(topic string) (map[string]interface{}, error) {
-	// Each message's schema indicates that it is an 'object' with some nested properties: those properties are the fields and their types.
-	properties := make(map[string]interface{})
-	schemaItems := make(map[string]interface{})
-	schemaItems["type"] = "object"
-	schemaItems["properties"] = properties
-
-	// Iterate over each of the fields in the message.
-	for _, field := range t.spec.Fields {
-		if field.IsArray {
-			// It's an array.
-			propertyContent := make(map[string]interface{})
-			properties[field.Name] = propertyContent
-
-			if field.GoType == "uint8" {
-				propertyContent["type"] = "string"
-			} else {
-				// Arrays all have a type of 'array', regardless of that the hold, then the 'item' keyword determines what type goes in the array.
-				propertyContent["type"] = "array"
-				arrayItems := make(map[string]interface{})
-				propertyContent["items"] = arrayItems
-
-				// Need to handle each type appropriately.
-				if field.IsBuiltin {
-					if field.Type == "string" {
-						arrayItems["type"] = "string"
-					} else if field.Type == "time" {
-						timeItems := make(map[string]interface{})
-						timeItems["sec"] = map[string]string{"type": "integer"}
-						timeItems["nsec"] = map[string]string{"type": "integer"}
-						arrayItems["type"] = "object"
-						arrayItems["properties"] = timeItems
-					} else if field.Type == "duration" {
-						timeItems := make(map[string]interface{})
-						timeItems["sec"] = map[string]string{"type": "integer"}
-						timeItems["nsec"] = map[string]string{"type": "integer"}
-						arrayItems["type"] = "object"
-						arrayItems["properties"] = timeItems
-					} else {
-						// It's a primitive.
-						var jsonType string
-						if field.GoType == "int8" || field.GoType == "int16" || field.GoType == "uint16" ||
-							field.GoType == "int32" || field.GoType == "uint32" || field.GoType == "int64" || field.GoType == "uint64" {
-							jsonType = "integer"
-						} else if field.GoType == "float32" || field.GoType == "float64" {
-							jsonType = "number"
-						} else if field.GoType == "bool" {
-							jsonType = "bool"
-						} else {
-							// Something went wrong.
-							return nil, errors.New("we haven't implemented this primitive yet")
-						}
-						arrayItems["type"] = jsonType
-					}
-
-				} else {
-					// It's another nested message.
-
-					// Generate the nested type.
-					msgType, err := newDynamicMessageTypeNested(field.Type, field.Package, nil, nil)
-					if err != nil {
-						return nil, errors.Wrap(err, "Schema Field: "+field.Name)
-					}
-
-					// Recursively generate schema information for the nested type.
-					schemaElement, err := msgType.generateJSONSchemaProperties(topic + Sep + field.Name)
-					if err != nil {
-						return nil, errors.Wrap(err, "Schema Field:"+field.Name)
-					}
-					arrayItems["type"] = schemaElement
-				}
-			}
-		} else {
-			// It's a scalar.
-			if field.IsBuiltin {
-				propertyContent := make(map[string]interface{})
-				properties[field.Name] = propertyContent
-
-				if field.Type == "string" {
-					propertyContent["type"] = "string"
-				} else if field.Type == "time" {
-					timeItems := make(map[string]interface{})
-					timeItems["sec"] = map[string]string{"type": "integer"}
-					timeItems["nsec"] = map[string]string{"type": "integer"}
-					propertyContent["type"] = "object"
-					propertyContent["properties"] = timeItems
-				} else if field.Type == "duration" {
-					timeItems := make(map[string]interface{})
-					timeItems["sec"] = map[string]string{"type": "integer"}
-					timeItems["nsec"] = map[string]string{"type": "integer"}
-					propertyContent["type"] = "object"
-					propertyContent["properties"] = timeItems
-				} else {
-					// It's a primitive.
-					var jsonType string
-					if field.GoType == "int8" || field.GoType == "uint8" || field.GoType == "int16" || field.GoType == "uint16" ||
-						field.GoType == "int32" || field.GoType == "uint32" || field.GoType == "int64" || field.GoType == "uint64" {
-						jsonType = "integer"
-						jsonType = "integer"
-						jsonType = "integer"
-					} else if field.GoType == "float32" || field.GoType == "float64" {
-						jsonType = "number"
-					} else if field.GoType == "bool" {
-						jsonType = "bool"
-					} else {
-						// Something went wrong.
-						return nil, errors.New("we haven't implemented this primitive yet")
-					}
-					propertyContent["type"] = jsonType
-				}
-			} else {
-				// It's another nested message.
-
-				// Generate the nested type.
-				msgType, err := newDynamicMessageTypeNested(field.Type, field.Package, nil, nil)
-				if err != nil {
-					return nil, errors.Wrap(err, "Schema Field: "+field.Name)
-				}
-
-				// Recursively generate schema information for the nested type.
-				schemaElement, err := msgType.generateJSONSchemaProperties(topic + Sep + field.Name)
-				if err != nil {
-					return nil, errors.Wrap(err, "Schema Field:"+field.Name)
-				}
-				properties[field.Name] = schemaElement
-			}
-		}
-	}
-
-	// All done.
-	return schemaItems, nil
-}
-
 //	DynamicMessage
 
 // MarshalJSON provides a custom implementation of JSON marshalling, only the message payload is represented in compact form. Verification provided in dynamic_message_json_test.go.
 // The marshalled JSON must match the schema generated by GenerateJSONSchema().
 func (m *DynamicMessage) MarshalJSON() ([]byte, error) {
-	// Confirm the pointers are valid.
-	if err := messagePointerError(m); err != nil {
-		return nil, err
-	}
-
-	buf := make([]byte, 0, m.dynamicType.jsonPrealloc)
-
-	buf = append(buf, byte('{'))
-	for i, field := range m.dynamicType.spec.Fields {
-		// Marshal the JSON name key.
-		if i > 0 {
-			buf = append(buf, byte(','))
-		}
-		buf = strconv.AppendQuote(buf, field.Name)
-		buf = append(buf, byte(':'))
-
-		// Marshal the value.
-		v, ok := m.data[field.Name]
-		if !ok {
-			return nil, errors.Wrap(errors.New("key not in data"), "key: "+field.Name)
-		}
-		var err error
-		if field.IsArray {
-			err = marshalArrayValue(&field, v, &buf)
-		} else {
-			err = marshalSingularValue(&field, v, &buf)
-		}
-		if err != nil {
-			return nil, errors.Wrap(err, "field: "+field.Name)
-		}
-	}
-
-	buf = append(buf, byte('}'))
-
-	if length := len(buf); length > m.dynamicType.jsonPrealloc {
-		m.dynamicType.jsonPrealloc = length
-	}
-
-	return buf, nil
+	return m.marshalJSON(defaultJSONOptions)
 }
 
 // UnmarshalJSON provides a custom implementation of JSON unmarshalling. Verification provided in dynamic_message_json_test.go.
 func (m *DynamicMessage) UnmarshalJSON(buf []byte) (err error) {
-	// Confirm the pointers are valid.
-	if err := messagePointerError(m); err != nil {
-		return err
-	}
-
-	// JSON unmarshalling. Iterates and executes the callback for each item found in buf.
-	return jsonparser.ObjectEach(buf, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
-		fieldExists := false
-		var field libgengo.Field
-
-		// Find message spec field that matches JSON key
-		keyString := string(key)
-		for _, specField := range m.dynamicType.spec.Fields {
-			if keyString == specField.Name {
-				field = specField
-				fieldExists = true
-			}
-		}
-		if fieldExists == false {
-			return errors.New("Field Unknown: " + string(key))
-		}
-		switch dataType {
-
-		case jsonparser.String:
-			if field.IsArray && field.BuiltInType != libgengo.Uint8 {
-				return errors.Wrap(errors.New("attempted to unmarshal array as singular"), "field: "+field.Name+" value: "+string(value))
-			}
-			var result interface{}
-			if err := unmarshalString(value, &field, &result); err != nil {
-				return errors.Wrap(err, "field: "+field.Name)
-			}
-			m.data[field.Name] = result
-
-		case jsonparser.Number: // We have a JSON number; expect a float or integer.
-			if field.IsArray {
-				return errors.Wrap(errors.New("attempted to unmarshal array as singular"), "field: "+field.Name+" value: "+string(value))
-			}
-			var result interface{}
-			if err := unmarshalNumber(value, &field, &result); err != nil {
-				return errors.Wrap(err, "field: "+field.Name)
-			}
-			m.data[field.Name] = result
-
-		case jsonparser.Boolean:
-			if field.IsArray {
-				return errors.Wrap(errors.New("attempted to unmarshal array as singular"), "field: "+field.Name+" value: "+string(value))
-			}
-			if field.BuiltInType != libgengo.Bool {
-				return errors.Wrap(errors.New("attempted to parse "+field.Type+" as bool"), "field: "+field.Name+" value: "+string(value))
-			}
-			value, err := jsonparser.ParseBoolean(value)
-			if err != nil {
-				return errors.Wrap(err, "field: "+field.Name)
-			}
-			m.data[field.Name] = value
-
-		case jsonparser.Object:
-			if field.IsArray {
-				return errors.Wrap(errors.New("attempted to unmarshal array as singular"), "field: "+field.Name+" value: "+string(value))
-			}
-			var result interface{}
-			if err := unmarshalObject(m.dynamicType, value, &field, &result); err != nil {
-				return errors.Wrap(err, "field: "+field.Name)
-			}
-			m.data[field.Name] = result
-
-		case jsonparser.Array:
-			if field.IsArray == false {
-				return errors.Wrap(errors.New("attempted to unmarshal singular as array"), "field: "+field.Name+" value: "+string(value))
-			}
-			var result interface{}
-			if err := unmarshalArray(m.dynamicType, value, &field, &result); err != nil {
-				return errors.Wrap(err, "field: "+field.Name)
-			}
-			m.data[field.Name] = result
-		default:
-			// We do nothing here as blank fields may return value type NotExist or Null
-			return errors.Wrap(err, "Null field: "+string(key))
-		}
-
-		return err
-	})
+	return m.unmarshalJSON(buf, defaultJSONOptions)
 }
 
 // DEFINE PRIVATE STATIC FUNCTIONS.
@@ -388,8 +110,8 @@ func newTypeError(v interface{}, expected string) error {
 	return errors.New("has type " + reflect.TypeOf(v).Name() + ", expected " + expected)
 }
 
-func marshalArrayValue(field *libgengo.Field, v interface{}, buf *[]byte) error {
-	if field.BuiltInType == libgengo.Uint8 { // Special case, is marshalled as base64.
+func marshalArrayValue(field *libgengo.Field, v interface{}, buf *[]byte, o *jsonOptions) error {
+	if field.BuiltInType == libgengo.Uint8 && !o.uint8ArraysAsNumbers { // Special case, is marshalled as base64.
 		return marshalArrayUint8(v, buf)
 	}
 
@@ -406,7 +128,7 @@ func marshalArrayValue(field *libgengo.Field, v interface{}, buf *[]byte) error
 					return newTypeError(nested, "DynamicMessage")
 				}
 
-				nestedbuf, err := nestedDynamicMessage.MarshalJSON()
+				nestedbuf, err := nestedDynamicMessage.marshalJSON(o)
 				if err != nil {
 					return err
 				}
@@ -476,6 +198,17 @@ func marshalArrayValue(field *libgengo.Field, v interface{}, buf *[]byte) error
 				}
 				*buf = strconv.AppendInt(*buf, item, 10)
 			}
+		case libgengo.Uint8:
+			slice, ok := v.([]uint8)
+			if ok == false {
+				return newTypeError(v, "[]uint8")
+			}
+			for i, item := range slice {
+				if i > 0 {
+					*buf = append(*buf, byte(','))
+				}
+				*buf = strconv.AppendUint(*buf, uint64(item), 10)
+			}
 		case libgengo.Uint16:
 			slice, ok := v.([]uint16)
 			if ok == false {
@@ -518,7 +251,7 @@ func marshalArrayValue(field *libgengo.Field, v interface{}, buf *[]byte) error
 				if i > 0 {
 					*buf = append(*buf, byte(','))
 				}
-				marshalFloat(float64(item.F), &*buf, 32)
+				o.marshalFloat(float64(item.F), &*buf, 32)
 			}
 		case libgengo.Float64:
 			slice, ok := v.([]JsonFloat64)
@@ -529,7 +262,7 @@ func marshalArrayValue(field *libgengo.Field, v interface{}, buf *[]byte) error
 				if i > 0 {
 					*buf = append(*buf, byte(','))
 				}
-				marshalFloat(item.F, &*buf, 64)
+				o.marshalFloat(item.F, &*buf, 64)
 			}
 		case libgengo.String:
 			slice, ok := v.([]string)
@@ -551,7 +284,7 @@ func marshalArrayValue(field *libgengo.Field, v interface{}, buf *[]byte) error
 				if i > 0 {
 					*buf = append(*buf, byte(','))
 				}
-				marshalSecNSec(uint64(item.Sec), uint64(item.NSec), &*buf)
+				o.marshalTime(item, &*buf)
 			}
 		case libgengo.Duration:
 			slice, ok := v.([]Duration)
@@ -562,7 +295,7 @@ func marshalArrayValue(field *libgengo.Field, v interface{}, buf *[]byte) error
 				if i > 0 {
 					*buf = append(*buf, byte(','))
 				}
-				marshalSecNSec(uint64(item.Sec), uint64(item.NSec), &*buf)
+				o.marshalDuration(item, &*buf)
 			}
 		default:
 			// Something went wrong.
@@ -574,11 +307,11 @@ func marshalArrayValue(field *libgengo.Field, v interface{}, buf *[]byte) error
 	return nil
 }
 
-func marshalSingularValue(field *libgengo.Field, v interface{}, buf *[]byte) error {
+func marshalSingularValue(field *libgengo.Field, v interface{}, buf *[]byte, o *jsonOptions) error {
 	if field.IsBuiltin == false {
 		// The type encapsulates another ROS message, so we marshal the DynamicMessage.
 		if nested, ok := v.(*DynamicMessage); ok {
-			nestedBuf, err := nested.MarshalJSON()
+			nestedBuf, err := nested.marshalJSON(o)
 			if err != nil {
 				return err
 			}
@@ -646,19 +379,19 @@ func marshalSingularValue(field *libgengo.Field, v interface{}, buf *[]byte) err
 		if ok == false {
 			return newTypeError(v, "uint64")
 		}
-		*buf = strconv.AppendInt(*buf, int64(value), 10)
+		*buf = strconv.AppendUint(*buf, value, 10)
 	case libgengo.Float32:
 		value, ok := v.(JsonFloat32)
 		if ok == false {
 			return newTypeError(v, "JsonFloat32")
 		}
-		marshalFloat(float64(value.F), &*buf, 32)
+		o.marshalFloat(float64(value.F), &*buf, 32)
 	case libgengo.Float64:
 		value, ok := v.(JsonFloat64)
 		if ok == false {
 			return newTypeError(v, "JsonFloat64")
 		}
-		marshalFloat(value.F, &*buf, 64)
+		o.marshalFloat(value.F, &*buf, 64)
 	case libgengo.String:
 		value, ok := v.(string)
 		if ok == false {
@@ -670,13 +403,13 @@ func marshalSingularValue(field *libgengo.Field, v interface{}, buf *[]byte) err
 		if ok == false {
 			return newTypeError(v, "Time")
 		}
-		marshalSecNSec(uint64(value.Sec), uint64(value.NSec), &*buf)
+		o.marshalTime(value, &*buf)
 	case libgengo.Duration:
 		value, ok := v.(Duration)
 		if ok == false {
 			return newTypeError(v, "Duration")
 		}
-		marshalSecNSec(uint64(value.Sec), uint64(value.NSec), &*buf)
+		o.marshalDuration(value, &*buf)
 	default:
 		// Something went wrong.
 		return errors.New("unknown builtin type " + field.GoType)
@@ -764,6 +497,13 @@ func unmarshalNumber(value []byte, field *libgengo.Field, dest *interface{}) err
 		if err != nil {
 			return err
 		}
+	} else if field.BuiltInType == libgengo.Uint64 { //We have a uint64 to parse, which may not fit in an int64
+		uintValue, err := strconv.ParseUint(string(value), 10, 64)
+		if err != nil {
+			return err
+		}
+		*dest = uintValue
+		return nil
 	} else { //We have an int to parse
 		intValue, err = jsonparser.ParseInt(value)
 		if err != nil {
@@ -798,21 +538,11 @@ func unmarshalNumber(value []byte, field *libgengo.Field, dest *interface{}) err
 	return nil
 }
 
-func unmarshalObject(msgType *DynamicMessageType, value []byte, field *libgengo.Field, dest *interface{}) error {
+func unmarshalObject(msgType *DynamicMessageType, value []byte, field *libgengo.Field, dest *interface{}, o *jsonOptions) error {
 	if field.IsBuiltin {
 		switch field.BuiltInType {
-		case libgengo.Time:
-			sec, nsec, err := unmarshalSecNSecObject(value)
-			if err != nil {
-				return err
-			}
-			*dest = NewTime(sec, nsec)
-		case libgengo.Duration:
-			sec, nsec, err := unmarshalSecNSecObject(value)
-			if err != nil {
-				return err
-			}
-			*dest = NewDuration(sec, nsec)
+		case libgengo.Time, libgengo.Duration:
+			return o.unmarshalTemporal(value, jsonparser.Object, field, dest)
 		default:
 			return errors.New("unexpected object")
 		}
@@ -823,7 +553,7 @@ func unmarshalObject(msgType *DynamicMessageType, value []byte, field *libgengo.
 			return err
 		}
 		msg := msgType.NewDynamicMessage()
-		if err = msg.UnmarshalJSON(value); err != nil {
+		if err = msg.unmarshalJSON(value, o); err != nil {
 			return err
 		}
 		*dest = msg
@@ -831,7 +561,7 @@ func unmarshalObject(msgType *DynamicMessageType, value []byte, field *libgengo.
 	return nil
 }
 
-func unmarshalArray(msgType *DynamicMessageType, value []byte, field *libgengo.Field, dest *interface{}) error {
+func unmarshalArray(msgType *DynamicMessageType, value []byte, field *libgengo.Field, dest *interface{}, o *jsonOptions) error {
 	var err error
 	var unmarshalledLength int
 
@@ -839,7 +569,10 @@ func unmarshalArray(msgType *DynamicMessageType, value []byte, field *libgengo.F
 	if size < 0 {
 		size = 0
 	}
-	if field.IsBuiltin {
+	if field.IsBuiltin && isIntegerGoType(field.GoType) && bytes.IndexByte(value, '"') >= 0 {
+		// Integer arrays may hold the symbolic names of the message's constants.
+		unmarshalledLength, err = unmarshalIntegerArrayWithConstants(msgType, value, field, dest)
+	} else if field.IsBuiltin {
 		switch field.BuiltInType {
 		case libgengo.Bool:
 			array := make([]bool, 0, size)
@@ -889,12 +622,12 @@ func unmarshalArray(msgType *DynamicMessageType, value []byte, field *libgengo.F
 			*dest = array
 		case libgengo.Float32:
 			array := make([]JsonFloat32, 0, size)
-			err = unmarshalFloat32Array(value, &array)
+			err = unmarshalFloat32Array(value, &array, o)
 			unmarshalledLength = len(array)
 			*dest = array
 		case libgengo.Float64:
 			array := make([]JsonFloat64, 0, size)
-			err = unmarshalFloat64Array(value, &array)
+			err = unmarshalFloat64Array(value, &array, o)
 			unmarshalledLength = len(array)
 			*dest = array
 		case libgengo.String:
@@ -904,12 +637,12 @@ func unmarshalArray(msgType *DynamicMessageType, value []byte, field *libgengo.F
 			*dest = array
 		case libgengo.Time:
 			array := make([]Time, 0, size)
-			err = unmarshalTimeArray(value, &array)
+			err = unmarshalTimeArray(value, &array, field, o)
 			unmarshalledLength = len(array)
 			*dest = array
 		case libgengo.Duration:
 			array := make([]Duration, 0, size)
-			err = unmarshalDurationArray(value, &array)
+			err = unmarshalDurationArray(value, &array, field, o)
 			unmarshalledLength = len(array)
 			*dest = array
 		default:
@@ -921,7 +654,7 @@ func unmarshalArray(msgType *DynamicMessageType, value []byte, field *libgengo.F
 			return err
 		}
 		array := make([]Message, 0, size)
-		err = unmarshalMessageArray(value, &array, msgType)
+		err = unmarshalMessageArray(value, &array, msgType, o)
 		unmarshalledLength = len(array)
 		*dest = array
 	}
@@ -931,6 +664,36 @@ func unmarshalArray(msgType *DynamicMessageType, value []byte, field *libgengo.F
 	return err
 }
 
+// unmarshalIntegerArrayWithConstants unmarshals an integer array whose elements may be numbers or the names of the message's constants, returning the number of elements.
+func unmarshalIntegerArrayWithConstants(msgType *DynamicMessageType, value []byte, field *libgengo.Field, dest *interface{}) (int, error) {
+	zero, err := msgType.zeroValueArray(field, 0)
+	if err != nil {
+		return 0, err
+	}
+	array := reflect.ValueOf(zero)
+	element := reflect.New(array.Type().Elem()).Elem()
+	arrayHandler := func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
+		if err != nil {
+			return // Stop processing if there is an error.
+		}
+		var bits uint64
+		if bits, err = msgType.parseJSONInteger(field, value, dataType); err != nil {
+			err = errors.Wrap(err, "index "+strconv.Itoa(array.Len()))
+			return
+		}
+		switch element.Kind() {
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			element.SetInt(int64(bits))
+		default:
+			element.SetUint(bits)
+		}
+		array = reflect.Append(array, element)
+	}
+	jsonparser.ArrayEach(value, arrayHandler)
+	*dest = array.Interface()
+	return array.Len(), err
+}
+
 func unmarshalBoolArray(value []byte, array *[]bool) error {
 	var err error
 	arrayHandler := func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
@@ -1106,12 +869,12 @@ func unmarshalUint64Array(value []byte, array *[]uint64) error {
 			return // Stop processing if there is an error.
 		}
 		if dataType == jsonparser.Number {
-			var data int64
-			data, err = jsonparser.ParseInt(value)
+			var data uint64
+			data, err = strconv.ParseUint(string(value), 10, 64)
 			if err != nil {
 				return
 			}
-			*array = append(*array, uint64(data))
+			*array = append(*array, data)
 		} else {
 			err = errors.New("unexpected type, expecting uint64")
 		}
@@ -1120,13 +883,15 @@ func unmarshalUint64Array(value []byte, array *[]uint64) error {
 	return err
 }
 
-func unmarshalFloat32Array(value []byte, array *[]JsonFloat32) error {
+func unmarshalFloat32Array(value []byte, array *[]JsonFloat32, o *jsonOptions) error {
 	var err error
 	arrayHandler := func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
 		if err != nil {
 			return // Stop processing if there is an error.
 		}
-		if dataType == jsonparser.String || dataType == jsonparser.Number {
+		if dataType == jsonparser.Null && o.nanAsNull {
+			*array = append(*array, JsonFloat32{F: float32(math.NaN())})
+		} else if dataType == jsonparser.String || dataType == jsonparser.Number {
 			var floatValue float64
 			floatValue, err = strconv.ParseFloat(string(value), 32)
 			if err != nil {
@@ -1141,13 +906,15 @@ func unmarshalFloat32Array(value []byte, array *[]JsonFloat32) error {
 	return err
 }
 
-func unmarshalFloat64Array(value []byte, array *[]JsonFloat64) error {
+func unmarshalFloat64Array(value []byte, array *[]JsonFloat64, o *jsonOptions) error {
 	var err error
 	arrayHandler := func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
 		if err != nil {
 			return // Stop processing if there is an error.
 		}
-		if dataType == jsonparser.String || dataType == jsonparser.Number {
+		if dataType == jsonparser.Null && o.nanAsNull {
+			*array = append(*array, JsonFloat64{F: math.NaN()})
+		} else if dataType == jsonparser.String || dataType == jsonparser.Number {
 			var floatValue float64
 			floatValue, err = strconv.ParseFloat(string(value), 64)
 			if err != nil {
@@ -1183,47 +950,39 @@ func unmarshalStringArray(value []byte, array *[]string) error {
 	return err
 }
 
-func unmarshalTimeArray(value []byte, array *[]Time) error {
+func unmarshalTimeArray(value []byte, array *[]Time, field *libgengo.Field, o *jsonOptions) error {
 	var err error
 	arrayHandler := func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
 		if err != nil {
 			return // Stop processing if there is an error.
 		}
-		if dataType == jsonparser.Object {
-			sec, nsec, err := unmarshalSecNSecObject(value)
-			if err != nil {
-				return
-			}
-			*array = append(*array, NewTime(sec, nsec))
-		} else {
-			err = errors.New("unexpected type, expecting time object")
+		var item interface{}
+		if err = o.unmarshalTemporal(value, dataType, field, &item); err != nil {
+			return
 		}
+		*array = append(*array, item.(Time))
 	}
 	jsonparser.ArrayEach(value, arrayHandler)
 	return err
 }
 
-func unmarshalDurationArray(value []byte, array *[]Duration) error {
+func unmarshalDurationArray(value []byte, array *[]Duration, field *libgengo.Field, o *jsonOptions) error {
 	var err error
 	arrayHandler := func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
 		if err != nil {
 			return // Stop processing if there is an error.
 		}
-		if dataType == jsonparser.Object {
-			sec, nsec, err := unmarshalSecNSecObject(value)
-			if err != nil {
-				return
-			}
-			*array = append(*array, NewDuration(sec, nsec))
-		} else {
-			err = errors.New("unexpected type, expecting duration object")
+		var item interface{}
+		if err = o.unmarshalTemporal(value, dataType, field, &item); err != nil {
+			return
 		}
+		*array = append(*array, item.(Duration))
 	}
 	jsonparser.ArrayEach(value, arrayHandler)
 	return err
 }
 
-func unmarshalMessageArray(value []byte, array *[]Message, msgType *DynamicMessageType) error {
+func unmarshalMessageArray(value []byte, array *[]Message, msgType *DynamicMessageType, o *jsonOptions) error {
 	var err error
 	arrayHandler := func(value []byte, dataType jsonparser.ValueType, offset int, _ error) {
 		if err != nil {
@@ -1231,7 +990,7 @@ func unmarshalMessageArray(value []byte, array *[]Message, msgType *DynamicMessa
 		}
 		if dataType == jsonparser.Object {
 			msg := msgType.NewDynamicMessage()
-			if err = msg.UnmarshalJSON(value); err != nil {
+			if err = msg.unmarshalJSON(value, o); err != nil {
 				return
 			}
 			*array = append(*array, msg)
@@ -1245,4 +1004,173 @@ func unmarshalMessageArray(value []byte, array *[]Message, msgType *DynamicMessa
 
 // DEFINE PRIVATE RECEIVER FUNCTIONS.
 
+// marshalJSON marshals the message with the specified options.
+func (m *DynamicMessage) marshalJSON(o *jsonOptions) ([]byte, error) {
+	// Confirm the pointers are valid.
+	if err := messagePointerError(m); err != nil {
+		return nil, err
+	}
+	if err := m.decodeLazyFields(); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, m.dynamicType.jsonPrealloc)
+
+	buf = append(buf, byte('{'))
+	count := 0
+	for _, field := range m.dynamicType.spec.Fields {
+		v, ok := m.data[field.Name]
+		if !ok {
+			return nil, errors.Wrap(errors.New("key not in data"), "key: "+field.Name)
+		}
+		if o.omitEmptyArrays && field.IsArray && isEmptySlice(v) {
+			continue
+		}
+
+		// Marshal the JSON name key.
+		if count > 0 {
+			buf = append(buf, byte(','))
+		}
+		count++
+		buf = strconv.AppendQuote(buf, o.fieldName(field.Name))
+		buf = append(buf, byte(':'))
+
+		// Marshal the value.
+		var err error
+		if field.IsArray {
+			err = marshalArrayValue(&field, v, &buf, o)
+		} else {
+			err = marshalSingularValue(&field, v, &buf, o)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "field: "+field.Name)
+		}
+	}
+
+	buf = append(buf, byte('}'))
+
+	if length := len(buf); length > m.dynamicType.jsonPrealloc {
+		m.dynamicType.jsonPrealloc = length
+	}
+
+	return buf, nil
+}
+
+// unmarshalJSON unmarshals a JSON message with the specified options.  Field values are accepted in either their default form or the form selected by the options.
+func (m *DynamicMessage) unmarshalJSON(buf []byte, o *jsonOptions) (err error) {
+	// Confirm the pointers are valid.
+	if err := messagePointerError(m); err != nil {
+		return err
+	}
+	// Fields which aren't in the JSON keep their current values, so must be decoded first.
+	if err := m.decodeLazyFields(); err != nil {
+		return err
+	}
+
+	// JSON unmarshalling. Iterates and executes the callback for each item found in buf.
+	return jsonparser.ObjectEach(buf, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+		fieldExists := false
+		var field libgengo.Field
+
+		// Find message spec field that matches JSON key, which may be the field's name as defined or as converted for the options.
+		keyString := string(key)
+		for _, specField := range m.dynamicType.spec.Fields {
+			if keyString == specField.Name || keyString == o.fieldName(specField.Name) {
+				field = specField
+				fieldExists = true
+			}
+		}
+		if fieldExists == false {
+			return errors.New("Field Unknown: " + string(key))
+		}
+		if !field.IsArray && (field.BuiltInType == libgengo.Time || field.BuiltInType == libgengo.Duration) && dataType != jsonparser.Null {
+			// Times and durations may be objects, numbers or strings, depending on the options.
+			var result interface{}
+			if err := o.unmarshalTemporal(value, dataType, &field, &result); err != nil {
+				return errors.Wrap(err, "field: "+field.Name)
+			}
+			m.data[field.Name] = result
+			return nil
+		}
+		switch dataType {
+
+		case jsonparser.String:
+			if field.IsArray && field.BuiltInType != libgengo.Uint8 {
+				return errors.Wrap(errors.New("attempted to unmarshal array as singular"), "field: "+field.Name+" value: "+string(value))
+			}
+			if !field.IsArray && isIntegerGoType(field.GoType) {
+				// Integer fields may hold the symbolic name of one of the message's constants.
+				result, ok := m.dynamicType.lookupConstant(&field, string(value))
+				if !ok {
+					return errors.Wrap(errors.New("unknown constant "+string(value)), "field: "+field.Name)
+				}
+				m.data[field.Name] = result
+				return nil
+			}
+			var result interface{}
+			if err := unmarshalString(value, &field, &result); err != nil {
+				return errors.Wrap(err, "field: "+field.Name)
+			}
+			m.data[field.Name] = result
+
+		case jsonparser.Number: // We have a JSON number; expect a float or integer.
+			if field.IsArray {
+				return errors.Wrap(errors.New("attempted to unmarshal array as singular"), "field: "+field.Name+" value: "+string(value))
+			}
+			var result interface{}
+			if err := unmarshalNumber(value, &field, &result); err != nil {
+				return errors.Wrap(err, "field: "+field.Name)
+			}
+			m.data[field.Name] = result
+
+		case jsonparser.Boolean:
+			if field.IsArray {
+				return errors.Wrap(errors.New("attempted to unmarshal array as singular"), "field: "+field.Name+" value: "+string(value))
+			}
+			if field.BuiltInType != libgengo.Bool {
+				return errors.Wrap(errors.New("attempted to parse "+field.Type+" as bool"), "field: "+field.Name+" value: "+string(value))
+			}
+			value, err := jsonparser.ParseBoolean(value)
+			if err != nil {
+				return errors.Wrap(err, "field: "+field.Name)
+			}
+			m.data[field.Name] = value
+
+		case jsonparser.Object:
+			if field.IsArray {
+				return errors.Wrap(errors.New("attempted to unmarshal array as singular"), "field: "+field.Name+" value: "+string(value))
+			}
+			var result interface{}
+			if err := unmarshalObject(m.dynamicType, value, &field, &result, o); err != nil {
+				return errors.Wrap(err, "field: "+field.Name)
+			}
+			m.data[field.Name] = result
+
+		case jsonparser.Array:
+			if field.IsArray == false {
+				return errors.Wrap(errors.New("attempted to unmarshal singular as array"), "field: "+field.Name+" value: "+string(value))
+			}
+			var result interface{}
+			if err := unmarshalArray(m.dynamicType, value, &field, &result, o); err != nil {
+				return errors.Wrap(err, "field: "+field.Name)
+			}
+			m.data[field.Name] = result
+
+		case jsonparser.Null:
+			// Null floats are NaN if the options say so; otherwise null fields are ignored.
+			if o.nanAsNull && !field.IsArray && field.BuiltInType == libgengo.Float32 {
+				m.data[field.Name] = JsonFloat32{F: float32(math.NaN())}
+			} else if o.nanAsNull && !field.IsArray && field.BuiltInType == libgengo.Float64 {
+				m.data[field.Name] = JsonFloat64{F: math.NaN()}
+			}
+
+		default:
+			// We do nothing here as blank fields may return value type NotExist or Null
+			return errors.Wrap(err, "Null field: "+string(key))
+		}
+
+		return err
+	})
+}
+
 // ALL DONE.
@@ -0,0 +1,285 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// JSONOption configures how DynamicMessage.MarshalJSONWithOptions() and DynamicMessage.UnmarshalJSONWithOptions() represent messages.  With no options the JSON is the same as
+// MarshalJSON() and UnmarshalJSON().
+type JSONOption func(*jsonOptions)
+
+// JSONTimeFormat selects how time and duration fields are represented in JSON.
+type JSONTimeFormat int
+
+// JSONFieldNameCase selects how the names of message fields are converted to JSON keys.
+type JSONFieldNameCase int
+
+// DEFINE PRIVATE STRUCTURES.
+
+// jsonOptions holds the configuration built from a list of JSONOptions.
+type jsonOptions struct {
+	uint8ArraysAsNumbers bool
+	timeFormat           JSONTimeFormat
+	nanAsNull            bool
+	fieldNameCase        JSONFieldNameCase
+	omitEmptyArrays      bool
+}
+
+// DEFINE PUBLIC GLOBALS.
+
+const (
+	// JSONTimeSecNSec represents times and durations as {"sec":n,"nsec":n} objects.  This is the default.
+	JSONTimeSecNSec JSONTimeFormat = iota
+	// JSONTimeSeconds represents times and durations as numbers of seconds, written with at most nine decimal places so that no precision is lost.
+	JSONTimeSeconds
+	// JSONTimeRFC3339 represents times as RFC 3339 strings in UTC with nanosecond precision, e.g. "2020-01-02T03:04:05.5Z".  Durations are represented as for JSONTimeSeconds.
+	JSONTimeRFC3339
+)
+
+const (
+	// JSONFieldNamesAsDefined uses field names as they appear in the message definition, normally snake_case.  This is the default.
+	JSONFieldNamesAsDefined JSONFieldNameCase = iota
+	// JSONFieldNamesCamelCase converts snake_case field names to camelCase, e.g. frame_id to frameId.
+	JSONFieldNamesCamelCase
+	// JSONFieldNamesPascalCase converts snake_case field names to PascalCase, e.g. frame_id to FrameId.
+	JSONFieldNamesPascalCase
+)
+
+// DEFINE PRIVATE GLOBALS.
+
+// defaultJSONOptions are used by MarshalJSON() and UnmarshalJSON().
+var defaultJSONOptions = &jsonOptions{}
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// WithUint8ArraysAsNumbers represents uint8[] and char[] fields as arrays of numbers rather than base64 strings.
+func WithUint8ArraysAsNumbers() JSONOption {
+	return func(o *jsonOptions) {
+		o.uint8ArraysAsNumbers = true
+	}
+}
+
+// WithJSONTimeFormat selects how time and duration fields are represented.
+func WithJSONTimeFormat(format JSONTimeFormat) JSONOption {
+	return func(o *jsonOptions) {
+		o.timeFormat = format
+	}
+}
+
+// WithNaNAsNull represents NaN and infinite float values as null, rather than the strings "nan", "+inf" and "-inf".  When unmarshalling null float values become NaN.
+func WithNaNAsNull() JSONOption {
+	return func(o *jsonOptions) {
+		o.nanAsNull = true
+	}
+}
+
+// WithJSONFieldNameCase selects how field names are converted to JSON keys.  When unmarshalling keys may be either the converted names or the names as defined.
+func WithJSONFieldNameCase(nameCase JSONFieldNameCase) JSONOption {
+	return func(o *jsonOptions) {
+		o.fieldNameCase = nameCase
+	}
+}
+
+// WithOmitEmptyArrays leaves variable length array fields with no elements out of the JSON.  When unmarshalling missing fields keep their current values, as always.
+func WithOmitEmptyArrays() JSONOption {
+	return func(o *jsonOptions) {
+		o.omitEmptyArrays = true
+	}
+}
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// MarshalJSONWithOptions marshals the message payload as MarshalJSON() does, but with the representation of fields configured by the options.
+func (m *DynamicMessage) MarshalJSONWithOptions(options ...JSONOption) ([]byte, error) {
+	return m.marshalJSON(newJSONOptions(options))
+}
+
+// UnmarshalJSONWithOptions unmarshals JSON produced by MarshalJSONWithOptions() with the same options.  Values in the default representation are accepted too.
+func (m *DynamicMessage) UnmarshalJSONWithOptions(buf []byte, options ...JSONOption) error {
+	return m.unmarshalJSON(buf, newJSONOptions(options))
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// newJSONOptions applies a list of JSONOptions to the default configuration.
+func newJSONOptions(options []JSONOption) *jsonOptions {
+	if len(options) == 0 {
+		return defaultJSONOptions
+	}
+	o := &jsonOptions{}
+	for _, option := range options {
+		option(o)
+	}
+	return o
+}
+
+// isEmptySlice returns true if the value is a slice with no elements.
+func isEmptySlice(v interface{}) bool {
+	value := reflect.ValueOf(v)
+	return value.Kind() == reflect.Slice && value.Len() == 0
+}
+
+// appendSeconds appends a number of nanoseconds to a JSON buffer as an exact decimal number of seconds.
+func appendSeconds(buf []byte, nsec int64) []byte {
+	if nsec < 0 {
+		buf = append(buf, byte('-'))
+		nsec = -nsec
+	}
+	buf = strconv.AppendInt(buf, nsec/1e9, 10)
+	if fraction := nsec % 1e9; fraction != 0 {
+		digits := strconv.FormatInt(1e9+fraction, 10)[1:]
+		buf = append(buf, byte('.'))
+		buf = append(buf, strings.TrimRight(digits, "0")...)
+	}
+	return buf
+}
+
+// parseSeconds parses a JSON number of seconds into nanoseconds.  Plain decimals are parsed exactly; numbers with exponents are parsed as floats.
+func parseSeconds(value []byte) (int64, error) {
+	text := string(value)
+	if strings.ContainsAny(text, "eE") {
+		seconds, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return 0, err
+		}
+		return int64(math.Round(seconds * 1e9)), nil
+	}
+
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+	whole, fraction := text, ""
+	if dot := strings.IndexByte(text, '.'); dot >= 0 {
+		whole, fraction = text[:dot], text[dot+1:]
+	}
+	sec, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	// Nanoseconds beyond the ninth decimal place are truncated.
+	if len(fraction) > 9 {
+		fraction = fraction[:9]
+	}
+	var nsec int64
+	if fraction != "" {
+		if nsec, err = strconv.ParseInt(fraction+strings.Repeat("0", 9-len(fraction)), 10, 64); err != nil {
+			return 0, err
+		}
+	}
+	total := sec*1e9 + nsec
+	if negative {
+		total = -total
+	}
+	return total, nil
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// fieldName returns the JSON key for a field.
+func (o *jsonOptions) fieldName(name string) string {
+	if o.fieldNameCase == JSONFieldNamesAsDefined {
+		return name
+	}
+	words := strings.Split(name, "_")
+	for i, word := range words {
+		if word == "" || (i == 0 && o.fieldNameCase == JSONFieldNamesCamelCase) {
+			continue
+		}
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, "")
+}
+
+// marshalFloat appends a float, with NaN and infinities represented as configured.
+func (o *jsonOptions) marshalFloat(f float64, buf *[]byte, bits int) {
+	if o.nanAsNull && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		*buf = append(*buf, []byte("null")...)
+		return
+	}
+	marshalFloat(f, buf, bits)
+}
+
+// marshalTime appends a time in the configured format.
+func (o *jsonOptions) marshalTime(t Time, buf *[]byte) {
+	switch o.timeFormat {
+	case JSONTimeSeconds:
+		*buf = appendSeconds(*buf, int64(t.Sec)*1e9+int64(t.NSec))
+	case JSONTimeRFC3339:
+		*buf = append(*buf, byte('"'))
+		*buf = time.Unix(int64(t.Sec), int64(t.NSec)).UTC().AppendFormat(*buf, time.RFC3339Nano)
+		*buf = append(*buf, byte('"'))
+	default:
+		marshalSecNSec(uint64(t.Sec), uint64(t.NSec), buf)
+	}
+}
+
+// marshalDuration appends a duration in the configured format.  Durations are signed, so their seconds are negative in the seconds formats when the top bit is set.
+func (o *jsonOptions) marshalDuration(d Duration, buf *[]byte) {
+	switch o.timeFormat {
+	case JSONTimeSeconds, JSONTimeRFC3339:
+		*buf = appendSeconds(*buf, int64(int32(d.Sec))*1e9+int64(d.NSec))
+	default:
+		marshalSecNSec(uint64(d.Sec), uint64(d.NSec), buf)
+	}
+}
+
+// unmarshalTemporal unmarshals a time or duration field, or element of an array field, from {"sec","nsec"} objects or the configured format.
+func (o *jsonOptions) unmarshalTemporal(value []byte, dataType jsonparser.ValueType, field *libgengo.Field, dest *interface{}) error {
+	isTime := field.BuiltInType == libgengo.Time
+	var sec, nsec uint32
+	switch {
+	case dataType == jsonparser.Object:
+		var err error
+		if sec, nsec, err = unmarshalSecNSecObject(value); err != nil {
+			return err
+		}
+
+	case dataType == jsonparser.Number && o.timeFormat != JSONTimeSecNSec && (!isTime || o.timeFormat == JSONTimeSeconds):
+		total, err := parseSeconds(value)
+		if err != nil {
+			return err
+		}
+		// Whole seconds are rounded down, so that nanoseconds are always positive.
+		wholeSec, fraction := total/1e9, total%1e9
+		if fraction < 0 {
+			wholeSec, fraction = wholeSec-1, fraction+1e9
+		}
+		if (isTime && (wholeSec < 0 || wholeSec > math.MaxUint32)) || (!isTime && (wholeSec < math.MinInt32 || wholeSec > math.MaxInt32)) {
+			return errors.New(field.Type + " out of range: " + string(value))
+		}
+		sec, nsec = uint32(wholeSec), uint32(fraction)
+
+	case dataType == jsonparser.String && isTime && o.timeFormat == JSONTimeRFC3339:
+		parsed, err := time.Parse(time.RFC3339Nano, string(value))
+		if err != nil {
+			return err
+		}
+		if parsed.Unix() < 0 || parsed.Unix() > math.MaxUint32 {
+			return errors.New(field.Type + " out of range: " + string(value))
+		}
+		sec, nsec = uint32(parsed.Unix()), uint32(parsed.Nanosecond())
+
+	default:
+		return errors.New("unexpected type, expecting " + field.Type)
+	}
+
+	if isTime {
+		*dest = NewTime(sec, nsec)
+	} else {
+		*dest = NewDuration(sec, nsec)
+	}
+	return nil
+}
+
+// ALL DONE.
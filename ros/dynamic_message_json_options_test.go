@@ -0,0 +1,137 @@
+package ros
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func newJSONOptionsTestMessage(t *testing.T) *DynamicMessage {
+	msgType := newRegisteredTestType(t, "test_msgs/Options", "time stamp\nduration period\ntime[] stamps\nuint8[] data\nfloat64[] values\nfloat32 scale\nint16[] empty\nint32 max_count")
+	msg, err := msgType.NewDynamicMessageFromMap(map[string]interface{}{
+		"stamp":     NewTime(1577934245, 500000000),
+		"period":    Duration{temporal{Sec: uint32(0xffffffff), NSec: 750000000}},
+		"stamps":    []interface{}{NewTime(1, 5), NewTime(2, 0)},
+		"data":      []byte{1, 2, 255},
+		"values":    []interface{}{1.5, math.NaN(), math.Inf(-1)},
+		"scale":     float32(math.Inf(1)),
+		"max_count": 7,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestDynamicMessage_MarshalJSONWithOptions(t *testing.T) {
+	msg := newJSONOptionsTestMessage(t)
+
+	testCases := []struct {
+		options  []JSONOption
+		expected string
+	}{
+		{
+			nil,
+			`{"stamp":{"sec":1577934245,"nsec":500000000},"period":{"sec":4294967295,"nsec":750000000},"stamps":[{"sec":1,"nsec":5},{"sec":2,"nsec":0}],"data":"AQL/","values":[1.5,"nan","-inf"],"scale":"+inf","empty":[],"max_count":7}`,
+		},
+		{
+			[]JSONOption{WithUint8ArraysAsNumbers(), WithNaNAsNull(), WithOmitEmptyArrays(), WithJSONFieldNameCase(JSONFieldNamesCamelCase)},
+			`{"stamp":{"sec":1577934245,"nsec":500000000},"period":{"sec":4294967295,"nsec":750000000},"stamps":[{"sec":1,"nsec":5},{"sec":2,"nsec":0}],"data":[1,2,255],"values":[1.5,null,null],"scale":null,"maxCount":7}`,
+		},
+		{
+			[]JSONOption{WithJSONTimeFormat(JSONTimeSeconds), WithJSONFieldNameCase(JSONFieldNamesPascalCase)},
+			`{"Stamp":1577934245.5,"Period":-0.25,"Stamps":[1.000000005,2],"Data":"AQL/","Values":[1.5,"nan","-inf"],"Scale":"+inf","Empty":[],"MaxCount":7}`,
+		},
+		{
+			[]JSONOption{WithJSONTimeFormat(JSONTimeRFC3339)},
+			`{"stamp":"2020-01-02T03:04:05.5Z","period":-0.25,"stamps":["1970-01-01T00:00:01.000000005Z","1970-01-01T00:00:02Z"],"data":"AQL/","values":[1.5,"nan","-inf"],"scale":"+inf","empty":[],"max_count":7}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		buf, err := msg.MarshalJSONWithOptions(testCase.options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != testCase.expected {
+			t.Fatalf("expected %s, got %s", testCase.expected, buf)
+		}
+
+		// And back again.  Infinities marshalled as null come back as NaN.
+		result := msg.dynamicType.NewDynamicMessage()
+		if err := result.UnmarshalJSONWithOptions(buf, testCase.options...); err != nil {
+			t.Fatal(err)
+		}
+		expected := msg.Clone()
+		if bytes.Contains(buf, []byte("null")) {
+			expected.data["scale"] = JsonFloat32{F: float32(math.NaN())}
+			expected.data["values"] = []JsonFloat64{{F: 1.5}, {F: math.NaN()}, {F: math.NaN()}}
+		}
+		if diff, err := expected.Diff(result, WithNaNEqual()); err != nil || len(diff) != 0 {
+			t.Fatalf("%s: unmarshalled message differs: %v, %v", buf, diff, err)
+		}
+	}
+}
+
+func TestDynamicMessage_UnmarshalJSONWithOptions(t *testing.T) {
+	msgType := newJSONOptionsTestMessage(t).dynamicType
+
+	// The default representation is accepted whatever the options, as are field names as defined.
+	msg := msgType.NewDynamicMessage()
+	json := []byte(`{"stamp":{"sec":3,"nsec":4},"stamps":[1.25],"data":"AQI=","max_count":1,"maxCount":2}`)
+	if err := msg.UnmarshalJSONWithOptions(json, WithJSONTimeFormat(JSONTimeSeconds), WithJSONFieldNameCase(JSONFieldNamesCamelCase)); err != nil {
+		t.Fatal(err)
+	}
+	if stamp, _ := msg.Get("stamp"); stamp != NewTime(3, 4) {
+		t.Fatalf("unexpected stamp %v", stamp)
+	}
+	if stamps, _ := msg.Get("stamps"); stamps.([]Time)[0] != NewTime(1, 250000000) {
+		t.Fatalf("unexpected stamps %v", stamps)
+	}
+	if count, _ := msg.GetInt64("max_count"); count != 2 {
+		t.Fatalf("expected max_count 2, got %v", count)
+	}
+
+	// Exponents are allowed, and fractions beyond nanoseconds are truncated.
+	if err := msg.UnmarshalJSONWithOptions([]byte(`{"stamp":1.5e1,"period":-1.0000000019}`), WithJSONTimeFormat(JSONTimeSeconds)); err != nil {
+		t.Fatal(err)
+	}
+	if stamp, _ := msg.Get("stamp"); stamp != NewTime(15, 0) {
+		t.Fatalf("unexpected stamp %v", stamp)
+	}
+	if period, _ := msg.Get("period"); period != NewDuration(uint32(0xfffffffe), 999999999) {
+		t.Fatalf("unexpected period %v", period)
+	}
+
+	errorCases := []struct {
+		json    string
+		options []JSONOption
+	}{
+		{`{"stamp":1}`, nil},
+		{`{"stamp":"1970-01-01T00:00:01Z"}`, []JSONOption{WithJSONTimeFormat(JSONTimeSeconds)}},
+		{`{"stamp":1}`, []JSONOption{WithJSONTimeFormat(JSONTimeRFC3339)}},
+		{`{"stamp":"1969-12-31T23:59:59Z"}`, []JSONOption{WithJSONTimeFormat(JSONTimeRFC3339)}},
+		{`{"stamp":-1}`, []JSONOption{WithJSONTimeFormat(JSONTimeSeconds)}},
+		{`{"period":"1s"}`, []JSONOption{WithJSONTimeFormat(JSONTimeRFC3339)}},
+		{`{"period":3000000000}`, []JSONOption{WithJSONTimeFormat(JSONTimeSeconds)}},
+		{`{"stamps":[{"sec":1}]}`, nil},
+		{`{"MaxCount":1}`, []JSONOption{WithJSONFieldNameCase(JSONFieldNamesCamelCase)}},
+	}
+	for _, errorCase := range errorCases {
+		if err := msgType.NewDynamicMessage().UnmarshalJSONWithOptions([]byte(errorCase.json), errorCase.options...); err == nil {
+			t.Errorf("%s: expected error", errorCase.json)
+		}
+	}
+
+	// Without the option, null floats are ignored.
+	msg = msgType.NewDynamicMessage()
+	if err := msg.UnmarshalJSON([]byte(`{"scale":null,"values":[1]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if scale, _ := msg.GetFloat64("scale"); scale != 0 {
+		t.Fatalf("expected scale 0, got %v", scale)
+	}
+	if err := msg.UnmarshalJSON([]byte(`{"values":[null]}`)); err == nil {
+		t.Fatal("expected error for null array element")
+	}
+}
@@ -0,0 +1,260 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// jsonSchemaBuilder generates the schemas of messages, collecting the definitions of the nested message types they use so that each is only described once.
+type jsonSchemaBuilder struct {
+	defs map[string]interface{}
+}
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// jsonSchemaDialect is the version of JSON Schema generated; $defs requires at least draft 2019-09.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaIntegerBounds are the minimum and maximum values of each ROS integer type.
+var jsonSchemaIntegerBounds = map[string][2]interface{}{
+	"int8":   {math.MinInt8, math.MaxInt8},
+	"int16":  {math.MinInt16, math.MaxInt16},
+	"int32":  {math.MinInt32, math.MaxInt32},
+	"int64":  {int64(math.MinInt64), int64(math.MaxInt64)},
+	"uint8":  {0, math.MaxUint8},
+	"uint16": {0, math.MaxUint16},
+	"uint32": {0, uint32(math.MaxUint32)},
+	"uint64": {0, uint64(math.MaxUint64)},
+}
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+//	DynamicMessageType
+
+// GenerateJSONSchema generates a JSON schema (draft 2020-12) for the associated DynamicMessageType, describing exactly the JSON produced by DynamicMessage.MarshalJSON(); however
+// note that since we are mostly interested in making schema's for particular _topics_, the function takes a string prefix, and string topic name, which are used to id the resulting
+// schema.  Nested message types are described once each under "$defs", keyed by their full type names.
+func (t *DynamicMessageType) GenerateJSONSchema(prefix string, topic string) ([]byte, error) {
+	b := &jsonSchemaBuilder{defs: make(map[string]interface{})}
+	schema, err := b.messageSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	return b.document(prefix+topic, schema)
+}
+
+//	DynamicServiceType
+
+// GenerateJSONSchema generates a JSON schema for the associated DynamicServiceType, in the same way as DynamicMessageType.GenerateJSONSchema().  The schema describes an object with
+// "request" and "response" properties, so that requests can be validated against the subschema "<prefix><name>#/properties/request", and responses likewise.
+func (t *DynamicServiceType) GenerateJSONSchema(prefix string, name string) ([]byte, error) {
+	return generateJSONSchemaForTypes(prefix+name, []string{"request", "response"}, []MessageType{t.reqType, t.resType})
+}
+
+//	DynamicActionType
+
+// GenerateJSONSchema generates a JSON schema for the associated DynamicActionType, in the same way as DynamicMessageType.GenerateJSONSchema().  The schema describes an object with
+// "goal", "feedback" and "result" properties, holding the action messages sent on the action's goal, feedback and result topics, e.g. "<prefix><name>#/properties/goal".
+func (t *DynamicActionType) GenerateJSONSchema(prefix string, name string) ([]byte, error) {
+	types := []MessageType{nil, nil, nil}
+	if goalType, ok := t.goalType.(*DynamicActionGoalType); ok {
+		types[0] = &goalType.DynamicMessageType
+	}
+	if feedbackType, ok := t.feedbackType.(*DynamicActionFeedbackType); ok {
+		types[1] = &feedbackType.DynamicMessageType
+	}
+	if resultType, ok := t.resultType.(*DynamicActionResultType); ok {
+		types[2] = &resultType.DynamicMessageType
+	}
+	return generateJSONSchemaForTypes(prefix+name, []string{"goal", "feedback", "result"}, types)
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// generateJSONSchemaForTypes generates a schema for an object whose properties are messages of the specified types, as used for services and actions.
+func generateJSONSchemaForTypes(id string, names []string, types []MessageType) ([]byte, error) {
+	b := &jsonSchemaBuilder{defs: make(map[string]interface{})}
+	properties := make(map[string]interface{})
+	for i, name := range names {
+		msgType, ok := types[i].(*DynamicMessageType)
+		if !ok || msgType == nil {
+			return nil, errors.New("Schema Property: " + name + " is not a dynamic message type")
+		}
+		ref, err := b.messageRef(msgType)
+		if err != nil {
+			return nil, errors.Wrap(err, "Schema Property: "+name)
+		}
+		properties[name] = ref
+	}
+	return b.document(id, map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             names,
+		"additionalProperties": false,
+	})
+}
+
+// jsonSchemaPointer escapes a key for use in a JSON pointer, such as a $ref, as described by RFC 6901.
+func jsonSchemaPointer(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// document adds the identifying keywords and the collected definitions to a root schema, and marshals it.
+func (b *jsonSchemaBuilder) document(id string, schema map[string]interface{}) ([]byte, error) {
+	schema["$schema"] = jsonSchemaDialect
+	schema["$id"] = id
+	if len(b.defs) > 0 {
+		schema["$defs"] = b.defs
+	}
+	return json.Marshal(schema)
+}
+
+// messageRef returns a reference to the definition of a message type, adding the definition if it hasn't been seen before.
+func (b *jsonSchemaBuilder) messageRef(t *DynamicMessageType) (map[string]interface{}, error) {
+	name := t.Name()
+	if _, ok := b.defs[name]; !ok {
+		// Reserve the name first; ROS messages can't be recursive, but there's no need to rely on that.
+		b.defs[name] = nil
+		schema, err := b.messageSchema(t)
+		if err != nil {
+			delete(b.defs, name)
+			return nil, err
+		}
+		b.defs[name] = schema
+	}
+	return map[string]interface{}{"$ref": "#/$defs/" + jsonSchemaPointer(name)}, nil
+}
+
+// messageSchema returns the schema of a message: an object with a property for each field.  Fields may be missing when unmarshalling, but no others are allowed.
+func (b *jsonSchemaBuilder) messageSchema(t *DynamicMessageType) (map[string]interface{}, error) {
+	if t.spec == nil {
+		return nil, errors.New("incomplete message type " + t.Name())
+	}
+	properties := make(map[string]interface{})
+	for _, field := range t.spec.Fields {
+		schema, err := b.fieldSchema(t, &field)
+		if err != nil {
+			return nil, errors.Wrap(err, "Schema Field: "+field.Name)
+		}
+		properties[field.Name] = schema
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}, nil
+}
+
+// fieldSchema returns the schema of a field of a message.
+func (b *jsonSchemaBuilder) fieldSchema(t *DynamicMessageType, field *libgengo.Field) (map[string]interface{}, error) {
+	if !field.IsArray {
+		return b.elementSchema(t, field)
+	}
+
+	// Byte arrays are base64 encoded strings, whose length is known if the array's is.
+	if field.IsBuiltin && field.GoType == "uint8" {
+		schema := map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		if field.ArrayLen >= 0 {
+			length := base64.StdEncoding.EncodedLen(field.ArrayLen)
+			schema["minLength"] = length
+			schema["maxLength"] = length
+		}
+		return schema, nil
+	}
+
+	items, err := b.elementSchema(t, field)
+	if err != nil {
+		return nil, err
+	}
+	schema := map[string]interface{}{"type": "array", "items": items}
+	if field.ArrayLen >= 0 {
+		schema["minItems"] = field.ArrayLen
+		schema["maxItems"] = field.ArrayLen
+	}
+	return schema, nil
+}
+
+// elementSchema returns the schema of a singular field, or of each element of an array field.
+func (b *jsonSchemaBuilder) elementSchema(t *DynamicMessageType, field *libgengo.Field) (map[string]interface{}, error) {
+	if !field.IsBuiltin {
+		msgType, err := t.getNestedTypeFromField(field)
+		if err != nil {
+			return nil, err
+		}
+		return b.messageRef(msgType)
+	}
+
+	switch field.GoType {
+	case "bool":
+		return map[string]interface{}{"type": "boolean"}, nil
+	case "string":
+		return map[string]interface{}{"type": "string"}, nil
+	case "float32", "float64":
+		// Non-finite values are marshalled as strings.
+		return map[string]interface{}{"anyOf": []interface{}{
+			map[string]interface{}{"type": "number"},
+			map[string]interface{}{"enum": []string{"nan", "+inf", "-inf"}},
+		}}, nil
+	case "ros.Time", "ros.Duration":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"sec":  map[string]interface{}{"type": "integer", "minimum": 0, "maximum": uint32(math.MaxUint32)},
+				"nsec": map[string]interface{}{"type": "integer", "minimum": 0, "maximum": 999999999},
+			},
+			"required":             []string{"sec", "nsec"},
+			"additionalProperties": false,
+		}, nil
+	}
+
+	bounds, ok := jsonSchemaIntegerBounds[field.GoType]
+	if !ok {
+		// Something went wrong.
+		return nil, errors.New("we haven't implemented this primitive yet: " + field.GoType)
+	}
+	schema := map[string]interface{}{"type": "integer", "minimum": bounds[0], "maximum": bounds[1]}
+	t.addJSONSchemaConstants(field, schema)
+	return schema, nil
+}
+
+//	DynamicMessageType
+
+// addJSONSchemaConstants annotates the schema of an integer field with the message constants of the same type, so that user interfaces can show the symbolic names of values.  The
+// constants are hints rather than restrictions, since a message's constants needn't cover every value of every field of that type.
+func (t *DynamicMessageType) addJSONSchemaConstants(field *libgengo.Field, schema map[string]interface{}) {
+	if !isIntegerGoType(field.GoType) {
+		return
+	}
+
+	hints := make([]interface{}, 0)
+	for _, constant := range t.Constants() {
+		if libgengo.ToGoType("", constant.Type) != field.GoType {
+			continue
+		}
+		hints = append(hints, map[string]interface{}{"const": constant.Value, "title": t.spec.ShortName + "." + constant.Name})
+	}
+	if len(hints) == 0 {
+		return
+	}
+	hints = append(hints, map[string]interface{}{"type": "integer"})
+	schema["anyOf"] = hints
+}
+
+// ALL DONE.
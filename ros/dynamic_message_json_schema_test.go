@@ -0,0 +1,294 @@
+package ros
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// validateJSONSchema checks a decoded JSON value against the subset of JSON Schema generated by GenerateJSONSchema().
+func validateJSONSchema(root map[string]interface{}, schema map[string]interface{}, value interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.Replace(strings.TrimPrefix(ref, "#/$defs/"), "~1", "/", -1)
+		def, ok := root["$defs"].(map[string]interface{})[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: missing definition %s", path, ref)
+		}
+		return validateJSONSchema(root, def, value, path)
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, option := range anyOf {
+			if validateJSONSchema(root, option.(map[string]interface{}), value, path) == nil {
+				matched = true
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: %v matches none of %v", path, value, anyOf)
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		matched := false
+		for _, option := range enum {
+			matched = matched || reflect.DeepEqual(option, value)
+		}
+		if !matched {
+			return fmt.Errorf("%s: %v not in %v", path, value, enum)
+		}
+	}
+	if constant, ok := schema["const"]; ok && !reflect.DeepEqual(constant, value) {
+		return fmt.Errorf("%s: %v is not %v", path, value, constant)
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %v", path, value)
+		}
+		properties := schema["properties"].(map[string]interface{})
+		for key, item := range object {
+			property, ok := properties[key].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s: unexpected property %s", path, key)
+			}
+			if err := validateJSONSchema(root, property, item, path+"/"+key); err != nil {
+				return err
+			}
+		}
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, key := range required {
+				if _, ok := object[key.(string)]; !ok {
+					return fmt.Errorf("%s: missing property %s", path, key)
+				}
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %v", path, value)
+		}
+		if min, ok := schema["minItems"].(float64); ok && float64(len(array)) < min {
+			return fmt.Errorf("%s: too few items", path)
+		}
+		if max, ok := schema["maxItems"].(float64); ok && float64(len(array)) > max {
+			return fmt.Errorf("%s: too many items", path)
+		}
+		for i, item := range array {
+			if err := validateJSONSchema(root, schema["items"].(map[string]interface{}), item, fmt.Sprintf("%s/%d", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string, got %v", path, value)
+		}
+		if min, ok := schema["minLength"].(float64); ok && float64(len(text)) < min {
+			return fmt.Errorf("%s: too short", path)
+		}
+		if max, ok := schema["maxLength"].(float64); ok && float64(len(text)) > max {
+			return fmt.Errorf("%s: too long", path)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %v", path, value)
+		}
+	case "number", "integer":
+		number, ok := value.(float64)
+		if !ok || (schema["type"] == "integer" && number != math.Trunc(number)) {
+			return fmt.Errorf("%s: expected %s, got %v", path, schema["type"], value)
+		}
+		if min, ok := schema["minimum"].(float64); ok && number < min {
+			return fmt.Errorf("%s: %v below minimum %v", path, number, min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && number > max {
+			return fmt.Errorf("%s: %v above maximum %v", path, number, max)
+		}
+	}
+	return nil
+}
+
+// checkJSONSchema parses a generated schema and validates a JSON document against it, or one of its properties.
+func checkJSONSchema(t *testing.T, schema []byte, property string, document []byte) error {
+	var root map[string]interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		t.Fatal(err)
+	}
+	if root["$schema"] != jsonSchemaDialect {
+		t.Fatalf("unexpected dialect %v", root["$schema"])
+	}
+	var value interface{}
+	if err := json.Unmarshal(document, &value); err != nil {
+		t.Fatal(err)
+	}
+	target := root
+	if property != "" {
+		target = root["properties"].(map[string]interface{})[property].(map[string]interface{})
+	}
+	return validateJSONSchema(root, target, value, "")
+}
+
+func TestDynamicMessageType_GenerateJSONSchema(t *testing.T) {
+	registry := NewTypeRegistry("")
+	registry.RegisterMessageDefinition("test_msgs/Everything", strings.Join([]string{
+		"Header header", "bool flag", "int8 i8", "uint8 u8", "int16 i16", "uint16 u16", "int32 i32", "uint32 u32", "int64 i64", "uint64 u64",
+		"float32 f32", "float64 f64", "string text", "duration period", "uint8[] data", "uint8[4] fixed_data", "float64[3] vector",
+		"geometry_msgs/Point[] points", "geometry_msgs/Pose pose",
+	}, "\n"))
+	msgType, err := registry.NewDynamicMessageType("test_msgs/Everything")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := msgType.GenerateJSONSchema("/test", "/everything")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed struct {
+		ID         string                     `json:"$id"`
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       map[string]json.RawMessage `json:"$defs"`
+	}
+	if err := json.Unmarshal(schema, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.ID != "/test/everything" {
+		t.Fatalf("unexpected id %s", parsed.ID)
+	}
+	expectedProperties := map[string]string{
+		"i8":         `{"maximum":127,"minimum":-128,"type":"integer"}`,
+		"u64":        `{"maximum":18446744073709551615,"minimum":0,"type":"integer"}`,
+		"i64":        `{"maximum":9223372036854775807,"minimum":-9223372036854775808,"type":"integer"}`,
+		"flag":       `{"type":"boolean"}`,
+		"f32":        `{"anyOf":[{"type":"number"},{"enum":["nan","+inf","-inf"]}]}`,
+		"data":       `{"contentEncoding":"base64","type":"string"}`,
+		"fixed_data": `{"contentEncoding":"base64","maxLength":8,"minLength":8,"type":"string"}`,
+		"vector":     `{"items":{"anyOf":[{"type":"number"},{"enum":["nan","+inf","-inf"]}]},"maxItems":3,"minItems":3,"type":"array"}`,
+		"points":     `{"items":{"$ref":"#/$defs/geometry_msgs~1Point"},"type":"array"}`,
+		"header":     `{"$ref":"#/$defs/std_msgs~1Header"}`,
+	}
+	for name, expected := range expectedProperties {
+		if string(parsed.Properties[name]) != expected {
+			t.Errorf("%s: expected %s, got %s", name, expected, parsed.Properties[name])
+		}
+	}
+
+	// Each nested type is defined once, including those only used by other nested types.
+	for _, name := range []string{"std_msgs/Header", "geometry_msgs/Point", "geometry_msgs/Pose", "geometry_msgs/Quaternion"} {
+		if _, ok := parsed.Defs[name]; !ok {
+			t.Errorf("expected definition of %s", name)
+		}
+	}
+	if len(parsed.Defs) != 4 {
+		t.Errorf("unexpected definitions %v", parsed.Defs)
+	}
+
+	// Marshalled messages match the schema, including non-finite floats.
+	msg := msgType.NewDynamicMessage()
+	for path, value := range map[string]interface{}{"f64": math.Inf(-1), "f32": math.NaN(), "i64": int64(math.MinInt64), "u64": uint64(math.MaxUint64), "header.stamp": NewTime(math.MaxUint32, 999999999)} {
+		if err := msg.Set(path, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := msg.Set("points", []interface{}{map[string]interface{}{"x": 1}}); err != nil {
+		t.Fatal(err)
+	}
+	marshalled, err := msg.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkJSONSchema(t, schema, "", marshalled); err != nil {
+		t.Fatalf("%s: %v", marshalled, err)
+	}
+	result := msgType.NewDynamicMessage()
+	if err := result.UnmarshalJSON(marshalled); err != nil {
+		t.Fatal(err)
+	}
+	if u64, _ := result.Get("u64"); u64 != uint64(math.MaxUint64) {
+		t.Fatalf("expected max uint64, got %v", u64)
+	}
+
+	errorCases := []string{
+		`{"unknown":1}`,
+		`{"u8":256}`,
+		`{"i16":-32769}`,
+		`{"flag":1}`,
+		`{"f64":"NaN"}`,
+		`{"vector":[1,2]}`,
+		`{"fixed_data":"AQID"}`,
+		`{"period":{"sec":1}}`,
+		`{"period":{"sec":1,"nsec":1000000000}}`,
+		`{"points":[{"x":"1"}]}`,
+		`{"pose":{"orientation":{"w":true}}}`,
+	}
+	for _, document := range errorCases {
+		if err := checkJSONSchema(t, schema, "", []byte(document)); err == nil {
+			t.Errorf("%s: expected schema violation", document)
+		}
+	}
+}
+
+func TestDynamicServiceType_GenerateJSONSchema(t *testing.T) {
+	registry := NewTypeRegistry("")
+	registry.RegisterServiceDefinition("test_msgs/Move", "geometry_msgs/Point target\nuint8 SLOW=0\nuint8 FAST=1\nuint8 speed\n---\nbool success\ngeometry_msgs/Point position")
+	srvType, err := registry.NewDynamicServiceType("test_msgs/Move")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := srvType.GenerateJSONSchema("/srv", "/move")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(schema), `"request":{"$ref":"#/$defs/test_msgs~1MoveRequest"}`) || !strings.Contains(string(schema), `"title":"MoveRequest.FAST"`) {
+		t.Fatalf("unexpected schema %s", schema)
+	}
+
+	if err := checkJSONSchema(t, schema, "request", []byte(`{"target":{"x":1,"y":2,"z":3},"speed":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkJSONSchema(t, schema, "response", []byte(`{"success":true,"position":{"x":1,"y":2,"z":3}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkJSONSchema(t, schema, "request", []byte(`{"success":true}`)); err == nil {
+		t.Fatal("expected response to be rejected as request")
+	}
+	if err := checkJSONSchema(t, schema, "", []byte(`{"request":{}}`)); err == nil {
+		t.Fatal("expected missing response to be rejected")
+	}
+}
+
+func TestDynamicActionType_GenerateJSONSchema(t *testing.T) {
+	registry := NewTypeRegistry("")
+	registry.RegisterActionDefinition("test_msgs/Count", "int32 target\n---\nint32 total\n---\nint32 progress")
+	actionType, err := registry.NewDynamicActionType("test_msgs/Count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := actionType.GenerateJSONSchema("/action", "/count")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The action messages wrap the definition's goal, result and feedback.
+	goal := actionType.GoalType().NewGoalMessage().(*DynamicActionGoal)
+	if err := goal.Set("goal.target", 5); err != nil {
+		t.Fatal(err)
+	}
+	marshalled, err := goal.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkJSONSchema(t, schema, "goal", marshalled); err != nil {
+		t.Fatalf("%s: %v", marshalled, err)
+	}
+	if err := checkJSONSchema(t, schema, "feedback", []byte(`{"feedback":{"progress":1},"status":{"status":1,"goal_id":{"id":"a"}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkJSONSchema(t, schema, "result", []byte(`{"result":{"progress":1}}`)); err == nil {
+		t.Fatal("expected feedback to be rejected as result")
+	}
+}
@@ -960,8 +960,9 @@ func TestDynamicMessage_marshalJSON_nestedWithTypeError(t *testing.T) {
 }
 
 func TestDynamicMessage_marshalJSON_arrayOfNestedMessages(t *testing.T) {
-	// We don't care about Pose in this step, but we want to load libgengo's context.
-	_, err := NewDynamicMessageType("geometry_msgs/Pose")
+	// We don't care about Pose in this step, but we want to load libgengo's context.  A registry of our own is used, so that no types are cached yet.
+	registry := NewTypeRegistry("")
+	_, err := registry.NewDynamicMessageType("geometry_msgs/Pose")
 
 	if err != nil {
 		t.Skip("test skipped because ROS environment not set up")
@@ -973,16 +974,16 @@ func TestDynamicMessage_marshalJSON_arrayOfNestedMessages(t *testing.T) {
 	}
 	msgSpec := generateTestSpec(fields)
 	msgSpec.FullName = "test/x0Message"
-	context.RegisterMsg("test/x0Message", msgSpec)
+	registry.pkgContext.RegisterMsg("test/x0Message", msgSpec)
 
 	fields = []gengo.Field{
 		*gengo.NewField("test", "x0Message", "x", true, 2),
 	}
 	msgSpec = generateTestSpec(fields)
 	msgSpec.FullName = "test/z0Message"
-	context.RegisterMsg("test/z0Message", msgSpec)
+	registry.pkgContext.RegisterMsg("test/z0Message", msgSpec)
 
-	testMessageType, err := NewDynamicMessageType("test/z0Message")
+	testMessageType, err := registry.NewDynamicMessageType("test/z0Message")
 	if err != nil {
 		t.Fatalf("Failed to create testMessageType, error: %v", err)
 	}
@@ -1609,3 +1610,60 @@ func TestDynamicMessage_marshalJSON_invalidPointers(t *testing.T) {
 	}
 
 }
+
+func TestDynamicMessage_JSON_constants(t *testing.T) {
+	msgType := newConstantsTestType(t)
+
+	// Symbolic constant names are accepted for integer fields.
+	msg := msgType.NewDynamicMessage()
+	if err := msg.UnmarshalJSON([]byte(`{"status":"GoalStatus.SUCCEEDED","limit":"LIMIT","text":"NAME"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if msg.data["status"] != uint8(3) || msg.data["limit"] != int32(-5) || msg.data["text"] != "NAME" {
+		t.Fatalf("unexpected data %v", msg.data)
+	}
+	if err := msg.UnmarshalJSON([]byte(`{"status":"UNKNOWN"}`)); err == nil {
+		t.Fatal("expected error for unknown constant")
+	}
+	if err := msg.UnmarshalJSON([]byte(`{"limits":["LIMIT",7,"GoalStatus.LIMIT"]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if limits := msg.data["limits"]; !reflect.DeepEqual(limits, []int32{-5, 7, -5}) {
+		t.Fatalf("expected limits [-5 7 -5], got %#v", limits)
+	}
+	if err := msg.UnmarshalJSON([]byte(`{"limits":[1,"UNKNOWN"]}`)); err == nil {
+		t.Fatal("expected error for unknown constant in array")
+	}
+
+	// The schema lists the constants which apply to each integer field.
+	schema, err := msgType.GenerateJSONSchema("/test", "/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed struct {
+		Properties map[string]struct {
+			Type  string `json:"type"`
+			AnyOf []struct {
+				Const interface{} `json:"const"`
+				Title string      `json:"title"`
+				Type  string      `json:"type"`
+			} `json:"anyOf"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(schema, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	status := parsed.Properties["status"]
+	if status.Type != "integer" || len(status.AnyOf) != 4 {
+		t.Fatalf("unexpected status schema %+v", status)
+	}
+	if status.AnyOf[1].Title != "GoalStatus.SUCCEEDED" || status.AnyOf[1].Const != float64(3) || status.AnyOf[3].Type != "integer" {
+		t.Fatalf("unexpected status hints %+v", status.AnyOf)
+	}
+	if limit := parsed.Properties["limit"]; len(limit.AnyOf) != 2 || limit.AnyOf[0].Title != "GoalStatus.LIMIT" {
+		t.Fatalf("unexpected limit schema %+v", limit)
+	}
+	if text := parsed.Properties["text"]; len(text.AnyOf) != 0 {
+		t.Fatalf("unexpected text schema %+v", text)
+	}
+}
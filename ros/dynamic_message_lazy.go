@@ -0,0 +1,372 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// fieldProjection is the set of fields to decode from a message, mapping each field name to the projection of its nested fields; a nil projection decodes the whole field.
+type fieldProjection map[string]fieldProjection
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+//	DynamicMessageType
+
+// SetLazyDecoding enables or disables lazy decoding of messages of this type.  When enabled, Deserialize() only checks the layout of the message and keeps a copy of its bytes; each
+// top level field is decoded when it is first accessed, and a message which is serialized without being accessed is written out unchanged.  This makes relaying or filtering on a
+// few fields of large messages, such as sensor_msgs/PointCloud2, much cheaper.  As reading a field decodes it into the message, lazily deserialized messages aren't safe for
+// concurrent reads, unlike other messages; call DecodeLazyFields() before sharing one between goroutines.
+func (t *DynamicMessageType) SetLazyDecoding(lazy bool) {
+	t.lazy = lazy
+}
+
+// LazyDecoding returns whether lazy decoding is enabled for messages of this type.
+func (t *DynamicMessageType) LazyDecoding() bool {
+	return t.lazy
+}
+
+//	DynamicMessage
+
+// DecodeLazyFields decodes all remaining fields of a lazily deserialized message, returning the error of the first field which fails to decode; it does nothing for other
+// messages.  Afterwards the message behaves as if it had been deserialized normally, and may be read from several goroutines at once.
+func (m *DynamicMessage) DecodeLazyFields() error {
+	return m.decodeLazyFields()
+}
+
+// DeserializeFields parses a byte stream into a DynamicMessage, decoding only the fields named by paths and skipping over all others using the schema; fields which are skipped
+// are zeroed.  Paths use the same syntax as Get() without array indices, e.g. "header.stamp"; a path through an array of messages selects the field from every element.
+func (m *DynamicMessage) DeserializeFields(buf *bytes.Reader, paths []string) error {
+	if m.dynamicType == nil || m.dynamicType.spec == nil {
+		return errors.New("dynamic message type spec is nil")
+	}
+	if m.dynamicType.nested == nil {
+		return errors.New("dynamic message type nested is nil")
+	}
+
+	projection, err := m.dynamicType.newFieldProjection(paths)
+	if err != nil {
+		return err
+	}
+
+	data, err := m.dynamicType.deserializeProjected(m.dynamicType.ByteDecoder(), buf, projection)
+	if err != nil {
+		return err
+	}
+	m.data = data
+	m.raw = nil
+	m.rawOffsets = nil
+	return nil
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// skipBytes moves the reader forward over the specified number of bytes.
+func skipBytes(buf *bytes.Reader, n int) error {
+	if n < 0 || n > buf.Len() {
+		return errors.New("buffer too short: need " + strconv.Itoa(n) + " bytes, have " + strconv.Itoa(buf.Len()))
+	}
+	_, err := buf.Seek(int64(n), io.SeekCurrent)
+	return err
+}
+
+// builtinSize returns the serialized size of a builtin type, or false for strings.
+func builtinSize(goType string) (int, bool) {
+	switch goType {
+	case "bool", "int8", "uint8":
+		return 1, true
+	case "int16", "uint16":
+		return 2, true
+	case "int32", "uint32", "float32":
+		return 4, true
+	case "int64", "uint64", "float64", "ros.Time", "ros.Duration":
+		return 8, true
+	}
+	return 0, false
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+//	DynamicMessageType
+
+// newFieldProjection builds the projection of the fields named by paths, checking each path against the schema.
+func (t *DynamicMessageType) newFieldProjection(paths []string) (fieldProjection, error) {
+	projection := make(fieldProjection)
+	for _, path := range paths {
+		elements, err := parsePath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		current := projection
+		msgType := t
+		for i, element := range elements {
+			if element.index >= 0 {
+				return nil, errors.New("Field: " + path + ": array indices are not supported when deserializing fields.")
+			}
+			field, err := msgType.getFieldByName(element.name)
+			if err != nil {
+				return nil, errors.Wrap(err, "Field: "+path)
+			}
+
+			last := i == len(elements)-1
+			if last {
+				// The whole field is needed, which also covers any nested paths requested previously.
+				current[field.Name] = nil
+				break
+			}
+			if field.IsBuiltin {
+				return nil, errors.New("Field: " + path + ": " + element.name + " is a " + field.Type + ", not a message.")
+			}
+			next, ok := current[field.Name]
+			if ok && next == nil {
+				break // The whole field was already requested.
+			}
+			if !ok {
+				next = make(fieldProjection)
+				current[field.Name] = next
+			}
+			if msgType, err = msgType.getNestedTypeFromField(field); err != nil {
+				return nil, errors.Wrap(err, "Field: "+path)
+			}
+			current = next
+		}
+	}
+	return projection, nil
+}
+
+// deserializeProjected decodes the fields in the projection from a byte stream, skipping all others.
+func (t *DynamicMessageType) deserializeProjected(d ByteDecoder, buf *bytes.Reader, projection fieldProjection) (map[string]interface{}, error) {
+	data, err := t.zeroValueData()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range t.spec.Fields {
+		field := &t.spec.Fields[i]
+		nested, ok := projection[field.Name]
+		switch {
+		case !ok:
+			err = t.skipField(d, buf, field)
+		case nested == nil || field.IsBuiltin:
+			data[field.Name], err = t.decodeField(d, buf, field)
+		default:
+			data[field.Name], err = t.deserializeProjectedMessages(d, buf, field, nested)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Field: "+field.Name)
+		}
+	}
+	return data, nil
+}
+
+// deserializeProjectedMessages decodes part of a nested message field, or of each element of an array of nested messages.
+func (t *DynamicMessageType) deserializeProjectedMessages(d ByteDecoder, buf *bytes.Reader, field *libgengo.Field, projection fieldProjection) (interface{}, error) {
+	msgType, err := t.getNestedTypeFromField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	decode := func() (Message, error) {
+		data, err := msgType.deserializeProjected(d, buf, projection)
+		if err != nil {
+			return nil, err
+		}
+		return &DynamicMessage{dynamicType: msgType, data: data}, nil
+	}
+
+	if !field.IsArray {
+		return decode()
+	}
+	size := field.ArrayLen
+	if size < 0 {
+		usize, err := d.DecodeUint32(buf)
+		if err != nil {
+			return nil, err
+		}
+		size = int(usize)
+	}
+	messages := make([]Message, size)
+	for i := range messages {
+		if messages[i], err = decode(); err != nil {
+			return nil, errors.Wrap(err, "index "+strconv.Itoa(i))
+		}
+	}
+	return messages, nil
+}
+
+// skipField moves the reader over a field without decoding it, checking that the buffer holds the whole field.
+func (t *DynamicMessageType) skipField(d ByteDecoder, buf *bytes.Reader, field *libgengo.Field) error {
+	count := 1
+	if field.IsArray {
+		count = field.ArrayLen
+		if count < 0 {
+			usize, err := d.DecodeUint32(buf)
+			if err != nil {
+				return err
+			}
+			count = int(usize)
+		}
+	}
+
+	// Fixed size elements can be skipped in one go.
+	size, fixed, err := t.fieldElementSize(field)
+	if err != nil {
+		return err
+	}
+	if fixed {
+		if count > 0 && size > buf.Len()/count {
+			return errors.New("buffer too short for " + strconv.Itoa(count) + " elements of " + strconv.Itoa(size) + " bytes")
+		}
+		return skipBytes(buf, count*size)
+	}
+
+	var msgType *DynamicMessageType
+	if !field.IsBuiltin {
+		if msgType, err = t.getNestedTypeFromField(field); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < count; i++ {
+		if msgType != nil {
+			err = msgType.skipMessage(d, buf)
+		} else {
+			// Strings are the only variable length builtin.
+			var length uint32
+			if length, err = d.DecodeUint32(buf); err == nil {
+				err = skipBytes(buf, int(length))
+			}
+		}
+		if err != nil {
+			return errors.Wrap(err, "index "+strconv.Itoa(i))
+		}
+	}
+	return nil
+}
+
+// skipMessage moves the reader over a whole message without decoding it.
+func (t *DynamicMessageType) skipMessage(d ByteDecoder, buf *bytes.Reader) error {
+	for i := range t.spec.Fields {
+		if err := t.skipField(d, buf, &t.spec.Fields[i]); err != nil {
+			return errors.Wrap(err, "Field: "+t.spec.Fields[i].Name)
+		}
+	}
+	return nil
+}
+
+// fieldElementSize returns the serialized size of a single element of a field, if every element has the same size.
+func (t *DynamicMessageType) fieldElementSize(field *libgengo.Field) (int, bool, error) {
+	if field.IsBuiltin {
+		size, fixed := builtinSize(field.GoType)
+		return size, fixed, nil
+	}
+	msgType, err := t.getNestedTypeFromField(field)
+	if err != nil {
+		return 0, false, err
+	}
+	return msgType.fixedSize()
+}
+
+// fixedSize returns the serialized size of messages of this type, if every message has the same size.
+func (t *DynamicMessageType) fixedSize() (int, bool, error) {
+	total := 0
+	for i := range t.spec.Fields {
+		field := &t.spec.Fields[i]
+		if field.IsArray && field.ArrayLen < 0 {
+			return 0, false, nil
+		}
+		size, fixed, err := t.fieldElementSize(field)
+		if err != nil || !fixed {
+			return 0, false, err
+		}
+		if field.IsArray {
+			size *= field.ArrayLen
+		}
+		total += size
+	}
+	return total, true, nil
+}
+
+//	DynamicMessage
+
+// deserializeLazy checks the layout of a serialized message and keeps a copy of its bytes, so that fields can be decoded when they are first accessed.
+func (m *DynamicMessage) deserializeLazy(buf *bytes.Reader) error {
+	d := m.dynamicType.ByteDecoder()
+	start := int(buf.Size()) - buf.Len()
+
+	offsets := make([]int, 0, len(m.dynamicType.spec.Fields)+1)
+	for i := range m.dynamicType.spec.Fields {
+		field := &m.dynamicType.spec.Fields[i]
+		offsets = append(offsets, int(buf.Size())-buf.Len()-start)
+		if err := m.dynamicType.skipField(d, buf, field); err != nil {
+			return errors.Wrap(err, "Field: "+field.Name)
+		}
+	}
+	end := int(buf.Size()) - buf.Len()
+	offsets = append(offsets, end-start)
+
+	raw := make([]byte, end-start)
+	if _, err := buf.ReadAt(raw, int64(start)); err != nil && len(raw) > 0 {
+		return err
+	}
+
+	m.data = make(map[string]interface{}, len(m.dynamicType.spec.Fields))
+	m.raw = raw
+	m.rawOffsets = offsets
+	return nil
+}
+
+// decodeLazyField decodes a field of a lazily deserialized message, if it hasn't been decoded already.
+func (m *DynamicMessage) decodeLazyField(name string) error {
+	if m.raw == nil {
+		return nil
+	}
+	if _, ok := m.data[name]; ok {
+		return nil
+	}
+	for i := range m.dynamicType.spec.Fields {
+		field := &m.dynamicType.spec.Fields[i]
+		if field.Name != name {
+			continue
+		}
+		value, err := m.dynamicType.decodeField(m.dynamicType.ByteDecoder(), bytes.NewReader(m.raw[m.rawOffsets[i]:m.rawOffsets[i+1]]), field)
+		if err != nil {
+			return errors.Wrap(err, "Field: "+field.Name)
+		}
+		m.data[field.Name] = value
+		return nil
+	}
+	return nil
+}
+
+// decodeLazyFields decodes all remaining fields of a lazily deserialized message, after which the message behaves as if it had been deserialized normally.
+func (m *DynamicMessage) decodeLazyFields() error {
+	if m == nil || m.raw == nil {
+		return nil
+	}
+	for i := range m.dynamicType.spec.Fields {
+		if err := m.decodeLazyField(m.dynamicType.spec.Fields[i].Name); err != nil {
+			return err
+		}
+	}
+	m.raw = nil
+	m.rawOffsets = nil
+	return nil
+}
+
+// ALL DONE.
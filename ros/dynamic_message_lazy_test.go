@@ -0,0 +1,149 @@
+package ros
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func serializeTestMessage(t *testing.T, msg *DynamicMessage) []byte {
+	var buf bytes.Buffer
+	if err := msg.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDynamicMessage_LazyDecoding(t *testing.T) {
+	msgType := newPathTestType(t)
+	source := newYAMLTestMessage(t, msgType)
+	wire := serializeTestMessage(t, source)
+
+	msgType.SetLazyDecoding(true)
+	if !msgType.LazyDecoding() {
+		t.Fatal("expected lazy decoding to be enabled")
+	}
+
+	// Untouched messages are written out unchanged.
+	msg := msgType.NewDynamicMessage()
+	if err := msg.Deserialize(bytes.NewReader(wire)); err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.data) != 0 {
+		t.Fatalf("expected no decoded fields, got %v", msg.data)
+	}
+	if out := serializeTestMessage(t, msg); !bytes.Equal(out, wire) {
+		t.Fatalf("expected passthrough of %v, got %v", wire, out)
+	}
+
+	// Only the fields accessed are decoded.
+	if z, err := msg.GetFloat64("points[0].z"); err != nil || z != 1e16 {
+		t.Fatalf("expected 1e16, got %v, %v", z, err)
+	}
+	if _, ok := msg.data["frame_id"]; ok || len(msg.data) != 1 {
+		t.Fatalf("expected only points to be decoded, got %v", msg.data)
+	}
+
+	// The remaining fields are decoded up front on request.
+	if err := msg.DecodeLazyFields(); err != nil {
+		t.Fatal(err)
+	}
+	if msg.raw != nil || len(msg.data) != len(msgType.spec.Fields) {
+		t.Fatalf("expected every field to be decoded, got %v", msg.data)
+	}
+
+	// Modified messages are serialized from their data.
+	if err := msg.Set("frame_id", "odom"); err != nil {
+		t.Fatal(err)
+	}
+	result := msgType.NewDynamicMessage()
+	if err := result.Deserialize(bytes.NewReader(serializeTestMessage(t, msg))); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.Set("frame_id", "odom"); err != nil {
+		t.Fatal(err)
+	}
+	if diff, err := source.Diff(result, WithNaNEqual()); err != nil || len(diff) != 0 {
+		t.Fatalf("lazy round trip differs: %v, %v", diff, err)
+	}
+
+	// The layout is still checked up front.
+	if err := msgType.NewDynamicMessage().Deserialize(bytes.NewReader(wire[:len(wire)-1])); err == nil {
+		t.Fatal("expected error for truncated message")
+	}
+}
+
+func TestDynamicMessage_DeserializeFields(t *testing.T) {
+	msgType := newPathTestType(t)
+	source := newYAMLTestMessage(t, msgType)
+	wire := serializeTestMessage(t, source)
+
+	msg := msgType.NewDynamicMessage()
+	if err := msg.DeserializeFields(bytes.NewReader(wire), []string{"pose.id", "points.z", "scale"}); err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := msg.GetInt64("pose.id"); id != 4 {
+		t.Fatalf("expected pose.id 4, got %v", id)
+	}
+	if z, _ := msg.GetFloat64("points[0].z"); z != 1e16 {
+		t.Fatalf("expected points[0].z 1e16, got %v", z)
+	}
+	if f, _ := msg.GetFloat64("scale"); f != float64(float32(0.1)) {
+		t.Fatalf("expected scale 0.1, got %v", f)
+	}
+
+	// Skipped fields are zeroed.
+	if s, _ := msg.GetString("frame_id"); s != "" {
+		t.Fatalf("expected empty frame_id, got %q", s)
+	}
+	if x, _ := msg.GetFloat64("pose.position.x"); x != 0 {
+		t.Fatalf("expected zero pose.position.x, got %v", x)
+	}
+	if c, _ := msg.GetInt64("counts[2]"); c != 0 {
+		t.Fatalf("expected zero counts[2], got %v", c)
+	}
+
+	errorCases := []struct {
+		paths []string
+		wire  []byte
+	}{
+		{[]string{"points[0].z"}, wire},
+		{[]string{"frame_id.x"}, wire},
+		{[]string{"unknown"}, wire},
+		{[]string{"scale"}, wire[:len(wire)-1]},
+		{[]string{"stamp"}, wire[:20]},
+	}
+	for _, testCase := range errorCases {
+		if err := msgType.NewDynamicMessage().DeserializeFields(bytes.NewReader(testCase.wire), testCase.paths); err == nil {
+			t.Errorf("%v: expected error", testCase.paths)
+		}
+	}
+}
+
+func TestDynamicMessage_LazyDecodingErrors(t *testing.T) {
+	msgType := newPathTestType(t)
+	wire := serializeTestMessage(t, newYAMLTestMessage(t, msgType))
+	msgType.SetLazyDecoding(true)
+
+	// Corrupt the raw bytes after the layout has been checked, so that decoding fails.
+	msg := msgType.NewDynamicMessage()
+	if err := msg.Deserialize(bytes.NewReader(wire)); err != nil {
+		t.Fatal(err)
+	}
+	for i := range msg.rawOffsets {
+		msg.rawOffsets[i] = 0
+	}
+
+	if s := msg.String(); !strings.Contains(s, "Field: ") {
+		t.Fatalf("expected the decoding error in %q", s)
+	}
+	if data := msg.Data(); len(data) != 0 {
+		t.Fatalf("expected no decoded fields, got %v", data)
+	}
+	if err := msg.DecodeLazyFields(); err == nil || !strings.Contains(err.Error(), "Field: ") {
+		t.Fatalf("expected the decoding error from DecodeLazyFields(), got %v", err)
+	}
+	if errs := msg.Validate(); len(errs) == 0 {
+		t.Fatal("expected the decoding error to be reported by Validate()")
+	}
+}
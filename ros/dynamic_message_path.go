@@ -0,0 +1,654 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// pathElement is a single step of a field path, such as `points[3]` in `points[3].x`; index is -1 when the step does not index into an array.
+type pathElement struct {
+	name  string
+	index int
+}
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// Get returns the value at the specified field path of a DynamicMessage.  Paths are field names separated by '.', where array fields may be indexed, e.g. "points[3].x".  The
+// returned value has the same representation as the values in Data(); nested messages are returned as *DynamicMessage.
+func (m *DynamicMessage) Get(path string) (interface{}, error) {
+	elements, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk down to the message which holds the final field.
+	parent, err := m.walkPath(path, elements[:len(elements)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := elements[len(elements)-1]
+	field, err := parent.dynamicType.getFieldByName(last.name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Field: "+path)
+	}
+	if err := parent.decodeLazyField(field.Name); err != nil {
+		return nil, err
+	}
+	value, ok := parent.data[field.Name]
+	if !ok {
+		return nil, errors.New("Field: " + path + ": No data found.")
+	}
+	if last.index >= 0 {
+		if value, err = indexArray(field, value, last.index); err != nil {
+			return nil, errors.Wrap(err, "Field: "+path)
+		}
+	}
+
+	// Unwrap nested messages so they can be used directly.
+	if msg, ok := value.(Message); ok {
+		if dynamic, ok := msg.(*DynamicMessage); ok {
+			return dynamic, nil
+		}
+	}
+	return value, nil
+}
+
+// Set stores the value at the specified field path of a DynamicMessage, using the same path syntax as Get().  The value is coerced to the type expected by the message schema; for
+// example an int may be used to set an int32 or float64 field, provided it is in range.  Integer fields, and the elements of integer arrays, may also be set using the name of
+// one of the message's constants.  Setting an array field replaces the whole array, while setting an indexed field replaces a single element.
+func (m *DynamicMessage) Set(path string, value interface{}) error {
+	elements, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+
+	// Walk down to the message which holds the final field.
+	parent, err := m.walkPath(path, elements[:len(elements)-1])
+	if err != nil {
+		return err
+	}
+
+	last := elements[len(elements)-1]
+	field, err := parent.dynamicType.getFieldByName(last.name)
+	if err != nil {
+		return errors.Wrap(err, "Field: "+path)
+	}
+
+	if last.index < 0 {
+		// Replace the whole field.
+		var coerced interface{}
+		if field.IsArray {
+			coerced, err = parent.dynamicType.coerceArrayValue(field, value)
+		} else {
+			coerced, err = parent.dynamicType.coerceSingularValue(field, value)
+		}
+		if err != nil {
+			return errors.Wrap(err, "Field: "+path)
+		}
+		parent.data[field.Name] = coerced
+		return nil
+	}
+
+	// Replace a single element of an array.
+	if !field.IsArray {
+		return errors.New("Field: " + path + ": cannot index a field which is not an array.")
+	}
+	if err := parent.decodeLazyField(field.Name); err != nil {
+		return err
+	}
+	array, ok := parent.data[field.Name]
+	if !ok {
+		return errors.New("Field: " + path + ": No data found.")
+	}
+	arrayValue := reflect.ValueOf(array)
+	if arrayValue.Kind() != reflect.Slice && arrayValue.Kind() != reflect.Array {
+		return errors.New("Field: " + path + ": expected an array.")
+	}
+	if last.index >= arrayValue.Len() {
+		return errors.New("Field: " + path + ": index " + strconv.Itoa(last.index) + " out of range for array of length " + strconv.Itoa(arrayValue.Len()) + ".")
+	}
+	coerced, err := parent.dynamicType.coerceSingularValue(field, value)
+	if err != nil {
+		return errors.Wrap(err, "Field: "+path)
+	}
+	element := reflect.ValueOf(coerced)
+	if !element.Type().AssignableTo(arrayValue.Type().Elem()) {
+		return errors.New("Field: " + path + ": cannot store " + element.Type().String() + " in " + arrayValue.Type().String() + ".")
+	}
+	arrayValue.Index(last.index).Set(element)
+	return nil
+}
+
+// GetFloat64 returns the numeric value at the specified field path as a float64.  Any integer or floating point field may be read.
+func (m *DynamicMessage) GetFloat64(path string) (float64, error) {
+	value, err := m.Get(path)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := numericToFloat64(value)
+	if !ok {
+		return 0, newPathTypeError(path, value, "a number")
+	}
+	return f, nil
+}
+
+// GetInt64 returns the integer value at the specified field path as an int64.  Unsigned values which do not fit in an int64 are reported as errors.
+func (m *DynamicMessage) GetInt64(path string) (int64, error) {
+	value, err := m.Get(path)
+	if err != nil {
+		return 0, err
+	}
+	switch v := value.(type) {
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, errors.New("Field: " + path + ": value " + strconv.FormatUint(v, 10) + " overflows int64.")
+		}
+		return int64(v), nil
+	}
+	return 0, newPathTypeError(path, value, "an integer")
+}
+
+// GetBool returns the boolean value at the specified field path.
+func (m *DynamicMessage) GetBool(path string) (bool, error) {
+	value, err := m.Get(path)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, newPathTypeError(path, value, "a bool")
+	}
+	return b, nil
+}
+
+// GetString returns the string value at the specified field path.
+func (m *DynamicMessage) GetString(path string) (string, error) {
+	value, err := m.Get(path)
+	if err != nil {
+		return "", err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", newPathTypeError(path, value, "a string")
+	}
+	return s, nil
+}
+
+// GetTime returns the time value at the specified field path.
+func (m *DynamicMessage) GetTime(path string) (Time, error) {
+	value, err := m.Get(path)
+	if err != nil {
+		return Time{}, err
+	}
+	t, ok := value.(Time)
+	if !ok {
+		return Time{}, newPathTypeError(path, value, "a time")
+	}
+	return t, nil
+}
+
+// GetDuration returns the duration value at the specified field path.
+func (m *DynamicMessage) GetDuration(path string) (Duration, error) {
+	value, err := m.Get(path)
+	if err != nil {
+		return Duration{}, err
+	}
+	d, ok := value.(Duration)
+	if !ok {
+		return Duration{}, newPathTypeError(path, value, "a duration")
+	}
+	return d, nil
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// parsePath splits a field path such as "pose.points[3].x" into its elements.
+func parsePath(path string) ([]pathElement, error) {
+	if path == "" {
+		return nil, errors.New("empty field path")
+	}
+
+	parts := strings.Split(path, ".")
+	elements := make([]pathElement, 0, len(parts))
+	for _, part := range parts {
+		element := pathElement{name: part, index: -1}
+		if open := strings.IndexByte(part, '['); open >= 0 {
+			if !strings.HasSuffix(part, "]") {
+				return nil, errors.New("invalid field path " + path + ": unterminated index in " + part)
+			}
+			index, err := strconv.Atoi(part[open+1 : len(part)-1])
+			if err != nil || index < 0 {
+				return nil, errors.New("invalid field path " + path + ": bad index in " + part)
+			}
+			element.name = part[:open]
+			element.index = index
+		}
+		if element.name == "" {
+			return nil, errors.New("invalid field path " + path + ": empty field name")
+		}
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
+
+// indexArray returns the element at the specified index of an array field.
+func indexArray(field *libgengo.Field, array interface{}, index int) (interface{}, error) {
+	if !field.IsArray {
+		return nil, errors.New("cannot index a field which is not an array")
+	}
+	arrayValue := reflect.ValueOf(array)
+	if arrayValue.Kind() != reflect.Slice && arrayValue.Kind() != reflect.Array {
+		return nil, errors.New("expected an array")
+	}
+	if index >= arrayValue.Len() {
+		return nil, errors.New("index " + strconv.Itoa(index) + " out of range for array of length " + strconv.Itoa(arrayValue.Len()))
+	}
+	return arrayValue.Index(index).Interface(), nil
+}
+
+// numericToFloat64 converts any of the numeric representations used by DynamicMessage into a float64.
+func numericToFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case JsonFloat32:
+		return float64(v.F), true
+	case JsonFloat64:
+		return v.F, true
+	}
+	return 0, false
+}
+
+// goNumberToFloat64 converts any Go number, or DynamicMessage float representation, into a float64.
+func goNumberToFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return numericToFloat64(value)
+}
+
+// goNumberToInteger converts any Go integer, or integral floating point number, into either a signed or an unsigned 64 bit integer; isUnsigned reports which of the results holds
+// the value and ok whether the conversion succeeded.
+func goNumberToInteger(value interface{}) (i int64, u uint64, isUnsigned bool, ok bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), 0, false, true
+	case int8:
+		return int64(v), 0, false, true
+	case int16:
+		return int64(v), 0, false, true
+	case int32:
+		return int64(v), 0, false, true
+	case int64:
+		return v, 0, false, true
+	case uint:
+		return 0, uint64(v), true, true
+	case uint8:
+		return 0, uint64(v), true, true
+	case uint16:
+		return 0, uint64(v), true, true
+	case uint32:
+		return 0, uint64(v), true, true
+	case uint64:
+		return 0, v, true, true
+	}
+	f, isNumber := goNumberToFloat64(value)
+	if !isNumber || f != math.Trunc(f) || math.IsInf(f, 0) {
+		return 0, 0, false, false
+	}
+	if f >= 0 {
+		if f >= math.MaxUint64 {
+			return 0, 0, false, false
+		}
+		return 0, uint64(f), true, true
+	}
+	if f < math.MinInt64 {
+		return 0, 0, false, false
+	}
+	return int64(f), 0, false, true
+}
+
+// coerceInteger converts a Go number into the integer type named by goType, checking that it is in range.
+func coerceInteger(goType string, value interface{}) (interface{}, error) {
+	i, u, isUnsigned, ok := goNumberToInteger(value)
+	if !ok {
+		return nil, errors.New("cannot use " + describeValue(value) + " as " + goType)
+	}
+
+	// Work out the allowed range of the target type.
+	var min int64
+	var max uint64
+	switch goType {
+	case "int8":
+		min, max = math.MinInt8, math.MaxInt8
+	case "int16":
+		min, max = math.MinInt16, math.MaxInt16
+	case "int32":
+		min, max = math.MinInt32, math.MaxInt32
+	case "int64":
+		min, max = math.MinInt64, math.MaxInt64
+	case "uint8":
+		min, max = 0, math.MaxUint8
+	case "uint16":
+		min, max = 0, math.MaxUint16
+	case "uint32":
+		min, max = 0, math.MaxUint32
+	case "uint64":
+		min, max = 0, math.MaxUint64
+	default:
+		return nil, errors.New("unknown integer type " + goType)
+	}
+	if (isUnsigned && u > max) || (!isUnsigned && (i < min || (i > 0 && uint64(i) > max))) {
+		return nil, errors.New(describeValue(value) + " overflows " + goType)
+	}
+	if isUnsigned {
+		i = int64(u)
+	}
+
+	switch goType {
+	case "int8":
+		return int8(i), nil
+	case "int16":
+		return int16(i), nil
+	case "int32":
+		return int32(i), nil
+	case "int64":
+		return i, nil
+	case "uint8":
+		return uint8(i), nil
+	case "uint16":
+		return uint16(i), nil
+	case "uint32":
+		return uint32(i), nil
+	default:
+		return uint64(i), nil
+	}
+}
+
+// isIntegerGoType reports whether the Go type of a field is one of the integer types.
+func isIntegerGoType(goType string) bool {
+	switch goType {
+	case "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64":
+		return true
+	}
+	return false
+}
+
+// describeValue describes a value and its type for error messages.
+func describeValue(value interface{}) string {
+	if value == nil {
+		return "nil"
+	}
+	return reflect.TypeOf(value).String() + " value"
+}
+
+// newPathTypeError reports that the value found at a path is not of the expected kind.
+func newPathTypeError(path string, value interface{}, expected string) error {
+	return errors.New("Field: " + path + ": found " + describeValue(value) + ", expected " + expected + ".")
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// walkPath follows the path elements down through nested messages, returning the message reached.
+func (m *DynamicMessage) walkPath(path string, elements []pathElement) (*DynamicMessage, error) {
+	current := m
+	for _, element := range elements {
+		if current == nil || current.dynamicType == nil || current.dynamicType.spec == nil {
+			return nil, errors.New("Field: " + path + ": invalid dynamic message")
+		}
+		field, err := current.dynamicType.getFieldByName(element.name)
+		if err != nil {
+			return nil, errors.Wrap(err, "Field: "+path)
+		}
+		if field.IsBuiltin {
+			return nil, errors.New("Field: " + path + ": " + element.name + " is a " + field.Type + ", not a message.")
+		}
+		if err := current.decodeLazyField(field.Name); err != nil {
+			return nil, err
+		}
+		value, ok := current.data[field.Name]
+		if !ok {
+			return nil, errors.New("Field: " + path + ": No data found for " + element.name + ".")
+		}
+		if element.index >= 0 {
+			if value, err = indexArray(field, value, element.index); err != nil {
+				return nil, errors.Wrap(err, "Field: "+path)
+			}
+		} else if field.IsArray {
+			return nil, errors.New("Field: " + path + ": " + element.name + " is an array and must be indexed.")
+		}
+		next, ok := value.(*DynamicMessage)
+		if !ok {
+			return nil, newPathTypeError(path, value, "a dynamic message")
+		}
+		current = next
+	}
+	if current == nil || current.dynamicType == nil || current.dynamicType.spec == nil {
+		return nil, errors.New("Field: " + path + ": invalid dynamic message")
+	}
+	return current, nil
+}
+
+// getFieldByName returns the field of the message type with the specified name.
+func (t *DynamicMessageType) getFieldByName(name string) (*libgengo.Field, error) {
+	for i := range t.spec.Fields {
+		if t.spec.Fields[i].Name == name {
+			return &t.spec.Fields[i], nil
+		}
+	}
+	return nil, errors.New("no field " + name + " in " + t.Name())
+}
+
+// coerceSingularValue converts a value into the representation DynamicMessage uses for a single element of the field.
+func (t *DynamicMessageType) coerceSingularValue(field *libgengo.Field, value interface{}) (interface{}, error) {
+	if mapValue, ok := value.(map[string]interface{}); ok {
+		return t.coerceMapValue(field, mapValue)
+	}
+
+	switch field.GoType {
+	case "bool":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case "string":
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64":
+		if name, ok := value.(string); ok {
+			if constant, ok := t.lookupConstant(field, name); ok {
+				return constant, nil
+			}
+			return nil, errors.New("no constant " + name + " of type " + field.Type + " in " + t.Name())
+		}
+		return coerceInteger(field.GoType, value)
+	case "float32":
+		if f, ok := goNumberToFloat64(value); ok {
+			return JsonFloat32{F: float32(f)}, nil
+		}
+	case "float64":
+		if f, ok := goNumberToFloat64(value); ok {
+			return JsonFloat64{F: f}, nil
+		}
+	case "ros.Time":
+		switch v := value.(type) {
+		case Time:
+			return v, nil
+		case *Time:
+			return *v, nil
+		case time.Time:
+			return NewTime(uint32(v.Unix()), uint32(v.Nanosecond())), nil
+		}
+	case "ros.Duration":
+		switch v := value.(type) {
+		case Duration:
+			return v, nil
+		case *Duration:
+			return *v, nil
+		case time.Duration:
+			if v < 0 {
+				return nil, errors.New("cannot use negative duration " + v.String())
+			}
+			var d Duration
+			d.FromNSec(uint64(v))
+			return d, nil
+		}
+	default:
+		if field.IsBuiltin {
+			return nil, errors.New("we haven't implemented this primitive yet")
+		}
+		msgType, err := t.getNestedTypeFromField(field)
+		if err != nil {
+			return nil, err
+		}
+		if msg, ok := value.(*DynamicMessage); ok && msg != nil && msg.dynamicType != nil {
+			if msg.dynamicType.Name() != msgType.Name() || msg.dynamicType.MD5Sum() != msgType.MD5Sum() {
+				return nil, errors.New("cannot use message of type " + msg.dynamicType.Name() + " as " + msgType.Name())
+			}
+			return msg, nil
+		}
+		return nil, errors.New("cannot use " + describeValue(value) + " as " + msgType.Name())
+	}
+	return nil, errors.New("cannot use " + describeValue(value) + " as " + field.Type)
+}
+
+// coerceArrayValue converts a slice or array into the typed slice DynamicMessage uses for the field, checking the length of fixed size arrays.
+func (t *DynamicMessageType) coerceArrayValue(field *libgengo.Field, value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok && field.GoType == "uint8" {
+		return t.coerceBase64Value(field, s)
+	}
+
+	arrayValue := reflect.ValueOf(value)
+	if !arrayValue.IsValid() || (arrayValue.Kind() != reflect.Slice && arrayValue.Kind() != reflect.Array) {
+		return nil, errors.New("cannot use " + describeValue(value) + " as " + field.Type + "[]")
+	}
+	size := arrayValue.Len()
+	if field.ArrayLen >= 0 && size != field.ArrayLen {
+		return nil, errors.New("expected array of length " + strconv.Itoa(field.ArrayLen) + ", got " + strconv.Itoa(size))
+	}
+
+	// Work out the slice type that DynamicMessage expects.
+	zero, err := t.zeroValueArray(field, 0)
+	if err != nil {
+		return nil, err
+	}
+	sliceType := reflect.TypeOf(zero)
+
+	// Already the right type, store it as is.
+	if arrayValue.Type() == sliceType {
+		return value, nil
+	}
+
+	result := reflect.MakeSlice(sliceType, size, size)
+	for i := 0; i < size; i++ {
+		element, err := t.coerceSingularValue(field, arrayValue.Index(i).Interface())
+		if err != nil {
+			return nil, errors.Wrap(err, "index "+strconv.Itoa(i))
+		}
+		result.Index(i).Set(reflect.ValueOf(element))
+	}
+	return result.Interface(), nil
+}
+
+// zeroValueArray creates a zeroed array of the specified size of the representation DynamicMessage uses for the field.
+func (t *DynamicMessageType) zeroValueArray(field *libgengo.Field, size int) (interface{}, error) {
+	switch field.GoType {
+	case "bool":
+		return make([]bool, size), nil
+	case "int8":
+		return make([]int8, size), nil
+	case "int16":
+		return make([]int16, size), nil
+	case "int32":
+		return make([]int32, size), nil
+	case "int64":
+		return make([]int64, size), nil
+	case "uint8":
+		return make([]uint8, size), nil
+	case "uint16":
+		return make([]uint16, size), nil
+	case "uint32":
+		return make([]uint32, size), nil
+	case "uint64":
+		return make([]uint64, size), nil
+	case "float32":
+		return make([]JsonFloat32, size), nil
+	case "float64":
+		return make([]JsonFloat64, size), nil
+	case "string":
+		return make([]string, size), nil
+	case "ros.Time":
+		return make([]Time, size), nil
+	case "ros.Duration":
+		return make([]Duration, size), nil
+	default:
+		if field.IsBuiltin {
+			return nil, errors.New("we haven't implemented this primitive yet")
+		}
+		msgType, err := t.getNestedTypeFromField(field)
+		if err != nil {
+			return nil, errors.Wrap(err, "Field: "+field.Name)
+		}
+		messages := make([]Message, size)
+		for i := 0; i < size; i++ {
+			messages[i] = msgType.NewMessage()
+		}
+		return messages, nil
+	}
+}
+
+// ALL DONE.
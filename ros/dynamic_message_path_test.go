@@ -0,0 +1,161 @@
+package ros
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// newPathTestType creates a message type with nested messages, arrays and time fields from message definitions held in strings.
+func newPathTestType(t *testing.T) *DynamicMessageType {
+	ctx, err := libgengo.NewPkgContext(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	definitions := []struct{ name, text string }{
+		{"geometry_msgs/Point", "float64 x\nfloat64 y\nfloat64 z"},
+		{"test_msgs/Pose", "geometry_msgs/Point position\nuint8 id"},
+		{"test_msgs/Path", "time stamp\nduration period\nstring frame_id\nPose pose\ngeometry_msgs/Point[] points\nint32[3] counts\nfloat32 scale"},
+	}
+	var spec *libgengo.MsgSpec
+	for _, definition := range definitions {
+		if spec, err = ctx.LoadMsgFromString(definition.text, definition.name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msgType, err := newDynamicMessageTypeFromSpecInContext(ctx, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msgType
+}
+
+func TestDynamicMessage_GetSet(t *testing.T) {
+	msg := newPathTestType(t).NewDynamicMessage()
+
+	if err := msg.Set("pose.position.x", 1.5); err != nil {
+		t.Fatal(err)
+	}
+	if x, err := msg.GetFloat64("pose.position.x"); err != nil || x != 1.5 {
+		t.Fatalf("expected 1.5, got %v, %v", x, err)
+	}
+	if v, err := msg.Get("pose.position.x"); err != nil || v != (JsonFloat64{F: 1.5}) {
+		t.Fatalf("expected JsonFloat64 1.5, got %#v, %v", v, err)
+	}
+
+	// Integers are coerced to the schema type.
+	if err := msg.Set("pose.id", 7); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := msg.Get("pose.id"); v != uint8(7) {
+		t.Fatalf("expected uint8 7, got %#v", v)
+	}
+	if err := msg.Set("scale", 2); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := msg.Get("scale"); v != (JsonFloat32{F: 2}) {
+		t.Fatalf("expected JsonFloat32 2, got %#v", v)
+	}
+
+	if err := msg.Set("frame_id", "map"); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := msg.GetString("frame_id"); err != nil || s != "map" {
+		t.Fatalf("expected map, got %v, %v", s, err)
+	}
+
+	stamp := NewTime(10, 20)
+	if err := msg.Set("stamp", stamp); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := msg.GetTime("stamp"); err != nil || v != stamp {
+		t.Fatalf("expected %v, got %v, %v", stamp, v, err)
+	}
+
+	// Nested messages are returned as dynamic messages.
+	pose, err := msg.Get("pose")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dynamic, ok := pose.(*DynamicMessage); !ok || dynamic.Type().Name() != "test_msgs/Pose" {
+		t.Fatalf("expected test_msgs/Pose, got %#v", pose)
+	}
+}
+
+func TestDynamicMessage_GetSetArrays(t *testing.T) {
+	msgType := newPathTestType(t)
+	msg := msgType.NewDynamicMessage()
+
+	// Generic slices are coerced to the typed slice.
+	if err := msg.Set("counts", []interface{}{1, 2.0, int64(3)}); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := msg.Get("counts"); len(v.([]int32)) != 3 || v.([]int32)[2] != 3 {
+		t.Fatalf("unexpected counts %#v", v)
+	}
+	if err := msg.Set("counts[1]", 5); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := msg.GetInt64("counts[1]"); err != nil || v != 5 {
+		t.Fatalf("expected 5, got %v, %v", v, err)
+	}
+
+	// Fixed size arrays must keep their size.
+	if err := msg.Set("counts", []int32{1}); err == nil {
+		t.Fatal("expected error setting fixed size array with the wrong length")
+	}
+
+	pointType, err := msgType.getNestedTypeFromField(&msgType.spec.Fields[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := make([]Message, 4)
+	for i := range points {
+		points[i] = pointType.NewMessage()
+	}
+	if err := msg.Set("points", points); err != nil {
+		t.Fatal(err)
+	}
+	if err := msg.Set("points[3].x", float32(4)); err != nil {
+		t.Fatal(err)
+	}
+	if x, err := msg.GetFloat64("points[3].x"); err != nil || x != 4 {
+		t.Fatalf("expected 4, got %v, %v", x, err)
+	}
+}
+
+func TestDynamicMessage_GetSetErrors(t *testing.T) {
+	msg := newPathTestType(t).NewDynamicMessage()
+
+	testCases := []struct {
+		path     string
+		value    interface{}
+		expected string
+	}{
+		{"pose.missing", 1, "no field missing in test_msgs/Pose"},
+		{"frame_id.x", 1, "not a message"},
+		{"points.x", 1, "must be indexed"},
+		{"points[0].x", 1, "out of range"},
+		{"counts[", 1, "unterminated index"},
+		{"pose.id", 256, "overflows uint8"},
+		{"pose.id", -1, "overflows uint8"},
+		{"counts[0]", 1.5, "cannot use float64 value as int32"},
+		{"frame_id", 1, "cannot use int value as string"},
+		{"pose", "x", "cannot use string value as test_msgs/Pose"},
+		{"frame_id[0]", "x", "not an array"},
+	}
+	for _, testCase := range testCases {
+		err := msg.Set(testCase.path, testCase.value)
+		if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+			t.Errorf("Set(%q): expected error containing %q, got %v", testCase.path, testCase.expected, err)
+		}
+	}
+
+	if _, err := msg.GetString("pose.id"); err == nil || !strings.Contains(err.Error(), "expected a string") {
+		t.Errorf("expected type error, got %v", err)
+	}
+	if _, err := msg.Get(""); err == nil {
+		t.Error("expected error for empty path")
+	}
+}
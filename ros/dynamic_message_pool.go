@@ -0,0 +1,272 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+//	DynamicMessageType
+
+// Acquire returns a DynamicMessage of this type to deserialize into, recycling a message which has been released if there is one.  Deserializing into an acquired message reuses
+// its data map, the arrays of its fields and its nested messages wherever they are large enough, instead of allocating new ones for every message received.  Messages decoded by
+// DeserializeZeroCopy() are never decoded into, as their arrays alias the caller's buffer, nor is memory reused by DeserializeZeroCopy() itself.  A recycled message holds the
+// fields of the released message until it is deserialized into.
+func (t *DynamicMessageType) Acquire() *DynamicMessage {
+	// Messages released by a copy of the type are dropped, as the copy may decode differently.
+	if m, ok := t.messagePool().Get().(*DynamicMessage); ok && m.dynamicType == t {
+		return m
+	}
+	m := t.NewDynamicMessage()
+	m.pooled = true
+	return m
+}
+
+//	DynamicMessage
+
+// Release returns a message obtained from Acquire() to the pool of its type, so that its memory can be reused by a later message.  Neither the message nor any value held by it,
+// such as an array or a nested message, may be used after it has been released.  Messages which weren't acquired are left alone.
+func (m *DynamicMessage) Release() {
+	if m == nil || m.dynamicType == nil || !m.pooled {
+		return
+	}
+	m.raw = nil
+	m.rawOffsets = nil
+	m.dynamicType.messagePool().Put(m)
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// reusableMessage returns previous as a DynamicMessage of the given type which can be deserialized into, or nil if it isn't one or its arrays may alias another buffer.
+func reusableMessage(previous interface{}, msgType *DynamicMessageType) *DynamicMessage {
+	msg, ok := previous.(*DynamicMessage)
+	if !ok || msg == nil || msg.dynamicType != msgType || msg.data == nil || msg.raw != nil || msg.aliased {
+		return nil
+	}
+	return msg
+}
+
+// decoderAliases reports whether arrays decoded from the buffer by the decoder may share memory with it.
+func decoderAliases(d ByteDecoder, buf *bytes.Reader) bool {
+	zeroCopy, ok := d.(ZeroCopyByteDecoder)
+	return ok && zeroCopy.reader != nil && zeroCopy.reader == buf
+}
+
+// byteOrderDecoderOf returns the decoder which the builtin decoders decode numbers with, or false for any other decoder.
+func byteOrderDecoderOf(d ByteDecoder) (byteOrderDecoder, bool) {
+	switch d.(type) {
+	case LEByteDecoder, ZeroCopyByteDecoder:
+		return leDecoder, true
+	case BEByteDecoder:
+		return beDecoder, true
+	}
+	return byteOrderDecoder{}, false
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+//	DynamicMessageType
+
+// messagePool returns the pool of released messages of the type, creating it if need be.
+func (t *DynamicMessageType) messagePool() *sync.Pool {
+	if pool := atomic.LoadPointer(&t.pool); pool != nil {
+		return (*sync.Pool)(pool)
+	}
+	atomic.CompareAndSwapPointer(&t.pool, nil, unsafe.Pointer(&sync.Pool{}))
+	return (*sync.Pool)(atomic.LoadPointer(&t.pool))
+}
+
+//	DynamicMessage
+
+// deserializeReusing reads the fields of a DynamicMessage from a byte stream, decoding them into the data map, arrays and nested messages which the message already holds.
+func (m *DynamicMessage) deserializeReusing(d ByteDecoder, buf *bytes.Reader) error {
+	// The message is left empty if decoding fails part way through.
+	data := m.data
+	m.data = nil
+	m.aliased = decoderAliases(d, buf)
+
+	for i := range m.dynamicType.spec.Fields {
+		field := &m.dynamicType.spec.Fields[i]
+		value, err := m.dynamicType.decodeFieldReusing(d, buf, field, data[field.Name])
+		if err != nil {
+			return errors.Wrap(err, "Field: "+field.Name)
+		}
+		data[field.Name] = value
+	}
+
+	m.data = data
+	return nil
+}
+
+//	DynamicMessageType
+
+// decodeFieldReusing decodes the value of a single field from a byte stream, reusing the previous value of the field where possible.
+func (t *DynamicMessageType) decodeFieldReusing(d ByteDecoder, buf *bytes.Reader, field *libgengo.Field, previous interface{}) (interface{}, error) {
+	if !field.IsArray {
+		if field.IsBuiltin {
+			return t.decodeField(d, buf, field)
+		}
+
+		// The type encapsulates another ROS message, which can be deserialized into.
+		msgType, err := t.getNestedTypeFromField(field)
+		if err != nil {
+			return nil, err
+		}
+		if msg := reusableMessage(previous, msgType); msg != nil {
+			return msg, msg.deserializeReusing(d, buf)
+		}
+		return d.DecodeMessage(buf, msgType)
+	}
+
+	size, err := t.decodeArraySize(d, buf, field)
+	if err != nil {
+		return nil, err
+	}
+	if value, ok, err := t.decodeArrayReusing(d, buf, field, size, previous); ok {
+		return value, err
+	}
+	return t.decodeArray(d, buf, field, size)
+}
+
+// decodeArrayReusing decodes the elements of an array field into the previous array of the field, returning false if the previous array can't hold them.
+func (t *DynamicMessageType) decodeArrayReusing(d ByteDecoder, buf *bytes.Reader, field *libgengo.Field, size int, previous interface{}) (interface{}, bool, error) {
+	// Arrays of numbers are read straight into the previous array and converted in place by the builtin decoders, unless the decoder aliases them to the buffer instead.
+	order, ok := byteOrderDecoderOf(d)
+	numeric := ok && !decoderAliases(d, buf)
+
+	var err error
+	switch field.GoType {
+	case "bool":
+		if s, ok := previous.([]bool); ok && cap(s) >= size && numeric {
+			s = s[:size]
+			return s, true, order.decodeBools(buf, s)
+		}
+	case "int8":
+		if s, ok := previous.([]int8); ok && cap(s) >= size && numeric {
+			s = s[:size]
+			return s, true, order.decodeInt8s(buf, s)
+		}
+	case "int16":
+		if s, ok := previous.([]int16); ok && cap(s) >= size && numeric {
+			s = s[:size]
+			return s, true, order.decodeInt16s(buf, s)
+		}
+	case "int32":
+		if s, ok := previous.([]int32); ok && cap(s) >= size && numeric {
+			s = s[:size]
+			return s, true, order.decodeInt32s(buf, s)
+		}
+	case "int64":
+		if s, ok := previous.([]int64); ok && cap(s) >= size && numeric {
+			s = s[:size]
+			return s, true, order.decodeInt64s(buf, s)
+		}
+	case "uint8":
+		if s, ok := previous.([]uint8); ok && cap(s) >= size && numeric {
+			s = s[:size]
+			return s, true, order.decodeUint8s(buf, s)
+		}
+	case "uint16":
+		if s, ok := previous.([]uint16); ok && cap(s) >= size && numeric {
+			s = s[:size]
+			return s, true, order.decodeUint16s(buf, s)
+		}
+	case "uint32":
+		if s, ok := previous.([]uint32); ok && cap(s) >= size && numeric {
+			s = s[:size]
+			return s, true, order.decodeUint32s(buf, s)
+		}
+	case "uint64":
+		if s, ok := previous.([]uint64); ok && cap(s) >= size && numeric {
+			s = s[:size]
+			return s, true, order.decodeUint64s(buf, s)
+		}
+	case "float32":
+		if s, ok := previous.([]JsonFloat32); ok && cap(s) >= size && numeric {
+			s = s[:size]
+			return s, true, order.decodeFloat32s(buf, s)
+		}
+	case "float64":
+		if s, ok := previous.([]JsonFloat64); ok && cap(s) >= size && numeric {
+			s = s[:size]
+			return s, true, order.decodeFloat64s(buf, s)
+		}
+	case "string":
+		if s, ok := previous.([]string); ok && cap(s) >= size {
+			s = s[:size]
+			for i := range s {
+				if s[i], err = d.DecodeString(buf); err != nil {
+					return nil, true, err
+				}
+			}
+			return s, true, nil
+		}
+	case "ros.Time":
+		if s, ok := previous.([]Time); ok && cap(s) >= size {
+			s = s[:size]
+			for i := range s {
+				if s[i], err = d.DecodeTime(buf); err != nil {
+					return nil, true, err
+				}
+			}
+			return s, true, nil
+		}
+	case "ros.Duration":
+		if s, ok := previous.([]Duration); ok && cap(s) >= size {
+			s = s[:size]
+			for i := range s {
+				if s[i], err = d.DecodeDuration(buf); err != nil {
+					return nil, true, err
+				}
+			}
+			return s, true, nil
+		}
+	default:
+		if field.IsBuiltin {
+			return nil, false, nil
+		}
+
+		// Arrays of messages reuse the previous elements, including any beyond the length of the previous array, and grow as needed.
+		msgType, err := t.getNestedTypeFromField(field)
+		if err != nil {
+			return nil, true, err
+		}
+		msgs, _ := previous.([]Message)
+		if cap(msgs) < size {
+			grown := make([]Message, size)
+			copy(grown, msgs[:cap(msgs)])
+			msgs = grown
+		}
+		msgs = msgs[:size]
+		for i := range msgs {
+			if msg := reusableMessage(msgs[i], msgType); msg != nil {
+				err = msg.deserializeReusing(d, buf)
+			} else {
+				msgs[i], err = d.DecodeMessage(buf, msgType)
+			}
+			if err != nil {
+				return nil, true, err
+			}
+		}
+		return msgs, true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,195 @@
+package ros
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDynamicMessage_AcquireReusesArrays(t *testing.T) {
+	msgType := newBinaryTestType(t)
+	msg := msgType.Acquire()
+
+	inputs := [][]byte{
+		mustDecodeHex(t, "01"+"04000000"+"01020304"+"01000000"+"02000000"),
+		mustDecodeHex(t, "02"+"02000000"+"0506"+"03000000"+"04000000"),
+		mustDecodeHex(t, "03"+"06000000"+"010203040506"+"05000000"+"06000000"),
+	}
+	var first []uint8
+	for i, input := range inputs {
+		if err := msg.Deserialize(bytes.NewReader(input)); err != nil {
+			t.Fatal(err)
+		}
+		expected := msgType.NewDynamicMessage()
+		if err := expected.Deserialize(bytes.NewReader(input)); err != nil {
+			t.Fatal(err)
+		}
+		if diff, err := expected.Diff(msg); err != nil || len(diff) != 0 {
+			t.Fatalf("%d: deserialized message differs: %v, %v", i, diff, err)
+		}
+
+		b, _ := msg.Get("b")
+		switch i {
+		case 0:
+			first = b.([]uint8)
+		case 1:
+			// The shorter array is decoded into the previous one.
+			if &b.([]uint8)[0] != &first[0] {
+				t.Fatal("expected b to be reused")
+			}
+		}
+	}
+
+	// Messages which weren't acquired don't reuse their arrays.
+	plain := msgType.NewDynamicMessage()
+	if err := plain.Deserialize(bytes.NewReader(inputs[0])); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := plain.Get("b")
+	if err := plain.Deserialize(bytes.NewReader(inputs[1])); err != nil {
+		t.Fatal(err)
+	}
+	if b.([]uint8)[0] != 1 {
+		t.Fatalf("expected the previous array to be left alone, got %v", b)
+	}
+	plain.Release()
+
+	// Truncated messages still fail.
+	if err := msg.Deserialize(bytes.NewReader(inputs[0][:7])); err == nil {
+		t.Fatal("expected error for truncated message")
+	}
+	msg.Release()
+}
+
+func TestDynamicMessage_AcquireReusesNestedMessages(t *testing.T) {
+	msgType := newPathTestType(t)
+	var inputs [][]byte
+	for _, points := range [][]interface{}{
+		{map[string]interface{}{"x": 1.0}, map[string]interface{}{"y": 2.0}},
+		{},
+		{map[string]interface{}{"z": 3.0}, map[string]interface{}{"x": 4.0}, map[string]interface{}{"y": 5.0}},
+	} {
+		original, err := msgType.NewDynamicMessageFromMap(map[string]interface{}{"frame_id": "map", "points": points, "pose": map[string]interface{}{"id": len(points)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := original.Serialize(&buf); err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, buf.Bytes())
+	}
+
+	msg := msgType.Acquire()
+	defer msg.Release()
+	var pose, point interface{}
+	for i, input := range inputs {
+		if err := msg.Deserialize(bytes.NewReader(input)); err != nil {
+			t.Fatal(err)
+		}
+		expected := msgType.NewDynamicMessage()
+		if err := expected.Deserialize(bytes.NewReader(input)); err != nil {
+			t.Fatal(err)
+		}
+		if diff, err := expected.Diff(msg); err != nil || len(diff) != 0 {
+			t.Fatalf("%d: deserialized message differs: %v, %v", i, diff, err)
+		}
+
+		points, _ := msg.Get("points")
+		if i == 0 {
+			pose, _ = msg.Get("pose")
+			point = points.([]Message)[0]
+			continue
+		}
+		if current, _ := msg.Get("pose"); current != pose {
+			t.Fatalf("%d: expected pose to be reused", i)
+		}
+		if i == 2 && points.([]Message)[0] != point {
+			t.Fatal("expected points to be reused")
+		}
+	}
+}
+
+func TestDynamicMessage_AcquireReusesNumericArrays(t *testing.T) {
+	msgType := newRegisteredTestType(t, "test_msgs/Arrays", "int8[] a\nint16[] b\nint32[] c\nint64[] d\nuint16[] e\nuint32[] f\nuint64[] g\nfloat32[] h\nfloat64[] i")
+	data := map[string]interface{}{
+		"a": []interface{}{-1, 2, 5},
+		"b": []interface{}{-1, 2, -300},
+		"c": []interface{}{-1, 2, -300, 40000},
+		"d": []interface{}{-1, 2, -300, 40000},
+		"e": []interface{}{1, 2, 300},
+		"f": []interface{}{1, 2, 300, 40000},
+		"g": []interface{}{1, 2, 300, 40000},
+		"h": []interface{}{-1, 0.5, 300},
+		"i": []interface{}{-1, 0.5, 300, 1e100},
+	}
+	original, err := msgType.NewDynamicMessageFromMap(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Arrays are decoded into the previous arrays in either byte order.
+	for _, order := range []struct {
+		decoder ByteDecoder
+		encoder ByteEncoder
+	}{{LEByteDecoder{}, LEByteEncoder{}}, {BEByteDecoder{}, BEByteEncoder{}}} {
+		msgType.SetByteDecoder(order.decoder)
+		msgType.SetByteEncoder(order.encoder)
+		var buf bytes.Buffer
+		if err := original.Serialize(&buf); err != nil {
+			t.Fatal(err)
+		}
+		msg := msgType.Acquire()
+		for i := 0; i < 2; i++ {
+			if err := msg.Deserialize(bytes.NewReader(buf.Bytes())); err != nil {
+				t.Fatal(err)
+			}
+			if diff, err := original.Diff(msg); err != nil || len(diff) != 0 {
+				t.Fatalf("%T: deserialized message differs: %v, %v", order.decoder, diff, err)
+			}
+		}
+		c, _ := msg.Get("c")
+		if err := msg.Deserialize(bytes.NewReader(buf.Bytes())); err != nil {
+			t.Fatal(err)
+		}
+		if current, _ := msg.Get("c"); &current.([]int32)[0] != &c.([]int32)[0] {
+			t.Fatalf("%T: expected c to be reused", order.decoder)
+		}
+		msg.Release()
+	}
+}
+
+func TestDynamicMessage_AcquireNeverReusesAliasedArrays(t *testing.T) {
+	msgType := newBinaryTestType(t)
+	msg := msgType.Acquire()
+	defer msg.Release()
+
+	aliased := mustDecodeHex(t, "01"+"04000000"+"01020304"+"01000000"+"02000000")
+	if err := msg.DeserializeZeroCopy(aliased); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := msg.Get("b")
+	if &b.([]uint8)[0] != &aliased[5] {
+		t.Fatal("expected b to alias the buffer")
+	}
+
+	// Deserializing into the message again leaves the caller's buffer alone.
+	input := mustDecodeHex(t, "02"+"02000000"+"0506"+"03000000"+"04000000")
+	if err := msg.Deserialize(bytes.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	if aliased[5] != 1 || aliased[6] != 2 {
+		t.Fatalf("expected the aliased buffer to be left alone, got %v", aliased)
+	}
+	b, _ = msg.Get("b")
+	if &b.([]uint8)[0] == &aliased[5] {
+		t.Fatal("expected b not to be reused")
+	}
+
+	// Arrays which were copied are reused as usual.
+	if err := msg.Deserialize(bytes.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	if current, _ := msg.Get("b"); &current.([]uint8)[0] != &b.([]uint8)[0] {
+		t.Fatal("expected b to be reused")
+	}
+}
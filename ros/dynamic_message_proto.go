@@ -0,0 +1,518 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// Protobuf wire types; groups aren't used by the mapping, so aren't supported.
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+	protoWireFixed32 = 5
+)
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+//	DynamicMessage
+
+// MarshalProto encodes the message in protobuf wire format, as the message declared by libgengo.GenerateProto() for the message's type.  Fields are numbered in definition order;
+// as in proto3, scalar fields with zero values and empty arrays are left out, but nested messages, times and durations are always written.
+func (m *DynamicMessage) MarshalProto() ([]byte, error) {
+	if err := messagePointerError(m); err != nil {
+		return nil, err
+	}
+	return m.appendProto(nil)
+}
+
+// UnmarshalProto decodes a message in protobuf wire format, as produced by MarshalProto() or any protobuf implementation using the .proto file generated for the message's type.
+// Fields which aren't given are zeroed, and unknown fields are skipped.  Integers are range checked against the types of the fields, as are the lengths of fixed size arrays.
+func (m *DynamicMessage) UnmarshalProto(buf []byte) error {
+	if m == nil || m.dynamicType == nil {
+		return errors.New("nil pointer to DynamicMessage")
+	}
+	data, err := m.dynamicType.zeroValueData()
+	if err != nil {
+		return err
+	}
+	m.data = data
+	m.raw = nil
+	m.rawOffsets = nil
+	return m.mergeProto(buf)
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// protoWireType returns the wire type of a protobuf type, as returned by libgengo.ToProtoType().
+func protoWireType(protoType string) int {
+	switch protoType {
+	case "bool", "int32", "int64", "uint32", "uint64":
+		return protoWireVarint
+	case "float":
+		return protoWireFixed32
+	case "double":
+		return protoWireFixed64
+	}
+	return protoWireBytes
+}
+
+// appendProtoVarint appends an unsigned integer in base 128, least significant group first.
+func appendProtoVarint(buf []byte, value uint64) []byte {
+	for value >= 0x80 {
+		buf = append(buf, byte(value)|0x80)
+		value >>= 7
+	}
+	return append(buf, byte(value))
+}
+
+// appendProtoFixed appends the low size bytes of an unsigned integer, least significant first.
+func appendProtoFixed(buf []byte, value uint64, size int) []byte {
+	for i := 0; i < size; i++ {
+		buf = append(buf, byte(value>>(8*uint(i))))
+	}
+	return buf
+}
+
+// appendProtoBytes appends a length delimited field.
+func appendProtoBytes(buf []byte, number int, value []byte) []byte {
+	buf = appendProtoVarint(buf, uint64(number)<<3|protoWireBytes)
+	buf = appendProtoVarint(buf, uint64(len(value)))
+	return append(buf, value...)
+}
+
+// appendProtoScalar appends the payload of a scalar value, without its key, returning false if the value is zero and can be left out of singular fields.
+func appendProtoScalar(buf []byte, value interface{}) ([]byte, bool, error) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return appendProtoVarint(buf, 1), true, nil
+		}
+		return appendProtoVarint(buf, 0), false, nil
+	case JsonFloat32:
+		bits := math.Float32bits(v.F)
+		return appendProtoFixed(buf, uint64(bits), 4), bits != 0, nil
+	case JsonFloat64:
+		bits := math.Float64bits(v.F)
+		return appendProtoFixed(buf, bits, 8), bits != 0, nil
+	}
+	i, u, isUnsigned, ok := goNumberToInteger(value)
+	if !ok {
+		return nil, false, errors.New("cannot encode " + describeValue(value))
+	}
+	if isUnsigned {
+		return appendProtoVarint(buf, u), u != 0, nil
+	}
+	// Negative numbers are sign extended to 64 bits, as protobuf requires for int32 as well as int64.
+	return appendProtoVarint(buf, uint64(i)), i != 0, nil
+}
+
+// appendProtoTemporal appends the payload of a Timestamp or Duration; the nanoseconds have the same sign as the seconds.
+func appendProtoTemporal(buf []byte, seconds int64, nanos int64) []byte {
+	if seconds != 0 {
+		buf = appendProtoVarint(appendProtoVarint(buf, 1<<3|protoWireVarint), uint64(seconds))
+	}
+	if nanos != 0 {
+		buf = appendProtoVarint(appendProtoVarint(buf, 2<<3|protoWireVarint), uint64(nanos))
+	}
+	return buf
+}
+
+// protoTimeParts returns the seconds and nanoseconds of the Timestamp for a time.
+func protoTimeParts(t Time) (int64, int64) {
+	return int64(t.Sec) + int64(t.NSec/1e9), int64(t.NSec % 1e9)
+}
+
+// protoDurationParts returns the seconds and nanoseconds of the Duration for a duration, whose seconds are signed.
+func protoDurationParts(d Duration) (int64, int64) {
+	total := int64(int32(d.Sec))*1e9 + int64(d.NSec)
+	return total / 1e9, total % 1e9
+}
+
+// appendProtoField appends a field of a message, with its key.
+func appendProtoField(buf []byte, field *libgengo.Field, number int, value interface{}) ([]byte, error) {
+	protoType := libgengo.ToProtoType(field)
+	if protoType == "bytes" {
+		data, ok := value.([]uint8)
+		if !ok {
+			return nil, errors.New("cannot encode " + describeValue(value) + " as bytes")
+		}
+		if len(data) == 0 {
+			return buf, nil
+		}
+		return appendProtoBytes(buf, number, data), nil
+	}
+
+	if !field.IsArray {
+		return appendProtoElement(buf, number, protoType, value, false)
+	}
+
+	array := reflect.ValueOf(value)
+	if !array.IsValid() || array.Kind() != reflect.Slice {
+		return nil, errors.New("cannot encode " + describeValue(value) + " as an array")
+	}
+	if array.Len() == 0 {
+		return buf, nil
+	}
+	if protoWireType(protoType) == protoWireBytes {
+		for i := 0; i < array.Len(); i++ {
+			var err error
+			if buf, err = appendProtoElement(buf, number, protoType, array.Index(i).Interface(), true); err != nil {
+				return nil, errors.Wrap(err, "index "+strconv.Itoa(i))
+			}
+		}
+		return buf, nil
+	}
+
+	// Arrays of scalars are packed.
+	packed := make([]byte, 0, array.Len())
+	for i := 0; i < array.Len(); i++ {
+		var err error
+		if packed, _, err = appendProtoScalar(packed, array.Index(i).Interface()); err != nil {
+			return nil, errors.Wrap(err, "index "+strconv.Itoa(i))
+		}
+	}
+	return appendProtoBytes(buf, number, packed), nil
+}
+
+// appendProtoElement appends a singular field, or an element of a repeated field, with its key; zero scalars are left out unless they're elements.
+func appendProtoElement(buf []byte, number int, protoType string, value interface{}, isElement bool) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		if len(v) == 0 && !isElement {
+			return buf, nil
+		}
+		return appendProtoBytes(buf, number, []byte(v)), nil
+	case Time:
+		seconds, nanos := protoTimeParts(v)
+		return appendProtoBytes(buf, number, appendProtoTemporal(nil, seconds, nanos)), nil
+	case Duration:
+		seconds, nanos := protoDurationParts(v)
+		return appendProtoBytes(buf, number, appendProtoTemporal(nil, seconds, nanos)), nil
+	case *DynamicMessage:
+		if err := messagePointerError(v); err != nil {
+			return nil, err
+		}
+		payload, err := v.appendProto(nil)
+		if err != nil {
+			return nil, err
+		}
+		return appendProtoBytes(buf, number, payload), nil
+	}
+
+	wireType := protoWireType(protoType)
+	if wireType == protoWireBytes {
+		return nil, errors.New("cannot encode " + describeValue(value) + " as " + protoType)
+	}
+	payload, nonZero, err := appendProtoScalar(nil, value)
+	if err != nil {
+		return nil, err
+	}
+	if !nonZero && !isElement {
+		return buf, nil
+	}
+	buf = appendProtoVarint(buf, uint64(number)<<3|uint64(wireType))
+	return append(buf, payload...), nil
+}
+
+// consumeProtoVarint reads an unsigned integer in base 128, returning the rest of the buffer.
+func consumeProtoVarint(buf []byte) (uint64, []byte, error) {
+	var value uint64
+	for i := 0; i < len(buf) && i < 10; i++ {
+		value |= uint64(buf[i]&0x7f) << (7 * uint(i))
+		if buf[i] < 0x80 {
+			return value, buf[i+1:], nil
+		}
+	}
+	if len(buf) < 10 {
+		return 0, nil, errors.New("truncated varint")
+	}
+	return 0, nil, errors.New("varint too long")
+}
+
+// consumeProtoFixed reads a little endian unsigned integer of the specified number of bytes, returning the rest of the buffer.
+func consumeProtoFixed(buf []byte, size int) (uint64, []byte, error) {
+	if len(buf) < size {
+		return 0, nil, errors.New("truncated fixed" + strconv.Itoa(8*size))
+	}
+	var value uint64
+	for i := size - 1; i >= 0; i-- {
+		value = value<<8 | uint64(buf[i])
+	}
+	return value, buf[size:], nil
+}
+
+// consumeProtoValue reads the value of a field with the specified wire type; varints and fixed values are returned as integers, and length delimited values as payloads.
+func consumeProtoValue(buf []byte, wireType int) (uint64, []byte, []byte, error) {
+	switch wireType {
+	case protoWireVarint:
+		value, rest, err := consumeProtoVarint(buf)
+		return value, nil, rest, err
+	case protoWireFixed64:
+		value, rest, err := consumeProtoFixed(buf, 8)
+		return value, nil, rest, err
+	case protoWireFixed32:
+		value, rest, err := consumeProtoFixed(buf, 4)
+		return value, nil, rest, err
+	case protoWireBytes:
+		length, rest, err := consumeProtoVarint(buf)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		if length > uint64(len(rest)) {
+			return 0, nil, nil, errors.New("truncated length delimited field")
+		}
+		return 0, rest[:length], rest[length:], nil
+	}
+	return 0, nil, nil, errors.New("unsupported wire type " + strconv.Itoa(wireType))
+}
+
+// consumeProtoTemporal reads the seconds and nanoseconds of a Timestamp or Duration.
+func consumeProtoTemporal(payload []byte) (int64, int64, error) {
+	var seconds, nanos int64
+	for len(payload) > 0 {
+		key, rest, err := consumeProtoVarint(payload)
+		if err != nil {
+			return 0, 0, err
+		}
+		value, _, rest, err := consumeProtoValue(rest, int(key&7))
+		if err != nil {
+			return 0, 0, err
+		}
+		payload = rest
+		switch {
+		case key == 1<<3|protoWireVarint:
+			seconds = int64(value)
+		case key == 2<<3|protoWireVarint:
+			nanos = int64(int32(value))
+		case key>>3 == 1 || key>>3 == 2:
+			return 0, 0, errors.New("unexpected wire type " + strconv.Itoa(int(key&7)) + " in time")
+		}
+	}
+	if nanos <= -1e9 || nanos >= 1e9 {
+		return 0, 0, errors.New("nanoseconds out of range")
+	}
+	return seconds, nanos, nil
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+//	DynamicMessage
+
+// appendProto appends the fields of the message in protobuf wire format.
+func (m *DynamicMessage) appendProto(buf []byte) ([]byte, error) {
+	if err := m.decodeLazyFields(); err != nil {
+		return nil, err
+	}
+	for i := range m.dynamicType.spec.Fields {
+		field := &m.dynamicType.spec.Fields[i]
+		value, ok := m.data[field.Name]
+		if !ok {
+			return nil, errors.New("Field: " + field.Name + ": No data found.")
+		}
+		var err error
+		if buf, err = appendProtoField(buf, field, libgengo.ProtoFieldNumber(i), value); err != nil {
+			return nil, errors.Wrap(err, "Field: "+field.Name)
+		}
+	}
+	return buf, nil
+}
+
+// mergeProto decodes fields in protobuf wire format into the message: singular fields are replaced, except for nested messages which are merged, and arrays are appended to.
+func (m *DynamicMessage) mergeProto(buf []byte) error {
+	t := m.dynamicType
+	if t == nil || t.spec == nil {
+		return errors.New("nil pointer to MsgSpec")
+	}
+	if err := m.decodeLazyFields(); err != nil {
+		return err
+	}
+
+	arrays := make(map[int][]interface{})
+	for len(buf) > 0 {
+		key, rest, err := consumeProtoVarint(buf)
+		if err != nil {
+			return err
+		}
+		number, wireType := key>>3, int(key&7)
+		if number == 0 {
+			return errors.New("invalid field number 0")
+		}
+		scalar, payload, rest, err := consumeProtoValue(rest, wireType)
+		if err != nil {
+			return errors.Wrap(err, "field number "+strconv.FormatUint(number, 10))
+		}
+		buf = rest
+
+		// Unknown fields are skipped, so that older definitions can read newer messages.
+		if number > uint64(len(t.spec.Fields)) {
+			continue
+		}
+		index := int(number) - 1
+		field := &t.spec.Fields[index]
+
+		if err := m.mergeProtoField(index, wireType, scalar, payload, arrays); err != nil {
+			return errors.Wrap(err, "Field: "+field.Name)
+		}
+	}
+
+	// Store the decoded array elements.
+	for index, values := range arrays {
+		field := &t.spec.Fields[index]
+		if field.ArrayLen >= 0 && len(values) != field.ArrayLen {
+			return errors.New("Field: " + field.Name + ": expected array of length " + strconv.Itoa(field.ArrayLen) + ", got " + strconv.Itoa(len(values)))
+		}
+		array, err := t.zeroValueArray(field, len(values))
+		if err != nil {
+			return errors.Wrap(err, "Field: "+field.Name)
+		}
+		arrayValue := reflect.ValueOf(array)
+		for i, value := range values {
+			arrayValue.Index(i).Set(reflect.ValueOf(value))
+		}
+		if existing, ok := m.data[field.Name]; ok && field.ArrayLen < 0 {
+			arrayValue = reflect.AppendSlice(reflect.ValueOf(existing), arrayValue)
+		}
+		m.data[field.Name] = arrayValue.Interface()
+	}
+	return nil
+}
+
+// mergeProtoField decodes the value of the field at index into the message, or into arrays for array fields.
+func (m *DynamicMessage) mergeProtoField(index int, wireType int, scalar uint64, payload []byte, arrays map[int][]interface{}) error {
+	t := m.dynamicType
+	field := &t.spec.Fields[index]
+	protoType := libgengo.ToProtoType(field)
+
+	if protoType == "bytes" {
+		if wireType != protoWireBytes {
+			return errors.New("unexpected wire type " + strconv.Itoa(wireType) + " for bytes")
+		}
+		if field.ArrayLen >= 0 && len(payload) != field.ArrayLen {
+			return errors.New("expected array of length " + strconv.Itoa(field.ArrayLen) + ", got " + strconv.Itoa(len(payload)))
+		}
+		m.data[field.Name] = append([]uint8{}, payload...)
+		return nil
+	}
+
+	elementWireType := protoWireType(protoType)
+	if field.IsArray && wireType == protoWireBytes && elementWireType != protoWireBytes {
+		// Packed elements.
+		for len(payload) > 0 {
+			value, _, rest, err := consumeProtoValue(payload, elementWireType)
+			if err != nil {
+				return err
+			}
+			payload = rest
+			element, err := t.decodeProtoElement(field, protoType, value, nil)
+			if err != nil {
+				return errors.Wrap(err, "index "+strconv.Itoa(len(arrays[index])))
+			}
+			arrays[index] = append(arrays[index], element)
+		}
+		return nil
+	}
+
+	if wireType != elementWireType {
+		return errors.New("unexpected wire type " + strconv.Itoa(wireType) + " for " + protoType)
+	}
+	if field.IsArray {
+		element, err := t.decodeProtoElement(field, protoType, scalar, payload)
+		if err != nil {
+			return errors.Wrap(err, "index "+strconv.Itoa(len(arrays[index])))
+		}
+		arrays[index] = append(arrays[index], element)
+		return nil
+	}
+
+	// Nested messages which appear more than once are merged.
+	if nested, ok := m.data[field.Name].(*DynamicMessage); ok && !field.IsBuiltin {
+		return nested.mergeProto(payload)
+	}
+	element, err := t.decodeProtoElement(field, protoType, scalar, payload)
+	if err != nil {
+		return err
+	}
+	m.data[field.Name] = element
+	return nil
+}
+
+//	DynamicMessageType
+
+// decodeProtoElement decodes a singular value, or an element of an array, into the representation DynamicMessage uses for the field.
+func (t *DynamicMessageType) decodeProtoElement(field *libgengo.Field, protoType string, scalar uint64, payload []byte) (interface{}, error) {
+	switch protoType {
+	case "bool":
+		return scalar != 0, nil
+	case "int32":
+		// Only the low 32 bits are significant.
+		return coerceInteger(field.GoType, int64(int32(scalar)))
+	case "int64":
+		return coerceInteger(field.GoType, int64(scalar))
+	case "uint32":
+		return coerceInteger(field.GoType, uint64(uint32(scalar)))
+	case "uint64":
+		return coerceInteger(field.GoType, scalar)
+	case "float":
+		return JsonFloat32{F: math.Float32frombits(uint32(scalar))}, nil
+	case "double":
+		return JsonFloat64{F: math.Float64frombits(scalar)}, nil
+	case "string":
+		return string(payload), nil
+	case libgengo.ProtoTimestampType:
+		seconds, nanos, err := consumeProtoTemporal(payload)
+		if err != nil {
+			return nil, err
+		}
+		if seconds < 0 || seconds > math.MaxUint32 || nanos < 0 {
+			return nil, errors.New("time out of range")
+		}
+		return Time{temporal{Sec: uint32(seconds), NSec: uint32(nanos)}}, nil
+	case libgengo.ProtoDurationType:
+		seconds, nanos, err := consumeProtoTemporal(payload)
+		if err != nil {
+			return nil, err
+		}
+		if seconds < math.MinInt32-1 || seconds > math.MaxInt32+1 {
+			return nil, errors.New("duration out of range")
+		}
+		// ROS durations have signed seconds but positive nanoseconds.
+		total := seconds*1e9 + nanos
+		sec, nsec := total/1e9, total%1e9
+		if nsec < 0 {
+			sec, nsec = sec-1, nsec+1e9
+		}
+		if sec < math.MinInt32 || sec > math.MaxInt32 {
+			return nil, errors.New("duration out of range")
+		}
+		return Duration{temporal{Sec: uint32(int32(sec)), NSec: uint32(nsec)}}, nil
+	}
+
+	msgType, err := t.getNestedTypeFromField(field)
+	if err != nil {
+		return nil, err
+	}
+	msg := msgType.NewDynamicMessage()
+	if err := msg.mergeProto(payload); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// ALL DONE.
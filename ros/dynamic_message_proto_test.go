@@ -0,0 +1,128 @@
+package ros
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"testing"
+)
+
+func TestDynamicMessage_ProtoRoundTrip(t *testing.T) {
+	msg := newJSONOptionsTestMessage(t)
+	if err := msg.Set("max_count", int32(math.MinInt32)); err != nil {
+		t.Fatal(err)
+	}
+	path := newPathTestType(t).NewDynamicMessage()
+	if err := path.Set("points", []interface{}{map[string]interface{}{"x": 1.5}, map[string]interface{}{}, map[string]interface{}{"z": -2.0}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := path.Set("counts", []int32{7, 0, -9}); err != nil {
+		t.Fatal(err)
+	}
+	if err := path.Set("pose.id", 200); err != nil {
+		t.Fatal(err)
+	}
+	if err := path.Set("period", NewDuration(uint32(0xfffffffe), 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, original := range []*DynamicMessage{msg, path, path.dynamicType.NewDynamicMessage()} {
+		buf, err := original.MarshalProto()
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := original.dynamicType.NewDynamicMessage()
+		if err := result.UnmarshalProto(buf); err != nil {
+			t.Fatalf("%x: %v", buf, err)
+		}
+		if diff, err := original.Diff(result, WithNaNEqual()); err != nil || len(diff) != 0 {
+			t.Fatalf("%x: unmarshalled message differs: %v, %v", buf, diff, err)
+		}
+	}
+}
+
+func TestDynamicMessage_MarshalProto(t *testing.T) {
+	msg, err := newBinaryTestType(t).NewDynamicMessageFromMap(map[string]interface{}{"a": -1, "b": []byte{1, 2}, "t": NewTime(1, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := msg.MarshalProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "08ffffffffffffffffff01" + "12020102" + "1a020801"; hex.EncodeToString(buf) != expected {
+		t.Fatalf("expected %s, got %x", expected, buf)
+	}
+
+	// Zero scalars and empty arrays are left out, but times are not.
+	buf, err = msg.dynamicType.NewDynamicMessage().MarshalProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, []byte{0x1a, 0x00}) {
+		t.Fatalf("expected 1a00, got %x", buf)
+	}
+
+	// Durations are normalised so that the seconds and nanoseconds have the same sign.
+	msg = newPathTestType(t).NewDynamicMessage()
+	if err := msg.Set("period", NewDuration(uint32(0xffffffff), 750000000)); err != nil {
+		t.Fatal(err)
+	}
+	buf, err = msg.MarshalProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "0a00" + "120b10809be588ffffffffff01" + "22020a00" + "3203000000"; hex.EncodeToString(buf) != expected {
+		t.Fatalf("expected %s, got %x", expected, buf)
+	}
+}
+
+func TestDynamicMessage_UnmarshalProto(t *testing.T) {
+	msgType := newPathTestType(t)
+
+	// Unpacked arrays, unknown fields and nested messages given more than once.
+	input := "3007" + "3008" + "3009" + "a00105" + "22021005" + "220b0a0909000000000000f83f" + "1a036d6170"
+	buf, _ := hex.DecodeString(input)
+	msg := msgType.NewDynamicMessage()
+	if err := msg.Set("frame_id", "stale"); err != nil {
+		t.Fatal(err)
+	}
+	if err := msg.UnmarshalProto(buf); err != nil {
+		t.Fatal(err)
+	}
+	if counts, _ := msg.Get("counts"); counts.([]int32)[2] != 9 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+	if id, _ := msg.GetInt64("pose.id"); id != 5 {
+		t.Fatalf("expected pose.id 5, got %v", id)
+	}
+	if x, _ := msg.GetFloat64("pose.position.x"); x != 1.5 {
+		t.Fatalf("expected pose.position.x 1.5, got %v", x)
+	}
+	if frameID, _ := msg.GetString("frame_id"); frameID != "map" {
+		t.Fatalf("expected frame_id map, got %v", frameID)
+	}
+
+	errorCases := []string{
+		"1801",                       // Wrong wire type.
+		"32020102",                   // Wrong fixed array length.
+		"2203108002",                 // Out of range.
+		"0a",                         // Missing length.
+		"1a056d6170",                 // Truncated string.
+		"0a0b08ffffffffffffffffff01", // Negative time.
+		"0a06108094ebdc03",           // Nanoseconds out of range.
+		"0b",                         // Groups.
+		"0000",                       // Field number zero.
+		"08ffffffffffffffffffff01",   // Varint too long.
+		"2a020801",                   // Wrong wire type in nested message.
+	}
+	for _, errorCase := range errorCases {
+		buf, err := hex.DecodeString(errorCase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := msgType.NewDynamicMessage().UnmarshalProto(buf); err == nil {
+			t.Errorf("%s: expected error", errorCase)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+	"reflect"
 	"testing"
 
 	gengo "github.com/team-rocos/rosgo/libgengo"
@@ -181,8 +182,9 @@ func TestDynamicMessage_DynamicType_LoadWithRepeatedFieldNames(t *testing.T) {
 }
 
 func TestDynamicMessage_TypeWithRecursion(t *testing.T) {
-	// We don't care about Pose in this step, but we want to load libgengo's context.
-	_, err := NewDynamicMessageType("geometry_msgs/Pose")
+	// We don't care about Pose in this step, but we want to load libgengo's context.  A registry of our own is used, so that no types are cached yet.
+	registry := NewTypeRegistry("")
+	_, err := registry.NewDynamicMessageType("geometry_msgs/Pose")
 
 	if err != nil {
 		t.Skip("test skipped because ROS environment not set up")
@@ -193,9 +195,9 @@ func TestDynamicMessage_TypeWithRecursion(t *testing.T) {
 	}
 	msgSpec := generateTestSpec(fields)
 	msgSpec.FullName = "recursiveMessage"
-	context.RegisterMsg("recursiveMessage", msgSpec)
+	registry.pkgContext.RegisterMsg("recursiveMessage", msgSpec)
 
-	_, err = NewDynamicMessageType("recursiveMessage") // If this isn't handled correctly, we get stack overflow.
+	_, err = registry.NewDynamicMessageType("recursiveMessage") // If this isn't handled correctly, we get stack overflow.
 
 	if err == nil {
 		t.Fatal("recursive message defintion did not result in an error")
@@ -203,8 +205,9 @@ func TestDynamicMessage_TypeWithRecursion(t *testing.T) {
 }
 
 func TestDynamicMessage_TypeWithBuriedRecursion(t *testing.T) {
-	// We don't care about Pose in this step, but we want to load libgengo's context.
-	_, err := NewDynamicMessageType("geometry_msgs/Pose")
+	// We don't care about Pose in this step, but we want to load libgengo's context.  A registry of our own is used, so that no types are cached yet.
+	registry := NewTypeRegistry("")
+	_, err := registry.NewDynamicMessageType("geometry_msgs/Pose")
 
 	if err != nil {
 		t.Skip("test skipped because ROS environment not set up")
@@ -216,23 +219,23 @@ func TestDynamicMessage_TypeWithBuriedRecursion(t *testing.T) {
 	}
 	msgSpec := generateTestSpec(fields)
 	msgSpec.FullName = "xMessage"
-	context.RegisterMsg("xMessage", msgSpec)
+	registry.pkgContext.RegisterMsg("xMessage", msgSpec)
 
 	fields = []gengo.Field{
 		*gengo.NewField("test", "zMessage", "z", true, -1),
 	}
 	msgSpec = generateTestSpec(fields)
 	msgSpec.FullName = "yMessage"
-	context.RegisterMsg("yMessage", msgSpec)
+	registry.pkgContext.RegisterMsg("yMessage", msgSpec)
 
 	fields = []gengo.Field{
 		*gengo.NewField("test", "xMessage", "x", true, -1),
 	}
 	msgSpec = generateTestSpec(fields)
 	msgSpec.FullName = "zMessage"
-	context.RegisterMsg("zMessage", msgSpec)
+	registry.pkgContext.RegisterMsg("zMessage", msgSpec)
 
-	_, err = NewDynamicMessageType("xMessage") // If this isn't handled correctly, we get stack overflow.
+	_, err = registry.NewDynamicMessageType("xMessage") // If this isn't handled correctly, we get stack overflow.
 
 	if err == nil {
 		t.Fatal("recursive message defintion did not result in an error")
@@ -240,8 +243,9 @@ func TestDynamicMessage_TypeWithBuriedRecursion(t *testing.T) {
 }
 
 func TestDynamicMessage_RepeatedTypes_ButNoRecursion(t *testing.T) {
-	// We don't care about Pose in this step, but we want to load libgengo's context.
-	_, err := NewDynamicMessageType("geometry_msgs/Pose")
+	// We don't care about Pose in this step, but we want to load libgengo's context.  A registry of our own is used, so that no types are cached yet.
+	registry := NewTypeRegistry("")
+	_, err := registry.NewDynamicMessageType("geometry_msgs/Pose")
 
 	if err != nil {
 		t.Skip("test skipped because ROS environment not set up")
@@ -253,7 +257,7 @@ func TestDynamicMessage_RepeatedTypes_ButNoRecursion(t *testing.T) {
 	}
 	msgSpec := generateTestSpec(fields)
 	msgSpec.FullName = "xMessage"
-	context.RegisterMsg("xMessage", msgSpec)
+	registry.pkgContext.RegisterMsg("xMessage", msgSpec)
 
 	fields = []gengo.Field{
 		*gengo.NewField("test", "xMessage", "x1", true, -1),
@@ -261,9 +265,9 @@ func TestDynamicMessage_RepeatedTypes_ButNoRecursion(t *testing.T) {
 	}
 	msgSpec = generateTestSpec(fields)
 	msgSpec.FullName = "zMessage"
-	context.RegisterMsg("zMessage", msgSpec)
+	registry.pkgContext.RegisterMsg("zMessage", msgSpec)
 
-	_, err = NewDynamicMessageType("zMessage")
+	_, err = registry.NewDynamicMessageType("zMessage")
 
 	if err != nil {
 		t.Fatalf("Recursion false positives, error: %v", err)
@@ -271,8 +275,9 @@ func TestDynamicMessage_RepeatedTypes_ButNoRecursion(t *testing.T) {
 }
 
 func TestDynamicMessage_RepeatedBuriedTypes_ButNoRecursion(t *testing.T) {
-	// We don't care about Pose in this step, but we want to load libgengo's context.
-	_, err := NewDynamicMessageType("geometry_msgs/Pose")
+	// We don't care about Pose in this step, but we want to load libgengo's context.  A registry of our own is used, so that no types are cached yet.
+	registry := NewTypeRegistry("")
+	_, err := registry.NewDynamicMessageType("geometry_msgs/Pose")
 
 	if err != nil {
 		t.Skip("test skipped because ROS environment not set up")
@@ -284,14 +289,14 @@ func TestDynamicMessage_RepeatedBuriedTypes_ButNoRecursion(t *testing.T) {
 	}
 	msgSpec := generateTestSpec(fields)
 	msgSpec.FullName = "xMessage"
-	context.RegisterMsg("xMessage", msgSpec)
+	registry.pkgContext.RegisterMsg("xMessage", msgSpec)
 
 	fields = []gengo.Field{
 		*gengo.NewField("test", "xMessage", "x", true, -1),
 	}
 	msgSpec = generateTestSpec(fields)
 	msgSpec.FullName = "yMessage"
-	context.RegisterMsg("yMessage", msgSpec)
+	registry.pkgContext.RegisterMsg("yMessage", msgSpec)
 
 	fields = []gengo.Field{
 		*gengo.NewField("test", "xMessage", "x", true, -1),
@@ -299,13 +304,16 @@ func TestDynamicMessage_RepeatedBuriedTypes_ButNoRecursion(t *testing.T) {
 	}
 	msgSpec = generateTestSpec(fields)
 	msgSpec.FullName = "zMessage"
-	context.RegisterMsg("zMessage", msgSpec)
+	registry.pkgContext.RegisterMsg("zMessage", msgSpec)
 
-	_, err = NewDynamicMessageType("zMessage")
+	msgType, err := registry.NewDynamicMessageType("zMessage")
 
 	if err != nil {
 		t.Fatalf("Recursion false positives, error: %v", err)
 	}
+	if len(msgType.spec.Fields) != 2 || msgType.spec.Fields[1].Name != "y" {
+		t.Fatalf("expected the registered zMessage, got fields %v", msgType.spec.Fields)
+	}
 }
 
 func TestDynamicMessage_Deserialize_Unknown(t *testing.T) {
@@ -803,3 +811,74 @@ func generateTestSpec(fields []gengo.Field) *gengo.MsgSpec {
 	msgSpec.Fields = fields
 	return msgSpec
 }
+
+// newConstantsTestType creates a message type which declares constants, loaded from a string.
+func newConstantsTestType(t *testing.T) *DynamicMessageType {
+	ctx, err := gengo.NewPkgContext(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "uint8 PENDING=0\nuint8 SUCCEEDED=3\nbyte FLAG=2\nint32 LIMIT=-5\nfloat64 SCALE=0.5\nstring NAME=goal\n\nuint8 status\nint32 limit\nstring text\nint32[] limits"
+	spec, err := ctx.LoadMsgFromString(text, "test_msgs/GoalStatus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgType, err := newDynamicMessageTypeFromSpecInContext(ctx, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msgType
+}
+
+func TestDynamicMessage_Constants(t *testing.T) {
+	msgType := newConstantsTestType(t)
+
+	expected := []DynamicMessageConstant{
+		{Name: "PENDING", Type: "uint8", Value: uint8(0)},
+		{Name: "SUCCEEDED", Type: "uint8", Value: uint8(3)},
+		{Name: "FLAG", Type: "byte", Value: uint8(2)},
+		{Name: "LIMIT", Type: "int32", Value: int32(-5)},
+		{Name: "SCALE", Type: "float64", Value: float64(0.5)},
+		{Name: "NAME", Type: "string", Value: "goal"},
+	}
+	constants := msgType.Constants()
+	if len(constants) != len(expected) {
+		t.Fatalf("expected %d constants, got %v", len(expected), constants)
+	}
+	for i := range expected {
+		if constants[i] != expected[i] {
+			t.Errorf("constant %d: expected %#v, got %#v", i, expected[i], constants[i])
+		}
+	}
+
+	// Integer fields may be set by constant name.
+	msg := msgType.NewDynamicMessage()
+	for _, name := range []string{"SUCCEEDED", "GoalStatus.SUCCEEDED", "test_msgs/GoalStatus.SUCCEEDED"} {
+		if err := msg.Set("status", name); err != nil {
+			t.Fatal(err)
+		}
+		if v, _ := msg.Get("status"); v != uint8(3) {
+			t.Fatalf("expected uint8 3 for %s, got %#v", name, v)
+		}
+	}
+	if err := msg.Set("status", "LIMIT"); err == nil {
+		t.Fatal("expected error setting uint8 field to int32 constant")
+	}
+	if err := msg.Set("status", "Other.SUCCEEDED"); err == nil {
+		t.Fatal("expected error for constant of another message type")
+	}
+
+	// So may the elements of integer arrays.
+	if err := msg.Set("limits", []interface{}{"LIMIT", 7}); err != nil {
+		t.Fatal(err)
+	}
+	if err := msg.Set("limits[1]", "GoalStatus.LIMIT"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := msg.Get("limits"); !reflect.DeepEqual(v, []int32{-5, -5}) {
+		t.Fatalf("expected limits [-5 -5], got %#v", v)
+	}
+	if err := msg.Set("limits", []string{"LIMIT", "PENDING"}); err == nil {
+		t.Fatal("expected error setting int32 array element to uint8 constant")
+	}
+}
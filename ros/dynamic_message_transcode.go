@@ -0,0 +1,498 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"strconv"
+
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// TranscodeToJSON reads a message of this type serialized by its ByteDecoder from buf and writes it to w as JSON, without building a DynamicMessage.  The JSON is identical to that produced by
+// DynamicMessage.MarshalJSON(), i.e. floats are numbers or "nan"/"+inf"/"-inf", times are {"sec","nsec"} objects and uint8 arrays are base64 strings.
+func (t *DynamicMessageType) TranscodeToJSON(w io.Writer, buf *bytes.Reader) error {
+	if t.spec == nil {
+		return errors.New("dynamic message type spec is nil")
+	}
+	if t.nested == nil {
+		return errors.New("dynamic message type nested is nil")
+	}
+
+	out := make([]byte, 0, t.jsonPrealloc)
+	if err := t.appendJSONFromWire(t.ByteDecoder(), buf, &out); err != nil {
+		return err
+	}
+	if length := len(out); length > t.jsonPrealloc {
+		t.jsonPrealloc = length
+	}
+	_, err := w.Write(out)
+	return err
+}
+
+// TranscodeFromJSON reads a JSON message of this type, in any form accepted by DynamicMessage.UnmarshalJSON(), and writes it to w serialized by its ByteEncoder without building
+// a DynamicMessage.  Fields which are missing from the JSON or are null are serialized as zero values.
+func (t *DynamicMessageType) TranscodeFromJSON(w io.Writer, buf []byte) error {
+	if t.spec == nil {
+		return errors.New("dynamic message type spec is nil")
+	}
+	if t.nested == nil {
+		return errors.New("dynamic message type nested is nil")
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(buf))
+	if err := t.appendWireFromJSON(t.ByteEncoder(), buf, &out); err != nil {
+		return err
+	}
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// encodeIntegerBits encodes the two's complement bits of an integer as the field's integer type.
+func encodeIntegerBits(e ByteEncoder, out *bytes.Buffer, field *libgengo.Field, bits uint64) error {
+	switch field.BuiltInType {
+	case libgengo.Int8:
+		return e.EncodeInt8(out, int8(bits))
+	case libgengo.Int16:
+		return e.EncodeInt16(out, int16(bits))
+	case libgengo.Int32:
+		return e.EncodeInt32(out, int32(bits))
+	case libgengo.Int64:
+		return e.EncodeInt64(out, int64(bits))
+	case libgengo.Uint8:
+		return e.EncodeUint8(out, uint8(bits))
+	case libgengo.Uint16:
+		return e.EncodeUint16(out, uint16(bits))
+	case libgengo.Uint32:
+		return e.EncodeUint32(out, uint32(bits))
+	case libgengo.Uint64:
+		return e.EncodeUint64(out, bits)
+	}
+	return errors.New("unknown integer type " + field.GoType)
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+//	Wire to JSON
+
+// appendJSONFromWire appends a serialized message of this type to out as a JSON object.
+func (t *DynamicMessageType) appendJSONFromWire(d ByteDecoder, buf *bytes.Reader, out *[]byte) error {
+	*out = append(*out, byte('{'))
+	for i := range t.spec.Fields {
+		field := &t.spec.Fields[i]
+		if i > 0 {
+			*out = append(*out, byte(','))
+		}
+		*out = strconv.AppendQuote(*out, field.Name)
+		*out = append(*out, byte(':'))
+
+		var err error
+		if field.IsArray {
+			err = t.appendJSONArrayFromWire(d, buf, field, out)
+		} else {
+			err = t.appendJSONElementFromWire(d, buf, field, out)
+		}
+		if err != nil {
+			return errors.Wrap(err, "Field: "+field.Name)
+		}
+	}
+	*out = append(*out, byte('}'))
+	return nil
+}
+
+// appendJSONArrayFromWire appends a serialized array field to out as JSON.
+func (t *DynamicMessageType) appendJSONArrayFromWire(d ByteDecoder, buf *bytes.Reader, field *libgengo.Field, out *[]byte) error {
+	size := field.ArrayLen
+	if size < 0 {
+		usize, err := d.DecodeUint32(buf)
+		if err != nil {
+			return err
+		}
+		size = int(usize)
+	}
+
+	if field.BuiltInType == libgengo.Uint8 { // Special case, is marshalled as base64.
+		if size > buf.Len() {
+			return errors.New("buffer too short for uint8 array of length " + strconv.Itoa(size))
+		}
+		slice, err := d.DecodeUint8Array(buf, size)
+		if err != nil {
+			return err
+		}
+		return marshalArrayUint8(slice, out)
+	}
+
+	*out = append(*out, byte('['))
+	for i := 0; i < size; i++ {
+		if i > 0 {
+			*out = append(*out, byte(','))
+		}
+		if err := t.appendJSONElementFromWire(d, buf, field, out); err != nil {
+			return errors.Wrap(err, "index "+strconv.Itoa(i))
+		}
+	}
+	*out = append(*out, byte(']'))
+	return nil
+}
+
+// appendJSONElementFromWire appends a single serialized value of the field's type to out as JSON.
+func (t *DynamicMessageType) appendJSONElementFromWire(d ByteDecoder, buf *bytes.Reader, field *libgengo.Field, out *[]byte) error {
+	if field.IsBuiltin == false {
+		// The type encapsulates another ROS message.
+		msgType, err := t.getNestedTypeFromField(field)
+		if err != nil {
+			return err
+		}
+		return msgType.appendJSONFromWire(d, buf, out)
+	}
+
+	switch field.BuiltInType {
+	case libgengo.Bool:
+		value, err := d.DecodeBool(buf)
+		if err != nil {
+			return err
+		}
+		*out = strconv.AppendBool(*out, value)
+	case libgengo.Int8:
+		value, err := d.DecodeInt8(buf)
+		if err != nil {
+			return err
+		}
+		*out = strconv.AppendInt(*out, int64(value), 10)
+	case libgengo.Int16:
+		value, err := d.DecodeInt16(buf)
+		if err != nil {
+			return err
+		}
+		*out = strconv.AppendInt(*out, int64(value), 10)
+	case libgengo.Int32:
+		value, err := d.DecodeInt32(buf)
+		if err != nil {
+			return err
+		}
+		*out = strconv.AppendInt(*out, int64(value), 10)
+	case libgengo.Int64:
+		value, err := d.DecodeInt64(buf)
+		if err != nil {
+			return err
+		}
+		*out = strconv.AppendInt(*out, value, 10)
+	case libgengo.Uint8:
+		value, err := d.DecodeUint8(buf)
+		if err != nil {
+			return err
+		}
+		*out = strconv.AppendUint(*out, uint64(value), 10)
+	case libgengo.Uint16:
+		value, err := d.DecodeUint16(buf)
+		if err != nil {
+			return err
+		}
+		*out = strconv.AppendUint(*out, uint64(value), 10)
+	case libgengo.Uint32:
+		value, err := d.DecodeUint32(buf)
+		if err != nil {
+			return err
+		}
+		*out = strconv.AppendUint(*out, uint64(value), 10)
+	case libgengo.Uint64:
+		value, err := d.DecodeUint64(buf)
+		if err != nil {
+			return err
+		}
+		*out = strconv.AppendUint(*out, value, 10)
+	case libgengo.Float32:
+		value, err := d.DecodeFloat32(buf)
+		if err != nil {
+			return err
+		}
+		marshalFloat(float64(value.F), out, 32)
+	case libgengo.Float64:
+		value, err := d.DecodeFloat64(buf)
+		if err != nil {
+			return err
+		}
+		marshalFloat(value.F, out, 64)
+	case libgengo.String:
+		size, err := d.DecodeUint32(buf)
+		if err != nil {
+			return err
+		}
+		if int(size) > buf.Len() {
+			return errors.New("buffer too short for string of length " + strconv.Itoa(int(size)))
+		}
+		value, err := d.DecodeUint8Array(buf, int(size))
+		if err != nil {
+			return err
+		}
+		*out = strconv.AppendQuote(*out, string(value))
+	case libgengo.Time:
+		value, err := d.DecodeTime(buf)
+		if err != nil {
+			return err
+		}
+		marshalSecNSec(uint64(value.Sec), uint64(value.NSec), out)
+	case libgengo.Duration:
+		value, err := d.DecodeDuration(buf)
+		if err != nil {
+			return err
+		}
+		marshalSecNSec(uint64(value.Sec), uint64(value.NSec), out)
+	default:
+		// Something went wrong.
+		return errors.New("unknown builtin type " + field.GoType)
+	}
+	return nil
+}
+
+//	JSON to wire
+
+// appendWireFromJSON appends the serialized form of a JSON object holding a message of this type to out.
+func (t *DynamicMessageType) appendWireFromJSON(e ByteEncoder, value []byte, out *bytes.Buffer) error {
+	// Check for keys which don't match any field, as UnmarshalJSON() does.
+	err := jsonparser.ObjectEach(value, func(key []byte, _ []byte, _ jsonparser.ValueType, _ int) error {
+		if _, err := t.getFieldByName(string(key)); err != nil {
+			return errors.New("Field Unknown: " + string(key))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range t.spec.Fields {
+		field := &t.spec.Fields[i]
+		fieldValue, dataType, _, err := jsonparser.Get(value, field.Name)
+		if err == jsonparser.KeyPathNotFoundError || dataType == jsonparser.Null {
+			err = t.appendZeroWire(e, field, out)
+		} else if err == nil {
+			if field.IsArray {
+				err = t.appendWireArrayFromJSON(e, field, fieldValue, dataType, out)
+			} else {
+				err = t.appendWireElementFromJSON(e, field, fieldValue, dataType, out)
+			}
+		}
+		if err != nil {
+			return errors.Wrap(err, "field: "+field.Name)
+		}
+	}
+	return nil
+}
+
+// appendWireArrayFromJSON appends the serialized form of a JSON array, or base64 string for uint8 arrays, to out.
+func (t *DynamicMessageType) appendWireArrayFromJSON(e ByteEncoder, field *libgengo.Field, value []byte, dataType jsonparser.ValueType, out *bytes.Buffer) error {
+	if dataType == jsonparser.String && field.BuiltInType == libgengo.Uint8 {
+		data := make([]byte, base64.StdEncoding.DecodedLen(len(value)))
+		n, err := base64.StdEncoding.Decode(data, value)
+		if err != nil {
+			return err
+		}
+		if field.ArrayLen >= 0 && n != field.ArrayLen {
+			return errors.New("fixed array size does not match unmarshalled size, fixed size: " + strconv.Itoa(field.ArrayLen))
+		}
+		if field.ArrayLen < 0 {
+			if err := e.EncodeUint32(out, uint32(n)); err != nil {
+				return err
+			}
+		}
+		out.Write(data[:n])
+		return nil
+	}
+	if dataType != jsonparser.Array {
+		return errors.Wrap(errors.New("attempted to unmarshal singular as array"), "value: "+string(value))
+	}
+
+	// Dynamic arrays start with their length, which is filled in once the elements have been counted.
+	start := out.Len()
+	if field.ArrayLen < 0 {
+		if err := e.EncodeUint32(out, 0); err != nil {
+			return err
+		}
+	}
+	count := 0
+	var err error
+	_, parseErr := jsonparser.ArrayEach(value, func(element []byte, elementType jsonparser.ValueType, _ int, _ error) {
+		if err != nil {
+			return // Stop processing if there is an error.
+		}
+		if err = t.appendWireElementFromJSON(e, field, element, elementType, out); err != nil {
+			err = errors.Wrap(err, "index "+strconv.Itoa(count))
+		}
+		count++
+	})
+	if err != nil {
+		return err
+	}
+	if parseErr != nil {
+		return parseErr
+	}
+
+	if field.ArrayLen >= 0 {
+		if count != field.ArrayLen {
+			return errors.New("fixed array size does not match unmarshalled size, fixed size: " + strconv.Itoa(field.ArrayLen))
+		}
+		return nil
+	}
+	var length bytes.Buffer
+	if err := e.EncodeUint32(&length, uint32(count)); err != nil {
+		return err
+	}
+	copy(out.Bytes()[start:], length.Bytes())
+	return nil
+}
+
+// appendWireElementFromJSON appends the serialized form of a single JSON value of the field's type to out.
+func (t *DynamicMessageType) appendWireElementFromJSON(e ByteEncoder, field *libgengo.Field, value []byte, dataType jsonparser.ValueType, out *bytes.Buffer) error {
+	if field.IsBuiltin == false {
+		if dataType != jsonparser.Object {
+			return errors.New("expected object for " + field.Type + ", got " + dataType.String())
+		}
+		msgType, err := t.getNestedTypeFromField(field)
+		if err != nil {
+			return err
+		}
+		return msgType.appendWireFromJSON(e, value, out)
+	}
+
+	switch field.BuiltInType {
+	case libgengo.Bool:
+		if dataType != jsonparser.Boolean {
+			return errors.New("attempted to parse " + dataType.String() + " as bool")
+		}
+		b, err := jsonparser.ParseBoolean(value)
+		if err != nil {
+			return err
+		}
+		return e.EncodeBool(out, b)
+	case libgengo.Int8, libgengo.Int16, libgengo.Int32, libgengo.Int64, libgengo.Uint8, libgengo.Uint16, libgengo.Uint32, libgengo.Uint64:
+		bits, err := t.parseJSONInteger(field, value, dataType)
+		if err != nil {
+			return err
+		}
+		return encodeIntegerBits(e, out, field, bits)
+	case libgengo.Float32, libgengo.Float64:
+		// Floats which aren't finite are marshalled as strings.
+		if dataType != jsonparser.Number && dataType != jsonparser.String {
+			return errors.New("attempted to parse " + dataType.String() + " as " + field.Type)
+		}
+		f, err := strconv.ParseFloat(string(value), 64)
+		if err != nil {
+			return err
+		}
+		if field.BuiltInType == libgengo.Float32 {
+			return e.EncodeFloat32(out, JsonFloat32{F: float32(f)})
+		}
+		return e.EncodeFloat64(out, JsonFloat64{F: f})
+	case libgengo.String:
+		if dataType != jsonparser.String {
+			return errors.New("attempted to parse " + dataType.String() + " as string")
+		}
+		s, err := jsonparser.ParseString(value)
+		if err != nil {
+			return err
+		}
+		return e.EncodeString(out, s)
+	case libgengo.Time, libgengo.Duration:
+		if dataType != jsonparser.Object {
+			return errors.New("attempted to parse " + dataType.String() + " as " + field.Type)
+		}
+		sec, nsec, err := unmarshalSecNSecObject(value)
+		if err != nil {
+			return err
+		}
+		if field.BuiltInType == libgengo.Time {
+			return e.EncodeTime(out, NewTime(sec, nsec))
+		}
+		return e.EncodeDuration(out, NewDuration(sec, nsec))
+	default:
+		// Something went wrong.
+		return errors.New("unknown builtin type " + field.GoType)
+	}
+}
+
+// parseJSONInteger parses a JSON number, or the name of one of the message's constants, returning its two's complement bits.  Values are truncated to the field's size, as in
+// UnmarshalJSON().
+func (t *DynamicMessageType) parseJSONInteger(field *libgengo.Field, value []byte, dataType jsonparser.ValueType) (uint64, error) {
+	switch dataType {
+	case jsonparser.Number:
+		if i, err := jsonparser.ParseInt(value); err == nil {
+			return uint64(i), nil
+		}
+		if field.BuiltInType == libgengo.Uint64 {
+			return strconv.ParseUint(string(value), 10, 64)
+		}
+		return 0, errors.New("invalid integer " + string(value))
+	case jsonparser.String:
+		constant, ok := t.lookupConstant(field, string(value))
+		if !ok {
+			return 0, errors.New("unknown constant " + string(value))
+		}
+		i, u, isUnsigned, ok := goNumberToInteger(constant)
+		if !ok {
+			return 0, errors.New("invalid constant " + string(value))
+		}
+		if isUnsigned {
+			return u, nil
+		}
+		return uint64(i), nil
+	}
+	return 0, errors.New("attempted to parse " + dataType.String() + " as " + field.Type)
+}
+
+// appendZeroWire appends the serialized form of the zero value of a field to out.
+func (t *DynamicMessageType) appendZeroWire(e ByteEncoder, field *libgengo.Field, out *bytes.Buffer) error {
+	count := 1
+	if field.IsArray {
+		if field.ArrayLen < 0 {
+			// An empty dynamic array is just its length.
+			return e.EncodeUint32(out, 0)
+		}
+		count = field.ArrayLen
+	}
+
+	if field.IsBuiltin {
+		// Zero strings are a zero length, so are the same size as a uint32.
+		size, fixed := builtinSize(field.GoType)
+		if !fixed {
+			size = 4
+		}
+		for i := 0; i < count*size; i++ {
+			out.WriteByte(0)
+		}
+		return nil
+	}
+
+	msgType, err := t.getNestedTypeFromField(field)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		for j := range msgType.spec.Fields {
+			if err := msgType.appendZeroWire(e, &msgType.spec.Fields[j], out); err != nil {
+				return errors.Wrap(err, "field: "+msgType.spec.Fields[j].Name)
+			}
+		}
+	}
+	return nil
+}
+
+// ALL DONE.
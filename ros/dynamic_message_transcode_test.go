@@ -0,0 +1,146 @@
+package ros
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDynamicMessageType_TranscodeToJSON(t *testing.T) {
+	testCases := []struct {
+		msgType *DynamicMessageType
+		wire    []byte
+	}{
+		{&singularMessageType, singularSerialized},
+		{newPathTestType(t), nil},
+		{newPathTestType(t), nil},
+	}
+	testCases[1].wire = serializeTestMessage(t, newYAMLTestMessage(t, testCases[1].msgType))
+
+	// Messages are transcoded using the decoder and encoder of the type.
+	testCases[2].msgType.SetByteDecoder(BEByteDecoder{})
+	testCases[2].msgType.SetByteEncoder(BEByteEncoder{})
+	testCases[2].wire = serializeTestMessage(t, newYAMLTestMessage(t, testCases[2].msgType))
+	if bytes.Equal(testCases[1].wire, testCases[2].wire) {
+		t.Fatal("expected big-endian message to differ")
+	}
+
+	for _, testCase := range testCases {
+		msg := testCase.msgType.NewDynamicMessage()
+		if err := msg.Deserialize(bytes.NewReader(testCase.wire)); err != nil {
+			t.Fatal(err)
+		}
+		expected, err := msg.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The transcoded JSON must match MarshalJSON() exactly.
+		var out bytes.Buffer
+		if err := testCase.msgType.TranscodeToJSON(&out, bytes.NewReader(testCase.wire)); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != string(expected) {
+			t.Fatalf("expected %s, got %s", expected, out.String())
+		}
+
+		// And back again.
+		var wire bytes.Buffer
+		if err := testCase.msgType.TranscodeFromJSON(&wire, out.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(wire.Bytes(), testCase.wire) {
+			t.Fatalf("expected %v, got %v", testCase.wire, wire.Bytes())
+		}
+
+		// Truncated messages are rejected.
+		if err := testCase.msgType.TranscodeToJSON(&out, bytes.NewReader(testCase.wire[:len(testCase.wire)-1])); err == nil {
+			t.Fatal("expected error for truncated message")
+		}
+	}
+}
+
+func TestDynamicMessageType_TranscodeFromJSON(t *testing.T) {
+	msgType := newPathTestType(t)
+
+	// Missing and null fields are zeroed, in the same way as UnmarshalJSON().
+	json := []byte(`{"frame_id":"map\n","scale":"nan","pose":{"id":3},"points":[{"x":1},{"y":-2}],"stamp":null}`)
+	var wire bytes.Buffer
+	if err := msgType.TranscodeFromJSON(&wire, json); err != nil {
+		t.Fatal(err)
+	}
+	expected := msgType.NewDynamicMessage()
+	if err := expected.UnmarshalJSON(json); err != nil {
+		t.Fatal(err)
+	}
+	result := msgType.NewDynamicMessage()
+	if err := result.Deserialize(bytes.NewReader(wire.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if diff, err := expected.Diff(result, WithNaNEqual()); err != nil || len(diff) != 0 {
+		t.Fatalf("transcoded message differs from unmarshalled: %v, %v", diff, err)
+	}
+
+	// Integer fields may use constant names.
+	constantsType := newConstantsTestType(t)
+	wire.Reset()
+	if err := constantsType.TranscodeFromJSON(&wire, []byte(`{"status":"SUCCEEDED","limit":"LIMIT","limits":["LIMIT",7]}`)); err != nil {
+		t.Fatal(err)
+	}
+	msg := constantsType.NewDynamicMessage()
+	if err := msg.Deserialize(bytes.NewReader(wire.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := msg.GetInt64("status"); status != 3 {
+		t.Fatalf("expected status 3, got %v", status)
+	}
+	if limits, _ := msg.Get("limits"); !reflect.DeepEqual(limits, []int32{-5, 7}) {
+		t.Fatalf("expected limits [-5 7], got %#v", limits)
+	}
+
+	errorCases := []string{
+		`{"unknown":1}`,
+		`{"counts":[1,2]}`,
+		`{"counts":1}`,
+		`{"frame_id":1}`,
+		`{"pose":{"id":true}}`,
+		`{"pose":[]}`,
+		`{"stamp":{"sec":1}}`,
+		`{"pose":{"id":"UNKNOWN"}}`,
+		`[1]`,
+	}
+	for _, json := range errorCases {
+		if err := msgType.TranscodeFromJSON(&wire, []byte(json)); err == nil {
+			t.Errorf("%s: expected error", json)
+		}
+	}
+}
+
+// Benchmark transcoding across all primitives, compared with deserializing and marshalling.
+func BenchmarkDynamicMessageType_TranscodeToJSON_SingularPrimitives(b *testing.B) {
+	var out bytes.Buffer
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out.Reset()
+		if err := singularMessageType.TranscodeToJSON(&out, bytes.NewReader(singularSerialized)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDynamicMessage_DeserializeMarshalJSON_SingularPrimitives(b *testing.B) {
+	var out bytes.Buffer
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out.Reset()
+		msg := singularMessageType.NewDynamicMessage()
+		if err := msg.Deserialize(bytes.NewReader(singularSerialized)); err != nil {
+			b.Fatal(err)
+		}
+		json, err := msg.MarshalJSON()
+		if err != nil {
+			b.Fatal(err)
+		}
+		out.Write(json)
+	}
+}
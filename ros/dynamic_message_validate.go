@@ -0,0 +1,234 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"encoding/base64"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// NewDynamicMessageFromMap constructs a DynamicMessage from a map of field names to values, such as one decoded from JSON or provided by a script.  Values are coerced into the exact
+// types expected by the message schema: Go ints and float64s become the field's numeric type, []interface{} becomes the field's typed slice, nested maps become nested messages,
+// {"sec", "nsec"} maps become times and durations, and base64 strings become uint8 arrays.  Fields missing from the map are zeroed.
+func (t *DynamicMessageType) NewDynamicMessageFromMap(data map[string]interface{}) (*DynamicMessage, error) {
+	if t == nil || t.spec == nil {
+		return nil, errors.New("invalid dynamic message type")
+	}
+
+	m := t.NewDynamicMessage()
+	if m.data == nil {
+		return nil, errors.New("failed to create zeroed message for " + t.Name())
+	}
+
+	// Visit the keys in order so that errors are reported consistently.
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field, err := t.getFieldByName(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "Field: "+key)
+		}
+		var value interface{}
+		if field.IsArray {
+			value, err = t.coerceArrayValue(field, data[key])
+		} else {
+			value, err = t.coerceSingularValue(field, data[key])
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Field: "+key)
+		}
+		m.data[field.Name] = value
+	}
+	return m, nil
+}
+
+// Validate checks the data of a DynamicMessage against its schema, including all nested messages.  Every problem found is reported as a separate error qualified by the path of the
+// offending field, e.g. "Field: points[2].x: has type int, expected JsonFloat64"; nil is returned for a valid message.
+func (m *DynamicMessage) Validate() []error {
+	if err := messagePointerError(m); err != nil {
+		return []error{err}
+	}
+	return m.validate("")
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// joinPath appends a field name to a field path.
+func joinPath(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// validate checks the data of a DynamicMessage against its schema, prefixing the paths in any errors with the specified path.
+func (m *DynamicMessage) validate(prefix string) []error {
+	var errs []error
+	if err := m.decodeLazyFields(); err != nil {
+		return append(errs, errors.Wrap(err, "Field: "+prefix))
+	}
+
+	for i := range m.dynamicType.spec.Fields {
+		field := &m.dynamicType.spec.Fields[i]
+		path := joinPath(prefix, field.Name)
+		value, ok := m.data[field.Name]
+		if !ok {
+			errs = append(errs, errors.New("Field: "+path+": No data found."))
+			continue
+		}
+		if field.IsArray {
+			errs = append(errs, m.dynamicType.validateArray(field, path, value)...)
+		} else {
+			errs = append(errs, m.dynamicType.validateSingular(field, path, value)...)
+		}
+	}
+
+	// Report any data which doesn't belong to a field, in a consistent order.
+	unknown := make([]string, 0)
+	for key := range m.data {
+		if _, err := m.dynamicType.getFieldByName(key); err != nil {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		errs = append(errs, errors.New("Field: "+joinPath(prefix, key)+": unknown field in "+m.dynamicType.Name()+"."))
+	}
+
+	return errs
+}
+
+// validateArray checks the value of an array field.
+func (t *DynamicMessageType) validateArray(field *libgengo.Field, path string, value interface{}) []error {
+	if !field.IsBuiltin {
+		messages, ok := value.([]Message)
+		if !ok {
+			return []error{newPathTypeError(path, value, "[]Message")}
+		}
+		if field.ArrayLen >= 0 && len(messages) != field.ArrayLen {
+			return []error{errors.New("Field: " + path + ": expected array of length " + strconv.Itoa(field.ArrayLen) + ", got " + strconv.Itoa(len(messages)) + ".")}
+		}
+		var errs []error
+		for i, msg := range messages {
+			errs = append(errs, t.validateSingular(field, path+"["+strconv.Itoa(i)+"]", msg)...)
+		}
+		return errs
+	}
+
+	zero, err := t.zeroValueArray(field, 0)
+	if err != nil {
+		return []error{errors.Wrap(err, "Field: "+path)}
+	}
+	arrayValue := reflect.ValueOf(value)
+	if !arrayValue.IsValid() || arrayValue.Type() != reflect.TypeOf(zero) {
+		return []error{newPathTypeError(path, value, reflect.TypeOf(zero).String())}
+	}
+	if field.ArrayLen >= 0 && arrayValue.Len() != field.ArrayLen {
+		return []error{errors.New("Field: " + path + ": expected array of length " + strconv.Itoa(field.ArrayLen) + ", got " + strconv.Itoa(arrayValue.Len()) + ".")}
+	}
+	return nil
+}
+
+// validateSingular checks the value of a singular field, or of a single element of an array field.
+func (t *DynamicMessageType) validateSingular(field *libgengo.Field, path string, value interface{}) []error {
+	if !field.IsBuiltin {
+		msgType, err := t.getNestedTypeFromField(field)
+		if err != nil {
+			return []error{errors.Wrap(err, "Field: "+path)}
+		}
+		msg, ok := value.(*DynamicMessage)
+		if !ok || msg == nil {
+			return []error{newPathTypeError(path, value, "*DynamicMessage")}
+		}
+		if err := messagePointerError(msg); err != nil {
+			return []error{errors.Wrap(err, "Field: "+path)}
+		}
+		if msg.dynamicType.Name() != msgType.Name() {
+			return []error{errors.New("Field: " + path + ": found msg " + msg.dynamicType.Name() + ", expected " + msgType.Name() + ".")}
+		}
+		return msg.validate(path)
+	}
+
+	zero, err := t.zeroValueArray(field, 0)
+	if err != nil {
+		return []error{errors.Wrap(err, "Field: "+path)}
+	}
+	expected := reflect.TypeOf(zero).Elem()
+	if value == nil || reflect.TypeOf(value) != expected {
+		return []error{newPathTypeError(path, value, expected.String())}
+	}
+	return nil
+}
+
+// coerceMapValue converts the map representations of times, durations and nested messages, as produced by decoding JSON into a map[string]interface{}, into the representation
+// DynamicMessage uses for the field.
+func (t *DynamicMessageType) coerceMapValue(field *libgengo.Field, value map[string]interface{}) (interface{}, error) {
+	switch field.GoType {
+	case "ros.Time", "ros.Duration":
+		if len(value) != 2 {
+			return nil, errors.New("expected map with keys sec and nsec")
+		}
+		var parts [2]uint32
+		for i, key := range []string{"sec", "nsec"} {
+			part, ok := value[key]
+			if !ok {
+				return nil, errors.New("expected map with keys sec and nsec")
+			}
+			converted, err := coerceInteger("uint32", part)
+			if err != nil {
+				return nil, errors.Wrap(err, key)
+			}
+			parts[i] = converted.(uint32)
+		}
+		if field.GoType == "ros.Time" {
+			return NewTime(parts[0], parts[1]), nil
+		}
+		return NewDuration(parts[0], parts[1]), nil
+	}
+
+	if field.IsBuiltin {
+		return nil, errors.New("cannot use " + describeValue(value) + " as " + field.Type)
+	}
+	msgType, err := t.getNestedTypeFromField(field)
+	if err != nil {
+		return nil, err
+	}
+	return msgType.NewDynamicMessageFromMap(value)
+}
+
+// coerceBase64Value decodes a base64 string into a uint8 array, matching the JSON representation of uint8 arrays.
+func (t *DynamicMessageType) coerceBase64Value(field *libgengo.Field, value string) (interface{}, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if field.ArrayLen >= 0 && len(data) != field.ArrayLen {
+		return nil, errors.New("expected array of length " + strconv.Itoa(field.ArrayLen) + ", got " + strconv.Itoa(len(data)))
+	}
+	return data, nil
+}
+
+// ALL DONE.
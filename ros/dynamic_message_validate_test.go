@@ -0,0 +1,121 @@
+package ros
+
+import (
+	"strings"
+	"testing"
+
+	gengo "github.com/team-rocos/rosgo/libgengo"
+)
+
+func TestDynamicMessage_NewDynamicMessageFromMap(t *testing.T) {
+	msgType := newPathTestType(t)
+
+	msg, err := msgType.NewDynamicMessageFromMap(map[string]interface{}{
+		"stamp":    map[string]interface{}{"sec": 1.0, "nsec": 2},
+		"frame_id": "map",
+		"pose": map[string]interface{}{
+			"position": map[string]interface{}{"x": 1, "y": 2.5},
+			"id":       float64(4),
+		},
+		"points": []interface{}{
+			map[string]interface{}{"x": 1},
+			map[string]interface{}{"z": -1},
+		},
+		"counts": []interface{}{1, 2, 3},
+		"scale":  0.5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := msg.Validate(); len(errs) != 0 {
+		t.Fatalf("expected valid message, got %v", errs)
+	}
+
+	if v, _ := msg.GetTime("stamp"); v != NewTime(1, 2) {
+		t.Fatalf("unexpected stamp %v", v)
+	}
+	if v, _ := msg.Get("pose.id"); v != uint8(4) {
+		t.Fatalf("unexpected id %#v", v)
+	}
+	if v, _ := msg.GetFloat64("points[1].z"); v != -1 {
+		t.Fatalf("unexpected z %v", v)
+	}
+	// Missing fields are zeroed.
+	if v, _ := msg.GetDuration("period"); v != (Duration{}) {
+		t.Fatalf("unexpected period %v", v)
+	}
+}
+
+func TestDynamicMessage_NewDynamicMessageFromMapErrors(t *testing.T) {
+	msgType := newPathTestType(t)
+
+	testCases := []struct {
+		data     map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"missing": 1}, "Field: missing: no field missing"},
+		{map[string]interface{}{"counts": []interface{}{1, 2}}, "Field: counts: expected array of length 3"},
+		{map[string]interface{}{"pose": map[string]interface{}{"id": 300}}, "Field: pose: Field: id: int value overflows uint8"},
+		{map[string]interface{}{"stamp": map[string]interface{}{"sec": 1}}, "Field: stamp: expected map with keys sec and nsec"},
+		{map[string]interface{}{"points": []interface{}{1}}, "Field: points: index 0: cannot use int value as geometry_msgs/Point"},
+	}
+	for _, testCase := range testCases {
+		_, err := msgType.NewDynamicMessageFromMap(testCase.data)
+		if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+			t.Errorf("expected error containing %q, got %v", testCase.expected, err)
+		}
+	}
+}
+
+func TestDynamicMessage_NewDynamicMessageFromMapBase64(t *testing.T) {
+	fields := []gengo.Field{
+		*gengo.NewField("Testing", "uint8", "data", true, -1),
+	}
+	msgType := &DynamicMessageType{
+		spec:   generateTestSpec(fields),
+		nested: make(map[string]*DynamicMessageType),
+	}
+
+	msg, err := msgType.NewDynamicMessageFromMap(map[string]interface{}{"data": "AQID"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := msg.data["data"].([]uint8); len(data) != 3 || data[2] != 3 {
+		t.Fatalf("unexpected data %v", data)
+	}
+}
+
+func TestDynamicMessage_Validate(t *testing.T) {
+	msg := newPathTestType(t).NewDynamicMessage()
+	if errs := msg.Validate(); len(errs) != 0 {
+		t.Fatalf("expected zeroed message to be valid, got %v", errs)
+	}
+
+	// Break the message in a number of ways, bypassing coercion.
+	pose := msg.data["pose"].(*DynamicMessage)
+	position := pose.data["position"].(*DynamicMessage)
+	position.data["x"] = 1
+	delete(pose.data, "id")
+	msg.data["counts"] = []int32{1}
+	msg.data["points"] = []Message{pose}
+	msg.data["scale"] = float32(1)
+	msg.data["extra"] = true
+
+	expected := []string{
+		"Field: pose.position.x: found int value, expected ros.JsonFloat64.",
+		"Field: pose.id: No data found.",
+		"Field: points[0]: found msg test_msgs/Pose, expected geometry_msgs/Point.",
+		"Field: counts: expected array of length 3, got 1.",
+		"Field: scale: found float32 value, expected ros.JsonFloat32.",
+		"Field: extra: unknown field in test_msgs/Path.",
+	}
+	errs := msg.Validate()
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for i := range expected {
+		if errs[i].Error() != expected[i] {
+			t.Errorf("error %d: expected %q, got %q", i, expected[i], errs[i].Error())
+		}
+	}
+}
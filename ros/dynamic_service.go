@@ -43,30 +43,96 @@ type DynamicService struct {
 // ROS service type name.  The first time the function is run, a message/service/action 'context' is created by searching through the available ROS definitions, then the ROS service to
 // be used for the definition is looked up by name.  On subsequent calls, the ROS service type is looked up directly from the existing context.
 func NewDynamicServiceType(typeName string) (*DynamicServiceType, error) {
-	return newDynamicServiceTypeNested(typeName, "")
+	return defaultTypeRegistry.NewDynamicServiceType(typeName)
 }
 
-// newDynamicServiceTypeNested generates a DynamicServiceType from the available ROS definitions.  The first time the function is run, a message/service 'context' is created by
-// searching through the available ROS definitions, then the ROS service type to use for the defintion is looked up by name.  On subsequent calls, the ROS service type
-// is looked up directly from the existing context.  This 'nested' version of the function is able to be called recursively, where packageName should be the typeName of the
-// parent ROS services; this is used internally for handling complex ROS services.
-func newDynamicServiceTypeNested(typeName string, packageName string) (*DynamicServiceType, error) {
+// NewDynamicServiceTypeFromHeader generates a DynamicServiceType purely from the ServiceHeader returned by probing a live service with Node.GetServiceType(); no ROS service
+// or message definitions need to be available locally.  The service server must advertise its request and response definitions, as rosgo service servers do; servers of
+// generated service types need the service's definition to be available to their default TypeRegistry to advertise its nested message definitions.  The MD5 sum computed
+// from the advertised definitions is checked against the MD5 sum advertised by the server.
+func NewDynamicServiceTypeFromHeader(header *ServiceHeader) (*DynamicServiceType, error) {
+	if header == nil {
+		return nil, errors.New("service header is nil")
+	}
+	if header.ServiceType == "" {
+		return nil, errors.New("service header does not contain a service type")
+	}
+	// The definitions of services such as std_srvs/Empty are empty, so only a header which has neither definition is known not to advertise them.
+	if !header.HasDefinitions && header.RequestDefinition == "" && header.ResponseDefinition == "" {
+		return nil, errors.New("service " + header.ServiceType + " does not advertise its request and response definitions")
+	}
+
+	// Use a fresh, empty context so that the advertised definitions can't clash with any locally installed definitions.
+	ctx, err := libgengo.NewPkgContext(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reqName := header.RequestType
+	if reqName == "" {
+		reqName = header.ServiceType + "Request"
+	}
+	resName := header.ResponseType
+	if resName == "" {
+		resName = header.ServiceType + "Response"
+	}
+
+	reqSpec, err := loadMessageDefinition(ctx, reqName, header.RequestDefinition)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading request definition")
+	}
+	resSpec, err := loadMessageDefinition(ctx, resName, header.ResponseDefinition)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading response definition")
+	}
+
+	packageName, shortName := "", header.ServiceType
+	if i := strings.Index(header.ServiceType, "/"); i >= 0 {
+		packageName, shortName = header.ServiceType[:i], header.ServiceType[i+1:]
+	}
+	spec := &libgengo.SrvSpec{
+		Package:   packageName,
+		ShortName: shortName,
+		FullName:  header.ServiceType,
+		Text:      reqSpec.Text + "\n---\n" + resSpec.Text,
+		Request:   reqSpec,
+		Response:  resSpec,
+	}
+	spec.MD5Sum, err = ctx.ComputeSrvMD5(spec)
+	if err != nil {
+		return nil, err
+	}
+	if header.Md5sum != "" && header.Md5sum != "*" && header.Md5sum != spec.MD5Sum {
+		return nil, errors.New("service " + header.ServiceType + ": md5sum " + spec.MD5Sum + " computed from advertised definitions does not match advertised md5sum " + header.Md5sum)
+	}
+
+	// Now we know all about the service!
+	m := new(DynamicServiceType)
+	m.name = spec.FullName
+	m.md5sum = spec.MD5Sum
+	m.text = spec.Text
+	m.reqType, err = newDynamicMessageTypeFromSpecInContext(ctx, reqSpec)
+	if err != nil {
+		return nil, errors.Wrap(err, "error generating request type")
+	}
+	m.resType, err = newDynamicMessageTypeFromSpecInContext(ctx, resSpec)
+	if err != nil {
+		return nil, errors.Wrap(err, "error generating response type")
+	}
+
+	return m, nil
+}
+
+// newDynamicServiceTypeNested generates a DynamicServiceType from the ROS definitions available in the provided context, as loaded by a TypeRegistry.  This 'nested' version of the
+// function is able to be called recursively, where packageName should be the typeName of the parent ROS services; this is used internally for handling complex ROS services.
+func newDynamicServiceTypeNested(ctx *libgengo.PkgContext, typeName string, packageName string) (*DynamicServiceType, error) {
 	// Create an empty action type.
 	m := new(DynamicServiceType)
 
-	// Create a message context if for some reason it does not exist yet, as it also contains service definitions
-	if context == nil {
-		// Create context for our ROS install.
-		c, err := libgengo.NewPkgContext(strings.Split(GetRuntimePackagePath(), ":"))
-		if err != nil {
-			return nil, err
-		}
-		context = c
-	}
 	// We need to try to look up the full name, in case we've just been given a short name.
 	fullname := typeName
 
-	_, ok := context.GetSrvs()[fullname]
+	_, ok := ctx.GetSrvs()[fullname]
 	if !ok {
 		// Seems like the package_name we were give wasn't the full name.
 
@@ -77,7 +143,7 @@ func newDynamicServiceTypeNested(typeName string, packageName string) (*DynamicS
 	}
 
 	// Load context for the target message.
-	spec, err := context.LoadSrv(fullname)
+	spec, err := ctx.LoadSrv(fullname)
 	if err != nil {
 		return nil, err
 	}
@@ -86,11 +152,11 @@ func newDynamicServiceTypeNested(typeName string, packageName string) (*DynamicS
 	m.name = spec.FullName
 	m.md5sum = spec.MD5Sum
 	m.text = spec.Text
-	m.reqType, err = NewDynamicMessageType(spec.Request.FullName)
+	m.reqType, err = newDynamicMessageTypeNestedInContext(ctx, spec.Request.FullName, "", nil, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "error generating request type")
 	}
-	m.resType, err = NewDynamicMessageType(spec.Response.FullName)
+	m.resType, err = newDynamicMessageTypeNestedInContext(ctx, spec.Response.FullName, "", nil, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "error generating request type")
 	}
@@ -162,6 +228,37 @@ func (s *DynamicService) Type() ServiceType {
 
 // DEFINE PRIVATE STATIC FUNCTIONS.
 
+// serviceDefinitions returns the full ROS message definitions of the request and response of a service type for advertising in connection headers.  Generated service types
+// only know the text of their request and response, so their full definitions are resolved through the default TypeRegistry; if the registry doesn't know the service, or
+// knows a different version of it, only the text is advertised.
+func serviceDefinitions(t ServiceType) (string, string) {
+	if _, ok := t.(*DynamicServiceType); !ok {
+		if dynamicType, err := defaultTypeRegistry.NewDynamicServiceType(t.Name()); err == nil && dynamicType.MD5Sum() == t.MD5Sum() {
+			t = dynamicType
+		}
+	}
+	return messageDefinition(t.RequestType()), messageDefinition(t.ResponseType())
+}
+
+// messageDefinition returns the full ROS message definition of a message type for advertising in connection headers.  Only DynamicMessageTypes know about their nested types,
+// so for other message types this is just the message text.
+func messageDefinition(t MessageType) string {
+	if dynamicType, ok := t.(*DynamicMessageType); ok {
+		return dynamicType.Definition()
+	}
+	return t.Text()
+}
+
 // DEFINE PRIVATE RECEIVER FUNCTIONS.
 
+//	DynamicServiceType
+
+// clone returns a copy of the service type whose request and response types are copies too.
+func (t *DynamicServiceType) clone() *DynamicServiceType {
+	m := *t
+	m.reqType = t.reqType.(*DynamicMessageType).clone()
+	m.resType = t.resType.(*DynamicMessageType).clone()
+	return &m
+}
+
 // ALL DONE.
@@ -2,6 +2,8 @@ package ros
 
 import (
 	"testing"
+
+	"github.com/team-rocos/rosgo/libgengo"
 )
 
 func TestDynamicService_ServiceType_Load(t *testing.T) {
@@ -36,3 +38,117 @@ func checkIfValidDynamicMessageType(t *testing.T, msgType MessageType, name stri
 		t.Fatalf("%s type Fields is nil!", name)
 	}
 }
+
+func TestDynamicService_ServiceType_FromHeader(t *testing.T) {
+	header := &ServiceHeader{
+		Md5sum:             "6a2e34150c00229791cc89ff309fff21",
+		RequestType:        "rospy_tutorials/AddTwoIntsRequest",
+		ResponseType:       "rospy_tutorials/AddTwoIntsResponse",
+		ServiceType:        "rospy_tutorials/AddTwoInts",
+		RequestDefinition:  "int64 a\nint64 b",
+		ResponseDefinition: "int64 sum",
+	}
+
+	serviceType, err := NewDynamicServiceTypeFromHeader(header)
+	if err != nil {
+		t.Fatalf("failed to create service type from header: %s", err)
+	}
+	if serviceType.Name() != header.ServiceType {
+		t.Fatalf("expected name %s, got %s", header.ServiceType, serviceType.Name())
+	}
+	if serviceType.MD5Sum() != header.Md5sum {
+		t.Fatalf("expected md5sum %s, got %s", header.Md5sum, serviceType.MD5Sum())
+	}
+	checkIfValidDynamicMessageType(t, serviceType.reqType, "request")
+	checkIfValidDynamicMessageType(t, serviceType.resType, "response")
+
+	srv := serviceType.NewService().(*DynamicService)
+	if _, ok := srv.Request.(*DynamicMessage).Data()["a"].(int64); !ok {
+		t.Fatalf("expected request field a to be an int64")
+	}
+
+	// A mismatched md5sum must be rejected.
+	header.Md5sum = "00000000000000000000000000000000"
+	if _, err := NewDynamicServiceTypeFromHeader(header); err == nil {
+		t.Fatalf("expected error for mismatched md5sum")
+	}
+}
+
+func TestDynamicService_ServiceType_FromHeaderNested(t *testing.T) {
+	// Build a service type with a nested message in its own context, then advertise it via the message definitions.
+	ctx, err := libgengo.NewPkgContext(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctx.LoadMsgFromString("float64 x\nfloat64 y\nfloat64 z", "geometry_msgs/Point"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctx.LoadMsgFromString("geometry_msgs/Point position\nfloat64 w", "test_msgs/Waypoint"); err != nil {
+		t.Fatal(err)
+	}
+	srvSpec, err := ctx.LoadSrvFromString("test_msgs/Waypoint[] waypoints\nstring label\n---\nbool ok", "test_msgs/SetPath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqType, err := newDynamicMessageTypeFromSpecInContext(ctx, srvSpec.Request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resType, err := newDynamicMessageTypeFromSpecInContext(ctx, srvSpec.Response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := &ServiceHeader{
+		Md5sum:             srvSpec.MD5Sum,
+		RequestType:        reqType.Name(),
+		ResponseType:       resType.Name(),
+		ServiceType:        "test_msgs/SetPath",
+		RequestDefinition:  messageDefinition(reqType),
+		ResponseDefinition: messageDefinition(resType),
+	}
+
+	serviceType, err := NewDynamicServiceTypeFromHeader(header)
+	if err != nil {
+		t.Fatalf("failed to create service type from header: %s", err)
+	}
+	if serviceType.MD5Sum() != srvSpec.MD5Sum {
+		t.Fatalf("expected md5sum %s, got %s", srvSpec.MD5Sum, serviceType.MD5Sum())
+	}
+
+	newReqType := serviceType.RequestType().(*DynamicMessageType)
+	if _, ok := newReqType.nested["test_msgs/Waypoint"]; !ok {
+		t.Fatalf("expected nested type test_msgs/Waypoint to be resolved")
+	}
+	if _, ok := newReqType.nested["geometry_msgs/Point"]; !ok {
+		t.Fatalf("expected nested type geometry_msgs/Point to be resolved")
+	}
+}
+
+func TestDynamicService_ServiceType_FromHeaderEmpty(t *testing.T) {
+	header := &ServiceHeader{
+		Md5sum:         "d41d8cd98f00b204e9800998ecf8427e",
+		RequestType:    "std_srvs/EmptyRequest",
+		ResponseType:   "std_srvs/EmptyResponse",
+		ServiceType:    "std_srvs/Empty",
+		HasDefinitions: true,
+	}
+
+	serviceType, err := NewDynamicServiceTypeFromHeader(header)
+	if err != nil {
+		t.Fatalf("failed to create service type from header: %s", err)
+	}
+	if serviceType.MD5Sum() != header.Md5sum {
+		t.Fatalf("expected md5sum %s, got %s", header.Md5sum, serviceType.MD5Sum())
+	}
+	srv := serviceType.NewService().(*DynamicService)
+	if data := srv.Request.(*DynamicMessage).Data(); len(data) != 0 {
+		t.Fatalf("expected an empty request, got %v", data)
+	}
+
+	// A service which doesn't advertise its definitions must be rejected.
+	header.HasDefinitions = false
+	if _, err := NewDynamicServiceTypeFromHeader(header); err == nil {
+		t.Fatalf("expected error for missing definitions")
+	}
+}
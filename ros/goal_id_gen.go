@@ -1,23 +1,57 @@
 package ros
 
 import (
+	"crypto/rand"
 	"fmt"
 	"sync"
 )
 
+// GoalIDGenerator generates the IDs of action goals which are sent without an
+// ID.
+type GoalIDGenerator interface {
+	GenerateID() string
+}
+
 type goalIDGenerator struct {
 	goals      int
 	goalsMutex sync.RWMutex
 	nodeName   string
 }
 
+type uuidGoalIDGenerator struct{}
+
+type deterministicGoalIDGenerator struct {
+	goals      int
+	goalsMutex sync.Mutex
+	prefix     string
+}
+
 func newGoalIDGenerator(nodeName string) *goalIDGenerator {
 	return &goalIDGenerator{
 		nodeName: nodeName,
 	}
 }
 
-func (g *goalIDGenerator) generateID() string {
+// NewDefaultGoalIDGenerator returns the default generator, which builds IDs
+// from the node name, a counter and the current time, as actionlib does.
+func NewDefaultGoalIDGenerator(nodeName string) GoalIDGenerator {
+	return newGoalIDGenerator(nodeName)
+}
+
+// NewUUIDGoalIDGenerator returns a generator of random (version 4) UUIDs.
+func NewUUIDGoalIDGenerator() GoalIDGenerator {
+	return uuidGoalIDGenerator{}
+}
+
+// NewDeterministicGoalIDGenerator returns a generator of the IDs prefix-1,
+// prefix-2 and so on, which is useful for tests.
+func NewDeterministicGoalIDGenerator(prefix string) GoalIDGenerator {
+	return &deterministicGoalIDGenerator{
+		prefix: prefix,
+	}
+}
+
+func (g *goalIDGenerator) GenerateID() string {
 	g.goalsMutex.Lock()
 	defer g.goalsMutex.Unlock()
 
@@ -26,3 +60,23 @@ func (g *goalIDGenerator) generateID() string {
 	timeNow := Now()
 	return fmt.Sprintf("%s-%d-%d-%d", g.nodeName, g.goals, timeNow.Sec, timeNow.NSec)
 }
+
+func (g uuidGoalIDGenerator) GenerateID() string {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		panic(fmt.Errorf("failed to generate goal uuid: %v", err))
+	}
+	uuid[6] = (uuid[6] & 0x0f) | 0x40 // version 4
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+}
+
+func (g *deterministicGoalIDGenerator) GenerateID() string {
+	g.goalsMutex.Lock()
+	defer g.goalsMutex.Unlock()
+
+	g.goals++
+
+	return fmt.Sprintf("%s-%d", g.prefix, g.goals)
+}
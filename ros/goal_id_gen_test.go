@@ -0,0 +1,51 @@
+package ros
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGoalIDGenerator_Default(t *testing.T) {
+	gen := NewDefaultGoalIDGenerator("/test_node")
+	first := gen.GenerateID()
+	second := gen.GenerateID()
+
+	if !strings.HasPrefix(first, "/test_node-1-") {
+		t.Fatalf("unexpected first id %s", first)
+	}
+	if !strings.HasPrefix(second, "/test_node-2-") {
+		t.Fatalf("unexpected second id %s", second)
+	}
+}
+
+func TestGoalIDGenerator_UUID(t *testing.T) {
+	gen := NewUUIDGoalIDGenerator()
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	seen := make(map[string]struct{})
+	for i := 0; i < 100; i++ {
+		id := gen.GenerateID()
+		if !uuidPattern.MatchString(id) {
+			t.Fatalf("id %s is not a version 4 uuid", id)
+		}
+		if _, ok := seen[id]; ok {
+			t.Fatalf("duplicate id %s", id)
+		}
+		seen[id] = struct{}{}
+	}
+}
+
+func TestGoalIDGenerator_Deterministic(t *testing.T) {
+	gen := NewDeterministicGoalIDGenerator("goal")
+	for _, expected := range []string{"goal-1", "goal-2", "goal-3"} {
+		if id := gen.GenerateID(); id != expected {
+			t.Fatalf("expected id %s, got %s", expected, id)
+		}
+	}
+
+	// A second generator starts again from the beginning.
+	if id := NewDeterministicGoalIDGenerator("goal").GenerateID(); id != "goal-1" {
+		t.Fatalf("expected id goal-1, got %s", id)
+	}
+}
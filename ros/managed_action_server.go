@@ -0,0 +1,309 @@
+package ros
+
+import (
+	goContext "context"
+	"fmt"
+	"sync"
+
+	modular "github.com/edwinhayes/logrus-modular"
+)
+
+// GoalPolicy decides what a managed action server does with a new goal.
+type GoalPolicy uint8
+
+const (
+	// GoalPolicyParallel runs up to maxGoals goals in parallel and rejects
+	// new goals beyond that.
+	GoalPolicyParallel GoalPolicy = iota
+	// GoalPolicyQueue runs up to maxGoals goals in parallel and queues new
+	// goals beyond that, starting them in FIFO order.
+	GoalPolicyQueue
+	// GoalPolicyReject runs a single goal and rejects new goals while busy.
+	GoalPolicyReject
+	// GoalPolicyPreemptOldest runs up to maxGoals goals in parallel and
+	// preempts the oldest running goal to make room for a new goal, which
+	// starts once the preempted goal has returned. A goal still waiting for
+	// its turn is cancelled if a newer goal arrives.
+	GoalPolicyPreemptOldest
+)
+
+func (p GoalPolicy) String() string {
+	switch p {
+	case GoalPolicyParallel:
+		return "PARALLEL"
+	case GoalPolicyQueue:
+		return "QUEUE"
+	case GoalPolicyReject:
+		return "REJECT"
+	case GoalPolicyPreemptOldest:
+		return "PREEMPT_OLDEST"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+type managedActionServer struct {
+	actionServer   *defaultActionServer
+	policy         GoalPolicy
+	maxGoals       int
+	executeCb      interface{}
+	logger         *modular.ModuleLogger
+	goalsMutex     sync.Mutex
+	running        []*managedGoal
+	queue          []*managedGoal
+	shutdownCtx    goContext.Context
+	shutdownCancel goContext.CancelFunc
+}
+
+type managedGoal struct {
+	handler   *serverGoalHandler
+	cancel    goContext.CancelFunc
+	preempted bool
+}
+
+func newManagedActionServer(node Node, action string, actType ActionType, executeCb interface{}, policy GoalPolicy, maxGoals int, start bool) (*managedActionServer, error) {
+	if !isContextExecuteCallback(executeCb) {
+		return nil, fmt.Errorf("execute callback must have the form func(context.Context, FeedbackPublisher, T) (R, error)")
+	}
+	if policy > GoalPolicyPreemptOldest {
+		return nil, fmt.Errorf("unknown goal policy %d", policy)
+	}
+	if policy == GoalPolicyReject || maxGoals < 1 {
+		maxGoals = 1
+	}
+
+	s := new(managedActionServer)
+	s.actionServer = newDefaultActionServer(node, action, actType, s.internalGoalCallback, s.internalCancelCallback, start)
+	s.policy = policy
+	s.maxGoals = maxGoals
+	s.executeCb = executeCb
+	s.logger = node.Logger()
+	s.shutdownCtx, s.shutdownCancel = goContext.WithCancel(goContext.Background())
+	return s, nil
+}
+
+func (s *managedActionServer) Start() {
+	go s.actionServer.Start()
+}
+
+// Shutdown stops the action server, cancelling the context of every running
+// goal and cancelling every queued goal.
+func (s *managedActionServer) Shutdown() {
+	s.shutdownCancel()
+
+	s.goalsMutex.Lock()
+	queue := s.queue
+	s.queue = nil
+	s.goalsMutex.Unlock()
+
+	for _, g := range queue {
+		g.handler.SetCancelled(s.getDefaultResult(), "This goal was canceled because the action server is shutting down")
+	}
+
+	if s.actionServer.isStarted() {
+		s.actionServer.Shutdown()
+	}
+}
+
+// NumActiveGoals returns the number of goals currently executing.
+func (s *managedActionServer) NumActiveGoals() int {
+	s.goalsMutex.Lock()
+	defer s.goalsMutex.Unlock()
+
+	return len(s.running)
+}
+
+// NumQueuedGoals returns the number of goals waiting to execute.
+func (s *managedActionServer) NumQueuedGoals() int {
+	s.goalsMutex.Lock()
+	defer s.goalsMutex.Unlock()
+
+	return len(s.queue)
+}
+
+func (s *managedActionServer) getDefaultResult() Message {
+	return s.actionServer.actionResultType.NewMessage()
+}
+
+func (s *managedActionServer) internalGoalCallback(ag ActionGoal) {
+	logger := *s.logger
+	agID, err := ag.GetGoalId()
+	if err != nil {
+		logger.Errorf("error getting ActionGoal goal id, err: %v", err)
+		return
+	}
+	gh := s.actionServer.getHandler(agID.GetID())
+	if gh == nil {
+		logger.Errorf("[ManagedActionServer] no goal handler for goal with id %s", agID.GetID())
+		return
+	}
+	logger.Debugf("[ManagedActionServer] Server received new goal with id %s", agID.GetID())
+
+	s.goalsMutex.Lock()
+	defer s.goalsMutex.Unlock()
+
+	if s.shutdownCtx.Err() != nil {
+		gh.SetRejected(s.getDefaultResult(), "This goal was rejected because the action server is shutting down")
+		return
+	}
+
+	if len(s.running) >= s.maxGoals {
+		switch s.policy {
+		case GoalPolicyParallel, GoalPolicyReject:
+			gh.SetRejected(s.getDefaultResult(),
+				"This goal was rejected because the action server is busy")
+			return
+
+		case GoalPolicyQueue:
+			s.queue = append(s.queue, &managedGoal{handler: gh})
+			logger.Debugf("[ManagedActionServer] Queued goal with id %s", agID.GetID())
+			return
+
+		case GoalPolicyPreemptOldest:
+			s.preemptOldest(&managedGoal{handler: gh})
+			return
+		}
+	}
+
+	s.startGoal(&managedGoal{handler: gh})
+}
+
+// preemptOldest preempts the oldest running goal which isn't already being
+// preempted, queueing the new goal to start once the preempted goal has
+// returned. If every running goal is already being preempted, the oldest
+// queued goal gives up its place to the new goal. Requires goalsMutex.
+func (s *managedActionServer) preemptOldest(g *managedGoal) {
+	for _, r := range s.running {
+		if !r.preempted {
+			r.preempted = true
+			r.handler.SetCancelRequested()
+			r.cancel()
+			s.queue = append(s.queue, g)
+			return
+		}
+	}
+
+	if len(s.queue) > 0 {
+		s.queue[0].handler.SetCancelled(s.getDefaultResult(),
+			"This goal was canceled because another goal was received by the managed action server")
+		s.queue = s.queue[1:]
+	}
+	s.queue = append(s.queue, g)
+}
+
+func (s *managedActionServer) internalCancelCallback(gID ActionGoalID) {
+	s.goalsMutex.Lock()
+	defer s.goalsMutex.Unlock()
+
+	// The action server has already moved cancelled goals to preempting or
+	// recalling, so act on every goal in one of those states.
+	for _, g := range s.running {
+		if isCancelRequestedStatus(g.handler) {
+			g.cancel()
+		}
+	}
+
+	queue := s.queue[:0]
+	for _, g := range s.queue {
+		if isCancelRequestedStatus(g.handler) {
+			g.handler.SetCancelled(s.getDefaultResult(), "This goal was canceled before it started executing")
+			continue
+		}
+		queue = append(queue, g)
+	}
+	s.queue = queue
+}
+
+// startGoal accepts the goal and executes it in its own goroutine. Requires
+// goalsMutex.
+func (s *managedActionServer) startGoal(g *managedGoal) {
+	logger := *s.logger
+	if err := g.handler.SetAccepted("This goal has been accepted by the managed action server"); err != nil {
+		logger.Errorf("[ManagedActionServer] failed to set accepted for action goal: %v", err)
+		return
+	}
+
+	var ctx goContext.Context
+	ctx, g.cancel = goContext.WithCancel(s.shutdownCtx)
+	s.running = append(s.running, g)
+
+	go s.executeGoal(ctx, g)
+}
+
+func (s *managedActionServer) executeGoal(ctx goContext.Context, g *managedGoal) {
+	logger := *s.logger
+	defer s.finishGoal(g)
+
+	go cancelOnNodeShutdown(ctx, g.cancel, s.actionServer.node)
+
+	goal, err := g.handler.GetGoal()
+	if err != nil {
+		logger.Errorf("[ManagedActionServer] error getting goal, err: %v", err)
+		g.handler.SetAborted(s.getDefaultResult(), err.Error())
+		return
+	}
+
+	result, err := callContextExecuteCallback(s.executeCb, ctx, g.handler, goal)
+	if typeErr, ok := err.(*goalTypeError); ok {
+		logger.Errorf("[ManagedActionServer] %v", typeErr)
+		g.handler.SetAborted(s.getDefaultResult(), typeErr.Error())
+		return
+	}
+	if result == nil {
+		result = s.getDefaultResult()
+	}
+
+	// the callback may have already set a terminal status itself
+	st, stErr := g.handler.GetGoalStatus()
+	if stErr != nil {
+		logger.Errorf("[ManagedActionServer] error getting goal status, err: %v", stErr)
+		return
+	}
+	if status := st.GetStatus(); status != uint8(1) && status != uint8(6) {
+		return
+	}
+
+	switch {
+	case err == nil:
+		err = g.handler.SetSucceeded(result, "")
+	case ctx.Err() != nil:
+		err = g.handler.SetCancelled(result, err.Error())
+	default:
+		err = g.handler.SetAborted(result, err.Error())
+	}
+	if err != nil {
+		logger.Errorf("[ManagedActionServer] failed to set terminal status for goal, err: %v", err)
+	}
+}
+
+// finishGoal removes a finished goal from the running goals and starts the
+// next queued goal, if any.
+func (s *managedActionServer) finishGoal(g *managedGoal) {
+	g.cancel()
+
+	s.goalsMutex.Lock()
+	defer s.goalsMutex.Unlock()
+
+	for i, r := range s.running {
+		if r == g {
+			s.running = append(s.running[:i], s.running[i+1:]...)
+			break
+		}
+	}
+
+	for len(s.queue) > 0 && len(s.running) < s.maxGoals && s.shutdownCtx.Err() == nil {
+		next := s.queue[0]
+		s.queue = s.queue[1:]
+		s.startGoal(next)
+	}
+}
+
+// isCancelRequestedStatus checks whether a goal is preempting or recalling.
+func isCancelRequestedStatus(gh *serverGoalHandler) bool {
+	st, err := gh.GetGoalStatus()
+	if err != nil {
+		return false
+	}
+	status := st.GetStatus()
+	return status == uint8(6) || status == uint8(7)
+}
@@ -0,0 +1,314 @@
+package ros
+
+import (
+	goContext "context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Execute callback for the test action type which runs each goal until it is released or its context is cancelled.
+type testGoalExecutor struct {
+	mutex      sync.Mutex
+	started    chan int32
+	release    chan struct{}
+	running    int
+	maxRunning int
+}
+
+func newTestGoalExecutor() *testGoalExecutor {
+	return &testGoalExecutor{
+		started: make(chan int32, 10),
+		release: make(chan struct{}),
+	}
+}
+
+func (e *testGoalExecutor) execute(ctx goContext.Context, fb FeedbackPublisher, goal *DynamicMessage) (*DynamicMessage, error) {
+	e.mutex.Lock()
+	e.running++
+	if e.running > e.maxRunning {
+		e.maxRunning = e.running
+	}
+	e.mutex.Unlock()
+	defer func() {
+		e.mutex.Lock()
+		e.running--
+		e.mutex.Unlock()
+	}()
+
+	target, _ := goal.GetInt64("target")
+	e.started <- int32(target)
+	select {
+	case <-e.release:
+		return nil, nil
+	case <-ctx.Done():
+		// Take a while to wind down, so that a goal started too early would overlap.
+		time.Sleep(10 * time.Millisecond)
+		return nil, ctx.Err()
+	}
+}
+
+// waitForStart waits for the goals with the targets to start executing, in any order.
+func (e *testGoalExecutor) waitForStart(t *testing.T, targets ...int32) {
+	t.Helper()
+	expected := map[int32]bool{}
+	for _, target := range targets {
+		expected[target] = true
+	}
+	for range targets {
+		select {
+		case started := <-e.started:
+			if !expected[started] {
+				t.Fatalf("expected goals %v to start, goal %d started", targets, started)
+			}
+			delete(expected, started)
+		case <-time.After(time.Second):
+			t.Fatalf("took too long waiting for goals %v to start", targets)
+		}
+	}
+}
+
+// expectNoStart checks that no goal starts executing for a little while.
+func (e *testGoalExecutor) expectNoStart(t *testing.T) {
+	t.Helper()
+	select {
+	case started := <-e.started:
+		t.Fatalf("expected no goal to start, goal %d started", started)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+// releaseOne lets one of the running goals succeed.
+func (e *testGoalExecutor) releaseOne(t *testing.T) {
+	t.Helper()
+	select {
+	case e.release <- struct{}{}:
+	case <-time.After(time.Second):
+		t.Fatal("took too long waiting for a running goal to release")
+	}
+}
+
+func (e *testGoalExecutor) maxConcurrent() int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.maxRunning
+}
+
+func TestManagedActionServer_InvalidArguments(t *testing.T) {
+	if _, err := newManagedActionServer(nil, "/test", nil, func(goal Message) {}, GoalPolicyQueue, 1, false); err == nil {
+		t.Fatal("expected error for execute callback without context")
+	}
+
+	executeCb := func(ctx goContext.Context, fb FeedbackPublisher, goal Message) (Message, error) { return nil, nil }
+	if _, err := newManagedActionServer(nil, "/test", nil, executeCb, GoalPolicy(42), 1, false); err == nil {
+		t.Fatal("expected error for unknown goal policy")
+	}
+}
+
+func TestManagedActionServer_GoalPolicyString(t *testing.T) {
+	expected := map[GoalPolicy]string{
+		GoalPolicyParallel:      "PARALLEL",
+		GoalPolicyQueue:         "QUEUE",
+		GoalPolicyReject:        "REJECT",
+		GoalPolicyPreemptOldest: "PREEMPT_OLDEST",
+		GoalPolicy(42):          "UNKNOWN",
+	}
+	for policy, name := range expected {
+		if policy.String() != name {
+			t.Fatalf("expected %s, got %s", name, policy.String())
+		}
+	}
+}
+
+func TestManagedActionServer_Parallel(t *testing.T) {
+	executor := newTestGoalExecutor()
+	s, node := startTestManagedActionServer(t, executor, GoalPolicyParallel, 2)
+	defer s.Shutdown()
+
+	sendTestActionGoal(t, s.actionServer, node, "goal1", 1)
+	executor.waitForStart(t, 1)
+	sendTestActionGoal(t, s.actionServer, node, "goal2", 2)
+	executor.waitForStart(t, 2)
+
+	// Goals beyond maxGoals are rejected.
+	sendTestActionGoal(t, s.actionServer, node, "goal3", 3)
+	waitForActionGoalStatus(t, s.actionServer, "goal3", 5) // REJECTED
+	if n := s.NumActiveGoals(); n != 2 {
+		t.Fatalf("expected 2 active goals, got %d", n)
+	}
+
+	executor.releaseOne(t)
+	executor.releaseOne(t)
+	waitForActionGoalStatus(t, s.actionServer, "goal1", 3) // SUCCEEDED
+	waitForActionGoalStatus(t, s.actionServer, "goal2", 3) // SUCCEEDED
+	waitForCondition(t, "goals to finish", func() bool { return s.NumActiveGoals() == 0 })
+}
+
+func TestManagedActionServer_Queue(t *testing.T) {
+	executor := newTestGoalExecutor()
+	s, node := startTestManagedActionServer(t, executor, GoalPolicyQueue, 1)
+	defer s.Shutdown()
+
+	sendTestActionGoal(t, s.actionServer, node, "goal1", 1)
+	executor.waitForStart(t, 1)
+	sendTestActionGoal(t, s.actionServer, node, "goal2", 2)
+	sendTestActionGoal(t, s.actionServer, node, "goal3", 3)
+	executor.expectNoStart(t)
+	if n := s.NumQueuedGoals(); n != 2 {
+		t.Fatalf("expected 2 queued goals, got %d", n)
+	}
+	waitForActionGoalStatus(t, s.actionServer, "goal2", 0) // PENDING
+
+	// Queued goals start in order as running goals finish.
+	executor.releaseOne(t)
+	executor.waitForStart(t, 2)
+	waitForActionGoalStatus(t, s.actionServer, "goal1", 3) // SUCCEEDED
+	executor.releaseOne(t)
+	executor.waitForStart(t, 3)
+	executor.releaseOne(t)
+	waitForActionGoalStatus(t, s.actionServer, "goal3", 3) // SUCCEEDED
+
+	if n := executor.maxConcurrent(); n != 1 {
+		t.Fatalf("expected at most 1 goal to run at once, %d did", n)
+	}
+}
+
+func TestManagedActionServer_QueuedGoalCancelled(t *testing.T) {
+	executor := newTestGoalExecutor()
+	s, node := startTestManagedActionServer(t, executor, GoalPolicyQueue, 1)
+	defer s.Shutdown()
+
+	sendTestActionGoal(t, s.actionServer, node, "goal1", 1)
+	executor.waitForStart(t, 1)
+	sendTestActionGoal(t, s.actionServer, node, "goal2", 2)
+	sendTestActionGoal(t, s.actionServer, node, "goal3", 3)
+
+	cancelTestActionGoal(t, node, "goal2")
+	waitForActionGoalStatus(t, s.actionServer, "goal2", 8) // RECALLED
+	if n := s.NumQueuedGoals(); n != 1 {
+		t.Fatalf("expected 1 queued goal, got %d", n)
+	}
+
+	executor.releaseOne(t)
+	executor.waitForStart(t, 3)
+	executor.releaseOne(t)
+}
+
+func TestManagedActionServer_Reject(t *testing.T) {
+	executor := newTestGoalExecutor()
+	// The reject policy runs a single goal whatever maxGoals is.
+	s, node := startTestManagedActionServer(t, executor, GoalPolicyReject, 3)
+	defer s.Shutdown()
+
+	sendTestActionGoal(t, s.actionServer, node, "goal1", 1)
+	executor.waitForStart(t, 1)
+	sendTestActionGoal(t, s.actionServer, node, "goal2", 2)
+	waitForActionGoalStatus(t, s.actionServer, "goal2", 5) // REJECTED
+
+	executor.releaseOne(t)
+	waitForActionGoalStatus(t, s.actionServer, "goal1", 3) // SUCCEEDED
+	waitForCondition(t, "goal to finish", func() bool { return s.NumActiveGoals() == 0 })
+	sendTestActionGoal(t, s.actionServer, node, "goal3", 3)
+	executor.waitForStart(t, 3)
+	executor.releaseOne(t)
+}
+
+func TestManagedActionServer_PreemptOldest(t *testing.T) {
+	executor := newTestGoalExecutor()
+	s, node := startTestManagedActionServer(t, executor, GoalPolicyPreemptOldest, 2)
+	defer s.Shutdown()
+
+	sendTestActionGoal(t, s.actionServer, node, "goal1", 1)
+	executor.waitForStart(t, 1)
+	sendTestActionGoal(t, s.actionServer, node, "goal2", 2)
+	executor.waitForStart(t, 2)
+
+	// The new goal starts once the oldest goal has returned.
+	sendTestActionGoal(t, s.actionServer, node, "goal3", 3)
+	executor.waitForStart(t, 3)
+	waitForActionGoalStatus(t, s.actionServer, "goal1", 2) // PREEMPTED
+	if status := actionGoalStatus(s.actionServer, "goal2"); status != 1 {
+		t.Fatalf("expected goal2 to still be active, got status %d", status)
+	}
+
+	// Goals waiting for a preempted goal to return give way to newer goals.
+	sendTestActionGoal(t, s.actionServer, node, "goal4", 4)
+	sendTestActionGoal(t, s.actionServer, node, "goal5", 5)
+	sendTestActionGoal(t, s.actionServer, node, "goal6", 6)
+	waitForActionGoalStatus(t, s.actionServer, "goal4", 8) // RECALLED
+	executor.waitForStart(t, 5, 6)
+	waitForActionGoalStatus(t, s.actionServer, "goal2", 2) // PREEMPTED
+	waitForActionGoalStatus(t, s.actionServer, "goal3", 2) // PREEMPTED
+
+	if n := executor.maxConcurrent(); n != 2 {
+		t.Fatalf("expected at most 2 goals to run at once, %d did", n)
+	}
+	executor.releaseOne(t)
+	executor.releaseOne(t)
+}
+
+func TestManagedActionServer_Shutdown(t *testing.T) {
+	executor := newTestGoalExecutor()
+	s, node := startTestManagedActionServer(t, executor, GoalPolicyQueue, 1)
+
+	sendTestActionGoal(t, s.actionServer, node, "goal1", 1)
+	executor.waitForStart(t, 1)
+	sendTestActionGoal(t, s.actionServer, node, "goal2", 2)
+
+	s.Shutdown()
+	waitForActionGoalStatus(t, s.actionServer, "goal1", 2) // PREEMPTED
+	waitForActionGoalStatus(t, s.actionServer, "goal2", 8) // RECALLED
+	executor.expectNoStart(t)
+	waitForCondition(t, "action server to stop", func() bool { return !s.actionServer.isStarted() })
+}
+
+// Test helper functions.
+
+// startTestManagedActionServer starts a managed action server on a fake node, waiting for it to be initialized.
+func startTestManagedActionServer(t *testing.T, executor *testGoalExecutor, policy GoalPolicy, maxGoals int) (*managedActionServer, *testActionNode) {
+	node := newTestActionNode()
+	s, err := newManagedActionServer(node, "/test", newTestActionType(t), executor.execute, policy, maxGoals, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	waitForCondition(t, "action server to start", s.actionServer.isStarted)
+	return s, node
+}
+
+// sendTestActionGoal delivers a goal to the action server's goal subscription.
+func sendTestActionGoal(t *testing.T, as *defaultActionServer, node *testActionNode, id string, target int32) {
+	t.Helper()
+	goal := newTestActionGoal(t, as.actionType, id, Now(), target)
+	callback := node.subscriber("/test/goal").(func(interface{}, MessageEvent) error)
+	if err := callback(goal, MessageEvent{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// cancelTestActionGoal delivers a cancel request for the goal to the action server's cancel subscription.
+func cancelTestActionGoal(t *testing.T, node *testActionNode, id string) {
+	t.Helper()
+	goalID, err := NewActionGoalIDType().(*DynamicActionGoalIDType).NewDynamicMessageFromMap(map[string]interface{}{"id": id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback := node.subscriber("/test/cancel").(func(interface{}, MessageEvent))
+	callback(goalID, MessageEvent{})
+}
+
+// actionGoalStatus returns the status of a goal tracked by the action server, or 255 if it isn't tracked.
+func actionGoalStatus(as *defaultActionServer, id string) uint8 {
+	for _, goal := range as.GetGoals() {
+		if goal.ID == id {
+			return goal.Status
+		}
+	}
+	return 255
+}
+
+// waitForActionGoalStatus waits for a goal tracked by the action server to reach the status.
+func waitForActionGoalStatus(t *testing.T, as *defaultActionServer, id string, status uint8) {
+	t.Helper()
+	waitForCondition(t, id+" status", func() bool { return actionGoalStatus(as, id) == status })
+}
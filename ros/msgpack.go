@@ -0,0 +1,392 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// MsgPackEncoder writes a stream of messages as consecutive MessagePack values.
+type MsgPackEncoder struct {
+	w io.Writer
+}
+
+// MsgPackDecoder reads a stream of messages written by MsgPackEncoder.
+type MsgPackDecoder struct {
+	r *bufio.Reader
+}
+
+// DEFINE PRIVATE STRUCTURES.
+
+// msgPackFormat implements binaryFormat for MessagePack.
+type msgPackFormat struct{}
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// MessagePack formats used by messages; the fixed formats hold their value or length in their low bits.
+const (
+	msgPackPositiveFixInt byte = 0x00
+	msgPackFixMap         byte = 0x80
+	msgPackFixArray       byte = 0x90
+	msgPackFixStr         byte = 0xa0
+	msgPackNil            byte = 0xc0
+	msgPackFalse          byte = 0xc2
+	msgPackTrue           byte = 0xc3
+	msgPackBin8           byte = 0xc4
+	msgPackBin16          byte = 0xc5
+	msgPackBin32          byte = 0xc6
+	msgPackExt8           byte = 0xc7
+	msgPackExt16          byte = 0xc8
+	msgPackExt32          byte = 0xc9
+	msgPackFloat32        byte = 0xca
+	msgPackFloat64        byte = 0xcb
+	msgPackUint8          byte = 0xcc
+	msgPackUint16         byte = 0xcd
+	msgPackUint32         byte = 0xce
+	msgPackUint64         byte = 0xcf
+	msgPackInt8           byte = 0xd0
+	msgPackInt16          byte = 0xd1
+	msgPackInt32          byte = 0xd2
+	msgPackInt64          byte = 0xd3
+	msgPackFixExt1        byte = 0xd4
+	msgPackFixExt4        byte = 0xd6
+	msgPackFixExt8        byte = 0xd7
+	msgPackFixExt16       byte = 0xd8
+	msgPackStr8           byte = 0xd9
+	msgPackStr16          byte = 0xda
+	msgPackStr32          byte = 0xdb
+	msgPackArray16        byte = 0xdc
+	msgPackArray32        byte = 0xdd
+	msgPackMap16          byte = 0xde
+	msgPackMap32          byte = 0xdf
+	msgPackNegativeFixInt byte = 0xe0
+
+	// msgPackTimestamp is the extension type of the predefined timestamp, written as 0xff.
+	msgPackTimestamp int8 = -1
+)
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// MarshalMsgPack encodes a DynamicMessage or a message generated by gengo as MessagePack.  Messages are maps from field names to values, in definition order; uint8 arrays are
+// binary, float32 and float64 keep their precision, and integers use their smallest encoding.  Times are timestamps (extension type -1); durations, having no equivalent, are maps
+// of "sec" and "nsec" as in JSON.
+func MarshalMsgPack(msg Message) ([]byte, error) {
+	return marshalBinary(msgPackFormat{}, msg)
+}
+
+// UnmarshalMsgPack decodes a message encoded by MarshalMsgPack(), or by any other MessagePack encoder using the same mapping.  Integers and floats are converted to the types of
+// the fields, provided they are in range.  Fields which are not given are zeroed.  The message must be a *DynamicMessage or a pointer to a message generated by gengo.
+func UnmarshalMsgPack(buf []byte, msg Message) error {
+	return unmarshalBinary(msgPackFormat{}, buf, msg)
+}
+
+// NewMsgPackEncoder creates a MsgPackEncoder writing to w.
+func NewMsgPackEncoder(w io.Writer) *MsgPackEncoder {
+	return &MsgPackEncoder{w: w}
+}
+
+// NewMsgPackDecoder creates a MsgPackDecoder reading from r.
+func NewMsgPackDecoder(r io.Reader) *MsgPackDecoder {
+	return &MsgPackDecoder{r: bufio.NewReader(r)}
+}
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// Encode writes a message.
+func (e *MsgPackEncoder) Encode(msg Message) error {
+	buf, err := MarshalMsgPack(msg)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(buf)
+	return err
+}
+
+// Decode reads the next message from the stream into msg, returning io.EOF when no messages remain.
+func (d *MsgPackDecoder) Decode(msg Message) error {
+	return decodeBinary(msgPackFormat{}, d.r, msg)
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// appendMsgPackLength appends the format of a string, binary, array or map with the specified length, using the fixed format if there is one and it's long enough.
+func appendMsgPackLength(buf []byte, fixed byte, fixedMax int, formats [3]byte, length int) []byte {
+	switch {
+	case length <= fixedMax:
+		return append(buf, fixed|byte(length))
+	case formats[0] != 0 && length <= math.MaxUint8:
+		return append(buf, formats[0], byte(length))
+	case length <= math.MaxUint16:
+		return appendBigEndian(append(buf, formats[1]), uint64(length), 2)
+	}
+	return appendBigEndian(append(buf, formats[2]), uint64(length), 4)
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+func (msgPackFormat) appendBool(buf []byte, value bool) []byte {
+	if value {
+		return append(buf, msgPackTrue)
+	}
+	return append(buf, msgPackFalse)
+}
+
+func (f msgPackFormat) appendInt(buf []byte, value int64) []byte {
+	switch {
+	case value >= 0:
+		return f.appendUint(buf, uint64(value))
+	case value >= -32:
+		return append(buf, byte(value))
+	case value >= math.MinInt8:
+		return append(buf, msgPackInt8, byte(value))
+	case value >= math.MinInt16:
+		return appendBigEndian(append(buf, msgPackInt16), uint64(value), 2)
+	case value >= math.MinInt32:
+		return appendBigEndian(append(buf, msgPackInt32), uint64(value), 4)
+	}
+	return appendBigEndian(append(buf, msgPackInt64), uint64(value), 8)
+}
+
+func (msgPackFormat) appendUint(buf []byte, value uint64) []byte {
+	switch {
+	case value < 0x80:
+		return append(buf, byte(value))
+	case value <= math.MaxUint8:
+		return append(buf, msgPackUint8, byte(value))
+	case value <= math.MaxUint16:
+		return appendBigEndian(append(buf, msgPackUint16), value, 2)
+	case value <= math.MaxUint32:
+		return appendBigEndian(append(buf, msgPackUint32), value, 4)
+	}
+	return appendBigEndian(append(buf, msgPackUint64), value, 8)
+}
+
+func (msgPackFormat) appendFloat32(buf []byte, value float32) []byte {
+	return appendBigEndian(append(buf, msgPackFloat32), uint64(math.Float32bits(value)), 4)
+}
+
+func (msgPackFormat) appendFloat64(buf []byte, value float64) []byte {
+	return appendBigEndian(append(buf, msgPackFloat64), math.Float64bits(value), 8)
+}
+
+func (msgPackFormat) appendString(buf []byte, value string) []byte {
+	return append(appendMsgPackLength(buf, msgPackFixStr, 31, [3]byte{msgPackStr8, msgPackStr16, msgPackStr32}, len(value)), value...)
+}
+
+func (msgPackFormat) appendBytes(buf []byte, value []byte) []byte {
+	return append(appendMsgPackLength(buf, 0, -1, [3]byte{msgPackBin8, msgPackBin16, msgPackBin32}, len(value)), value...)
+}
+
+func (msgPackFormat) appendArrayHeader(buf []byte, length int) []byte {
+	return appendMsgPackLength(buf, msgPackFixArray, 15, [3]byte{0, msgPackArray16, msgPackArray32}, length)
+}
+
+func (msgPackFormat) appendMapHeader(buf []byte, length int) []byte {
+	return appendMsgPackLength(buf, msgPackFixMap, 15, [3]byte{0, msgPackMap16, msgPackMap32}, length)
+}
+
+// appendTime appends a time as a timestamp, using the 32 bit format when there are no nanoseconds, and the 64 bit format otherwise.  Nanoseconds beyond a second, which the
+// timestamp formats don't allow, are carried into the seconds.
+func (msgPackFormat) appendTime(buf []byte, value Time) []byte {
+	sec := uint64(value.Sec) + uint64(value.NSec/1e9)
+	nsec := uint64(value.NSec % 1e9)
+	if nsec == 0 && sec <= math.MaxUint32 {
+		buf = append(buf, msgPackFixExt4, 0xff)
+		return appendBigEndian(buf, sec, 4)
+	}
+	buf = append(buf, msgPackFixExt8, 0xff)
+	return appendBigEndian(buf, nsec<<34|sec, 8)
+}
+
+// appendDuration appends a duration as a map of "sec" and "nsec", as in JSON.
+func (f msgPackFormat) appendDuration(buf []byte, value Duration) []byte {
+	buf = f.appendUint(f.appendString(f.appendMapHeader(buf, 2), "sec"), uint64(value.Sec))
+	return f.appendUint(f.appendString(buf, "nsec"), uint64(value.NSec))
+}
+
+func (f msgPackFormat) decodeValue(r binaryReader, depth int) (interface{}, error) {
+	if depth > maxBinaryDepth {
+		return nil, errors.New("msgpack nested too deeply")
+	}
+	format, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case format < msgPackFixMap:
+		return uint64(format), nil
+	case format >= msgPackNegativeFixInt:
+		return int64(int8(format)), nil
+	case format < msgPackFixArray:
+		return f.decodeMap(r, uint64(format&0x0f), depth)
+	case format < msgPackFixStr:
+		return f.decodeArray(r, uint64(format&0x0f), depth)
+	case format < msgPackNil:
+		return f.decodeString(r, uint64(format&0x1f))
+	}
+
+	switch format {
+	case msgPackNil:
+		return nil, nil
+	case msgPackFalse:
+		return false, nil
+	case msgPackTrue:
+		return true, nil
+	case msgPackFloat32:
+		bits, err := readBinaryUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(uint32(bits)), nil
+	case msgPackFloat64:
+		bits, err := readBinaryUint(r, 8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(bits), nil
+	case msgPackUint8, msgPackUint16, msgPackUint32, msgPackUint64:
+		return readBinaryUint(r, 1<<(format-msgPackUint8))
+	case msgPackInt8, msgPackInt16, msgPackInt32, msgPackInt64:
+		size := 1 << (format - msgPackInt8)
+		bits, err := readBinaryUint(r, size)
+		if err != nil {
+			return nil, err
+		}
+		// Sign extend, and keep non-negative values unsigned like the other formats.
+		shift := uint(64 - 8*size)
+		value := int64(bits<<shift) >> shift
+		if value >= 0 {
+			return uint64(value), nil
+		}
+		return value, nil
+	}
+
+	// The rest are followed by their length.
+	var length uint64
+	switch format {
+	case msgPackBin8, msgPackStr8, msgPackExt8:
+		length, err = readBinaryUint(r, 1)
+	case msgPackBin16, msgPackStr16, msgPackArray16, msgPackMap16, msgPackExt16:
+		length, err = readBinaryUint(r, 2)
+	case msgPackBin32, msgPackStr32, msgPackArray32, msgPackMap32, msgPackExt32:
+		length, err = readBinaryUint(r, 4)
+	case msgPackFixExt1, msgPackFixExt1 + 1, msgPackFixExt4, msgPackFixExt8, msgPackFixExt16:
+		length = 1 << (format - msgPackFixExt1)
+	default:
+		return nil, errors.New("invalid msgpack format 0x" + strconv.FormatUint(uint64(format), 16))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case msgPackBin8, msgPackBin16, msgPackBin32:
+		return readBinaryBytes(r, length)
+	case msgPackStr8, msgPackStr16, msgPackStr32:
+		return f.decodeString(r, length)
+	case msgPackArray16, msgPackArray32:
+		return f.decodeArray(r, length, depth)
+	case msgPackMap16, msgPackMap32:
+		return f.decodeMap(r, length, depth)
+	}
+	return f.decodeExtension(r, length)
+}
+
+// decodeString reads a string of the specified length.
+func (msgPackFormat) decodeString(r binaryReader, length uint64) (interface{}, error) {
+	data, err := readBinaryBytes(r, length)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// decodeArray reads the elements of an array.
+func (f msgPackFormat) decodeArray(r binaryReader, length uint64, depth int) (interface{}, error) {
+	array := make([]interface{}, 0, binaryContainerCapacity(length))
+	for i := uint64(0); i < length; i++ {
+		item, err := f.decodeValue(r, depth+1)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		array = append(array, item)
+	}
+	return array, nil
+}
+
+// decodeMap reads the keys and values of a map, whose keys must be strings.
+func (f msgPackFormat) decodeMap(r binaryReader, length uint64, depth int) (interface{}, error) {
+	mapping := make(map[string]interface{}, binaryContainerCapacity(length))
+	for i := uint64(0); i < length; i++ {
+		key, err := f.decodeValue(r, depth+1)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, errors.New("expected msgpack map key to be a string, got " + describeValue(key))
+		}
+		if mapping[name], err = f.decodeValue(r, depth+1); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+	}
+	return mapping, nil
+}
+
+// decodeExtension reads an extension of the specified length; only timestamps are supported.
+func (msgPackFormat) decodeExtension(r binaryReader, length uint64) (interface{}, error) {
+	extType, err := r.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if int8(extType) != msgPackTimestamp {
+		return nil, errors.New("unsupported msgpack extension type " + strconv.Itoa(int(int8(extType))))
+	}
+
+	var sec int64
+	var nsec uint64
+	switch length {
+	case 4:
+		value, err := readBinaryUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		sec = int64(value)
+	case 8:
+		value, err := readBinaryUint(r, 8)
+		if err != nil {
+			return nil, err
+		}
+		sec, nsec = int64(value&(1<<34-1)), value>>34
+	case 12:
+		if nsec, err = readBinaryUint(r, 4); err != nil {
+			return nil, err
+		}
+		value, err := readBinaryUint(r, 8)
+		if err != nil {
+			return nil, err
+		}
+		sec = int64(value)
+	default:
+		return nil, errors.New("invalid msgpack timestamp length " + strconv.FormatUint(length, 10))
+	}
+	if nsec > 999999999 {
+		return nil, errors.New("invalid msgpack timestamp nanoseconds")
+	}
+	if sec < 0 || sec > math.MaxUint32 {
+		return nil, errors.New("msgpack timestamp out of range")
+	}
+	return NewTime(uint32(sec), uint32(nsec)), nil
+}
+
+// ALL DONE.
@@ -92,11 +92,14 @@ type defaultNode struct {
 
 // serviceheader is the header returned from probing a ros service, containing all type information
 type ServiceHeader struct {
-	Callerid     string
-	Md5sum       string
-	RequestType  string
-	ResponseType string
-	ServiceType  string
+	Callerid           string
+	Md5sum             string
+	RequestType        string
+	ResponseType       string
+	ServiceType        string
+	RequestDefinition  string
+	ResponseDefinition string
+	HasDefinitions     bool // Whether the service advertised its request and response definitions, which are empty for services such as std_srvs/Empty.
 }
 
 func listenRandomPort(address string, trialLimit int) (net.Listener, error) {
@@ -116,6 +119,22 @@ func listenRandomPort(address string, trialLimit int) (net.Listener, error) {
 	return nil, fmt.Errorf("listenRandomPort exceeds trial limit")
 }
 
+// newServiceHeader creates a ServiceHeader from the connection header returned by probing a service.
+func newServiceHeader(resHeaderMap map[string]string) *ServiceHeader {
+	_, hasRequestDefinition := resHeaderMap["request_definition"]
+	_, hasResponseDefinition := resHeaderMap["response_definition"]
+	return &ServiceHeader{
+		Callerid:           resHeaderMap["callerid"],
+		Md5sum:             resHeaderMap["md5sum"],
+		RequestType:        resHeaderMap["request_type"],
+		ResponseType:       resHeaderMap["response_type"],
+		ServiceType:        resHeaderMap["type"],
+		RequestDefinition:  resHeaderMap["request_definition"],
+		ResponseDefinition: resHeaderMap["response_definition"],
+		HasDefinitions:     hasRequestDefinition && hasResponseDefinition,
+	}
+}
+
 func newDefaultNodeWithLogs(name string, logger *modular.ModuleLogger, args []string) (*defaultNode, error) {
 	node, err := newDefaultNode(name, args)
 	if err != nil {
@@ -536,14 +555,7 @@ func (node *defaultNode) GetServiceType(serviceName string) (*ServiceHeader, err
 	if len(resHeaders) == 1 {
 		return nil, errors.Errorf("error probing service type: %s", resHeaders[0])
 	}
-	srvHeader := ServiceHeader{
-		Callerid:     resHeaderMap["callerid"],
-		Md5sum:       resHeaderMap["md5sum"],
-		RequestType:  resHeaderMap["request_type"],
-		ResponseType: resHeaderMap["response_type"],
-		ServiceType:  resHeaderMap["type"],
-	}
-	return &srvHeader, nil
+	return newServiceHeader(resHeaderMap), nil
 }
 
 // Master API call for getPublishedTopics
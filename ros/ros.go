@@ -26,6 +26,10 @@ type Node interface {
 	NewSubscriber(topic string, msgType MessageType, callback interface{}) (Subscriber, error)
 	NewSubscriberWithFlowControl(topic string, msgType MessageType, enable chan bool, callback interface{}) (Subscriber, error)
 	NewServiceClient(service string, srvType ServiceType) ServiceClient
+	// callback should be a function which takes 1 or 2 arguments and
+	// returns an error.  The first argument should be of the generated
+	// service type.  If the function takes 2 arguments, the second
+	// argument should be of type ServiceEvent.
 	NewServiceServer(service string, srvType ServiceType, callback interface{}) ServiceServer
 
 	RemoveSubscriber(topic string)
@@ -97,6 +101,14 @@ type MessageEvent struct {
 	ConnectionHeader map[string]string
 }
 
+//ServiceEvent is an optional second argument to a ServiceServer handler.
+type ServiceEvent struct {
+	CallerID         string
+	ConnectionHeader map[string]string
+	RemoteAddress    string
+	ReceiptTime      time.Time
+}
+
 //ServiceHandler is a service handling interface
 type ServiceHandler interface{}
 
@@ -119,16 +119,14 @@ func (gh *serverGoalHandler) SetAborted(result Message, text string) error {
 }
 
 func (gh *serverGoalHandler) SetSucceeded(result Message, text string) error {
-	logger := *gh.logger
 	if gh.goal == nil {
 		return fmt.Errorf("attempt to set handler on an uninitialized handler handler")
 	}
 
 	status, err := gh.sm.transition(Succeed, text)
 	if err != nil {
-		logger.Errorf("to transition to an Succeeded state, the goal must be in a pending"+
+		return fmt.Errorf("to transition to an Succeeded state, the goal must be in a pending"+
 			"or recalling state, it is currently in state: %d", status.GetStatus())
-		return err
 	}
 
 	gh.SetHandlerDestructionTime(Now())
@@ -174,7 +172,7 @@ func (gh *serverGoalHandler) GetGoalId() (ActionGoalID, error) {
 
 func (gh *serverGoalHandler) GetGoalStatus() (ActionStatus, error) {
 	status := gh.sm.getStatus()
-	if status.GetStatus() != 0 && gh.goal != nil {
+	if gh.goal != nil {
 		if id, err := gh.goal.GetGoalId(); err != nil {
 			return nil, err
 		} else if id.GetID() != "" {
@@ -182,7 +180,7 @@ func (gh *serverGoalHandler) GetGoalStatus() (ActionStatus, error) {
 		}
 	}
 	// Create a new goal status message
-	status = NewActionStatusType().NewMessage().(*DynamicActionStatus)
+	status = NewActionStatusType().NewStatusMessage()
 	return status, nil
 }
 
@@ -35,6 +35,13 @@ func (e Event) String() string {
 	}
 }
 
+// goalStatusType is generated once and shared by every goal, as the state
+// machines copy their status on each transition and status publish.
+var (
+	goalStatusTypeOnce sync.Once
+	goalStatusType     ActionStatusType
+)
+
 type serverStateMachine struct {
 	goalStatus ActionStatus
 	mutex      sync.RWMutex
@@ -42,7 +49,7 @@ type serverStateMachine struct {
 
 func newServerStateMachine(goalID ActionGoalID) *serverStateMachine {
 	// Create a goal status message with pending status
-	status := NewActionStatusType().NewStatusMessage().(*DynamicActionStatus)
+	status := newGoalStatus()
 	status.SetStatus(0)
 	status.SetGoalID(goalID)
 	return &serverStateMachine{
@@ -72,7 +79,7 @@ func (sm *serverStateMachine) transition(event Event, text string) (ActionStatus
 			nextState = uint8(1)
 			break
 		default:
-			return sm.goalStatus, fmt.Errorf("invalid transition Event")
+			return sm.copyStatus(), fmt.Errorf("invalid transition Event")
 		}
 
 	case uint8(7):
@@ -87,7 +94,7 @@ func (sm *serverStateMachine) transition(event Event, text string) (ActionStatus
 			nextState = uint8(6)
 			break
 		default:
-			return sm.goalStatus, fmt.Errorf("invalid transition Event")
+			return sm.copyStatus(), fmt.Errorf("invalid transition Event")
 		}
 
 	case uint8(1):
@@ -105,7 +112,7 @@ func (sm *serverStateMachine) transition(event Event, text string) (ActionStatus
 			nextState = uint8(4)
 			break
 		default:
-			return sm.goalStatus, fmt.Errorf("invalid transition Event")
+			return sm.copyStatus(), fmt.Errorf("invalid transition Event")
 		}
 
 	case uint8(6):
@@ -120,7 +127,7 @@ func (sm *serverStateMachine) transition(event Event, text string) (ActionStatus
 			nextState = uint8(4)
 			break
 		default:
-			return sm.goalStatus, fmt.Errorf("invalid transition Event")
+			return sm.copyStatus(), fmt.Errorf("invalid transition Event")
 		}
 	case uint8(5):
 		break
@@ -133,18 +140,41 @@ func (sm *serverStateMachine) transition(event Event, text string) (ActionStatus
 	case uint8(4):
 		break
 	default:
-		return sm.goalStatus, fmt.Errorf("invalid state")
+		return sm.copyStatus(), fmt.Errorf("invalid state")
 	}
 
 	sm.goalStatus.SetStatus(nextState)
 	sm.goalStatus.SetStatusText(text)
 
-	return sm.goalStatus, nil
+	return sm.copyStatus(), nil
 }
 
 func (sm *serverStateMachine) getStatus() ActionStatus {
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
 
-	return sm.goalStatus
+	return sm.copyStatus()
+}
+
+// copyStatus returns a copy of the goal status, which can be read and published while the goal
+// carries on changing state. Requires mutex.
+func (sm *serverStateMachine) copyStatus() ActionStatus {
+	status := newGoalStatus()
+	status.SetGoalID(sm.goalStatus.GetGoalID())
+	status.SetStatus(sm.goalStatus.GetStatus())
+	status.SetStatusText(sm.goalStatus.GetStatusText())
+	return status
+}
+
+// newGoalStatus creates an empty goal status message of the shared goal status type.
+func newGoalStatus() *DynamicActionStatus {
+	goalStatusTypeOnce.Do(func() {
+		statusType, err := NewDynamicMessageType("actionlib_msgs/GoalStatus")
+		if err != nil {
+			DefaultLogger().Errorf("failed to generate goal status type: %v", err)
+			statusType = &DynamicMessageType{}
+		}
+		goalStatusType = &DynamicActionStatusType{*statusType}
+	})
+	return goalStatusType.NewStatusMessage().(*DynamicActionStatus)
 }
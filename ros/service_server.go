@@ -35,6 +35,10 @@ type defaultServiceServer struct {
 
 func newDefaultServiceServer(node *defaultNode, service string, srvType ServiceType, handler interface{}) *defaultServiceServer {
 	logger := node.logger
+	if err := checkServiceHandler(srvType, handler); err != nil {
+		logger.Errorf("failed to advertise service %s : %v", service, err)
+		return nil
+	}
 	server := new(defaultServiceServer)
 	if listener, err := listenRandomPort(node.listenIP, 10); err != nil {
 		logger.Errorf("failed to listen to random port : %v", err)
@@ -76,6 +80,27 @@ func newDefaultServiceServer(node *defaultNode, service string, srvType ServiceT
 	return server
 }
 
+// checkServiceHandler checks that a service handler takes the service, optionally followed by a ServiceEvent, and returns an error.
+func checkServiceHandler(srvType ServiceType, handler interface{}) error {
+	fun := reflect.TypeOf(handler)
+	if fun == nil || fun.Kind() != reflect.Func {
+		return fmt.Errorf("Service handler must be a function")
+	}
+	if fun.NumIn() < 1 || fun.NumIn() > 2 {
+		return fmt.Errorf("Service handler must take 1 or 2 arguments, takes %d", fun.NumIn())
+	}
+	if srv := reflect.TypeOf(srvType.NewService()); !srv.AssignableTo(fun.In(0)) {
+		return fmt.Errorf("Service handler takes %s, expected %s", fun.In(0), srv)
+	}
+	if fun.NumIn() == 2 && !reflect.TypeOf(ServiceEvent{}).AssignableTo(fun.In(1)) {
+		return fmt.Errorf("Service handler takes %s as its second argument, expected ros.ServiceEvent", fun.In(1))
+	}
+	if fun.NumOut() != 1 || fun.Out(0) != reflect.TypeOf((*error)(nil)).Elem() {
+		return fmt.Errorf("Service handler must return an error")
+	}
+	return nil
+}
+
 func (s *defaultServiceServer) Shutdown() {
 	s.shutdownChan <- struct{}{}
 }
@@ -204,6 +229,11 @@ func (s *remoteClientSession) start() {
 	headers = append(headers, header{"md5sum", md5sum})
 	headers = append(headers, header{"type", srvType})
 	headers = append(headers, header{"callerid", nodeID})
+	headers = append(headers, header{"request_type", s.server.srvType.RequestType().Name()})
+	headers = append(headers, header{"response_type", s.server.srvType.ResponseType().Name()})
+	reqDefinition, resDefinition := serviceDefinitions(s.server.srvType)
+	headers = append(headers, header{"request_definition", reqDefinition})
+	headers = append(headers, header{"response_definition", resDefinition})
 	logger.Debug("TCPROS Response Header")
 	for _, h := range headers {
 		logger.Debugf("  `%s` = `%s`", h.key, h.value)
@@ -238,6 +268,12 @@ func (s *remoteClientSession) start() {
 	if _, err = io.ReadFull(conn, resBuffer); err != nil {
 		panic(err)
 	}
+	srvEvent := ServiceEvent{
+		CallerID:         reqHeaderMap["callerid"],
+		ConnectionHeader: reqHeaderMap,
+		RemoteAddress:    conn.RemoteAddr().String(),
+		ReceiptTime:      time.Now(),
+	}
 
 	s.server.node.jobChan <- func() {
 		srv := s.server.srvType.NewService()
@@ -246,9 +282,14 @@ func (s *remoteClientSession) start() {
 		if err != nil {
 			s.errorChan <- err
 		}
-		args := []reflect.Value{reflect.ValueOf(srv)}
+		args := []reflect.Value{reflect.ValueOf(srv), reflect.ValueOf(srvEvent)}
 		fun := reflect.ValueOf(s.server.handler)
-		results := fun.Call(args)
+		numArgsNeeded := fun.Type().NumIn()
+		if numArgsNeeded < 1 || numArgsNeeded > 2 {
+			s.errorChan <- fmt.Errorf("Service handler has invalid signature")
+			return
+		}
+		results := fun.Call(args[:numArgsNeeded])
 
 		if len(results) != 1 {
 			logger.Debug("Service callback return type must be 'error'")
@@ -0,0 +1,305 @@
+package ros
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	modular "github.com/edwinhayes/logrus-modular"
+	"github.com/sirupsen/logrus"
+)
+
+// Tests
+
+func TestServiceServer_HandlerWithEvent(t *testing.T) {
+	var event ServiceEvent
+	handler := func(srv *testService, ev ServiceEvent) error {
+		event = ev
+		return nil
+	}
+
+	l, conn, node, session := setupServiceServerSession(t, testServiceType{}, handler)
+	defer l.Close()
+	defer conn.Close()
+
+	doServiceClientExchange(t, conn, node, session)
+	waitForSessionClose(t, session)
+
+	if event.CallerID != "/testClient" {
+		t.Fatalf("expected caller ID /testClient, got %s", event.CallerID)
+	}
+	if event.ConnectionHeader["service"] != "/test/service" {
+		t.Fatalf("expected connection header service /test/service, got %s", event.ConnectionHeader["service"])
+	}
+	if event.RemoteAddress != conn.LocalAddr().String() {
+		t.Fatalf("expected remote address %s, got %s", conn.LocalAddr().String(), event.RemoteAddress)
+	}
+	if event.ReceiptTime.IsZero() {
+		t.Fatal("expected receipt time to be set")
+	}
+}
+
+func TestServiceServer_HandlerWithoutEvent(t *testing.T) {
+	called := false
+	handler := func(srv *testService) error {
+		called = true
+		return nil
+	}
+
+	l, conn, node, session := setupServiceServerSession(t, testServiceType{}, handler)
+	defer l.Close()
+	defer conn.Close()
+
+	doServiceClientExchange(t, conn, node, session)
+	waitForSessionClose(t, session)
+
+	if !called {
+		t.Fatal("expected handler to be called")
+	}
+}
+
+func TestServiceServer_AdvertisesDefinitions(t *testing.T) {
+	// Service types which aren't known to the type registry advertise the text of their request and response.
+	resHeaders := probeServiceServer(t, testServiceType{})
+	expected := map[string]string{
+		"type":                "test_service",
+		"md5sum":              "0123456789abcdeffedcba9876543210",
+		"request_type":        "test_request",
+		"response_type":       "test_response",
+		"request_definition":  "test_request_type",
+		"response_definition": "test_response_type",
+	}
+	for key, value := range expected {
+		if resHeaders[key] != value {
+			t.Fatalf("expected %s header %s, got %s", key, value, resHeaders[key])
+		}
+	}
+
+	// Generated service types advertise the definitions of their nested messages too.
+	getPlan := &testGeneratedServiceType{
+		name:    "nav_msgs/GetPlan",
+		md5sum:  "421c8ea4d21c6c9db7054b4bbdf1e024",
+		reqType: &testGeneratedMessageType{"nav_msgs/GetPlanRequest", "e25a43e0752bcca599a8c2eef8282df8", "geometry_msgs/PoseStamped start\ngeometry_msgs/PoseStamped goal\nfloat32 tolerance\n"},
+		resType: &testGeneratedMessageType{"nav_msgs/GetPlanResponse", "0002bc113c0259d71f6cf8cbc9430e18", "nav_msgs/Path plan"},
+	}
+	header := newServiceHeader(probeServiceServer(t, getPlan))
+	if !strings.Contains(header.RequestDefinition, "\nMSG: geometry_msgs/PoseStamped\n") {
+		t.Fatalf("expected the request definition to include geometry_msgs/PoseStamped, got %q", header.RequestDefinition)
+	}
+	serviceType, err := NewDynamicServiceTypeFromHeader(header)
+	if err != nil {
+		t.Fatalf("failed to create service type from header: %s", err)
+	}
+	if serviceType.MD5Sum() != getPlan.md5sum {
+		t.Fatalf("expected md5sum %s, got %s", getPlan.md5sum, serviceType.MD5Sum())
+	}
+	if _, ok := serviceType.RequestType().(*DynamicMessageType).nested["geometry_msgs/PoseStamped"]; !ok {
+		t.Fatal("expected nested type geometry_msgs/PoseStamped to be resolved")
+	}
+
+	// Empty definitions are advertised as such.
+	empty := &testGeneratedServiceType{
+		name:    "std_srvs/Empty",
+		md5sum:  "d41d8cd98f00b204e9800998ecf8427e",
+		reqType: &testGeneratedMessageType{"std_srvs/EmptyRequest", "d41d8cd98f00b204e9800998ecf8427e", ""},
+		resType: &testGeneratedMessageType{"std_srvs/EmptyResponse", "d41d8cd98f00b204e9800998ecf8427e", ""},
+	}
+	header = newServiceHeader(probeServiceServer(t, empty))
+	if !header.HasDefinitions {
+		t.Fatal("expected the empty definitions to be advertised")
+	}
+	if _, err := NewDynamicServiceTypeFromHeader(header); err != nil {
+		t.Fatalf("failed to create service type from header: %s", err)
+	}
+}
+
+func TestServiceServer_CheckHandler(t *testing.T) {
+	valid := []interface{}{
+		func(srv *testService) error { return nil },
+		func(srv *testService, ev ServiceEvent) error { return nil },
+		func(srv Service) error { return nil },
+	}
+	for _, handler := range valid {
+		if err := checkServiceHandler(testServiceType{}, handler); err != nil {
+			t.Errorf("%T: unexpected error: %s", handler, err)
+		}
+	}
+
+	invalid := []interface{}{
+		nil,
+		"handler",
+		func() error { return nil },
+		func(srv *testService, ev ServiceEvent, extra int) error { return nil },
+		func(srv *testRequestMessage) error { return nil },
+		func(srv *testService, ev MessageEvent) error { return nil },
+		func(srv *testService, ev *ServiceEvent) error { return nil },
+		func(srv *testService) {},
+		func(srv *testService) bool { return true },
+	}
+	for _, handler := range invalid {
+		if err := checkServiceHandler(testServiceType{}, handler); err == nil {
+			t.Errorf("%T: expected error", handler)
+		}
+	}
+}
+
+// Test helper functions.
+
+// setupServiceServerSession creates a service server session of the service type for a connected client connection.  The session isn't started, as the server only waits
+// briefly for each part of the request; the client should write its request first.
+func setupServiceServerSession(t *testing.T, srvType ServiceType, handler interface{}) (net.Listener, net.Conn, *defaultNode, *remoteClientSession) {
+	rootLogger := modular.NewRootLogger(logrus.New())
+	logger := rootLogger.GetModuleLogger()
+	logger.SetLevel(logrus.WarnLevel)
+
+	node := &defaultNode{
+		qualifiedName: "/testServer",
+		jobChan:       make(chan func(), 1),
+		logger:        logger,
+	}
+	server := &defaultServiceServer{
+		node:             node,
+		service:          "/test/service",
+		srvType:          srvType,
+		handler:          handler,
+		sessionCloseChan: make(chan *remoteClientSessionCloseEvent, 10),
+	}
+
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := newRemoteClientSession(server, serverConn)
+	return l, conn, node, session
+}
+
+// probeServiceServer emulates a service client probing a server of the service type. Returns the response header.
+func probeServiceServer(t *testing.T, srvType ServiceType) map[string]string {
+	l, conn, _, session := setupServiceServerSession(t, srvType, func(srv Service) error { return nil })
+	defer l.Close()
+	defer conn.Close()
+
+	headers := []header{
+		{"probe", "1"},
+		{"md5sum", "*"},
+		{"callerid", "/testClient"},
+		{"service", "/test/service"},
+	}
+	if err := writeConnectionHeader(headers, conn); err != nil {
+		t.Fatalf("failed to write header: %s", err)
+	}
+	go session.start()
+
+	conn.SetDeadline(time.Now().Add(time.Second))
+	resHeaders, err := readConnectionHeader(conn)
+	if err != nil {
+		t.Fatalf("failed to read header: %s", err)
+	}
+	waitForSessionClose(t, session)
+
+	resHeaderMap := make(map[string]string)
+	for _, h := range resHeaders {
+		resHeaderMap[h.key] = h.value
+	}
+	return resHeaderMap
+}
+
+// doServiceClientExchange emulates a service client making a successful request of the session; the server's job is run on the test goroutine.
+func doServiceClientExchange(t *testing.T, conn net.Conn, node *defaultNode, session *remoteClientSession) {
+	headers := []header{
+		{"service", "/test/service"},
+		{"md5sum", testServiceType{}.MD5Sum()},
+		{"type", testServiceType{}.Name()},
+		{"callerid", "/testClient"},
+	}
+	if err := writeConnectionHeader(headers, conn); err != nil {
+		t.Fatalf("failed to write header: %s", err)
+	}
+	size := uint32(7)
+	if err := binary.Write(conn, binary.LittleEndian, &size); err != nil {
+		t.Fatalf("failed to write request size, %s", err)
+	}
+	if _, err := conn.Write([]byte("Request")); err != nil {
+		t.Fatalf("failed to write request, %s", err)
+	}
+	go session.start()
+
+	conn.SetDeadline(time.Now().Add(time.Second))
+	if _, err := readConnectionHeader(conn); err != nil {
+		t.Fatalf("failed to read header: %s", err)
+	}
+
+	select {
+	case job := <-node.jobChan:
+		job()
+	case <-time.After(time.Second):
+		t.Fatal("took too long for server to enqueue the handler job")
+	}
+
+	var ok uint8
+	if err := binary.Read(conn, binary.LittleEndian, &ok); err != nil {
+		t.Fatalf("failed to read ok byte, %s", err)
+	}
+	if ok != 1 {
+		t.Fatalf("expected ok byte 1, got %d", ok)
+	}
+	if err := binary.Read(conn, binary.LittleEndian, &size); err != nil {
+		t.Fatalf("failed to read response size, %s", err)
+	}
+	buffer := make([]byte, size)
+	if _, err := conn.Read(buffer); err != nil {
+		t.Fatalf("failed to read response, %s", err)
+	}
+	if string(buffer) != "Response" {
+		t.Fatalf("expected response `Response`, got %s", string(buffer))
+	}
+}
+
+// waitForSessionClose waits for the session to report that it has closed without error.
+func waitForSessionClose(t *testing.T, session *remoteClientSession) {
+	select {
+	case ev := <-session.server.sessionCloseChan:
+		if ev.err != nil {
+			t.Fatalf("session closed with error: %s", ev.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("took too long for session to close")
+	}
+}
+
+// testGeneratedMessageType is a message type like those generated by gengo, which only knows the text of its own definition.
+type testGeneratedMessageType struct {
+	name   string
+	md5sum string
+	text   string
+}
+
+func (t *testGeneratedMessageType) Text() string        { return t.text }
+func (t *testGeneratedMessageType) Name() string        { return t.name }
+func (t *testGeneratedMessageType) MD5Sum() string      { return t.md5sum }
+func (t *testGeneratedMessageType) NewMessage() Message { return nil }
+
+// testGeneratedServiceType is a service type like those generated by gengo.
+type testGeneratedServiceType struct {
+	name    string
+	md5sum  string
+	reqType MessageType
+	resType MessageType
+}
+
+func (t *testGeneratedServiceType) Name() string              { return t.name }
+func (t *testGeneratedServiceType) MD5Sum() string            { return t.md5sum }
+func (t *testGeneratedServiceType) RequestType() MessageType  { return t.reqType }
+func (t *testGeneratedServiceType) ResponseType() MessageType { return t.resType }
+func (t *testGeneratedServiceType) NewService() Service       { return nil }
@@ -77,6 +77,10 @@ func (sc *simpleActionClient) WaitForServer(timeout Duration) bool {
 	return sc.ac.WaitForServer(timeout)
 }
 
+func (sc *simpleActionClient) ServerConnected() bool {
+	return sc.ac.ServerConnected()
+}
+
 func (sc *simpleActionClient) WaitForResult(timeout Duration) bool {
 	logger := *sc.logger
 	if sc.gh == nil {
@@ -226,6 +230,21 @@ func (sc *simpleActionClient) transitionHandler(gh ClientGoalHandler) {
 		case SimpleStateDone:
 			logger.Errorf("[SimpleActionClient] received DONE twice")
 		}
+
+	case Lost:
+		switch sc.simpleState {
+		case SimpleStatePending, SimpleStateActive:
+			sc.setSimpleState(SimpleStateDone)
+			sc.sendDone()
+
+			if sc.doneCb == nil {
+				break
+			}
+
+			callbackType = "done"
+			args = append(args, reflect.ValueOf(uint8(9)))
+			args = append(args, sc.noDoneResult())
+		}
 	}
 
 	if len(callbackType) > 0 {
@@ -233,6 +252,16 @@ func (sc *simpleActionClient) transitionHandler(gh ClientGoalHandler) {
 	}
 }
 
+// noDoneResult returns a nil result of the type taken by the done callback,
+// for goals which finished without a result.
+func (sc *simpleActionClient) noDoneResult() reflect.Value {
+	funType := reflect.TypeOf(sc.doneCb)
+	if funType.NumIn() < 2 {
+		return reflect.Zero(reflect.TypeOf((*Message)(nil)).Elem())
+	}
+	return reflect.Zero(funType.In(1))
+}
+
 func (sc *simpleActionClient) sendDone() {
 	logger := *sc.logger
 	select {
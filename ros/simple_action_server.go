@@ -1,6 +1,7 @@
 package ros
 
 import (
+	goContext "context"
 	"fmt"
 	"reflect"
 	"sync"
@@ -22,8 +23,17 @@ type simpleActionServer struct {
 	preemptCallback       interface{}
 	executeCb             interface{}
 	executorCh            chan struct{}
+	executeCancel         goContext.CancelFunc
+	shutdownCtx           goContext.Context
+	shutdownCancel        goContext.CancelFunc
 }
 
+var (
+	contextType           = reflect.TypeOf((*goContext.Context)(nil)).Elem()
+	feedbackPublisherType = reflect.TypeOf((*FeedbackPublisher)(nil)).Elem()
+	errorType             = reflect.TypeOf((*error)(nil)).Elem()
+)
+
 func newSimpleActionServer(node Node, action string, actType ActionType, executeCb interface{}, start bool) *simpleActionServer {
 	s := new(simpleActionServer)
 	s.actionServer = newDefaultActionServer(node, action, actType, s.internalGoalCallback, s.internalPreemptCallback, start)
@@ -33,6 +43,7 @@ func newSimpleActionServer(node Node, action string, actType ActionType, execute
 	s.executeCb = executeCb
 	s.logger = node.Logger()
 	s.executorCh = make(chan struct{}, 100)
+	s.shutdownCtx, s.shutdownCancel = goContext.WithCancel(goContext.Background())
 	return s
 }
 
@@ -44,6 +55,14 @@ func (s *simpleActionServer) Start() {
 	go s.actionServer.Start()
 }
 
+// Shutdown stops the simple action server; the context passed to a running context execute callback is cancelled.
+func (s *simpleActionServer) Shutdown() {
+	s.shutdownCancel()
+	if s.actionServer.isStarted() {
+		s.actionServer.Shutdown()
+	}
+}
+
 func (s *simpleActionServer) IsNewGoalAvailable() bool {
 	s.goalMutex.Lock()
 	defer s.goalMutex.Unlock()
@@ -235,7 +254,7 @@ func (s *simpleActionServer) internalGoalCallback(ag ActionGoal) {
 		args := []reflect.Value{reflect.ValueOf(goal)}
 
 		if s.IsActive() {
-			s.preemptRequest = true
+			s.requestPreempt()
 			if err := s.runCallback("preempt", args); err != nil {
 				logger.Error(err)
 				return
@@ -276,7 +295,7 @@ func (s *simpleActionServer) internalPreemptCallback(gID ActionGoalID) {
 		logger.Errorf("error getting current goal id, err: %v", err)
 	}
 	if ghID.GetID() == currentID.GetID() {
-		s.preemptRequest = true
+		s.requestPreempt()
 		goal, err := goalHandler.GetGoal()
 		if err != nil {
 			logger.Errorf("error getting goal, err: %v", err)
@@ -297,6 +316,9 @@ func (s *simpleActionServer) goalExecutor() {
 
 	for s.actionServer.node.OK() {
 		select {
+		case <-s.shutdownCtx.Done():
+			return
+
 		case <-s.executorCh:
 			if err := s.execute(); err != nil {
 				logger.Error(err)
@@ -329,6 +351,10 @@ func (s *simpleActionServer) execute() error {
 			return fmt.Errorf("execute callback must exist. This is a bug in SimpleActionServer")
 		}
 
+		if isContextExecuteCallback(s.executeCb) {
+			return s.executeWithContext(goal)
+		}
+
 		args := []reflect.Value{reflect.ValueOf(goal), reflect.ValueOf(s.actionServer.actionType)}
 		if err := s.runCallback("execute", args); err != nil {
 			return err
@@ -347,6 +373,136 @@ func (s *simpleActionServer) execute() error {
 	return nil
 }
 
+// executeWithContext runs a context execute callback of the form
+// func(ctx context.Context, feedback FeedbackPublisher, goal T) (R, error),
+// cancelling ctx on preempt or shutdown, and maps the returned result and
+// error onto a terminal goal status.
+func (s *simpleActionServer) executeWithContext(goal Message) error {
+	logger := *s.logger
+	ctx, cancel := goContext.WithCancel(s.shutdownCtx)
+	defer cancel()
+
+	s.goalMutex.Lock()
+	s.executeCancel = cancel
+	feedback := s.currentGoal
+	if s.preemptRequest {
+		cancel()
+	}
+	s.goalMutex.Unlock()
+
+	defer func() {
+		s.goalMutex.Lock()
+		s.executeCancel = nil
+		s.goalMutex.Unlock()
+	}()
+
+	// cancel the execute context if the node is shut down while executing
+	go cancelOnNodeShutdown(ctx, cancel, s.actionServer.node)
+
+	result, err := callContextExecuteCallback(s.executeCb, ctx, feedback, goal)
+	if typeErr, ok := err.(*goalTypeError); ok {
+		logger.Errorf("[SimpleActionServer] %v", typeErr)
+		return s.SetAborted(nil, typeErr.Error())
+	}
+
+	// the callback may have already set a terminal status itself
+	if !s.IsActive() {
+		return nil
+	}
+
+	switch {
+	case err == nil:
+		return s.SetSucceeded(result, "")
+	case ctx.Err() != nil:
+		return s.SetPreempted(result, err.Error())
+	default:
+		return s.SetAborted(result, err.Error())
+	}
+}
+
+// requestPreempt flags a preempt request for the current goal and cancels
+// the context of a running context execute callback. Requires goalMutex.
+func (s *simpleActionServer) requestPreempt() {
+	s.preemptRequest = true
+	if s.executeCancel != nil {
+		s.executeCancel()
+	}
+}
+
+// isContextExecuteCallback checks whether the callback has the form
+// func(context.Context, FeedbackPublisher, T) (R, error).
+func isContextExecuteCallback(callback interface{}) bool {
+	funType := reflect.TypeOf(callback)
+	if funType == nil || funType.Kind() != reflect.Func {
+		return false
+	}
+	if funType.NumIn() != 3 || funType.NumOut() != 2 {
+		return false
+	}
+
+	return funType.In(0) == contextType &&
+		funType.In(1) == feedbackPublisherType &&
+		funType.Out(1) == errorType
+}
+
+// cancelOnNodeShutdown cancels ctx once the node is shut down, returning
+// when ctx is done.
+func cancelOnNodeShutdown(ctx goContext.Context, cancel goContext.CancelFunc, node Node) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !node.OK() {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// goalTypeError is returned by callContextExecuteCallback when the goal can't
+// be passed to the callback, as opposed to an error returned by the callback.
+type goalTypeError struct {
+	goal    Message
+	argType reflect.Type
+}
+
+func (e *goalTypeError) Error() string {
+	return fmt.Sprintf("goal of type %T is not assignable to execute callback argument of type %s", e.goal, e.argType)
+}
+
+// callContextExecuteCallback calls a context execute callback, returning the
+// result and error returned by the callback, or a *goalTypeError if the
+// callback could not be called with the goal.
+func callContextExecuteCallback(callback interface{}, ctx goContext.Context, feedback FeedbackPublisher, goal Message) (result Message, err error) {
+	fun := reflect.ValueOf(callback)
+	goalValue := reflect.ValueOf(goal)
+	if !goalValue.IsValid() || !goalValue.Type().AssignableTo(fun.Type().In(2)) {
+		return nil, &goalTypeError{goal, fun.Type().In(2)}
+	}
+
+	results := fun.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(feedback), goalValue})
+
+	if r := results[0]; r.IsValid() {
+		switch r.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			if !r.IsNil() {
+				result, _ = r.Interface().(Message)
+			}
+		default:
+			result, _ = r.Interface().(Message)
+		}
+	}
+	if e := results[1]; !e.IsNil() {
+		err = e.Interface().(error)
+	}
+
+	return result, err
+}
+
 func (s *simpleActionServer) runCallback(cbType string, args []reflect.Value) error {
 	var callback interface{}
 	switch cbType {
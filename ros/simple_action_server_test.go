@@ -0,0 +1,372 @@
+package ros
+
+import (
+	goContext "context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	modular "github.com/edwinhayes/logrus-modular"
+	"github.com/sirupsen/logrus"
+)
+
+// Fake node for action servers, recording the messages they publish without connecting to a ROS master.
+type testActionNode struct {
+	Node
+	mutex       sync.Mutex
+	ok          bool
+	params      map[string]interface{}
+	publishers  map[string]*testRecordingPublisher
+	subscribers map[string]interface{}
+	logger      modular.ModuleLogger
+}
+
+func newTestActionNode() *testActionNode {
+	logger := modular.NewRootLogger(logrus.New()).GetModuleLogger()
+	logger.SetLevel(logrus.FatalLevel)
+	return &testActionNode{
+		ok:          true,
+		params:      map[string]interface{}{},
+		publishers:  map[string]*testRecordingPublisher{},
+		subscribers: map[string]interface{}{},
+		logger:      logger,
+	}
+}
+
+func (n *testActionNode) OK() bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.ok
+}
+
+func (n *testActionNode) Shutdown() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.ok = false
+}
+
+func (n *testActionNode) Name() string                  { return "test_node" }
+func (n *testActionNode) Logger() *modular.ModuleLogger { return &n.logger }
+
+func (n *testActionNode) GetParam(name string) (interface{}, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if value, ok := n.params[name]; ok {
+		return value, nil
+	}
+	return nil, errors.New("parameter " + name + " is not set")
+}
+
+func (n *testActionNode) NewPublisher(topic string, msgType MessageType) (Publisher, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	pub := &testRecordingPublisher{}
+	n.publishers[topic] = pub
+	return pub, nil
+}
+
+func (n *testActionNode) NewSubscriber(topic string, msgType MessageType, callback interface{}) (Subscriber, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.subscribers[topic] = callback
+	return &testCountingSubscriber{1}, nil
+}
+
+func (n *testActionNode) subscriber(topic string) interface{} {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.subscribers[topic]
+}
+
+func (n *testActionNode) publisher(topic string) *testRecordingPublisher {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.publishers[topic]
+}
+
+// Fake publisher recording the messages published on it.
+type testRecordingPublisher struct {
+	mutex    sync.Mutex
+	messages []Message
+}
+
+func (p *testRecordingPublisher) TryPublish(msg Message) error {
+	p.Publish(msg)
+	return nil
+}
+
+func (p *testRecordingPublisher) Publish(msg Message) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.messages = append(p.messages, msg)
+}
+
+func (p *testRecordingPublisher) GetNumSubscribers() int { return 1 }
+func (p *testRecordingPublisher) Shutdown()              {}
+
+func (p *testRecordingPublisher) published() []Message {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]Message(nil), p.messages...)
+}
+
+func TestSimpleActionServer_IsContextExecuteCallback(t *testing.T) {
+	valid := []interface{}{
+		func(ctx goContext.Context, fb FeedbackPublisher, goal Message) (Message, error) { return nil, nil },
+		func(ctx goContext.Context, fb FeedbackPublisher, goal *DynamicMessage) (*DynamicMessage, error) {
+			return nil, nil
+		},
+	}
+	for i, cb := range valid {
+		if !isContextExecuteCallback(cb) {
+			t.Fatalf("expected callback %d to be a context execute callback", i)
+		}
+	}
+
+	invalid := []interface{}{
+		nil,
+		"not a function",
+		func(goal Message) {},
+		func(goal Message, actionType ActionType) {},
+		func(ctx goContext.Context, goal Message) (Message, error) { return nil, nil },
+		func(ctx goContext.Context, fb FeedbackPublisher, goal Message) error { return nil },
+		func(ctx goContext.Context, fb FeedbackPublisher, goal Message) (Message, bool) { return nil, false },
+	}
+	for i, cb := range invalid {
+		if isContextExecuteCallback(cb) {
+			t.Fatalf("expected callback %d not to be a context execute callback", i)
+		}
+	}
+}
+
+func TestSimpleActionServer_ContextExecuteSucceeded(t *testing.T) {
+	result := newTestActionResult(t, 3)
+	executeCb := func(ctx goContext.Context, fb FeedbackPublisher, goal *DynamicMessage) (*DynamicMessage, error) {
+		if target, err := goal.GetInt64("target"); err != nil || target != 3 {
+			return nil, errors.New("unexpected target")
+		}
+		return result, nil
+	}
+	s, node := startTestSimpleActionServer(t, executeCb)
+	defer s.Shutdown()
+
+	gh := offerTestActionGoal(t, s, "goal1", 3)
+	waitForGoalStatus(t, gh, 3) // SUCCEEDED
+
+	published := node.publisher("/test/result").published()
+	if len(published) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(published))
+	}
+	if actionResult := published[0].(*DynamicActionResult); actionResult.GetResult() != result {
+		t.Fatalf("expected the result returned by the callback, got %v", actionResult.GetResult())
+	}
+}
+
+func TestSimpleActionServer_ContextExecuteAborted(t *testing.T) {
+	executeCb := func(ctx goContext.Context, fb FeedbackPublisher, goal *DynamicMessage) (*DynamicMessage, error) {
+		return nil, errors.New("target out of reach")
+	}
+	s, _ := startTestSimpleActionServer(t, executeCb)
+	defer s.Shutdown()
+
+	gh := offerTestActionGoal(t, s, "goal1", 3)
+	waitForGoalStatus(t, gh, 4) // ABORTED
+	if text := gh.sm.getStatus().GetStatusText(); text != "target out of reach" {
+		t.Fatalf("expected the error as status text, got %q", text)
+	}
+}
+
+func TestSimpleActionServer_ContextExecuteGoalTypeMismatch(t *testing.T) {
+	called := false
+	executeCb := func(ctx goContext.Context, fb FeedbackPublisher, goal *DynamicActionResult) (*DynamicMessage, error) {
+		called = true
+		return nil, nil
+	}
+	s, _ := startTestSimpleActionServer(t, executeCb)
+	defer s.Shutdown()
+
+	gh := offerTestActionGoal(t, s, "goal1", 3)
+	waitForGoalStatus(t, gh, 4) // ABORTED
+	if text := gh.sm.getStatus().GetStatusText(); !strings.Contains(text, "is not assignable to execute callback argument") {
+		t.Fatalf("expected the goal type error as status text, got %q", text)
+	}
+	if called {
+		t.Fatal("expected the callback not to be called")
+	}
+}
+
+func TestSimpleActionServer_ContextExecutePreempted(t *testing.T) {
+	running := make(chan struct{})
+	executeCb := func(ctx goContext.Context, fb FeedbackPublisher, goal *DynamicMessage) (*DynamicMessage, error) {
+		close(running)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	s, _ := startTestSimpleActionServer(t, executeCb)
+	defer s.Shutdown()
+
+	gh := offerTestActionGoal(t, s, "goal1", 3)
+	waitForChannel(t, running, "execute callback to run")
+
+	goalID, err := gh.GetGoalId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.internalPreemptCallback(goalID)
+	waitForGoalStatus(t, gh, 2) // PREEMPTED
+	if !s.IsPreemptRequested() {
+		t.Fatal("expected preempt to be requested")
+	}
+}
+
+func TestSimpleActionServer_ContextExecuteCancelledOnShutdown(t *testing.T) {
+	running := make(chan struct{})
+	executeCb := func(ctx goContext.Context, fb FeedbackPublisher, goal *DynamicMessage) (*DynamicMessage, error) {
+		close(running)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	s, _ := startTestSimpleActionServer(t, executeCb)
+
+	gh := offerTestActionGoal(t, s, "goal1", 3)
+	waitForChannel(t, running, "execute callback to run")
+
+	s.Shutdown()
+	waitForGoalStatus(t, gh, 2) // PREEMPTED
+	waitForCondition(t, "action server to stop", func() bool { return !s.actionServer.isStarted() })
+}
+
+func TestSimpleActionServer_ContextExecuteCancelledOnNodeShutdown(t *testing.T) {
+	running := make(chan struct{})
+	executeCb := func(ctx goContext.Context, fb FeedbackPublisher, goal *DynamicMessage) (*DynamicMessage, error) {
+		close(running)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	s, node := startTestSimpleActionServer(t, executeCb)
+	defer s.Shutdown()
+
+	gh := offerTestActionGoal(t, s, "goal1", 3)
+	waitForChannel(t, running, "execute callback to run")
+
+	node.Shutdown()
+	waitForGoalStatus(t, gh, 2) // PREEMPTED
+}
+
+func TestSimpleActionServer_ShutdownBeforeStart(t *testing.T) {
+	s := newSimpleActionServer(newTestActionNode(), "/test", newTestActionType(t), nil, false)
+
+	done := make(chan struct{})
+	go func() {
+		s.Shutdown()
+		close(done)
+	}()
+	waitForChannel(t, done, "shutdown to return")
+}
+
+// Test helper functions.
+
+// newTestActionType creates an action type counting up to a target, from a registry of its own.
+func newTestActionType(t *testing.T) *DynamicActionType {
+	actType, err := newTestActionRegistry().NewDynamicActionType("test_msgs/Count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return actType
+}
+
+// newTestActionResult creates a result of the test action type.
+func newTestActionResult(t *testing.T, count int32) *DynamicMessage {
+	// The result type is generated along with the action type.
+	registry := newTestActionRegistry()
+	if _, err := registry.NewDynamicActionType("test_msgs/Count"); err != nil {
+		t.Fatal(err)
+	}
+	resultType, err := registry.NewDynamicMessageType("test_msgs/CountResult")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := resultType.NewDynamicMessageFromMap(map[string]interface{}{"count": count})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func newTestActionRegistry() *TypeRegistry {
+	registry := NewTypeRegistry("")
+	registry.RegisterActionDefinition("test_msgs/Count", "int32 target\n---\nint32 count\n---\nint32 progress")
+	return registry
+}
+
+// newTestActionGoal creates a goal message for the test action type, as received on the goal topic.
+func newTestActionGoal(t *testing.T, actType ActionType, id string, stamp Time, target int32) *DynamicMessage {
+	goal, err := actType.GoalType().(*DynamicActionGoalType).NewDynamicMessageFromMap(map[string]interface{}{
+		"goal_id": map[string]interface{}{"id": id, "stamp": stamp},
+		"goal":    map[string]interface{}{"target": target},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return goal
+}
+
+// startTestSimpleActionServer starts a simple action server on a fake node, waiting for it to be initialized.
+func startTestSimpleActionServer(t *testing.T, executeCb interface{}) (*simpleActionServer, *testActionNode) {
+	node := newTestActionNode()
+	s := newSimpleActionServer(node, "/test", newTestActionType(t), executeCb, false)
+	s.Start()
+	waitForCondition(t, "action server to start", s.actionServer.isStarted)
+	return s, node
+}
+
+// offerTestActionGoal makes a goal the next goal of the simple action server and notifies its executor, as a goal received with no current goal does.
+func offerTestActionGoal(t *testing.T, s *simpleActionServer, id string, target int32) *serverGoalHandler {
+	msg := newTestActionGoal(t, s.actionServer.actionType, id, Now(), target)
+	goal := s.actionServer.actionType.GoalType().(*DynamicActionGoalType).NewGoalMessageFromInterface(msg)
+	gh, err := newServerGoalHandlerWithGoal(s.actionServer, goal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.actionServer.handlersMutex.Lock()
+	s.actionServer.handlers[id] = gh
+	s.actionServer.handlersMutex.Unlock()
+
+	s.goalMutex.Lock()
+	s.nextGoal = gh
+	s.newGoal = true
+	s.goalMutex.Unlock()
+	s.executorCh <- struct{}{}
+	return gh
+}
+
+// waitForGoalStatus waits for the goal to reach the status.
+func waitForGoalStatus(t *testing.T, gh *serverGoalHandler, status uint8) {
+	t.Helper()
+	waitForCondition(t, "goal status", func() bool { return gh.sm.getStatus().GetStatus() == status })
+}
+
+// waitForCondition polls the condition until it holds, failing the test after a second.
+func waitForCondition(t *testing.T, description string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("took too long waiting for %s", description)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// waitForChannel waits for the channel to be closed, failing the test after a second.
+func waitForChannel(t *testing.T, ch <-chan struct{}, description string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("took too long waiting for %s", description)
+	}
+}
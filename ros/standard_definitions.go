@@ -0,0 +1,354 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// definitionText holds the text of a ROS message, service or action definition.
+type definitionText struct {
+	kind string // One of "msg", "srv" or "action".
+	name string // Fully-qualified type name.
+	text string
+}
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// standardDefinitions is a bundle of the core ROS definitions, used by every TypeRegistry when no definition file is found on its package path; this lets dynamic types of the
+// common topics be created without a ROS install.  Comments have been removed from the definitions, which doesn't affect their MD5 sums.
+var standardDefinitions = []definitionText{
+	// std_msgs
+	{"msg", "std_msgs/Bool", "bool data"},
+	{"msg", "std_msgs/Byte", "byte data"},
+	{"msg", "std_msgs/ByteMultiArray", "MultiArrayLayout layout\nbyte[] data"},
+	{"msg", "std_msgs/Char", "char data"},
+	{"msg", "std_msgs/ColorRGBA", "float32 r\nfloat32 g\nfloat32 b\nfloat32 a"},
+	{"msg", "std_msgs/Duration", "duration data"},
+	{"msg", "std_msgs/Empty", ""},
+	{"msg", "std_msgs/Float32", "float32 data"},
+	{"msg", "std_msgs/Float32MultiArray", "MultiArrayLayout layout\nfloat32[] data"},
+	{"msg", "std_msgs/Float64", "float64 data"},
+	{"msg", "std_msgs/Float64MultiArray", "MultiArrayLayout layout\nfloat64[] data"},
+	{"msg", "std_msgs/Header", "uint32 seq\ntime stamp\nstring frame_id"},
+	{"msg", "std_msgs/Int8", "int8 data"},
+	{"msg", "std_msgs/Int8MultiArray", "MultiArrayLayout layout\nint8[] data"},
+	{"msg", "std_msgs/Int16", "int16 data"},
+	{"msg", "std_msgs/Int16MultiArray", "MultiArrayLayout layout\nint16[] data"},
+	{"msg", "std_msgs/Int32", "int32 data"},
+	{"msg", "std_msgs/Int32MultiArray", "MultiArrayLayout layout\nint32[] data"},
+	{"msg", "std_msgs/Int64", "int64 data"},
+	{"msg", "std_msgs/Int64MultiArray", "MultiArrayLayout layout\nint64[] data"},
+	{"msg", "std_msgs/MultiArrayDimension", "string label\nuint32 size\nuint32 stride"},
+	{"msg", "std_msgs/MultiArrayLayout", "MultiArrayDimension[] dim\nuint32 data_offset"},
+	{"msg", "std_msgs/String", "string data"},
+	{"msg", "std_msgs/Time", "time data"},
+	{"msg", "std_msgs/UInt8", "uint8 data"},
+	{"msg", "std_msgs/UInt8MultiArray", "MultiArrayLayout layout\nuint8[] data"},
+	{"msg", "std_msgs/UInt16", "uint16 data"},
+	{"msg", "std_msgs/UInt16MultiArray", "MultiArrayLayout layout\nuint16[] data"},
+	{"msg", "std_msgs/UInt32", "uint32 data"},
+	{"msg", "std_msgs/UInt32MultiArray", "MultiArrayLayout layout\nuint32[] data"},
+	{"msg", "std_msgs/UInt64", "uint64 data"},
+	{"msg", "std_msgs/UInt64MultiArray", "MultiArrayLayout layout\nuint64[] data"},
+
+	// std_srvs
+	{"srv", "std_srvs/Empty", "---"},
+	{"srv", "std_srvs/SetBool", "bool data\n---\nbool success\nstring message"},
+	{"srv", "std_srvs/Trigger", "---\nbool success\nstring message"},
+
+	// geometry_msgs
+	{"msg", "geometry_msgs/Accel", "Vector3 linear\nVector3 angular"},
+	{"msg", "geometry_msgs/AccelStamped", "Header header\nAccel accel"},
+	{"msg", "geometry_msgs/AccelWithCovariance", "Accel accel\nfloat64[36] covariance"},
+	{"msg", "geometry_msgs/AccelWithCovarianceStamped", "Header header\nAccelWithCovariance accel"},
+	{"msg", "geometry_msgs/Inertia", "float64 m\ngeometry_msgs/Vector3 com\nfloat64 ixx\nfloat64 ixy\nfloat64 ixz\nfloat64 iyy\nfloat64 iyz\nfloat64 izz"},
+	{"msg", "geometry_msgs/InertiaStamped", "Header header\nInertia inertia"},
+	{"msg", "geometry_msgs/Point", "float64 x\nfloat64 y\nfloat64 z"},
+	{"msg", "geometry_msgs/Point32", "float32 x\nfloat32 y\nfloat32 z"},
+	{"msg", "geometry_msgs/PointStamped", "Header header\nPoint point"},
+	{"msg", "geometry_msgs/Polygon", "geometry_msgs/Point32[] points"},
+	{"msg", "geometry_msgs/PolygonStamped", "Header header\nPolygon polygon"},
+	{"msg", "geometry_msgs/Pose", "Point position\nQuaternion orientation"},
+	{"msg", "geometry_msgs/Pose2D", "float64 x\nfloat64 y\nfloat64 theta"},
+	{"msg", "geometry_msgs/PoseArray", "Header header\nPose[] poses"},
+	{"msg", "geometry_msgs/PoseStamped", "Header header\nPose pose"},
+	{"msg", "geometry_msgs/PoseWithCovariance", "Pose pose\nfloat64[36] covariance"},
+	{"msg", "geometry_msgs/PoseWithCovarianceStamped", "Header header\nPoseWithCovariance pose"},
+	{"msg", "geometry_msgs/Quaternion", "float64 x\nfloat64 y\nfloat64 z\nfloat64 w"},
+	{"msg", "geometry_msgs/QuaternionStamped", "Header header\nQuaternion quaternion"},
+	{"msg", "geometry_msgs/Transform", "Vector3 translation\nQuaternion rotation"},
+	{"msg", "geometry_msgs/TransformStamped", "Header header\nstring child_frame_id\nTransform transform"},
+	{"msg", "geometry_msgs/Twist", "Vector3 linear\nVector3 angular"},
+	{"msg", "geometry_msgs/TwistStamped", "Header header\nTwist twist"},
+	{"msg", "geometry_msgs/TwistWithCovariance", "Twist twist\nfloat64[36] covariance"},
+	{"msg", "geometry_msgs/TwistWithCovarianceStamped", "Header header\nTwistWithCovariance twist"},
+	{"msg", "geometry_msgs/Vector3", "float64 x\nfloat64 y\nfloat64 z"},
+	{"msg", "geometry_msgs/Vector3Stamped", "Header header\nVector3 vector"},
+	{"msg", "geometry_msgs/Wrench", "Vector3 force\nVector3 torque"},
+	{"msg", "geometry_msgs/WrenchStamped", "Header header\nWrench wrench"},
+
+	// sensor_msgs
+	{"msg", "sensor_msgs/BatteryState", `uint8 POWER_SUPPLY_STATUS_UNKNOWN = 0
+uint8 POWER_SUPPLY_STATUS_CHARGING = 1
+uint8 POWER_SUPPLY_STATUS_DISCHARGING = 2
+uint8 POWER_SUPPLY_STATUS_NOT_CHARGING = 3
+uint8 POWER_SUPPLY_STATUS_FULL = 4
+uint8 POWER_SUPPLY_HEALTH_UNKNOWN = 0
+uint8 POWER_SUPPLY_HEALTH_GOOD = 1
+uint8 POWER_SUPPLY_HEALTH_OVERHEAT = 2
+uint8 POWER_SUPPLY_HEALTH_DEAD = 3
+uint8 POWER_SUPPLY_HEALTH_OVERVOLTAGE = 4
+uint8 POWER_SUPPLY_HEALTH_UNSPEC_FAILURE = 5
+uint8 POWER_SUPPLY_HEALTH_COLD = 6
+uint8 POWER_SUPPLY_HEALTH_WATCHDOG_TIMER_EXPIRE = 7
+uint8 POWER_SUPPLY_HEALTH_SAFETY_TIMER_EXPIRE = 8
+uint8 POWER_SUPPLY_TECHNOLOGY_UNKNOWN = 0
+uint8 POWER_SUPPLY_TECHNOLOGY_NIMH = 1
+uint8 POWER_SUPPLY_TECHNOLOGY_LION = 2
+uint8 POWER_SUPPLY_TECHNOLOGY_LIPO = 3
+uint8 POWER_SUPPLY_TECHNOLOGY_LIFE = 4
+uint8 POWER_SUPPLY_TECHNOLOGY_NICD = 5
+uint8 POWER_SUPPLY_TECHNOLOGY_LIMN = 6
+Header  header
+float32 voltage
+float32 temperature
+float32 current
+float32 charge
+float32 capacity
+float32 design_capacity
+float32 percentage
+uint8   power_supply_status
+uint8   power_supply_health
+uint8   power_supply_technology
+bool    present
+float32[] cell_voltage
+float32[] cell_temperature
+string location
+string serial_number`},
+	{"msg", "sensor_msgs/CameraInfo", `Header header
+uint32 height
+uint32 width
+string distortion_model
+float64[] D
+float64[9]  K
+float64[9]  R
+float64[12] P
+uint32 binning_x
+uint32 binning_y
+RegionOfInterest roi`},
+	{"msg", "sensor_msgs/ChannelFloat32", "string name\nfloat32[] values"},
+	{"msg", "sensor_msgs/CompressedImage", "Header header\nstring format\nuint8[] data"},
+	{"msg", "sensor_msgs/FluidPressure", "Header header\nfloat64 fluid_pressure\nfloat64 variance"},
+	{"msg", "sensor_msgs/Illuminance", "Header header\nfloat64 illuminance\nfloat64 variance"},
+	{"msg", "sensor_msgs/Image", `Header header
+uint32 height
+uint32 width
+string encoding
+uint8 is_bigendian
+uint32 step
+uint8[] data`},
+	{"msg", "sensor_msgs/Imu", `Header header
+geometry_msgs/Quaternion orientation
+float64[9] orientation_covariance
+geometry_msgs/Vector3 angular_velocity
+float64[9] angular_velocity_covariance
+geometry_msgs/Vector3 linear_acceleration
+float64[9] linear_acceleration_covariance`},
+	{"msg", "sensor_msgs/JointState", "Header header\nstring[] name\nfloat64[] position\nfloat64[] velocity\nfloat64[] effort"},
+	{"msg", "sensor_msgs/Joy", "Header header\nfloat32[] axes\nint32[] buttons"},
+	{"msg", "sensor_msgs/JoyFeedback", "uint8 TYPE_LED    = 0\nuint8 TYPE_RUMBLE = 1\nuint8 TYPE_BUZZER = 2\nuint8 type\nuint8 id\nfloat32 intensity"},
+	{"msg", "sensor_msgs/JoyFeedbackArray", "sensor_msgs/JoyFeedback[] array"},
+	{"msg", "sensor_msgs/LaserEcho", "float32[] echoes"},
+	{"msg", "sensor_msgs/LaserScan", `Header header
+float32 angle_min
+float32 angle_max
+float32 angle_increment
+float32 time_increment
+float32 scan_time
+float32 range_min
+float32 range_max
+float32[] ranges
+float32[] intensities`},
+	{"msg", "sensor_msgs/MagneticField", "Header header\ngeometry_msgs/Vector3 magnetic_field\nfloat64[9] magnetic_field_covariance"},
+	{"msg", "sensor_msgs/MultiDOFJointState", `Header header
+string[] joint_names
+geometry_msgs/Transform[] transforms
+geometry_msgs/Twist[] twist
+geometry_msgs/Wrench[] wrench`},
+	{"msg", "sensor_msgs/MultiEchoLaserScan", `Header header
+float32 angle_min
+float32 angle_max
+float32 angle_increment
+float32 time_increment
+float32 scan_time
+float32 range_min
+float32 range_max
+LaserEcho[] ranges
+LaserEcho[] intensities`},
+	{"msg", "sensor_msgs/NavSatFix", `Header header
+NavSatStatus status
+float64 latitude
+float64 longitude
+float64 altitude
+float64[9] position_covariance
+uint8 COVARIANCE_TYPE_UNKNOWN = 0
+uint8 COVARIANCE_TYPE_APPROXIMATED = 1
+uint8 COVARIANCE_TYPE_DIAGONAL_KNOWN = 2
+uint8 COVARIANCE_TYPE_KNOWN = 3
+uint8 position_covariance_type`},
+	{"msg", "sensor_msgs/NavSatStatus", `int8 STATUS_NO_FIX =  -1
+int8 STATUS_FIX =      0
+int8 STATUS_SBAS_FIX = 1
+int8 STATUS_GBAS_FIX = 2
+int8 status
+uint16 SERVICE_GPS =     1
+uint16 SERVICE_GLONASS = 2
+uint16 SERVICE_COMPASS = 4
+uint16 SERVICE_GALILEO = 8
+uint16 service`},
+	{"msg", "sensor_msgs/PointCloud", "Header header\ngeometry_msgs/Point32[] points\nChannelFloat32[] channels"},
+	{"msg", "sensor_msgs/PointCloud2", `Header header
+uint32 height
+uint32 width
+PointField[] fields
+bool    is_bigendian
+uint32  point_step
+uint32  row_step
+uint8[] data
+bool is_dense`},
+	{"msg", "sensor_msgs/PointField", `uint8 INT8    = 1
+uint8 UINT8   = 2
+uint8 INT16   = 3
+uint8 UINT16  = 4
+uint8 INT32   = 5
+uint8 UINT32  = 6
+uint8 FLOAT32 = 7
+uint8 FLOAT64 = 8
+string name
+uint32 offset
+uint8  datatype
+uint32 count`},
+	{"msg", "sensor_msgs/Range", `Header header
+uint8 ULTRASOUND=0
+uint8 INFRARED=1
+uint8 radiation_type
+float32 field_of_view
+float32 min_range
+float32 max_range
+float32 range`},
+	{"msg", "sensor_msgs/RegionOfInterest", "uint32 x_offset\nuint32 y_offset\nuint32 height\nuint32 width\nbool do_rectify"},
+	{"msg", "sensor_msgs/RelativeHumidity", "Header header\nfloat64 relative_humidity\nfloat64 variance"},
+	{"msg", "sensor_msgs/Temperature", "Header header\nfloat64 temperature\nfloat64 variance"},
+	{"msg", "sensor_msgs/TimeReference", "Header header\ntime   time_ref\nstring source"},
+	{"srv", "sensor_msgs/SetCameraInfo", "sensor_msgs/CameraInfo camera_info\n---\nbool success\nstring status_message"},
+
+	// nav_msgs
+	{"msg", "nav_msgs/GridCells", "Header header\nfloat32 cell_width\nfloat32 cell_height\ngeometry_msgs/Point[] cells"},
+	{"msg", "nav_msgs/MapMetaData", "time map_load_time\nfloat32 resolution\nuint32 width\nuint32 height\ngeometry_msgs/Pose origin"},
+	{"msg", "nav_msgs/OccupancyGrid", "Header header\nMapMetaData info\nint8[] data"},
+	{"msg", "nav_msgs/Odometry", "Header header\nstring child_frame_id\ngeometry_msgs/PoseWithCovariance pose\ngeometry_msgs/TwistWithCovariance twist"},
+	{"msg", "nav_msgs/Path", "Header header\ngeometry_msgs/PoseStamped[] poses"},
+	{"srv", "nav_msgs/GetMap", "---\nnav_msgs/OccupancyGrid map"},
+	{"srv", "nav_msgs/GetPlan", "geometry_msgs/PoseStamped start\ngeometry_msgs/PoseStamped goal\nfloat32 tolerance\n---\nnav_msgs/Path plan"},
+	{"srv", "nav_msgs/SetMap", "nav_msgs/OccupancyGrid map\ngeometry_msgs/PoseWithCovarianceStamped initial_pose\n---\nbool success"},
+	{"action", "nav_msgs/GetMap", "---\nnav_msgs/OccupancyGrid map\n---"},
+
+	// actionlib_msgs
+	{"msg", "actionlib_msgs/GoalID", "time stamp\nstring id"},
+	{"msg", "actionlib_msgs/GoalStatus", `GoalID goal_id
+uint8 status
+uint8 PENDING         = 0
+uint8 ACTIVE          = 1
+uint8 PREEMPTED       = 2
+uint8 SUCCEEDED       = 3
+uint8 ABORTED         = 4
+uint8 REJECTED        = 5
+uint8 PREEMPTING      = 6
+uint8 RECALLING       = 7
+uint8 RECALLED        = 8
+uint8 LOST            = 9
+string text`},
+	{"msg", "actionlib_msgs/GoalStatusArray", "Header header\nGoalStatus[] status_list"},
+
+	// rosgraph_msgs
+	{"msg", "rosgraph_msgs/Clock", "time clock"},
+	{"msg", "rosgraph_msgs/Log", `byte DEBUG=1
+byte INFO=2
+byte WARN=4
+byte ERROR=8
+byte FATAL=16
+Header header
+byte level
+string name
+string msg
+string file
+string function
+uint32 line
+string[] topics`},
+	{"msg", "rosgraph_msgs/TopicStatistics", `string topic
+string node_pub
+string node_sub
+time window_start
+time window_stop
+int32 delivered_msgs
+int32 dropped_msgs
+int32 traffic
+duration period_mean
+duration period_stddev
+duration period_max
+duration stamp_age_mean
+duration stamp_age_stddev
+duration stamp_age_max`},
+
+	// diagnostic_msgs
+	{"msg", "diagnostic_msgs/DiagnosticArray", "Header header\nDiagnosticStatus[] status"},
+	{"msg", "diagnostic_msgs/DiagnosticStatus", `byte OK=0
+byte WARN=1
+byte ERROR=2
+byte STALE=3
+byte level
+string name
+string message
+string hardware_id
+KeyValue[] values`},
+	{"msg", "diagnostic_msgs/KeyValue", "string key\nstring value"},
+	{"srv", "diagnostic_msgs/AddDiagnostics", "string load_namespace\n---\nbool success\nstring message"},
+	{"srv", "diagnostic_msgs/SelfTest", "---\nstring id\nbyte passed\nDiagnosticStatus[] status"},
+
+	// tf2_msgs
+	{"msg", "tf2_msgs/TF2Error", `uint8 NO_ERROR = 0
+uint8 LOOKUP_ERROR = 1
+uint8 CONNECTIVITY_ERROR = 2
+uint8 EXTRAPOLATION_ERROR = 3
+uint8 INVALID_ARGUMENT_ERROR = 4
+uint8 TIMEOUT_ERROR = 5
+uint8 TRANSFORM_ERROR = 6
+uint8 error
+string error_string`},
+	{"msg", "tf2_msgs/TFMessage", "geometry_msgs/TransformStamped[] transforms"},
+	{"srv", "tf2_msgs/FrameGraph", "---\nstring frame_yaml"},
+	{"action", "tf2_msgs/LookupTransform", `string target_frame
+string source_frame
+time source_time
+duration timeout
+time target_time
+string fixed_frame
+bool advanced
+---
+geometry_msgs/TransformStamped transform
+tf2_msgs/TF2Error error
+---`},
+}
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// ALL DONE.
@@ -0,0 +1,62 @@
+package ros
+
+import "testing"
+
+func TestStandardDefinitions_MD5Sums(t *testing.T) {
+	// MD5 sums of the definitions shipped with ROS Noetic.
+	expected := map[string]string{
+		"std_msgs/Header":                 "2176decaecbce78abc3b96ef049fabed",
+		"std_msgs/String":                 "992ce8a1687cec8c8bd883ec73ca41d1",
+		"std_msgs/Empty":                  "d41d8cd98f00b204e9800998ecf8427e",
+		"std_msgs/ColorRGBA":              "a29a96539573343b1310c73607334b00",
+		"geometry_msgs/Point":             "4a842b65f413084dc2b10fb484ea7f17",
+		"geometry_msgs/Pose":              "e45d45a5a1ce597b249e23fb30fc871f",
+		"geometry_msgs/PoseStamped":       "d3812c3cbc69362b77dc0b19b345f8f5",
+		"geometry_msgs/Twist":             "9f195f881246fdfa2798d1d3eebca84a",
+		"geometry_msgs/TransformStamped":  "b5764a33bfeb3588febc2682852579b0",
+		"sensor_msgs/Imu":                 "6a62c6daae103f4ff57a132d6f95cec2",
+		"sensor_msgs/LaserScan":           "90c7ef2dc6895d81024acba2ac42f369",
+		"sensor_msgs/Image":               "060021388200f6f0f447d0fcd9c64743",
+		"sensor_msgs/PointCloud2":         "1158d486dd51d683ce2f1be655c3c181",
+		"sensor_msgs/JointState":          "3066dcd76a6cfaef579bd0f34173e9fd",
+		"sensor_msgs/NavSatFix":           "2d3a8cd499b9b4a0249fb98fd05cfa48",
+		"nav_msgs/Odometry":               "cd5e73d190d741a2f92e81eda573aca7",
+		"nav_msgs/OccupancyGrid":          "3381f2d731d4076ec5c71b0759edbe4e",
+		"nav_msgs/Path":                   "6227e2b7e9cce15051f669a5e197bbf7",
+		"actionlib_msgs/GoalID":           "302881f31927c1df708a2dbab0e80ee8",
+		"actionlib_msgs/GoalStatus":       "d388f9b87b3c471f784434d671988d4a",
+		"actionlib_msgs/GoalStatusArray":  "8b2b82f13216d0a8ea88bd3af735e619",
+		"rosgraph_msgs/Clock":             "a9c97c1d230cfc112e270351a944ee47",
+		"rosgraph_msgs/Log":               "acffd30cd6b6de30f120938c17c593fb",
+		"diagnostic_msgs/DiagnosticArray": "60810da900de1dd6ddd437c3503511da",
+		"tf2_msgs/TFMessage":              "94810edda583a504dfda3829e70d7eec",
+	}
+
+	registry := NewTypeRegistry("")
+	for name, md5sum := range expected {
+		msgType, err := registry.NewDynamicMessageType(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if msgType.MD5Sum() != md5sum {
+			t.Errorf("%s: expected md5sum %s, got %s", name, md5sum, msgType.MD5Sum())
+		}
+	}
+
+	// Every bundled definition must load.
+	for _, definition := range standardDefinitions {
+		var err error
+		switch definition.kind {
+		case "msg":
+			_, err = registry.NewDynamicMessageType(definition.name)
+		case "srv":
+			_, err = registry.NewDynamicServiceType(definition.name)
+		case "action":
+			_, err = registry.NewDynamicActionType(definition.name)
+		}
+		if err != nil {
+			t.Errorf("%s %s: %v", definition.kind, definition.name, err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// TypeRegistry resolves ROS message, service and action definitions into dynamic types.  Each registry owns its own package search path, definition context and cache of the
+// types it has generated, so several sets of definitions, e.g. different versions of the same packages, can be used side by side in one process.  Definitions are looked up
+// first amongst those registered with the registry, then on the package path, and finally amongst a bundle of the standard definitions (std_msgs, geometry_msgs, sensor_msgs,
+// nav_msgs, actionlib_msgs, etc.) so that common types can be used without a ROS install.  The package level functions such as NewDynamicMessageType() use the default
+// registry.  A TypeRegistry is safe for concurrent use.  Types are generated once and cached, but each caller is given its own copy, so that settings such as
+// SetLazyDecoding() and SetByteDecoder() only apply to the messages of the copy they are made on.
+type TypeRegistry struct {
+	mutex           sync.Mutex
+	pkgPath         string               // Colon separated list of paths to search for definitions on.
+	fromEnvironment bool                 // Whether an empty pkgPath defaults to ${ROS_PACKAGE_PATH}.
+	definitions     []definitionText     // Definitions registered with the registry, which take precedence over the package path.
+	pkgContext      *libgengo.PkgContext // Created on first use by searching pkgPath.
+	msgTypes        map[string]*DynamicMessageType
+	srvTypes        map[string]*DynamicServiceType
+	actionTypes     map[string]*DynamicActionType
+}
+
+// DEFINE PRIVATE STRUCTURES.
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// defaultTypeRegistry is used by the package level functions, and searches ${ROS_PACKAGE_PATH} unless SetRuntimePackagePath() is called.
+var defaultTypeRegistry = &TypeRegistry{fromEnvironment: true}
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// NewTypeRegistry creates a TypeRegistry which looks up definitions on the specified colon separated list of ROS package paths.  The paths aren't searched until the first type is
+// requested.
+func NewTypeRegistry(pkgPath string) *TypeRegistry {
+	return &TypeRegistry{pkgPath: pkgPath}
+}
+
+// DefaultTypeRegistry returns the registry used by NewDynamicMessageType(), NewDynamicServiceType() and NewDynamicActionType().
+func DefaultTypeRegistry() *TypeRegistry {
+	return defaultTypeRegistry
+}
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// SetPackagePath sets the colon separated list of ROS package paths the registry searches for definitions, discarding all definitions and types loaded so far.
+func (r *TypeRegistry) SetPackagePath(pkgPath string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.pkgPath = pkgPath
+	r.reset()
+}
+
+// PackagePath returns the colon separated list of ROS package paths the registry searches for definitions.
+func (r *TypeRegistry) PackagePath() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.packagePath()
+}
+
+// Reset discards all definitions and types loaded so far, so that the package paths are searched again when the next type is requested.  Types which have already been returned
+// remain valid.
+func (r *TypeRegistry) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.reset()
+}
+
+// RegisterMessageDefinition adds the text of a message definition, e.g. "Header header\nfloat64 value", under the fully-qualified ROS message type name.  Registered definitions
+// take precedence over definition files found on the package path, and are kept when the registry is reset.
+func (r *TypeRegistry) RegisterMessageDefinition(typeName string, text string) {
+	r.register(definitionText{"msg", typeName, text})
+}
+
+// RegisterServiceDefinition adds the text of a service definition, with the request and response separated by "---", under the fully-qualified ROS service type name.
+func (r *TypeRegistry) RegisterServiceDefinition(typeName string, text string) {
+	r.register(definitionText{"srv", typeName, text})
+}
+
+// RegisterActionDefinition adds the text of an action definition, with the goal, result and feedback separated by "---", under the fully-qualified ROS action type name.
+func (r *TypeRegistry) RegisterActionDefinition(typeName string, text string) {
+	r.register(definitionText{"action", typeName, text})
+}
+
+// NewDynamicMessageType returns a DynamicMessageType for the fully-qualified ROS message type name, generating it from the registry's definitions the first time it is requested.
+func (r *TypeRegistry) NewDynamicMessageType(typeName string) (*DynamicMessageType, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if t, ok := r.msgTypes[typeName]; ok {
+		return t.clone(), nil
+	}
+	ctx, err := r.loadContext()
+	if err != nil {
+		return &DynamicMessageType{}, err
+	}
+	t, err := newDynamicMessageTypeNestedInContext(ctx, typeName, "", nil, nil)
+	if err != nil {
+		return t, err
+	}
+	r.msgTypes[typeName] = t
+	return t.clone(), nil
+}
+
+// NewDynamicServiceType returns a DynamicServiceType for the fully-qualified ROS service type name, generating it from the registry's definitions the first time it is requested.
+func (r *TypeRegistry) NewDynamicServiceType(typeName string) (*DynamicServiceType, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if t, ok := r.srvTypes[typeName]; ok {
+		return t.clone(), nil
+	}
+	ctx, err := r.loadContext()
+	if err != nil {
+		return nil, err
+	}
+	t, err := newDynamicServiceTypeNested(ctx, typeName, "")
+	if err != nil {
+		return nil, err
+	}
+	r.srvTypes[typeName] = t
+	return t.clone(), nil
+}
+
+// NewDynamicActionType returns a DynamicActionType for the fully-qualified ROS action type name, generating it from the registry's definitions the first time it is requested.
+func (r *TypeRegistry) NewDynamicActionType(typeName string) (*DynamicActionType, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if t, ok := r.actionTypes[typeName]; ok {
+		return t.clone(), nil
+	}
+	ctx, err := r.loadContext()
+	if err != nil {
+		return nil, err
+	}
+	t, err := newDynamicActionTypeNested(ctx, typeName, "")
+	if err != nil {
+		return nil, err
+	}
+	r.actionTypes[typeName] = t
+	return t.clone(), nil
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// addDefinitionText adds the text of a definition to a context.
+func addDefinitionText(ctx *libgengo.PkgContext, definition definitionText, fallback bool) {
+	switch definition.kind {
+	case "msg":
+		ctx.AddMsgText(definition.name, definition.text, fallback)
+	case "srv":
+		ctx.AddSrvText(definition.name, definition.text, fallback)
+	case "action":
+		ctx.AddActionText(definition.name, definition.text, fallback)
+	}
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// register adds a definition to the registry, discarding the definitions and types loaded so far so that it takes effect.
+func (r *TypeRegistry) register(definition definitionText) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.definitions = append(r.definitions, definition)
+	r.reset()
+}
+
+// packagePath returns the package search path, falling back to the environment if required.  Requires mutex.
+func (r *TypeRegistry) packagePath() string {
+	// If a package path hasn't been set at the time of first use, by default we'll just use the ROS environment default.
+	if r.pkgPath == "" && r.fromEnvironment {
+		r.pkgPath = os.Getenv("ROS_PACKAGE_PATH")
+	}
+	return r.pkgPath
+}
+
+// reset discards the definition context and type caches.  Requires mutex.
+func (r *TypeRegistry) reset() {
+	r.pkgContext = nil
+	r.msgTypes = nil
+	r.srvTypes = nil
+	r.actionTypes = nil
+}
+
+// loadContext returns the registry's definition context, creating it by searching the package path if required.  Requires mutex.
+func (r *TypeRegistry) loadContext() (*libgengo.PkgContext, error) {
+	if r.pkgContext == nil {
+		// Create context for our ROS install.
+		ctx, err := libgengo.NewPkgContext(strings.Split(r.packagePath(), ":"))
+		if err != nil {
+			return nil, err
+		}
+		for _, definition := range standardDefinitions {
+			addDefinitionText(ctx, definition, true)
+		}
+		for _, definition := range r.definitions {
+			addDefinitionText(ctx, definition, false)
+		}
+		r.pkgContext = ctx
+		r.msgTypes = make(map[string]*DynamicMessageType)
+		r.srvTypes = make(map[string]*DynamicServiceType)
+		r.actionTypes = make(map[string]*DynamicActionType)
+	}
+	return r.pkgContext, nil
+}
+
+// ALL DONE.
@@ -0,0 +1,72 @@
+//go:build go1.16
+// +build go1.16
+
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// RegisterDefinitionsFS registers every definition found in a file system laid out like a ROS package path, i.e. as <package>/msg/<Type>.msg, <package>/srv/<Type>.srv and
+// <package>/action/<Type>.action at any depth.  This allows definitions to be embedded in a binary with go:embed.  The definitions are registered as if by
+// RegisterMessageDefinition(), RegisterServiceDefinition() and RegisterActionDefinition().
+func (r *TypeRegistry) RegisterDefinitionsFS(fsys fs.FS) error {
+	definitions := make([]definitionText, 0)
+	err := fs.WalkDir(fsys, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		// Only files in a directory named after their extension, within a package, are definitions.
+		kind := strings.TrimPrefix(path.Ext(filePath), ".")
+		dir := path.Dir(filePath)
+		if kind != "msg" && kind != "srv" && kind != "action" || path.Base(dir) != kind || path.Dir(dir) == "." {
+			return nil
+		}
+		packageName := path.Base(path.Dir(dir))
+		typeName := packageName + "/" + strings.TrimSuffix(path.Base(filePath), path.Ext(filePath))
+
+		text, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return err
+		}
+		definitions = append(definitions, definitionText{kind, typeName, string(text)})
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "error reading definitions")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.definitions = append(r.definitions, definitions...)
+	r.reset()
+	return nil
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// ALL DONE.
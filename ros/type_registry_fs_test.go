@@ -0,0 +1,43 @@
+//go:build go1.16
+// +build go1.16
+
+package ros
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestTypeRegistry_RegisterDefinitionsFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"share/test_msgs/msg/Status.msg":   {Data: []byte("uint8 level\nstring detail")},
+		"share/test_msgs/msg/Report.msg":   {Data: []byte("Header header\nStatus status")},
+		"share/test_msgs/srv/Query.srv":    {Data: []byte("string name\n---\nReport report")},
+		"share/test_msgs/action/Do.action": {Data: []byte("int32 goal\n---\nint32 result\n---\nStatus feedback")},
+		"share/test_msgs/README.md":        {Data: []byte("not a definition")},
+		"share/test_msgs/msg/notes.txt":    {Data: []byte("not a definition")},
+		"msg/Orphan.msg":                   {Data: []byte("not in a package")},
+	}
+
+	registry := NewTypeRegistry("")
+	if err := registry.RegisterDefinitionsFS(fsys); err != nil {
+		t.Fatal(err)
+	}
+	if len(registry.definitions) != 4 {
+		t.Fatalf("expected 4 definitions, got %v", registry.definitions)
+	}
+
+	report, err := registry.NewDynamicMessageType("test_msgs/Report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := report.NewDynamicMessage().Set("status.detail", "ok"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.NewDynamicServiceType("test_msgs/Query"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.NewDynamicActionType("test_msgs/Do"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,221 @@
+package ros
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// newRegisteredTestType creates a message type from the text of its definition, registered with a fresh TypeRegistry so that it can use the standard definitions.
+func newRegisteredTestType(t *testing.T, typeName string, text string) *DynamicMessageType {
+	registry := NewTypeRegistry("")
+	registry.RegisterMessageDefinition(typeName, text)
+	msgType, err := registry.NewDynamicMessageType(typeName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msgType
+}
+
+// writeTestPackage creates a ROS package under root, holding the specified definition files.
+func writeTestPackage(t *testing.T, root string, name string, files map[string]string) {
+	pkg := filepath.Join(root, name)
+	for name, text := range files {
+		path := filepath.Join(pkg, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(pkg, "package.xml"), []byte("<package/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTypeRegistry_Isolation(t *testing.T) {
+	root, err := ioutil.TempDir("", "rosgo_registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	v1, v2 := filepath.Join(root, "v1"), filepath.Join(root, "v2")
+	writeTestPackage(t, v1, "test_msgs", map[string]string{
+		"msg/Status.msg":   "uint8 level",
+		"msg/Report.msg":   "Status status\nstring text",
+		"srv/Query.srv":    "string name\n---\nReport report",
+		"action/Do.action": "int32 goal\n---\nint32 result\n---\nStatus feedback",
+	})
+	writeTestPackage(t, v2, "test_msgs", map[string]string{
+		"msg/Status.msg": "uint8 level\nstring detail",
+		"msg/Report.msg": "Status status\nstring text",
+	})
+
+	// Actions depend on the standard headers.
+	writeTestPackage(t, v1, "std_msgs", map[string]string{"msg/Header.msg": "uint32 seq\ntime stamp\nstring frame_id"})
+	writeTestPackage(t, v1, "actionlib_msgs", map[string]string{
+		"msg/GoalID.msg":     "time stamp\nstring id",
+		"msg/GoalStatus.msg": "GoalID goal_id\nuint8 status\nstring text",
+	})
+
+	r1, r2 := NewTypeRegistry(v1), NewTypeRegistry(v2)
+	report1, err := r1.NewDynamicMessageType("test_msgs/Report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report2, err := r2.NewDynamicMessageType("test_msgs/Report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report1.MD5Sum() == report2.MD5Sum() {
+		t.Fatal("expected different versions of test_msgs/Report to have different md5sums")
+	}
+	if err := report2.NewDynamicMessage().Set("status.detail", "ok"); err != nil {
+		t.Fatalf("expected nested type from v2: %v", err)
+	}
+	if err := report1.NewDynamicMessage().Set("status.detail", "ok"); err == nil {
+		t.Fatal("expected nested type from v1")
+	}
+
+	// Types are cached, but each caller is given a copy which can be configured independently.
+	again, _ := r1.NewDynamicMessageType("test_msgs/Report")
+	if again == report1 || again.spec != report1.spec {
+		t.Fatal("expected a copy of the cached type")
+	}
+	again.SetLazyDecoding(true)
+	again.SetByteDecoder(BEByteDecoder{})
+	if report1.LazyDecoding() || report1.ByteDecoder() != (LEByteDecoder{}) {
+		t.Fatal("expected settings to apply to the copy only")
+	}
+	if again.messagePool() == report1.messagePool() {
+		t.Fatal("expected the copy to have its own message pool")
+	}
+
+	// Services and actions are resolved from the same definitions.
+	srvType, err := r1.NewDynamicServiceType("test_msgs/Query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srvType.ResponseType().Name() != "test_msgs/QueryResponse" {
+		t.Fatalf("unexpected response type %s", srvType.ResponseType().Name())
+	}
+	if _, err := r2.NewDynamicServiceType("test_msgs/Query"); err == nil {
+		t.Fatal("expected error for service missing from v2")
+	}
+	if srvAgain, _ := r1.NewDynamicServiceType("test_msgs/Query"); srvAgain.RequestType() == srvType.RequestType() {
+		t.Fatal("expected a copy of the cached request type")
+	}
+	actionType, err := r1.NewDynamicActionType("test_msgs/Do")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actionAgain, _ := r1.NewDynamicActionType("test_msgs/Do")
+	actionAgain.GoalType().(*DynamicActionGoalType).SetLazyDecoding(true)
+	if actionType.GoalType().(*DynamicActionGoalType).LazyDecoding() {
+		t.Fatal("expected settings to apply to the copy of the goal type only")
+	}
+
+	// Changing the package path discards the cached definitions.
+	r1.SetPackagePath(v2)
+	if r1.PackagePath() != v2 {
+		t.Fatalf("expected package path %s, got %s", v2, r1.PackagePath())
+	}
+	report, err := r1.NewDynamicMessageType("test_msgs/Report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.MD5Sum() != report2.MD5Sum() {
+		t.Fatal("expected definitions from new package path")
+	}
+}
+
+func TestTypeRegistry_Concurrent(t *testing.T) {
+	root, err := ioutil.TempDir("", "rosgo_registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeTestPackage(t, root, "test_msgs", map[string]string{
+		"msg/Status.msg": "uint8 level",
+		"msg/Report.msg": "Status status\nStatus[] history\nstring text",
+	})
+
+	registry := NewTypeRegistry(root)
+	var wg sync.WaitGroup
+	types := make([]*DynamicMessageType, 8)
+	errs := make([]error, len(types))
+	for i := range types {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := "test_msgs/Report"
+			if i%2 == 1 {
+				name = "test_msgs/Status"
+				registry.Reset()
+			}
+			types[i], errs[i] = registry.NewDynamicMessageType(name)
+		}(i)
+	}
+	wg.Wait()
+	for i := range types {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if types[i].NewDynamicMessage() == nil {
+			t.Fatalf("invalid type %d", i)
+		}
+	}
+}
+
+func TestTypeRegistry_RegisterDefinitions(t *testing.T) {
+	root, err := ioutil.TempDir("", "rosgo_registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	writeTestPackage(t, root, "test_msgs", map[string]string{"msg/Status.msg": "uint8 level"})
+
+	registry := NewTypeRegistry(root)
+	status, err := registry.NewDynamicMessageType("test_msgs/Status")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Registered definitions may depend on each other and on the standard definitions, in any order, and take precedence over files.
+	registry.RegisterMessageDefinition("test_msgs/Report", "Header header\nStatus status\ngeometry_msgs/Point where")
+	registry.RegisterMessageDefinition("test_msgs/Status", "uint8 level\nstring detail")
+	registry.RegisterServiceDefinition("test_msgs/Query", "string name\n---\nReport report")
+	registry.RegisterActionDefinition("test_msgs/Do", "int32 goal\n---\nint32 result\n---\nStatus feedback")
+
+	report, err := registry.NewDynamicMessageType("test_msgs/Report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := report.NewDynamicMessage().Set("status.detail", "ok"); err != nil {
+		t.Fatalf("expected registered definition of test_msgs/Status: %v", err)
+	}
+	if again, _ := registry.NewDynamicMessageType("test_msgs/Status"); again == status || again.MD5Sum() == status.MD5Sum() {
+		t.Fatal("expected registering a definition to replace the cached type")
+	}
+	if _, err := registry.NewDynamicServiceType("test_msgs/Query"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.NewDynamicActionType("test_msgs/Do"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Registered definitions survive a reset.
+	registry.Reset()
+	if _, err := registry.NewDynamicMessageType("test_msgs/Report"); err != nil {
+		t.Fatal(err)
+	}
+
+	registry.RegisterMessageDefinition("test_msgs/Broken", "Missing missing")
+	if _, err := registry.NewDynamicMessageType("test_msgs/Broken"); err == nil {
+		t.Fatal("expected error for definition with missing dependency")
+	}
+}
@@ -0,0 +1,696 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/team-rocos/rosgo/libgengo"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// YAMLEncoder writes a stream of messages in the format of `rostopic echo`, each followed by a `---` separator line.
+type YAMLEncoder struct {
+	w io.Writer
+}
+
+// YAMLDecoder reads a stream of messages in the format written by YAMLEncoder or `rostopic echo`, where messages are separated by `---` lines.
+type YAMLDecoder struct {
+	scanner *bufio.Scanner
+}
+
+// DEFINE PRIVATE STRUCTURES.
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+var (
+	timeType     = reflect.TypeOf(Time{})
+	durationType = reflect.TypeOf(Duration{})
+)
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// MarshalYAML formats a message in the same way as `rostopic echo`, without the trailing `---` separator.  Both DynamicMessages and messages generated by gengo are supported; times
+// and durations are written as secs/nsecs, arrays of primitives (including uint8 arrays) as flow sequences and arrays of messages as block sequences.
+func MarshalYAML(msg Message) ([]byte, error) {
+	if msg == nil {
+		return nil, errors.New("nil message")
+	}
+	text, err := strifyYAML(msg, "")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(text + "\n"), nil
+}
+
+// UnmarshalYAML parses a message in the format written by `rostopic echo` or accepted by `rostopic pub`, including flow style such as `{linear: {x: 1.0}}`.  Fields which are not
+// given are zeroed, messages may be given as sequences of their field values, and times may be given as "now".  The message must be a *DynamicMessage or a pointer to a message
+// generated by gengo.
+func UnmarshalYAML(buf []byte, msg Message) error {
+	node, err := parseYAML(string(buf))
+	if err != nil {
+		return err
+	}
+
+	if dynamic, ok := msg.(*DynamicMessage); ok {
+		if dynamic == nil || dynamic.dynamicType == nil || dynamic.dynamicType.spec == nil {
+			return errors.New("invalid dynamic message")
+		}
+		return dynamic.fromYAML(node)
+	}
+
+	target := reflect.ValueOf(msg)
+	if !target.IsValid() || target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return errors.New("expected a pointer to a generated message struct")
+	}
+	zero := reflect.New(target.Elem().Type()).Elem()
+	if err := yamlIntoValue(node, zero); err != nil {
+		return err
+	}
+	target.Elem().Set(zero)
+	return nil
+}
+
+// NewYAMLEncoder creates a YAMLEncoder writing to w.
+func NewYAMLEncoder(w io.Writer) *YAMLEncoder {
+	return &YAMLEncoder{w: w}
+}
+
+// NewYAMLDecoder creates a YAMLDecoder reading from r.
+func NewYAMLDecoder(r io.Reader) *YAMLDecoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), math.MaxInt32)
+	return &YAMLDecoder{scanner: scanner}
+}
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// Encode writes a message followed by a `---` separator line.
+func (e *YAMLEncoder) Encode(msg Message) error {
+	buf, err := MarshalYAML(msg)
+	if err != nil {
+		return err
+	}
+	buf = append(buf, "---\n"...)
+	_, err = e.w.Write(buf)
+	return err
+}
+
+// Decode reads the next message from the stream into msg, returning io.EOF when no messages remain.
+func (d *YAMLDecoder) Decode(msg Message) error {
+	var document strings.Builder
+	for d.scanner.Scan() {
+		line := d.scanner.Text()
+		if strings.TrimRight(line, " \r") == "---" {
+			if strings.TrimSpace(document.String()) == "" {
+				continue
+			}
+			return UnmarshalYAML([]byte(document.String()), msg)
+		}
+		document.WriteString(line)
+		document.WriteByte('\n')
+	}
+	if err := d.scanner.Err(); err != nil {
+		return err
+	}
+	if strings.TrimSpace(document.String()) == "" {
+		return io.EOF
+	}
+	return UnmarshalYAML([]byte(document.String()), msg)
+}
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// Marshalling Helpers.
+
+// strifyYAML formats a value in the same way as genpy's strify_message(), which `rostopic echo` uses.  Messages nested below the top level start on a new line, with their fields
+// indented by two spaces more than their parent's.
+func strifyYAML(value interface{}, indent string) (string, error) {
+	switch v := value.(type) {
+	case *DynamicMessage:
+		if err := messagePointerError(v); err != nil {
+			return "", err
+		}
+		if err := v.decodeLazyFields(); err != nil {
+			return "", err
+		}
+		lines := make([]string, 0, len(v.dynamicType.spec.Fields))
+		for _, field := range v.dynamicType.spec.Fields {
+			fieldValue, ok := v.data[field.Name]
+			if !ok {
+				return "", errors.New("Field: " + field.Name + ": No data found.")
+			}
+			text, err := strifyYAML(fieldValue, indent+"  ")
+			if err != nil {
+				return "", errors.Wrap(err, "Field: "+field.Name)
+			}
+			lines = append(lines, indent+field.Name+": "+text)
+		}
+		return joinYAMLLines(lines, indent), nil
+	case Time:
+		return strifyYAMLTemporal(int64(v.Sec), v.NSec, indent), nil
+	case Duration:
+		return strifyYAMLTemporal(int64(int32(v.Sec)), v.NSec, indent), nil
+	case string:
+		if v == "" {
+			return "''", nil
+		}
+		return strconv.Quote(v), nil
+	}
+
+	if text, ok := strifyYAMLPrimitive(value); ok {
+		return text, nil
+	}
+
+	// Messages generated by gengo, and arrays.
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", errors.New("nil message")
+		}
+		return strifyYAML(rv.Elem().Interface(), indent)
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		lines := make([]string, 0, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			name, ok := rosmsgFieldName(rv.Type().Field(i))
+			if !ok {
+				continue
+			}
+			text, err := strifyYAML(rv.Field(i).Interface(), indent+"  ")
+			if err != nil {
+				return "", errors.Wrap(err, "Field: "+name)
+			}
+			lines = append(lines, indent+name+": "+text)
+		}
+		return joinYAMLLines(lines, indent), nil
+
+	case reflect.Slice, reflect.Array:
+		if rv.Len() == 0 {
+			return "[]", nil
+		}
+		// Arrays of primitives are written as flow sequences, as Python formats lists.
+		items := make([]string, rv.Len())
+		for i := range items {
+			item, ok := strifyYAMLFlowItem(rv.Index(i).Interface())
+			if !ok {
+				items = nil
+				break
+			}
+			items[i] = item
+		}
+		if items != nil {
+			return "[" + strings.Join(items, ", ") + "]", nil
+		}
+		// Arrays of messages, times and durations are written as block sequences.
+		lines := make([]string, rv.Len())
+		for i := range lines {
+			text, err := strifyYAML(rv.Index(i).Interface(), indent+"  ")
+			if err != nil {
+				return "", errors.Wrap(err, "index "+strconv.Itoa(i))
+			}
+			lines[i] = indent + "- " + text
+		}
+		return "\n" + strings.Join(lines, "\n"), nil
+	}
+
+	return "", errors.New("cannot format " + describeValue(value) + " as yaml")
+}
+
+// joinYAMLLines joins the field lines of a message, which start on a new line when the message is nested.
+func joinYAMLLines(lines []string, indent string) string {
+	if indent == "" {
+		return strings.Join(lines, "\n")
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
+
+// strifyYAMLTemporal formats a time or duration as a message with secs and nsecs fields; the seconds of durations are signed.
+func strifyYAMLTemporal(sec int64, nsec uint32, indent string) string {
+	return "\n" + indent + "secs: " + strconv.FormatInt(sec, 10) + "\n" + indent + "nsecs: " + strconv.FormatUint(uint64(nsec), 10)
+}
+
+// strifyYAMLPrimitive formats a number or bool as Python does.
+func strifyYAMLPrimitive(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return "True", true
+		}
+		return "False", true
+	case int8:
+		return strconv.FormatInt(int64(v), 10), true
+	case int16:
+		return strconv.FormatInt(int64(v), 10), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float32:
+		return formatPythonFloat(float64(v)), true
+	case float64:
+		return formatPythonFloat(v), true
+	case JsonFloat32:
+		return formatPythonFloat(float64(v.F)), true
+	case JsonFloat64:
+		return formatPythonFloat(v.F), true
+	}
+	return "", false
+}
+
+// strifyYAMLFlowItem formats an element of an array of primitives as Python formats list elements; strings use Python's quoting.
+func strifyYAMLFlowItem(value interface{}) (string, bool) {
+	if s, ok := value.(string); ok {
+		return formatPythonString(s), true
+	}
+	return strifyYAMLPrimitive(value)
+}
+
+// formatPythonFloat formats a float as Python's repr() does, e.g. 1.0, 0.1, 1e-05, 1e+16, nan and inf.  float32 values are formatted as the float64 they convert to, as genpy does.
+func formatPythonFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+
+	// Python switches to exponent notation outside 1e-4 <= |f| < 1e16.
+	exponent := strconv.FormatFloat(f, 'e', -1, 64)
+	power, _ := strconv.Atoi(exponent[strings.IndexByte(exponent, 'e')+1:])
+	if f != 0 && (power < -4 || power >= 16) {
+		return exponent
+	}
+	text := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.ContainsRune(text, '.') {
+		text += ".0"
+	}
+	return text
+}
+
+// formatPythonString quotes a string as Python's repr() does.
+func formatPythonString(s string) string {
+	quote := byte('\'')
+	if strings.IndexByte(s, '\'') >= 0 && strings.IndexByte(s, '"') < 0 {
+		quote = '"'
+	}
+
+	var buf strings.Builder
+	buf.WriteByte(quote)
+	for _, r := range s {
+		switch {
+		case r == rune(quote) || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r == '\n':
+			buf.WriteString("\\n")
+		case r == '\r':
+			buf.WriteString("\\r")
+		case r == '\t':
+			buf.WriteString("\\t")
+		case r < 0x20 || r == 0x7f:
+			buf.WriteString("\\x" + strconv.FormatUint(uint64(r)|0x100, 16)[1:])
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte(quote)
+	return buf.String()
+}
+
+// rosmsgFieldName returns the message field name from the `rosmsg` tag of a generated struct field.
+func rosmsgFieldName(field reflect.StructField) (string, bool) {
+	tag, ok := field.Tag.Lookup(rosmsgTag)
+	if !ok {
+		return "", false
+	}
+	if colon := strings.IndexByte(tag, ':'); colon >= 0 {
+		tag = tag[:colon]
+	}
+	return tag, true
+}
+
+// Unmarshalling Helpers.
+
+// yamlScalarValue returns the text of a scalar node.
+func yamlScalarValue(node interface{}) (yamlScalar, error) {
+	scalar, ok := node.(yamlScalar)
+	if !ok {
+		return yamlScalar{}, errors.New("expected a scalar, got " + describeYAMLNode(node))
+	}
+	return scalar, nil
+}
+
+// describeYAMLNode describes a parsed YAML node for error messages.
+func describeYAMLNode(node interface{}) string {
+	switch node.(type) {
+	case map[string]interface{}:
+		return "a mapping"
+	case []interface{}:
+		return "a sequence"
+	case yamlScalar:
+		return "a scalar"
+	}
+	return "nothing"
+}
+
+// parseYAMLBool parses the boolean forms accepted by YAML and Python.
+func parseYAMLBool(text string) (bool, error) {
+	switch strings.ToLower(text) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0":
+		return false, nil
+	}
+	return false, errors.New("invalid bool " + strconv.Quote(text))
+}
+
+// parseYAMLFloat parses a float, including the forms of NaN and infinity used by Python and YAML.
+func parseYAMLFloat(text string) (float64, error) {
+	switch strings.ToLower(text) {
+	case "nan", ".nan":
+		return math.NaN(), nil
+	case "inf", ".inf", "+inf", "+.inf":
+		return math.Inf(1), nil
+	case "-inf", "-.inf":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+// parseYAMLTemporal parses a time or duration given as a secs/nsecs mapping, or "now" for times.  The seconds of durations are signed, and are returned as their two's
+// complement.
+func parseYAMLTemporal(node interface{}, isTime bool) (uint32, uint32, error) {
+	if scalar, ok := node.(yamlScalar); ok && isTime && scalar.value == "now" {
+		now := Now()
+		return now.Sec, now.NSec, nil
+	}
+	mapping, ok := node.(map[string]interface{})
+	if !ok {
+		return 0, 0, errors.New("expected a mapping with secs and nsecs, got " + describeYAMLNode(node))
+	}
+	var parts [2]uint32
+	for key, value := range mapping {
+		index := -1
+		switch key {
+		case "secs", "sec":
+			index = 0
+		case "nsecs", "nsec":
+			index = 1
+		default:
+			return 0, 0, errors.New("unknown key " + key + ", expected secs and nsecs")
+		}
+		scalar, err := yamlScalarValue(value)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, key)
+		}
+		if index == 0 && !isTime {
+			part, err := strconv.ParseInt(scalar.value, 0, 32)
+			if err != nil {
+				return 0, 0, errors.Wrap(err, key)
+			}
+			parts[index] = uint32(int32(part))
+			continue
+		}
+		part, err := strconv.ParseUint(scalar.value, 0, 32)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, key)
+		}
+		parts[index] = uint32(part)
+	}
+	return parts[0], parts[1], nil
+}
+
+// yamlIntoValue stores a parsed YAML node into a field of a generated message struct.
+func yamlIntoValue(node interface{}, target reflect.Value) error {
+	if node == nil {
+		return nil // Missing values are zeroed.
+	}
+
+	switch target.Type() {
+	case timeType, durationType:
+		sec, nsec, err := parseYAMLTemporal(node, target.Type() == timeType)
+		if err != nil {
+			return err
+		}
+		target.FieldByName("Sec").SetUint(uint64(sec))
+		target.FieldByName("NSec").SetUint(uint64(nsec))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.Struct:
+		// Build an index from the message field names to the struct fields, in order.
+		names := make([]string, 0, target.NumField())
+		index := make(map[string]int, target.NumField())
+		for i := 0; i < target.NumField(); i++ {
+			if name, ok := rosmsgFieldName(target.Type().Field(i)); ok {
+				names = append(names, name)
+				index[name] = i
+			}
+		}
+
+		switch n := node.(type) {
+		case map[string]interface{}:
+			for key, value := range n {
+				i, ok := index[key]
+				if !ok {
+					return errors.New("Field: " + key + ": unknown field in " + target.Type().String())
+				}
+				if err := yamlIntoValue(value, target.Field(i)); err != nil {
+					return errors.Wrap(err, "Field: "+key)
+				}
+			}
+		case []interface{}:
+			// Messages may be given as a sequence of their field values, as rostopic pub allows.
+			if len(n) != len(names) {
+				return errors.New("expected " + strconv.Itoa(len(names)) + " field values for " + target.Type().String() + ", got " + strconv.Itoa(len(n)))
+			}
+			for i, value := range n {
+				if err := yamlIntoValue(value, target.Field(index[names[i]])); err != nil {
+					return errors.Wrap(err, "Field: "+names[i])
+				}
+			}
+		default:
+			return errors.New("expected a mapping for " + target.Type().String() + ", got " + describeYAMLNode(node))
+		}
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		// uint8 arrays may also be given as strings of bytes.
+		if scalar, ok := node.(yamlScalar); ok && scalar.quoted && target.Type().Elem().Kind() == reflect.Uint8 {
+			node = bytesToYAMLSequence([]byte(scalar.value))
+		}
+		sequence, ok := node.([]interface{})
+		if !ok {
+			return errors.New("expected a sequence, got " + describeYAMLNode(node))
+		}
+		if target.Kind() == reflect.Slice {
+			target.Set(reflect.MakeSlice(target.Type(), len(sequence), len(sequence)))
+		} else if target.Len() != len(sequence) {
+			return errors.New("expected array of length " + strconv.Itoa(target.Len()) + ", got " + strconv.Itoa(len(sequence)))
+		}
+		for i, value := range sequence {
+			if err := yamlIntoValue(value, target.Index(i)); err != nil {
+				return errors.Wrap(err, "index "+strconv.Itoa(i))
+			}
+		}
+		return nil
+	}
+
+	scalar, err := yamlScalarValue(node)
+	if err != nil {
+		return err
+	}
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(scalar.value)
+	case reflect.Bool:
+		b, err := parseYAMLBool(scalar.value)
+		if err != nil {
+			return err
+		}
+		target.SetBool(b)
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(scalar.value, 0, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetInt(i)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(scalar.value, 0, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := parseYAMLFloat(scalar.value)
+		if err != nil {
+			return err
+		}
+		target.SetFloat(f)
+	default:
+		return errors.New("cannot store yaml in " + target.Type().String())
+	}
+	return nil
+}
+
+// bytesToYAMLSequence converts a byte string into a sequence of scalars, one per byte.
+func bytesToYAMLSequence(data []byte) []interface{} {
+	sequence := make([]interface{}, len(data))
+	for i, b := range data {
+		sequence[i] = yamlScalar{value: strconv.Itoa(int(b))}
+	}
+	return sequence
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// fromYAML replaces the data of a DynamicMessage with a parsed YAML node; fields which aren't given are zeroed.
+func (m *DynamicMessage) fromYAML(node interface{}) error {
+	data, err := m.dynamicType.zeroValueData()
+	if err != nil {
+		return err
+	}
+
+	fields := m.dynamicType.spec.Fields
+	switch n := node.(type) {
+	case nil:
+	case map[string]interface{}:
+		for key, value := range n {
+			field, err := m.dynamicType.getFieldByName(key)
+			if err != nil {
+				return errors.Wrap(err, "Field: "+key)
+			}
+			if value == nil {
+				continue
+			}
+			if data[key], err = m.dynamicType.yamlToDynamic(field, value); err != nil {
+				return errors.Wrap(err, "Field: "+key)
+			}
+		}
+	case []interface{}:
+		// Messages may be given as a sequence of their field values, as rostopic pub allows.
+		if len(n) != len(fields) {
+			return errors.New("expected " + strconv.Itoa(len(fields)) + " field values for " + m.dynamicType.Name() + ", got " + strconv.Itoa(len(n)))
+		}
+		for i, value := range n {
+			if value == nil {
+				continue
+			}
+			if data[fields[i].Name], err = m.dynamicType.yamlToDynamic(&fields[i], value); err != nil {
+				return errors.Wrap(err, "Field: "+fields[i].Name)
+			}
+		}
+	default:
+		return errors.New("expected a mapping for " + m.dynamicType.Name() + ", got " + describeYAMLNode(node))
+	}
+
+	m.data = data
+	m.raw = nil
+	m.rawOffsets = nil
+	return nil
+}
+
+// yamlToDynamic converts a parsed YAML node into the representation DynamicMessage uses for the field.
+func (t *DynamicMessageType) yamlToDynamic(field *libgengo.Field, node interface{}) (interface{}, error) {
+	if !field.IsArray {
+		return t.yamlToDynamicElement(field, node)
+	}
+
+	if scalar, ok := node.(yamlScalar); ok && scalar.quoted && field.GoType == "uint8" {
+		node = bytesToYAMLSequence([]byte(scalar.value))
+	}
+	sequence, ok := node.([]interface{})
+	if !ok {
+		return nil, errors.New("expected a sequence, got " + describeYAMLNode(node))
+	}
+	elements := make([]interface{}, len(sequence))
+	for i, value := range sequence {
+		element, err := t.yamlToDynamicElement(field, value)
+		if err != nil {
+			return nil, errors.Wrap(err, "index "+strconv.Itoa(i))
+		}
+		elements[i] = element
+	}
+	return t.coerceArrayValue(field, elements)
+}
+
+// yamlToDynamicElement converts a parsed YAML node into the representation DynamicMessage uses for a single element of the field.
+func (t *DynamicMessageType) yamlToDynamicElement(field *libgengo.Field, node interface{}) (interface{}, error) {
+	if !field.IsBuiltin {
+		msgType, err := t.getNestedTypeFromField(field)
+		if err != nil {
+			return nil, err
+		}
+		msg := msgType.NewDynamicMessage()
+		if err := msg.fromYAML(node); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	switch field.GoType {
+	case "ros.Time":
+		sec, nsec, err := parseYAMLTemporal(node, true)
+		return NewTime(sec, nsec), err
+	case "ros.Duration":
+		sec, nsec, err := parseYAMLTemporal(node, false)
+		return NewDuration(sec, nsec), err
+	}
+
+	if node == nil {
+		zero, err := t.zeroValueArray(field, 1)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(zero).Index(0).Interface(), nil
+	}
+	scalar, err := yamlScalarValue(node)
+	if err != nil {
+		return nil, err
+	}
+	switch field.GoType {
+	case "string":
+		return scalar.value, nil
+	case "bool":
+		return parseYAMLBool(scalar.value)
+	case "float32", "float64":
+		f, err := parseYAMLFloat(scalar.value)
+		if err != nil {
+			return nil, err
+		}
+		return t.coerceSingularValue(field, f)
+	}
+
+	// Integers, which may also be given by constant name.
+	if i, err := strconv.ParseInt(scalar.value, 0, 64); err == nil {
+		return t.coerceSingularValue(field, i)
+	}
+	if u, err := strconv.ParseUint(scalar.value, 0, 64); err == nil {
+		return t.coerceSingularValue(field, u)
+	}
+	return t.coerceSingularValue(field, scalar.value)
+}
+
+// ALL DONE.
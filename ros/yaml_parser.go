@@ -0,0 +1,451 @@
+package ros
+
+// IMPORT REQUIRED PACKAGES.
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DEFINE PUBLIC STRUCTURES.
+
+// DEFINE PRIVATE STRUCTURES.
+
+// yamlScalar is a scalar parsed from YAML.  Scalars are kept as text until they are stored in a field, so that their type is decided by the message schema rather than by YAML's
+// implicit typing; e.g. `frame_id: 123` is the string "123".
+type yamlScalar struct {
+	value  string
+	quoted bool
+}
+
+// yamlLine is a non-blank line of a YAML document with its comment removed.
+type yamlLine struct {
+	number  int
+	indent  int
+	content string
+}
+
+// yamlParser parses the subset of YAML produced by `rostopic echo` and accepted by `rostopic pub`: block mappings and sequences, flow mappings and sequences, and plain, single
+// quoted and double quoted scalars.  Mappings are parsed as map[string]interface{}, sequences as []interface{}, scalars as yamlScalar and empty values as nil.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// DEFINE PUBLIC GLOBALS.
+
+// DEFINE PRIVATE GLOBALS.
+
+// DEFINE PUBLIC STATIC FUNCTIONS.
+
+// DEFINE PUBLIC RECEIVER FUNCTIONS.
+
+// DEFINE PRIVATE STATIC FUNCTIONS.
+
+// parseYAML parses a single YAML document; document markers (`---` and `...`) are ignored.
+func parseYAML(text string) (interface{}, error) {
+	p := &yamlParser{}
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(stripYAMLComment(line), " \t\r")
+		content := strings.TrimLeft(line, " ")
+		if content == "" || content == "---" || content == "..." {
+			continue
+		}
+		if strings.HasPrefix(content, "\t") {
+			return nil, errors.New("yaml line " + strconv.Itoa(i+1) + ": tabs may not be used for indentation")
+		}
+		p.lines = append(p.lines, yamlLine{number: i + 1, indent: len(line) - len(content), content: content})
+	}
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+
+	// A document which is a single flow collection may be split over several lines.
+	first := p.lines[0].content
+	if first[0] == '{' || first[0] == '[' {
+		parts := make([]string, len(p.lines))
+		for i, line := range p.lines {
+			parts[i] = line.content
+		}
+		return parseYAMLFlowDocument(strings.Join(parts, " "))
+	}
+
+	node, err := p.parseBlock(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, p.errorf("unexpected content " + strconv.Quote(p.lines[p.pos].content))
+	}
+	return node, nil
+}
+
+// parseYAMLFlowDocument parses a complete flow value, checking that nothing follows it.
+func parseYAMLFlowDocument(text string) (interface{}, error) {
+	node, pos, err := parseYAMLFlowValue(text, 0)
+	if err != nil {
+		return nil, err
+	}
+	if rest := strings.TrimSpace(text[pos:]); rest != "" {
+		return nil, errors.New("yaml: unexpected content after flow value " + strconv.Quote(rest))
+	}
+	return node, nil
+}
+
+// stripYAMLComment removes a trailing comment from a line, ignoring '#' within quoted scalars.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '\'' && c == '\'':
+			if i+1 < len(line) && line[i+1] == '\'' {
+				i++
+			} else {
+				quote = 0
+			}
+		case quote == '"' && c == '\\':
+			i++
+		case quote == '"' && c == '"':
+			quote = 0
+		case quote != 0:
+		case c == '\'' || c == '"':
+			if i == 0 || strings.ContainsRune(" \t[{,:", rune(line[i-1])) {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// splitYAMLKey splits the content of a block mapping line into its key and the rest of the line.  The key must be followed by ':' and then a space or the end of the line.
+func splitYAMLKey(content string) (key string, rest string, ok bool) {
+	if content == "" {
+		return "", "", false
+	}
+
+	// Quoted keys.
+	if content[0] == '"' || content[0] == '\'' {
+		scalar, pos, err := parseYAMLQuoted(content, 0)
+		if err != nil || pos >= len(content) || content[pos] != ':' || (pos+1 < len(content) && content[pos+1] != ' ') {
+			return "", "", false
+		}
+		return scalar.value, strings.TrimSpace(content[pos+1:]), true
+	}
+
+	// Flow collections and sequence entries aren't keys.
+	if strings.ContainsRune("[{-", rune(content[0])) && (content[0] != '-' || len(content) == 1 || content[1] == ' ') {
+		return "", "", false
+	}
+	for i := 0; i < len(content); i++ {
+		if content[i] == ':' && (i+1 == len(content) || content[i+1] == ' ') {
+			return strings.TrimSpace(content[:i]), strings.TrimSpace(content[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// isYAMLSequenceEntry reports whether a line starts an entry of a block sequence.
+func isYAMLSequenceEntry(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+// parseYAMLInline parses the value following a key or sequence entry on the same line.
+func parseYAMLInline(text string) (interface{}, error) {
+	if text == "" {
+		return nil, nil
+	}
+	switch text[0] {
+	case '[', '{', '"', '\'':
+		return parseYAMLFlowDocument(text)
+	}
+	return plainYAMLScalar(text), nil
+}
+
+// plainYAMLScalar creates an unquoted scalar, where `~` and `null` denote empty values.
+func plainYAMLScalar(text string) interface{} {
+	if text == "~" || text == "null" || text == "Null" || text == "NULL" {
+		return nil
+	}
+	return yamlScalar{value: text}
+}
+
+// parseYAMLFlowValue parses a flow value (a flow mapping, flow sequence or scalar) starting at pos, returning the position following it.
+func parseYAMLFlowValue(text string, pos int) (interface{}, int, error) {
+	pos = skipYAMLSpaces(text, pos)
+	if pos >= len(text) {
+		return nil, pos, nil
+	}
+
+	switch text[pos] {
+	case '[':
+		sequence := make([]interface{}, 0)
+		pos = skipYAMLSpaces(text, pos+1)
+		if pos < len(text) && text[pos] == ']' {
+			return sequence, pos + 1, nil
+		}
+		for {
+			value, next, err := parseYAMLFlowValue(text, pos)
+			if err != nil {
+				return nil, next, err
+			}
+			sequence = append(sequence, value)
+			pos = skipYAMLSpaces(text, next)
+			if pos >= len(text) {
+				return nil, pos, errors.New("yaml: unterminated flow sequence")
+			}
+			if text[pos] == ']' {
+				return sequence, pos + 1, nil
+			}
+			if text[pos] != ',' {
+				return nil, pos, errors.New("yaml: expected ',' or ']' in flow sequence at " + strconv.Quote(text[pos:]))
+			}
+			pos++
+		}
+
+	case '{':
+		mapping := make(map[string]interface{})
+		pos = skipYAMLSpaces(text, pos+1)
+		if pos < len(text) && text[pos] == '}' {
+			return mapping, pos + 1, nil
+		}
+		for {
+			key, next, err := parseYAMLFlowKey(text, pos)
+			if err != nil {
+				return nil, next, err
+			}
+			value, next, err := parseYAMLFlowValue(text, next)
+			if err != nil {
+				return nil, next, err
+			}
+			if _, ok := mapping[key]; ok {
+				return nil, next, errors.New("yaml: duplicate key " + strconv.Quote(key))
+			}
+			mapping[key] = value
+			pos = skipYAMLSpaces(text, next)
+			if pos >= len(text) {
+				return nil, pos, errors.New("yaml: unterminated flow mapping")
+			}
+			if text[pos] == '}' {
+				return mapping, pos + 1, nil
+			}
+			if text[pos] != ',' {
+				return nil, pos, errors.New("yaml: expected ',' or '}' in flow mapping at " + strconv.Quote(text[pos:]))
+			}
+			pos++
+		}
+
+	case '"', '\'':
+		scalar, next, err := parseYAMLQuoted(text, pos)
+		return scalar, next, err
+	}
+
+	// A plain scalar runs to the next flow indicator.
+	end := pos
+	for end < len(text) && !strings.ContainsRune(",]}", rune(text[end])) {
+		end++
+	}
+	return plainYAMLScalar(strings.TrimSpace(text[pos:end])), end, nil
+}
+
+// parseYAMLFlowKey parses the key of a flow mapping entry, returning the position following the ':'.
+func parseYAMLFlowKey(text string, pos int) (string, int, error) {
+	pos = skipYAMLSpaces(text, pos)
+	var key string
+	if pos < len(text) && (text[pos] == '"' || text[pos] == '\'') {
+		scalar, next, err := parseYAMLQuoted(text, pos)
+		if err != nil {
+			return "", next, err
+		}
+		key = scalar.value
+		pos = skipYAMLSpaces(text, next)
+	} else {
+		end := pos
+		for end < len(text) && !strings.ContainsRune(":,]}", rune(text[end])) {
+			end++
+		}
+		key = strings.TrimSpace(text[pos:end])
+		pos = end
+	}
+	if pos >= len(text) || text[pos] != ':' || key == "" {
+		return "", pos, errors.New("yaml: expected 'key: value' in flow mapping at " + strconv.Quote(text[pos:]))
+	}
+	return key, pos + 1, nil
+}
+
+// parseYAMLQuoted parses a single or double quoted scalar starting at pos, returning the position following the closing quote.
+func parseYAMLQuoted(text string, pos int) (yamlScalar, int, error) {
+	quote := text[pos]
+	if quote == '\'' {
+		var value strings.Builder
+		for i := pos + 1; i < len(text); i++ {
+			if text[i] != '\'' {
+				value.WriteByte(text[i])
+				continue
+			}
+			if i+1 < len(text) && text[i+1] == '\'' {
+				value.WriteByte('\'')
+				i++
+				continue
+			}
+			return yamlScalar{value: value.String(), quoted: true}, i + 1, nil
+		}
+		return yamlScalar{}, len(text), errors.New("yaml: unterminated single quoted string")
+	}
+
+	for i := pos + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(text[pos : i+1])
+			if err != nil {
+				return yamlScalar{}, i + 1, errors.Wrap(err, "yaml: invalid double quoted string "+text[pos:i+1])
+			}
+			return yamlScalar{value: value, quoted: true}, i + 1, nil
+		}
+	}
+	return yamlScalar{}, len(text), errors.New("yaml: unterminated double quoted string")
+}
+
+// skipYAMLSpaces returns the position of the next character which isn't a space.
+func skipYAMLSpaces(text string, pos int) int {
+	for pos < len(text) && (text[pos] == ' ' || text[pos] == '\t') {
+		pos++
+	}
+	return pos
+}
+
+// DEFINE PRIVATE RECEIVER FUNCTIONS.
+
+// errorf reports an error at the current line.
+func (p *yamlParser) errorf(message string) error {
+	if p.pos < len(p.lines) {
+		return errors.New("yaml line " + strconv.Itoa(p.lines[p.pos].number) + ": " + message)
+	}
+	return errors.New("yaml: " + message)
+}
+
+// parseBlock parses the block mapping or block sequence at the specified indent.
+func (p *yamlParser) parseBlock(indent int) (interface{}, error) {
+	if isYAMLSequenceEntry(p.lines[p.pos].content) {
+		return p.parseSequence(indent)
+	}
+	if _, _, ok := splitYAMLKey(p.lines[p.pos].content); ok {
+		return p.parseMapping(indent)
+	}
+
+	// A lone scalar or flow value, possibly continued over several lines.
+	line := p.lines[p.pos]
+	p.pos++
+	return p.parseInlineValue(line.content, indent)
+}
+
+// parseInlineValue parses the value on a line; flow collections may continue onto following lines indented by more than indent.
+func (p *yamlParser) parseInlineValue(text string, indent int) (interface{}, error) {
+	if text != "" && (text[0] == '[' || text[0] == '{') {
+		for !yamlFlowBalanced(text) && p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+			text += " " + p.lines[p.pos].content
+			p.pos++
+		}
+	}
+	return parseYAMLInline(text)
+}
+
+// parseMapping parses a block mapping whose keys are at the specified indent.
+func (p *yamlParser) parseMapping(indent int) (interface{}, error) {
+	mapping := make(map[string]interface{})
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		key, rest, ok := splitYAMLKey(p.lines[p.pos].content)
+		if !ok {
+			return nil, p.errorf("expected 'key: value', got " + strconv.Quote(p.lines[p.pos].content))
+		}
+		if _, ok := mapping[key]; ok {
+			return nil, p.errorf("duplicate key " + strconv.Quote(key))
+		}
+		p.pos++
+
+		var value interface{}
+		var err error
+		if rest != "" {
+			value, err = p.parseInlineValue(rest, indent)
+		} else if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+			value, err = p.parseBlock(p.lines[p.pos].indent)
+		} else if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSequenceEntry(p.lines[p.pos].content) {
+			// Sequences may be indented at the same level as their key.
+			value, err = p.parseSequence(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+	}
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return mapping, nil
+}
+
+// parseSequence parses a block sequence whose entries are at the specified indent.
+func (p *yamlParser) parseSequence(indent int) (interface{}, error) {
+	sequence := make([]interface{}, 0)
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSequenceEntry(p.lines[p.pos].content) {
+		line := p.lines[p.pos]
+		rest := strings.TrimLeft(line.content[1:], " ")
+
+		var value interface{}
+		var err error
+		if rest == "" {
+			p.pos++
+			if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+				value, err = p.parseBlock(p.lines[p.pos].indent)
+			}
+		} else if _, _, ok := splitYAMLKey(rest); ok || isYAMLSequenceEntry(rest) {
+			// A compact nested collection, e.g. `- x: 1.0`, continues at the indent of its first entry.
+			p.lines[p.pos] = yamlLine{number: line.number, indent: line.indent + len(line.content) - len(rest), content: rest}
+			value, err = p.parseBlock(p.lines[p.pos].indent)
+		} else {
+			p.pos++
+			value, err = p.parseInlineValue(rest, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sequence = append(sequence, value)
+	}
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return sequence, nil
+}
+
+// yamlFlowBalanced reports whether the brackets and braces of a flow value are balanced, ignoring those within quoted scalars.
+func yamlFlowBalanced(text string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// ALL DONE.
@@ -0,0 +1,240 @@
+package ros
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+// expectedPathYAML is the `rostopic echo` output for the message created by newYAMLTestMessage(); note that genpy leaves a trailing space after the keys of nested
+// messages.
+const expectedPathYAML = "" +
+	"stamp: \n" +
+	"  secs: 1\n" +
+	"  nsecs: 2\n" +
+	"period: \n" +
+	"  secs: 3\n" +
+	"  nsecs: 0\n" +
+	"frame_id: \"map\"\n" +
+	"pose: \n" +
+	"  position: \n" +
+	"    x: 1.0\n" +
+	"    y: -2.5\n" +
+	"    z: 1e-05\n" +
+	"  id: 4\n" +
+	"points: \n" +
+	"  - \n" +
+	"    x: 0.0\n" +
+	"    y: 0.0\n" +
+	"    z: 1e+16\n" +
+	"  - \n" +
+	"    x: nan\n" +
+	"    y: 0.0\n" +
+	"    z: 0.0\n" +
+	"counts: [1, 2, 3]\n" +
+	"scale: 0.10000000149011612\n"
+
+func newYAMLTestMessage(t *testing.T, msgType *DynamicMessageType) *DynamicMessage {
+	msg, err := msgType.NewDynamicMessageFromMap(map[string]interface{}{
+		"stamp":    NewTime(1, 2),
+		"period":   NewDuration(3, 0),
+		"frame_id": "map",
+		"pose":     map[string]interface{}{"position": map[string]interface{}{"x": 1, "y": -2.5, "z": 0.00001}, "id": 4},
+		"points":   []interface{}{map[string]interface{}{"z": 1e16}, map[string]interface{}{"x": math.NaN()}},
+		"counts":   []interface{}{1, 2, 3},
+		"scale":    float32(0.1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestYAML_MarshalDynamicMessage(t *testing.T) {
+	msgType := newPathTestType(t)
+	msg := newYAMLTestMessage(t, msgType)
+
+	buf, err := MarshalYAML(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != expectedPathYAML {
+		t.Fatalf("unexpected yaml:\n%s\nexpected:\n%s", buf, expectedPathYAML)
+	}
+
+	// And back again.
+	result := msgType.NewDynamicMessage()
+	if err := UnmarshalYAML(buf, result); err != nil {
+		t.Fatal(err)
+	}
+	if diff, err := msg.Diff(result, WithNaNEqual()); err != nil || len(diff) != 0 {
+		t.Fatalf("round trip differs: %v, %v", diff, err)
+	}
+}
+
+func TestYAML_GeneratedMessage(t *testing.T) {
+	msgType := newPathTestType(t)
+	msg := newYAMLTestMessage(t, msgType)
+	generated := &testPath{msgType: msgType}
+	if err := msg.Into(generated); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := MarshalYAML(generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != expectedPathYAML {
+		t.Fatalf("unexpected yaml:\n%s\nexpected:\n%s", buf, expectedPathYAML)
+	}
+
+	result := &testPath{msgType: msgType}
+	if err := UnmarshalYAML(buf, result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Stamp != generated.Stamp || result.Pose != generated.Pose || result.Counts != generated.Counts ||
+		len(result.Points) != 2 || result.Points[0] != generated.Points[0] || !math.IsNaN(result.Points[1].X) || result.Scale != generated.Scale {
+		t.Fatalf("round trip mismatch: expected %+v, got %+v", generated, result)
+	}
+}
+
+func TestYAML_NegativeDuration(t *testing.T) {
+	msgType := newPathTestType(t)
+	msg := newYAMLTestMessage(t, msgType)
+	negative := int32(-3)
+	if err := msg.Set("period", NewDuration(uint32(negative), 500000000)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Duration seconds are signed, as rostopic prints them, while time seconds aren't.
+	buf, err := MarshalYAML(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Replace(expectedPathYAML, "period: \n  secs: 3\n  nsecs: 0\n", "period: \n  secs: -3\n  nsecs: 500000000\n", 1)
+	if string(buf) != expected {
+		t.Fatalf("unexpected yaml:\n%s\nexpected:\n%s", buf, expected)
+	}
+
+	result := msgType.NewDynamicMessage()
+	if err := UnmarshalYAML(buf, result); err != nil {
+		t.Fatal(err)
+	}
+	if diff, err := msg.Diff(result, WithNaNEqual()); err != nil || len(diff) != 0 {
+		t.Fatalf("round trip differs: %v, %v", diff, err)
+	}
+
+	if err := UnmarshalYAML([]byte("stamp: {secs: -3, nsecs: 0}"), msgType.NewDynamicMessage()); err == nil {
+		t.Fatal("expected error for negative time")
+	}
+}
+
+func TestYAML_UnmarshalRostopicPubStyle(t *testing.T) {
+	msgType := newPathTestType(t)
+
+	testCases := []struct {
+		yaml  string
+		check func(m *DynamicMessage) bool
+	}{
+		{`{frame_id: base_link, pose: {position: {x: 1.5}, id: 7}}`, func(m *DynamicMessage) bool {
+			x, _ := m.GetFloat64("pose.position.x")
+			id, _ := m.GetInt64("pose.id")
+			s, _ := m.GetString("frame_id")
+			return x == 1.5 && id == 7 && s == "base_link"
+		}},
+		{"frame_id: 123 # a comment\ncounts: [0x10, 2, 3]\npoints:\n- x: 1.0\n  y: 2.0\n- [3.0, 4.0, 5.0]\n", func(m *DynamicMessage) bool {
+			s, _ := m.GetString("frame_id")
+			c, _ := m.GetInt64("counts[0]")
+			y, _ := m.GetFloat64("points[0].y")
+			z, _ := m.GetFloat64("points[1].z")
+			return s == "123" && c == 16 && y == 2 && z == 5
+		}},
+		{"stamp: now\nframe_id: 'it''s'\nscale: -inf", func(m *DynamicMessage) bool {
+			stamp, _ := m.GetTime("stamp")
+			s, _ := m.GetString("frame_id")
+			f, _ := m.GetFloat64("scale")
+			return !stamp.IsZero() && s == "it's" && math.IsInf(f, -1)
+		}},
+		{"{points: [\n  {x: 1},\n  {x: 2}]}", func(m *DynamicMessage) bool {
+			x, _ := m.GetFloat64("points[1].x")
+			return x == 2
+		}},
+	}
+	for _, testCase := range testCases {
+		msg := msgType.NewDynamicMessage()
+		if err := UnmarshalYAML([]byte(testCase.yaml), msg); err != nil {
+			t.Errorf("%q: %v", testCase.yaml, err)
+			continue
+		}
+		if !testCase.check(msg) {
+			t.Errorf("%q: unexpected result %v", testCase.yaml, msg)
+		}
+	}
+
+	errorCases := []string{
+		"unknown: 1",
+		"counts: [1, 2]",
+		"pose: {id: 256}",
+		"pose: {id: [1]}",
+		"frame_id: \"unterminated",
+		"stamp: {secs: 1, hours: 2}",
+	}
+	for _, text := range errorCases {
+		if err := UnmarshalYAML([]byte(text), msgType.NewDynamicMessage()); err == nil {
+			t.Errorf("%q: expected error", text)
+		}
+	}
+}
+
+func TestYAML_EncoderDecoder(t *testing.T) {
+	msgType := newPathTestType(t)
+
+	var buf bytes.Buffer
+	encoder := NewYAMLEncoder(&buf)
+	for _, frame := range []string{"a", "b"} {
+		msg := msgType.NewDynamicMessage()
+		if err := msg.Set("frame_id", frame); err != nil {
+			t.Fatal(err)
+		}
+		if err := encoder.Encode(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if strings.Count(buf.String(), "\n---\n") != 2 {
+		t.Fatalf("expected two separated messages, got:\n%s", buf.String())
+	}
+
+	decoder := NewYAMLDecoder(&buf)
+	for _, frame := range []string{"a", "b"} {
+		msg := msgType.NewDynamicMessage()
+		if err := decoder.Decode(msg); err != nil {
+			t.Fatal(err)
+		}
+		if s, _ := msg.GetString("frame_id"); s != frame {
+			t.Fatalf("expected %s, got %s", frame, s)
+		}
+	}
+	if err := decoder.Decode(msgType.NewDynamicMessage()); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestYAML_FormatPython(t *testing.T) {
+	floats := map[float64]string{
+		0: "0.0", 1: "1.0", -0.5: "-0.5", 0.0001: "0.0001", 0.00001: "1e-05", 1e15: "1000000000000000.0", 1e16: "1e+16", 123.456: "123.456",
+	}
+	for f, expected := range floats {
+		if text := formatPythonFloat(f); text != expected {
+			t.Errorf("formatPythonFloat(%v): expected %s, got %s", f, expected, text)
+		}
+	}
+
+	strs := map[string]string{"a": "'a'", "it's": "\"it's\"", "'\"": "'\\'\"'", "a\nb": "'a\\nb'"}
+	for s, expected := range strs {
+		if text := formatPythonString(s); text != expected {
+			t.Errorf("formatPythonString(%q): expected %s, got %s", s, expected, text)
+		}
+	}
+}